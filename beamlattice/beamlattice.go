@@ -7,6 +7,7 @@ import (
 	"errors"
 
 	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
 	"github.com/hpinc/go3mf/spec"
 )
 
@@ -29,6 +30,15 @@ var (
 
 func init() {
 	spec.Register(Namespace, Spec{})
+	for _, r := range []specerr.Rule{
+		{ID: "beamlattice.object.type", Severity: specerr.SeverityError, Chapter: "2", Err: ErrLatticeObjType},
+		{ID: "beamlattice.clipping.no-mesh", Severity: specerr.SeverityError, Chapter: "2", Err: ErrLatticeClippedNoMesh},
+		{ID: "beamlattice.clipping.invalid-mesh", Severity: specerr.SeverityError, Chapter: "2", Err: ErrLatticeInvalidMesh},
+		{ID: "beamlattice.beam.same-vertex", Severity: specerr.SeverityError, Chapter: "2", Err: ErrLatticeSameVertex},
+		{ID: "beamlattice.beam.r2", Severity: specerr.SeverityError, Chapter: "2", Err: ErrLatticeBeamR2},
+	} {
+		specerr.RegisterRule(r)
+	}
 }
 
 type Spec struct{}
@@ -0,0 +1,11 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package beamlattice
+
+//go:generate go run ../internal/cmd/xsdgen -pkg beamlattice -const schema -o schema_gen.go schemas/beamlattice.xsd
+
+// Schema returns the XSD of the Beam Lattice extension.
+func (Spec) Schema() []byte {
+	return []byte(schema)
+}
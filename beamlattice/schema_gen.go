@@ -0,0 +1,111 @@
+// Code generated by xsdgen from schemas/beamlattice.xsd. DO NOT EDIT.
+
+package beamlattice
+
+// schema is the content of schemas/beamlattice.xsd.
+const schema = `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns="http://schemas.microsoft.com/3dmanufacturing/beamlattice/2017/02" xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://schemas.microsoft.com/3dmanufacturing/beamlattice/2017/02" elementFormDefault="unqualified" attributeFormDefault="unqualified" blockDefault="#all">
+	<!-- Complex Types -->
+	<xs:complexType name="CT_BeamLattice">
+		<xs:sequence>
+			<xs:element ref="beams"/>
+			<xs:element ref="beamsets" minOccurs="0"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="minlength" type="ST_PositiveNumber" use="required"/>
+		<xs:attribute name="radius" type="ST_PositiveNumber" use="required"/>
+		<xs:attribute name="clippingmode" type="ST_ClippingMode" default="none"/>
+		<xs:attribute name="clippingmesh" type="ST_ResourceID"/>
+		<xs:attribute name="representationmesh" type="ST_ResourceID"/>
+		<xs:attribute name="cap" type="ST_CapMode" default="sphere"/>
+		<xs:attribute name="precision" type="ST_Number"/>
+		<xs:attribute name="clipping" type="ST_ClippingMode"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Beams">
+		<xs:sequence>
+			<xs:element ref="beam" minOccurs="0" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Beam">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="v1" type="ST_ResourceIndex" use="required"/>
+		<xs:attribute name="v2" type="ST_ResourceIndex" use="required"/>
+		<xs:attribute name="r1" type="ST_PositiveNumber"/>
+		<xs:attribute name="r2" type="ST_PositiveNumber"/>
+		<xs:attribute name="cap1" type="ST_CapMode"/>
+		<xs:attribute name="cap2" type="ST_CapMode"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_BeamSets">
+		<xs:sequence>
+			<xs:element ref="beamset" minOccurs="0" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_BeamSet">
+		<xs:sequence>
+			<xs:element ref="ref" minOccurs="0" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="name" type="xs:string"/>
+		<xs:attribute name="identifier" type="xs:string"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Ref">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="index" type="ST_ResourceIndex" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<!-- Simple Types -->
+	<xs:simpleType name="ST_ClippingMode">
+		<xs:restriction base="xs:string">
+			<xs:enumeration value="none"/>
+			<xs:enumeration value="inside"/>
+			<xs:enumeration value="outside"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_CapMode">
+		<xs:restriction base="xs:string">
+			<xs:enumeration value="sphere"/>
+			<xs:enumeration value="hemisphere"/>
+			<xs:enumeration value="butt"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_PositiveNumber">
+		<xs:restriction base="ST_Number">
+			<xs:minExclusive value="0"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_Number">
+		<xs:restriction base="xs:double">
+			<xs:whiteSpace value="collapse"/>
+			<xs:pattern value="((\-|\+)?(([0-9]+(\.[0-9]+)?)|(\.[0-9]+))((e|E)(\-|\+)?[0-9]+)?)"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_ResourceID">
+		<xs:restriction base="xs:positiveInteger">
+			<xs:maxExclusive value="2147483648"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_ResourceIndex">
+		<xs:restriction base="xs:nonNegativeInteger">
+			<xs:maxExclusive value="2147483648"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<!-- Elements -->
+	<xs:element name="beamlattice" type="CT_BeamLattice"/>
+	<xs:element name="beams" type="CT_Beams"/>
+	<xs:element name="beam" type="CT_Beam"/>
+	<xs:element name="beamsets" type="CT_BeamSets"/>
+	<xs:element name="beamset" type="CT_BeamSet"/>
+	<xs:element name="ref" type="CT_Ref"/>
+</xs:schema>
+`
@@ -0,0 +1,76 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package beamlattice
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func TestSpec_Schema(t *testing.T) {
+	newMesh := func() *go3mf.Mesh {
+		return &go3mf.Mesh{
+			Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {10, 0, 0}, {0, 10, 0}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+		}
+	}
+	withLattice := func(b *BeamLattice) *go3mf.Mesh {
+		m := newMesh()
+		m.Any = spec.Any{b}
+		return m
+	}
+	tests := []struct {
+		name  string
+		model *go3mf.Model
+		want  []string
+	}{
+		{"valid", &go3mf.Model{Resources: go3mf.Resources{Objects: []*go3mf.Object{{ID: 1, Mesh: newMesh()}, {ID: 2, Mesh: withLattice(&BeamLattice{
+			MinLength: 0.0001, Radius: 1, ClipMode: ClipInside, ClippingMeshID: 1, CapMode: CapModeButt,
+			Beams:    Beams{Beam: []Beam{{Indices: [2]uint32{0, 1}, Radius: [2]float32{1.5, 1.6}, CapMode: [2]CapMode{CapModeSphere, CapModeButt}}}},
+			BeamSets: BeamSets{BeamSet: []BeamSet{{Name: "test", Identifier: "set_id", Refs: []uint32{0}}}},
+		})}}}}, nil},
+		{"invalid", &go3mf.Model{Resources: go3mf.Resources{Objects: []*go3mf.Object{{ID: 1, Mesh: withLattice(&BeamLattice{
+			MinLength: 0.0001, Beams: Beams{Beam: []Beam{{Indices: [2]uint32{0, 1}}}},
+		})}}}}, []string{
+			fmt.Sprintf(`go3mf: Path: /3D/3dmodel.model Line: 2 XPath: /model/resources/object[0]/mesh/beamlattice[0]: %v: radius="0.0000"`, specerr.ErrSchemaAttrValue),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.model.Extensions = []go3mf.Extension{DefaultExtension}
+			var buf bytes.Buffer
+			if err := go3mf.NewEncoder(&buf).Encode(tt.model); err != nil {
+				t.Fatalf("go3mf.Encoder.Encode() error = %v", err)
+			}
+			d := go3mf.NewDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			d.ValidateSchema = true
+			var got []string
+			if err := d.Decode(new(go3mf.Model)); err != nil {
+				for _, err := range err.(*specerr.List).Errors {
+					got = append(got, err.Error())
+				}
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("go3mf.Decoder.Decode() = %v", diff)
+			}
+		})
+	}
+}
+
+func Test_schema(t *testing.T) {
+	b, err := ioutil.ReadFile("schemas/beamlattice.xsd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != schema {
+		t.Error("schema differs from schemas/beamlattice.xsd, run go generate")
+	}
+}
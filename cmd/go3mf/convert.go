@@ -0,0 +1,41 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"flag"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/importer"
+	"github.com/hpinc/go3mf/importer/obj"
+)
+
+func convertFlags(fs *flag.FlagSet) runFunc {
+	return func(args []string, stdout io.Writer) error {
+		if len(args) != 2 {
+			return errUsage
+		}
+		var m go3mf.Model
+		if err := importer.DecodeFile(args[0], &m); err != nil {
+			return err
+		}
+		return encodeFile(args[1], &m)
+	}
+}
+
+// encodeFile encodes m into the file name using the format of its extension.
+// The obj files are written along with their mtl and textures,
+// as the obj format can't be encoded into a single file.
+func encodeFile(name string, m *go3mf.Model) error {
+	ext := filepath.Ext(name)
+	if !strings.EqualFold(ext, ".obj") {
+		return importer.EncodeFile(name, m)
+	}
+	e := obj.NewEncoder(obj.Dir(filepath.Dir(name)))
+	e.Name = strings.TrimSuffix(filepath.Base(name), ext)
+	return e.Encode(m)
+}
@@ -0,0 +1,77 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// errUnsafePath is returned when a part name escapes the output directory.
+var errUnsafePath = errors.New("unsafe part name")
+
+func extractFlags(fs *flag.FlagSet) runFunc {
+	part := fs.String("part", "", "extract only the part with this name, such as /3D/3dmodel.model")
+	return func(args []string, stdout io.Writer) error {
+		if len(args) != 2 {
+			return errUsage
+		}
+		z, err := zip.OpenReader(args[0])
+		if err != nil {
+			return err
+		}
+		defer z.Close()
+		var found bool
+		for _, f := range z.File {
+			if strings.HasSuffix(f.Name, "/") {
+				continue
+			}
+			if *part != "" && !strings.EqualFold(path.Clean("/"+f.Name), path.Clean("/"+*part)) {
+				continue
+			}
+			found = true
+			if err := extractFile(f, args[1]); err != nil {
+				return err
+			}
+			fmt.Fprintln(stdout, "/"+f.Name)
+		}
+		if *part != "" && !found {
+			return fmt.Errorf("part %s not found", *part)
+		}
+		return nil
+	}
+}
+
+// extractFile writes f into dir, keeping its directory structure.
+func extractFile(f *zip.File, dir string) error {
+	name := path.Clean("/" + f.Name)
+	if name != "/"+f.Name {
+		return fmt.Errorf("%w: %s", errUnsafePath, f.Name)
+	}
+	dst := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,97 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/importer"
+)
+
+func infoFlags(fs *flag.FlagSet) runFunc {
+	objects := fs.Bool("objects", true, "list the objects")
+	return func(args []string, stdout io.Writer) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		var m go3mf.Model
+		if err := importer.DecodeFile(args[0], &m); err != nil {
+			return err
+		}
+		return writeInfo(stdout, &m, *objects)
+	}
+}
+
+// writeInfo writes a summary of m, listing its objects if objects is true.
+func writeInfo(stdout io.Writer, m *go3mf.Model, objects bool) error {
+	w := tabwriter.NewWriter(stdout, 0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "units:\t%s\n", m.Units)
+	for _, md := range m.Metadata {
+		fmt.Fprintf(w, "metadata:\t%s = %s\n", md.Name.Local, md.Value)
+	}
+	for _, ext := range m.Extensions {
+		if ext.IsRequired {
+			fmt.Fprintf(w, "extension:\t%s (required)\n", ext.Namespace)
+		} else {
+			fmt.Fprintf(w, "extension:\t%s\n", ext.Namespace)
+		}
+	}
+	var vertices, triangles int
+	type pathObject struct {
+		path string
+		obj  *go3mf.Object
+	}
+	var objs []pathObject
+	for _, obj := range m.Resources.Objects {
+		objs = append(objs, pathObject{m.PathOrDefault(), obj})
+	}
+	paths := make([]string, 0, len(m.Childs))
+	for path := range m.Childs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, obj := range m.Childs[path].Resources.Objects {
+			objs = append(objs, pathObject{path, obj})
+		}
+	}
+	for _, o := range objs {
+		if o.obj.Mesh != nil {
+			vertices += len(o.obj.Mesh.Vertices.Vertex)
+			triangles += len(o.obj.Mesh.Triangles.Triangle)
+		}
+	}
+	fmt.Fprintf(w, "objects:\t%d\n", len(objs))
+	fmt.Fprintf(w, "items:\t%d\n", len(m.Build.Items))
+	fmt.Fprintf(w, "vertices:\t%d\n", vertices)
+	fmt.Fprintf(w, "triangles:\t%d\n", triangles)
+	box := m.BoundingBox()
+	fmt.Fprintf(w, "bounding box:\t%g %g %g - %g %g %g\n",
+		box.Min.X(), box.Min.Y(), box.Min.Z(), box.Max.X(), box.Max.Y(), box.Max.Z())
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !objects || len(objs) == 0 {
+		return nil
+	}
+	fmt.Fprintln(stdout)
+	w = tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tID\tNAME\tTYPE\tVERTICES\tTRIANGLES\tCOMPONENTS")
+	for _, o := range objs {
+		var nv, nt, nc int
+		if o.obj.Mesh != nil {
+			nv, nt = len(o.obj.Mesh.Vertices.Vertex), len(o.obj.Mesh.Triangles.Triangle)
+		}
+		if o.obj.Components != nil {
+			nc = len(o.obj.Components.Component)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%d\t%d\t%d\n", o.path, o.obj.ID, o.obj.Name, o.obj.Type, nv, nt, nc)
+	}
+	return w.Flush()
+}
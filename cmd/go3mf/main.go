@@ -0,0 +1,125 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+// Command go3mf inspects, validates and converts 3MF files.
+//
+// Usage:
+//
+//	go3mf <command> [flags] <args>
+//
+// The commands are:
+//
+//	info      print the units, objects, extensions and bounding box of a model
+//	validate  check that a model is conformant with the 3MF specs
+//	convert   convert a model between 3MF and the importer formats
+//	extract   dump the parts of a 3MF package into a directory
+//	repack    re-encode a 3MF package with the chosen encoder options
+//
+// The exit code is 0 on success, 1 if the model is not valid
+// and 2 if the command could not be completed.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	_ "github.com/hpinc/go3mf/beamlattice"
+	_ "github.com/hpinc/go3mf/importer/amf"
+	_ "github.com/hpinc/go3mf/importer/gltf"
+	_ "github.com/hpinc/go3mf/importer/obj"
+	_ "github.com/hpinc/go3mf/importer/ply"
+	_ "github.com/hpinc/go3mf/importer/stl"
+	_ "github.com/hpinc/go3mf/materials"
+	_ "github.com/hpinc/go3mf/production"
+	_ "github.com/hpinc/go3mf/securecontent"
+	_ "github.com/hpinc/go3mf/slices"
+)
+
+// Exit codes.
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitError   = 2
+)
+
+var (
+	// errInvalid is returned by the commands that found an invalid model.
+	errInvalid = errors.New("invalid model")
+	// errUsage is returned by the commands called with the wrong number of arguments.
+	errUsage = errors.New("invalid arguments")
+)
+
+// runFunc runs a command with the positional arguments.
+type runFunc func(args []string, stdout io.Writer) error
+
+// A command defines its flags in a flag.FlagSet and returns the function that runs it.
+type command struct {
+	name  string
+	args  string
+	short string
+	flags func(fs *flag.FlagSet) runFunc
+}
+
+var commands = []command{
+	{name: "info", args: "<file>", short: "print the units, objects, extensions and bounding box of a model", flags: infoFlags},
+	{name: "validate", args: "<file>", short: "check that a model is conformant with the 3MF specs", flags: validateFlags},
+	{name: "convert", args: "<input> <output>", short: "convert a model between 3MF and the importer formats", flags: convertFlags},
+	{name: "extract", args: "<file.3mf> <dir>", short: "dump the parts of a 3MF package into a directory", flags: extractFlags},
+	{name: "repack", args: "<input.3mf> <output.3mf>", short: "re-encode a 3MF package with the chosen encoder options", flags: repackFlags},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command in args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitError
+	}
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+		fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		fs.Usage = func() {
+			fmt.Fprintf(stderr, "usage: go3mf %s [flags] %s\n", c.name, c.args)
+			fs.PrintDefaults()
+		}
+		runCmd := c.flags(fs)
+		if err := fs.Parse(args[1:]); err != nil {
+			if err == flag.ErrHelp {
+				return exitOK
+			}
+			return exitError
+		}
+		err := runCmd(fs.Args(), stdout)
+		switch {
+		case err == nil:
+			return exitOK
+		case errors.Is(err, errInvalid):
+			return exitInvalid
+		case errors.Is(err, errUsage):
+			fs.Usage()
+		default:
+			fmt.Fprintf(stderr, "go3mf %s: %v\n", c.name, err)
+		}
+		return exitError
+	}
+	fmt.Fprintf(stderr, "go3mf: unknown command %q\n", args[0])
+	usage(stderr)
+	return exitError
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: go3mf <command> [flags] <args>")
+	fmt.Fprintln(w, "\nThe commands are:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "\t%-9s %s\n", c.name, c.short)
+	}
+}
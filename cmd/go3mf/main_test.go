@@ -0,0 +1,231 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hpinc/go3mf"
+)
+
+const testFile = "../../testdata/cube.3mf"
+
+func newTestDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "go3mf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func writeTestFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestKey(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go3mf"},
+		NotBefore:    time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile, certFile := filepath.Join(dir, "key.pem"), filepath.Join(dir, "cert.pem")
+	writeTestFile(t, keyFile, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+	writeTestFile(t, certFile, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	return keyFile, certFile
+}
+
+func Test_run(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	invalid := filepath.Join(dir, "invalid.stl")
+	// A tetrahedron with a flipped face.
+	var stl strings.Builder
+	stl.WriteString("solid a\n")
+	for _, f := range [][3]string{{"0 0 0", "0 1 0", "1 0 0"}, {"0 0 0", "1 0 0", "0 0 1"}, {"0 0 0", "0 0 1", "0 1 0"}, {"1 0 0", "0 0 1", "0 1 0"}} {
+		stl.WriteString("facet normal 0 0 0\nouter loop\nvertex " + f[0] + "\nvertex " + f[1] + "\nvertex " + f[2] + "\nendloop\nendfacet\n")
+	}
+	stl.WriteString("endsolid a\n")
+	writeTestFile(t, invalid, stl.String())
+	unknown := filepath.Join(dir, "model.txt")
+	writeTestFile(t, unknown, "hello world")
+	tests := []struct {
+		name       string
+		args       []string
+		want       int
+		wantStdout []string
+	}{
+		{"empty", nil, exitError, nil},
+		{"unknown", []string{"foo"}, exitError, nil},
+		{"help", []string{"info", "-h"}, exitOK, nil},
+		{"badFlag", []string{"info", "-foo", testFile}, exitError, nil},
+		{"info", []string{"info", testFile}, exitOK, []string{"units:        millimeter", "triangles:    12", "bounding box: 30 30 50 - 130 130 150", "/3D/3dmodel.model  1   Cube  model  8         12         0"}},
+		{"infoNoObjects", []string{"info", "-objects=false", testFile}, exitOK, []string{"objects:      1"}},
+		{"infoUsage", []string{"info"}, exitError, nil},
+		{"infoMissing", []string{"info", filepath.Join(dir, "missing.3mf")}, exitError, nil},
+		{"infoUnknown", []string{"info", unknown}, exitError, nil},
+		{"validate", []string{"validate", testFile}, exitOK, []string{"valid"}},
+		{"validateInvalid", []string{"validate", invalid}, exitInvalid, []string{"1 errors"}},
+		{"validateNoCoherency", []string{"validate", "-coherency=false", invalid}, exitOK, []string{"valid"}},
+		{"validateJSON", []string{"validate", "-format", "json", invalid}, exitInvalid, []string{`"rule": "core.mesh.consistency"`, `"severity": "error"`}},
+		{"validateJUnit", []string{"validate", "-format", "junit", invalid}, exitInvalid, []string{`<testsuite name="` + invalid + `" tests="1" failures="1">`, `<failure message=`}},
+		{"validateJSONValid", []string{"validate", "-format", "json", testFile}, exitOK, []string{`"issues": []`}},
+		{"validateBadFormat", []string{"validate", "-format", "foo", testFile}, exitError, nil},
+		{"validateWarnings", []string{"validate", "-warnings", "-volume", "100,100,100", testFile}, exitOK, []string{"build items SHOULD be inside the build volume", "1 warnings", "valid"}},
+		{"validateWarningsJSON", []string{"validate", "-format", "json", "-warnings", "-volume", "200,200,200", testFile}, exitOK, []string{`"issues": []`}},
+		{"validateBadVolume", []string{"validate", "-volume", "1,2", testFile}, exitError, nil},
+		{"validateUnknown", []string{"validate", unknown}, exitError, nil},
+		{"validateSchema", []string{"validate", "-schema", testFile}, exitInvalid, []string{"Path: /3D/3dmodel.model Line: 2 XPath: /model", "1 errors"}},
+		{"validateSchemaNot3MF", []string{"validate", "-schema", invalid}, exitError, nil},
+		{"convertUsage", []string{"convert", testFile}, exitError, nil},
+		{"convertUnknown", []string{"convert", testFile, filepath.Join(dir, "model.txt")}, exitError, nil},
+		{"extractUsage", []string{"extract", testFile}, exitError, nil},
+		{"extractMissingPart", []string{"extract", "-part", "/foo", testFile, dir}, exitError, nil},
+		{"repackUsage", []string{"repack", "-key", "key.pem", testFile, filepath.Join(dir, "out.3mf")}, exitError, nil},
+		{"repackNoKey", []string{"repack", "-key", "key.pem", "-cert", "cert.pem", testFile, filepath.Join(dir, "out.3mf")}, exitError, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run(tt.args, &stdout, &stderr); got != tt.want {
+				t.Errorf("run() = %v, want %v, stderr: %s", got, tt.want, stderr.String())
+			}
+			for _, want := range tt.wantStdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("run() stdout = %s, want %s", stdout.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func Test_run_convert(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"cube.stl", "cube.ply", "cube.glb", "cube.obj"} {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			out := filepath.Join(dir, name)
+			if got := run([]string{"convert", testFile, out}, &stdout, &stderr); got != exitOK {
+				t.Fatalf("run() = %v, stderr: %s", got, stderr.String())
+			}
+			back := filepath.Join(dir, name+".3mf")
+			if got := run([]string{"convert", out, back}, &stdout, &stderr); got != exitOK {
+				t.Fatalf("run() = %v, stderr: %s", got, stderr.String())
+			}
+			r, err := go3mf.OpenReader(back)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			var m go3mf.Model
+			if err := r.Decode(&m); err != nil {
+				t.Fatal(err)
+			}
+			if box := m.BoundingBox(); box.Min != (go3mf.Point3D{30, 30, 50}) || box.Max != (go3mf.Point3D{130, 130, 150}) {
+				t.Errorf("run() bounding box = %v", box)
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cube.mtl")); err != nil {
+		t.Errorf("run() mtl error = %v", err)
+	}
+}
+
+func Test_run_extract(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	var stdout, stderr bytes.Buffer
+	if got := run([]string{"extract", testFile, dir}, &stdout, &stderr); got != exitOK {
+		t.Fatalf("run() = %v, stderr: %s", got, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "/3D/3dmodel.model") {
+		t.Errorf("run() stdout = %s", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "3D", "3dmodel.model")); err != nil {
+		t.Errorf("run() error = %v", err)
+	}
+	stdout.Reset()
+	partDir := filepath.Join(dir, "part")
+	if got := run([]string{"extract", "-part", "3D/3dmodel.model", testFile, partDir}, &stdout, &stderr); got != exitOK {
+		t.Fatalf("run() = %v, stderr: %s", got, stderr.String())
+	}
+	if stdout.String() != "/3D/3dmodel.model\n" {
+		t.Errorf("run() stdout = %s", stdout.String())
+	}
+}
+
+func Test_extractFile_unsafe(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	w.Create("../evil.txt")
+	w.Close()
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := extractFile(z.File[0], os.TempDir()); !errors.Is(err, errUnsafePath) {
+		t.Errorf("extractFile() error = %v, want %v", err, errUnsafePath)
+	}
+}
+
+func Test_run_repack(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	keyFile, certFile := writeTestKey(t, dir)
+	out := filepath.Join(dir, "out.3mf")
+	var stdout, stderr bytes.Buffer
+	if got := run([]string{"repack", "-precision", "2", "-key", keyFile, "-cert", certFile, testFile, out}, &stdout, &stderr); got != exitOK {
+		t.Fatalf("run() = %v, stderr: %s", got, stderr.String())
+	}
+	z, err := zip.OpenReader(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer z.Close()
+	var signed bool
+	for _, f := range z.File {
+		if strings.HasPrefix(f.Name, "package/services/digital-signature/") {
+			signed = true
+		}
+	}
+	if !signed {
+		t.Error("run() package is not signed")
+	}
+	if got := run([]string{"repack", "-key", certFile, "-cert", certFile, testFile, out}, &stdout, &stderr); got != exitError {
+		t.Errorf("run() = %v, want %v", got, exitError)
+	}
+}
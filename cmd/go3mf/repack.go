@@ -0,0 +1,104 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/hpinc/go3mf"
+)
+
+// errPEM is returned when the signing key or certificate is not a PEM block.
+var errPEM = errors.New("no PEM data found")
+
+func repackFlags(fs *flag.FlagSet) runFunc {
+	precision := fs.Int("precision", 4, "number of decimals of the encoded floats, -1 for the minimum needed to be exact")
+	keyFile := fs.String("key", "", "PEM file with the private key used to sign the package")
+	certFile := fs.String("cert", "", "PEM file with the certificate embedded in the signature, requires -key")
+	return func(args []string, stdout io.Writer) error {
+		if len(args) != 2 || (*certFile != "") != (*keyFile != "") {
+			return errUsage
+		}
+		r, err := go3mf.OpenReader(args[0])
+		if err != nil {
+			return err
+		}
+		var m go3mf.Model
+		err = r.Decode(&m)
+		r.Close()
+		if err != nil {
+			return err
+		}
+		f, err := os.Create(args[1])
+		if err != nil {
+			return err
+		}
+		e := go3mf.NewEncoder(f)
+		e.FloatPrecision = *precision
+		if *keyFile != "" {
+			if e.Signer, err = newSigner(*keyFile, *certFile); err != nil {
+				f.Close()
+				return err
+			}
+		}
+		if err = e.Encode(&m); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+}
+
+// newSigner returns a go3mf.Signer with the private key and the certificate
+// stored in the PEM files keyFile and certFile.
+func newSigner(keyFile, certFile string) (*go3mf.Signer, error) {
+	block, err := readPEM(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, go3mf.ErrSignatureKey
+	}
+	if block, err = readPEM(certFile); err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &go3mf.Signer{Key: signer, Certificate: cert}, nil
+}
+
+func readPEM(name string) (*pem.Block, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("%s: %w", name, errPEM)
+	}
+	return block, nil
+}
@@ -0,0 +1,162 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/importer"
+)
+
+func validateFlags(fs *flag.FlagSet) runFunc {
+	coherency := fs.Bool("coherency", true, "check that the meshes are non-empty, manifold and oriented")
+	format := fs.String("format", "text", "output format: text, json or junit")
+	warnings := fs.Bool("warnings", false, "also check the recommendations of the specs, reported as warnings")
+	volume := fs.String("volume", "", "build `volume` as width,depth,height, checked with -warnings")
+	schema := fs.Bool("schema", false, "also check the model parts against the core and extension XSDs, only for 3MF files")
+	return func(args []string, stdout io.Writer) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		if *format != "text" && *format != "json" && *format != "junit" {
+			return fmt.Errorf("unknown format %q", *format)
+		}
+		if *schema && !strings.EqualFold(filepath.Ext(args[0]), ".3mf") {
+			return errors.New("-schema is only supported for 3MF files")
+		}
+		var opts go3mf.WarningOptions
+		if *volume != "" {
+			var err error
+			if opts.BuildVolume, err = parseVolume(*volume); err != nil {
+				return err
+			}
+		}
+		var (
+			m     go3mf.Model
+			errs  error
+			warns error
+		)
+		var err error
+		if *schema {
+			errs, err = decodeSchema(args[0], &m)
+		} else {
+			err = importer.DecodeFile(args[0], &m)
+		}
+		if err != nil {
+			var pathErr *os.PathError
+			if errors.As(err, &pathErr) || errors.Is(err, importer.ErrUnknownFormat) {
+				return err
+			}
+			// The content could not be decoded, which makes the model invalid.
+			errs = specerr.Append(errs, err)
+		} else {
+			errs = specerr.Append(errs, m.Validate())
+			if *coherency {
+				errs = specerr.Append(errs, m.ValidateCoherency())
+			}
+			if *warnings {
+				warns = m.ValidateWarnings(opts)
+			}
+		}
+		if *format != "text" {
+			r := specerr.NewReport(errs)
+			r.Append(warns)
+			return writeReport(stdout, r, *format, args[0])
+		}
+		if msgs := errorMessages(warns); len(msgs) != 0 {
+			for _, msg := range msgs {
+				fmt.Fprintln(stdout, msg)
+			}
+			fmt.Fprintf(stdout, "%d warnings\n", len(msgs))
+		}
+		msgs := errorMessages(errs)
+		if len(msgs) == 0 {
+			fmt.Fprintln(stdout, "valid")
+			return nil
+		}
+		for _, msg := range msgs {
+			fmt.Fprintln(stdout, msg)
+		}
+		fmt.Fprintf(stdout, "%d errors\n", len(msgs))
+		return errInvalid
+	}
+}
+
+// decodeSchema decodes the 3MF file validating its model parts against the XSDs,
+// and returns the schema errors separately from the decoding error.
+func decodeSchema(name string, m *go3mf.Model) (schemaErrs error, err error) {
+	r, err := go3mf.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	r.ValidateSchema = true
+	err = r.Decode(m)
+	var se *specerr.SchemaError
+	if errors.As(err, &se) {
+		return err, nil
+	}
+	return nil, err
+}
+
+// errorMessages returns the sorted messages of the errors in err.
+func errorMessages(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var msgs []string
+	if list, ok := err.(*specerr.List); ok {
+		for _, e := range list.Errors {
+			msgs = append(msgs, e.Error())
+		}
+	} else {
+		msgs = append(msgs, err.Error())
+	}
+	sort.Strings(msgs)
+	return msgs
+}
+
+// writeReport writes r to stdout in the json or junit format.
+func writeReport(stdout io.Writer, r *specerr.Report, format, name string) error {
+	var err error
+	if format == "json" {
+		err = r.WriteJSON(stdout)
+	} else {
+		err = r.WriteJUnit(stdout, name)
+	}
+	if err != nil {
+		return err
+	}
+	if r.HasErrors() {
+		return errInvalid
+	}
+	return nil
+}
+
+// parseVolume parses a build volume with the form width,depth,height.
+func parseVolume(s string) (go3mf.Box, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 3 {
+		return go3mf.Box{}, fmt.Errorf("invalid volume %q", s)
+	}
+	var box go3mf.Box
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 32)
+		if err != nil || v <= 0 {
+			return go3mf.Box{}, fmt.Errorf("invalid volume %q", s)
+		}
+		box.Max[i] = float32(v)
+	}
+	return box, nil
+}
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/hpinc/go3mf/errors"
 	"github.com/hpinc/go3mf/spec"
 )
 
@@ -67,6 +68,19 @@ func (u Units) String() string {
 	}[u]
 }
 
+// Millimeters returns the size of one u in millimeters,
+// or 0 if u is not a supported unit.
+func (u Units) Millimeters() float64 {
+	return map[Units]float64{
+		UnitMillimeter: 1,
+		UnitMicrometer: 0.001,
+		UnitCentimeter: 10,
+		UnitInch:       25.4,
+		UnitFoot:       304.8,
+		UnitMeter:      1000,
+	}[u]
+}
+
 // ObjectType defines the allowed object types.
 type ObjectType int8
 
@@ -333,6 +347,60 @@ func (m *Model) WalkObjects(fn func(string, *Object) error) error {
 	return nil
 }
 
+// WalkItemMeshes walks the mesh objects referenced by item, directly or through components,
+// calling fn with the model part path of each object and the product of the item
+// and component transforms, and stopping if fn returns an error.
+//
+// Empty transforms are treated as the identity. Missing and recursive
+// components are reported as errors wrapped with the component index.
+func (m *Model) WalkItemMeshes(item *Item, fn func(string, *Object, Matrix) error) error {
+	obj, ok := m.FindObject(item.ObjectPath(), item.ObjectID)
+	if !ok {
+		return errors.ErrMissingResource
+	}
+	return m.WalkObjectMeshes(item.ObjectPath(), obj, item.Transform, fn)
+}
+
+// WalkObjectMeshes is like WalkItemMeshes but starts from obj,
+// defined in the model part path and transformed by transform.
+func (m *Model) WalkObjectMeshes(path string, obj *Object, transform Matrix, fn func(string, *Object, Matrix) error) error {
+	return m.walkObjectMeshes(path, obj, transformOrIdentity(transform), make(map[*Object]bool), fn)
+}
+
+func (m *Model) walkObjectMeshes(path string, obj *Object, transform Matrix, visiting map[*Object]bool, fn func(string, *Object, Matrix) error) error {
+	if visiting[obj] {
+		return errors.ErrRecursion
+	}
+	if obj.Mesh != nil {
+		if err := fn(path, obj, transform); err != nil {
+			return err
+		}
+	}
+	if obj.Components == nil {
+		return nil
+	}
+	visiting[obj] = true
+	defer delete(visiting, obj)
+	for i, comp := range obj.Components.Component {
+		cpath := comp.ObjectPath(path)
+		cobj, ok := m.FindObject(cpath, comp.ObjectID)
+		if !ok {
+			return errors.WrapIndex(errors.ErrMissingResource, attrComponent, i)
+		}
+		if err := m.walkObjectMeshes(cpath, cobj, transform.Mul(transformOrIdentity(comp.Transform)), visiting, fn); err != nil {
+			return errors.WrapIndex(err, attrComponent, i)
+		}
+	}
+	return nil
+}
+
+func transformOrIdentity(t Matrix) Matrix {
+	if t == (Matrix{}) {
+		return Identity()
+	}
+	return t
+}
+
 // Base defines the Model Base Material Resource.
 // A model material resource is an in memory representation of the 3MF
 // material resource object.
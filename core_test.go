@@ -4,9 +4,11 @@
 package go3mf
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
+	specerr "github.com/hpinc/go3mf/errors"
 	"github.com/hpinc/go3mf/spec"
 )
 
@@ -246,6 +248,28 @@ func TestUnits_String(t *testing.T) {
 	}
 }
 
+func TestUnits_Millimeters(t *testing.T) {
+	tests := []struct {
+		u    Units
+		want float64
+	}{
+		{UnitMicrometer, 0.001},
+		{UnitMillimeter, 1},
+		{UnitCentimeter, 10},
+		{UnitInch, 25.4},
+		{UnitFoot, 304.8},
+		{UnitMeter, 1000},
+		{Units(100), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.u.String(), func(t *testing.T) {
+			if got := tt.u.Millimeters(); got != tt.want {
+				t.Errorf("Units.Millimeters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMeshBuilder_AddVertex(t *testing.T) {
 	pos := Point3D{1.0, 2.0, 3.0}
 	existingStruct := NewMeshBuilder(new(Mesh))
@@ -437,6 +461,57 @@ func TestModel_WalkObjects(t *testing.T) {
 	}
 }
 
+func TestModel_WalkItemMeshes(t *testing.T) {
+	mesh := &Object{ID: 1, Mesh: new(Mesh)}
+	child := &Object{ID: 1, Mesh: new(Mesh)}
+	m := &Model{Childs: map[string]*ChildModel{"/other.model": {Resources: Resources{Objects: []*Object{child}}}}}
+	m.Resources.Objects = []*Object{mesh,
+		{ID: 2, Mesh: new(Mesh), Components: &Components{Component: []*Component{
+			{ObjectID: 1, Transform: Identity().Translate(1, 0, 0)},
+			{ObjectID: 1, Transform: Identity().Translate(0, 1, 0), AnyAttr: spec.AnyAttr{&fakeAttr{Value: "/other.model"}}},
+		}}},
+		{ID: 3, Components: &Components{Component: []*Component{{ObjectID: 4}}}},
+		{ID: 4, Components: &Components{Component: []*Component{{ObjectID: 3}}}},
+		{ID: 5, Components: &Components{Component: []*Component{{ObjectID: 10}}}},
+	}
+	type visit struct {
+		path      string
+		obj       *Object
+		transform Matrix
+	}
+	tests := []struct {
+		name    string
+		item    *Item
+		want    []visit
+		wantErr error
+	}{
+		{"mesh", &Item{ObjectID: 1}, []visit{{"", mesh, Identity()}}, nil},
+		{"components", &Item{ObjectID: 2, Transform: Identity().Translate(0, 0, 1)}, []visit{
+			{"", m.Resources.Objects[1], Identity().Translate(0, 0, 1)},
+			{"", mesh, Identity().Translate(1, 0, 1)},
+			{"/other.model", child, Identity().Translate(0, 1, 1)},
+		}, nil},
+		{"recursive", &Item{ObjectID: 3}, nil, specerr.ErrRecursion},
+		{"missingComponent", &Item{ObjectID: 5}, nil, specerr.ErrMissingResource},
+		{"missing", &Item{ObjectID: 10}, nil, specerr.ErrMissingResource},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []visit
+			err := m.WalkItemMeshes(tt.item, func(path string, obj *Object, transform Matrix) error {
+				got = append(got, visit{path, obj, transform})
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Model.WalkItemMeshes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Model.WalkItemMeshes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMesh_BoundingBox(t *testing.T) {
 	tests := []struct {
 		name string
@@ -15,6 +15,7 @@ import (
 
 	xml3mf "github.com/hpinc/go3mf/internal/xml"
 	"github.com/hpinc/go3mf/spec"
+	"github.com/qmuntal/opc"
 )
 
 const defaultFloatPrecision = 4
@@ -120,9 +121,17 @@ func CreateWriter(name string) (*WriteCloser, error) {
 // An Encoder writes Model data to an output stream.
 //
 // See the documentation for strconv.FormatFloat for details about the FloatPrecision behaviour.
+// If OnProgress is not nil it will be called periodically
+// while encoding, see ProgressFunc for more details.
+// If Encrypter is not nil it will be used to encrypt the package parts.
+// If Signer is not nil the package will be digitally signed, see Signer for more details.
 type Encoder struct {
 	FloatPrecision int
+	OnProgress     ProgressFunc
+	Encrypter      PartEncrypter
+	Signer         *Signer
 	w              packageWriter
+	reporter       *progressReporter
 }
 
 // NewEncoder returns a new encoder that writes to w.
@@ -135,6 +144,12 @@ func NewEncoder(w io.Writer) *Encoder {
 
 // Encode writes the XML encoding of m to the stream.
 func (e *Encoder) Encode(m *Model) error {
+	e.reporter = newProgressReporter(e.OnProgress)
+	if e.Signer != nil {
+		w := e.w
+		e.w = newSigningWriter(w, e.Signer)
+		defer func() { e.w = w }()
+	}
 	if err := e.writeAttachements(m.Attachments); err != nil {
 		return err
 	}
@@ -144,7 +159,7 @@ func (e *Encoder) Encode(m *Model) error {
 	}
 	e.w.AddRelationship(Relationship{Type: RelType3DModel, Path: rootName})
 
-	w, err := e.w.Create(rootName, ContentType3DModel)
+	w, err := e.create(rootName, ContentType3DModel, PhaseRootModel)
 	if err != nil {
 		return err
 	}
@@ -163,10 +178,26 @@ func (e *Encoder) Encode(m *Model) error {
 	for _, r := range enc.relationships {
 		w.AddRelationship(r)
 	}
+	if err = closePart(w); err != nil {
+		return err
+	}
 	if err = e.writeChildModels(m); err != nil {
 		return err
 	}
-
+	if e.Encrypter != nil {
+		parts, rels, err := e.Encrypter.Close()
+		if err != nil {
+			return err
+		}
+		for _, r := range rels {
+			e.w.AddRelationship(r)
+		}
+		for _, p := range parts {
+			if err = e.writeAttachment(p.Attachment, p.Relationships); err != nil {
+				return err
+			}
+		}
+	}
 	return e.w.Close()
 }
 
@@ -177,7 +208,7 @@ func (e *Encoder) writeChildModels(m *Model) error {
 			err error
 		)
 		path = resolveRelationship(m.PathOrDefault(), path)
-		if w, err = e.w.Create(path, ContentType3DModel); err != nil {
+		if w, err = e.create(path, ContentType3DModel, PhaseChildModels); err != nil {
 			return err
 		}
 		if _, err = w.Write([]byte(xml.Header)); err != nil {
@@ -191,20 +222,94 @@ func (e *Encoder) writeChildModels(m *Model) error {
 		for _, r := range enc.relationships {
 			w.AddRelationship(r)
 		}
+		if err = closePart(w); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 func (e *Encoder) writeAttachements(att []Attachment) error {
 	for _, a := range att {
-		w, err := e.w.Create(a.Path, a.ContentType)
-		if err == nil {
-			_, err = io.Copy(w, a.Stream)
+		if err := e.writeAttachment(a, nil); err != nil {
+			return err
 		}
+	}
+	return nil
+}
+
+func (e *Encoder) writeAttachment(a Attachment, rels []Relationship) error {
+	w, err := e.create(a.Path, a.ContentType, PhaseAttachments)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, a.Stream); err != nil {
+		return err
+	}
+	for _, r := range rels {
+		w.AddRelationship(r)
+	}
+	return closePart(w)
+}
+
+func (e *Encoder) create(name, contentType string, phase Phase) (packagePart, error) {
+	w, err := e.w.Create(name, contentType)
+	if err != nil {
+		return nil, err
+	}
+	if e.Encrypter != nil {
+		wc, err := e.Encrypter.Encrypt(opc.NormalizePartName(name), w)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if wc != nil {
+			w = &encryptedPart{packagePart: w, wc: wc}
 		}
 	}
+	return newProgressPart(w, e.reporter, phase, name), nil
+}
+
+// A PartEncrypter encrypts package parts while encoding.
+//
+// Encrypt is called for every model part and attachment before writing it.
+// It must return nil if the part is not encrypted, else a writer that
+// writes the encrypted content to w, at the latest when closed.
+// Close is called once all the parts have been written
+// and returns the new parts and root relationships to add
+// to the package, such as a key store.
+type PartEncrypter interface {
+	Encrypt(name string, w io.Writer) (io.WriteCloser, error)
+	Close() ([]EncrypterPart, []Relationship, error)
+}
+
+// An EncrypterPart is a package part added by a PartEncrypter
+// together with the relationships it owns.
+type EncrypterPart struct {
+	Attachment
+	Relationships []Relationship
+}
+
+type encryptedPart struct {
+	packagePart
+	wc io.WriteCloser
+}
+
+func (p *encryptedPart) Write(b []byte) (int, error) {
+	return p.wc.Write(b)
+}
+
+func (p *encryptedPart) closePart() error {
+	return p.wc.Close()
+}
+
+type partCloser interface {
+	closePart() error
+}
+
+func closePart(p packagePart) error {
+	if p, ok := p.(partCloser); ok {
+		return p.closePart()
+	}
 	return nil
 }
 
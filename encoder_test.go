@@ -40,9 +40,9 @@ func (m *mockPackagePart) AddRelationship(args0 Relationship) {
 	m.Called(args0)
 }
 
-func TestMarshalModel(t *testing.T) {
-	spec.Register(fakeSpec.Namespace, new(qmExtension))
-	m := &Model{
+// newTestModel returns a model that uses all the core elements and attributes.
+func newTestModel() *Model {
+	return &Model{
 		Units: UnitMillimeter, Language: "en-US", Path: "/3D/3dmodel.model", Thumbnail: "/thumbnail.png",
 		Extensions: []Extension{fakeSpec, fooSpec},
 		AnyAttr:    spec.AnyAttr{&fakeAttr{Value: "model_fake"}, &spec.UnknownAttrs{Space: fooSpace, Attr: []xml.Attr{{Name: fooName, Value: "foo1"}}}},
@@ -114,7 +114,11 @@ func TestMarshalModel(t *testing.T) {
 			{Name: xml.Name{Local: "Application"}, Value: "go3mf app"},
 			{Name: xml.Name{Space: "qm", Local: "CustomMetadata1"}, Preserve: true, Type: "xs:string", Value: "CE8A91FB-C44E-4F00-B634-BAA411465F6A"},
 		}}
+}
 
+func TestMarshalModel(t *testing.T) {
+	spec.Register(fakeSpec.Namespace, new(qmExtension))
+	m := newTestModel()
 	t.Run("base", func(t *testing.T) {
 		b, err := MarshalModel(m)
 		if err != nil {
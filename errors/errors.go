@@ -38,6 +38,27 @@ var (
 	ErrMeshConsistency        = errors.New("mesh has non-manifold edges without consistent triangle orientation")
 )
 
+// Warning guards.
+var (
+	ErrModelLocation       = errors.New("3D model parts SHOULD be stored in the /3D/ directory")
+	ErrThumbnailLocation   = errors.New("package thumbnails SHOULD be stored in the /Metadata/ directory")
+	ErrPrintTicketLocation = errors.New("print ticket SHOULD be stored as /3D/Metadata/Model_PT.xml")
+	ErrThumbnailFormat     = errors.New("thumbnails SHOULD be PNG or JPEG images")
+	ErrMetadataType        = errors.New("metadata type SHOULD be an XML schema simple type")
+	ErrMetadataDate        = errors.New("creation and modification dates SHOULD follow the ISO 8601 format")
+	ErrItemOutsideVolume   = errors.New("build items SHOULD be inside the build volume")
+)
+
+// Schema guards.
+var (
+	ErrSchemaElement        = errors.New("element is not allowed by the schema")
+	ErrSchemaMissingElement = errors.New("element is missing required child elements")
+	ErrSchemaAttr           = errors.New("attribute is not allowed by the schema")
+	ErrSchemaMissingAttr    = errors.New("required attribute is missing")
+	ErrSchemaAttrValue      = errors.New("attribute value does not match its schema type")
+	ErrSchemaText           = errors.New("text is not allowed by the schema")
+)
+
 type Level struct {
 	Name  string
 	Index int // -1 if not needed
@@ -126,6 +147,23 @@ func (e *MissingFieldError) Error() string {
 	return fmt.Sprintf("required field '%s' is not set", e.Name)
 }
 
+// SchemaError is a schema violation found in the XML of a model part.
+// Line is 1-based and XPath locates the element.
+type SchemaError struct {
+	Path  string
+	Line  int
+	XPath string
+	Err   error
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("go3mf: Path: %s Line: %d XPath: %s: %v", e.Path, e.Line, e.XPath, e.Err)
+}
+
 type ParseAttrError struct {
 	Name     string
 	Required bool
@@ -0,0 +1,167 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package errors
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Issue is a rule violation found by the validators.
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Chapter  string   `json:"chapter,omitempty"`
+	Path     string   `json:"path,omitempty"`
+	XPath    string   `json:"xpath,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// NewIssue returns the issue that describes err.
+func NewIssue(err error) Issue {
+	r, _ := RuleOf(err)
+	is := Issue{Rule: r.ID, Severity: r.Severity, Chapter: r.Chapter, Message: err.Error()}
+	var (
+		e  *Error
+		se *SchemaError
+	)
+	if errors.As(err, &e) {
+		is.Path = e.Path
+		is.XPath = e.XPath()
+		is.Message = e.Err.Error()
+	} else if errors.As(err, &se) {
+		is.Path = se.Path
+		is.XPath = se.XPath
+		is.Line = se.Line
+		is.Message = se.Err.Error()
+	}
+	return is
+}
+
+// Report is a structured list of issues which can be
+// encoded as JSON or as a JUnit XML report.
+type Report struct {
+	Issues []Issue `json:"issues"`
+}
+
+// NewReport returns a report with the issues of err, which may be a List.
+func NewReport(err error) *Report {
+	r := new(Report)
+	r.Append(err)
+	return r
+}
+
+// Append adds the issues of err, which may be a List, to the report.
+func (r *Report) Append(err error) {
+	if err == nil {
+		return
+	}
+	if l, ok := err.(*List); ok {
+		if l == nil {
+			return
+		}
+		for _, e := range l.Errors {
+			r.Append(e)
+		}
+		return
+	}
+	r.Issues = append(r.Issues, NewIssue(err))
+}
+
+// Count returns the number of issues with severity s.
+func (r *Report) Count(s Severity) int {
+	var n int
+	for _, is := range r.Issues {
+		if is.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+// HasErrors reports whether the report contains issues with SeverityError.
+func (r *Report) HasErrors() bool {
+	return r.Count(SeverityError) != 0
+}
+
+// WriteJSON writes the JSON encoding of the report to w.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if r.Issues == nil {
+		// Encode an empty list instead of null.
+		return enc.Encode(&Report{Issues: []Issue{}})
+	}
+	return enc.Encode(r)
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report to w as a JUnit XML test suite called name.
+//
+// Each issue is a test case named after its rule and the issues
+// with SeverityError are failures. The other issues are reported
+// as the standard output of a passed test case.
+func (r *Report) WriteJUnit(w io.Writer, name string) error {
+	suite := junitSuite{Name: name, Tests: len(r.Issues), Failures: r.Count(SeverityError)}
+	for _, is := range r.Issues {
+		c := junitCase{Name: is.Rule, ClassName: is.Path + is.XPath}
+		text := is.Severity.String() + ": " + is.Message
+		if is.Line != 0 {
+			text += fmt.Sprintf(" (line %d)", is.Line)
+		}
+		if is.Chapter != "" {
+			text += " (chapter " + is.Chapter + ")"
+		}
+		if is.Severity == SeverityError {
+			c.Failure = &junitFailure{Message: is.Message, Type: is.Rule, Text: text}
+		} else {
+			c.SystemOut = text
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// String returns a short summary of the report.
+func (r *Report) String() string {
+	return fmt.Sprintf("%d errors, %d warnings, %d infos",
+		r.Count(SeverityError), r.Count(SeverityWarning), r.Count(SeverityInfo))
+}
@@ -0,0 +1,139 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package errors
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestRuleOf(t *testing.T) {
+	custom := errors.New("custom")
+	RegisterRule(Rule{ID: "test.custom", Severity: SeverityWarning, Chapter: "1", Err: custom})
+	tests := []struct {
+		name   string
+		err    error
+		want   Rule
+		wantOk bool
+	}{
+		{"sentinel", ErrDuplicatedIndices, Rule{"core.mesh.duplicated-indices", SeverityError, "4.1.2", ErrDuplicatedIndices}, true},
+		{"wrapped", WrapIndex(ErrDuplicatedIndices, "triangle", 2), Rule{"core.mesh.duplicated-indices", SeverityError, "4.1.2", ErrDuplicatedIndices}, true},
+		{"fmt", fmt.Errorf("foo: %w", custom), Rule{"test.custom", SeverityWarning, "1", custom}, true},
+		{"missingField", Wrap(NewMissingFieldError("id"), "object"), RuleMissingField, true},
+		{"parseAttr", NewParseAttrError("id", true), RuleParseAttr, true},
+		{"unknown", errors.New("foo"), RuleUnknown, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RuleOf(tt.err)
+			if ok != tt.wantOk {
+				t.Errorf("RuleOf() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("RuleOf() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	rs := Rules()
+	for i := 1; i < len(rs); i++ {
+		if rs[i-1].ID >= rs[i].ID {
+			t.Errorf("Rules() not sorted: %s >= %s", rs[i-1].ID, rs[i].ID)
+		}
+	}
+}
+
+func TestSeverity_Text(t *testing.T) {
+	for _, s := range []Severity{SeverityError, SeverityWarning, SeverityInfo} {
+		b, err := s.MarshalText()
+		if err != nil {
+			t.Fatalf("Severity.MarshalText() error = %v", err)
+		}
+		var got Severity
+		if err := got.UnmarshalText(b); err != nil || got != s {
+			t.Errorf("Severity.UnmarshalText() = %v, %v, want %v", got, err, s)
+		}
+	}
+	if _, err := Severity(5).MarshalText(); err == nil {
+		t.Error("Severity.MarshalText() expected error")
+	}
+	if err := new(Severity).UnmarshalText([]byte("foo")); err == nil {
+		t.Error("Severity.UnmarshalText() expected error")
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	err := Append(nil,
+		WrapPath(WrapIndex(ErrDuplicatedIndices, "triangle", 1), "model", "/3D/3dmodel.model"),
+		errors.New("foo"),
+	)
+	want := &Report{Issues: []Issue{
+		{Rule: "core.mesh.duplicated-indices", Severity: SeverityError, Chapter: "4.1.2", Path: "/3D/3dmodel.model", XPath: "/model/triangle[1]", Message: ErrDuplicatedIndices.Error()},
+		{Rule: "unknown", Severity: SeverityError, Message: "foo"},
+	}}
+	got := NewReport(err)
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("NewReport() = %v", diff)
+	}
+	if !got.HasErrors() {
+		t.Error("Report.HasErrors() = false")
+	}
+	if s := got.String(); s != "2 errors, 0 warnings, 0 infos" {
+		t.Errorf("Report.String() = %s", s)
+	}
+	if NewReport(nil).HasErrors() {
+		t.Error("Report.HasErrors() = true")
+	}
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		r    *Report
+		want string
+	}{
+		{"empty", new(Report), "{\n  \"issues\": []\n}\n"},
+		{"base", &Report{Issues: []Issue{{Rule: "a.b", Severity: SeverityWarning, Message: "foo"}}},
+			"{\n  \"issues\": [\n    {\n      \"rule\": \"a.b\",\n      \"severity\": \"warning\",\n      \"message\": \"foo\"\n    }\n  ]\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.r.WriteJSON(&buf); err != nil {
+				t.Fatalf("Report.WriteJSON() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Report.WriteJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReport_WriteJUnit(t *testing.T) {
+	r := &Report{Issues: []Issue{
+		{Rule: "a.b", Severity: SeverityError, Chapter: "4", Path: "/3D/3dmodel.model", XPath: "/model", Message: "foo"},
+		{Rule: "a.c", Severity: SeverityWarning, Message: "bar"},
+	}}
+	var buf bytes.Buffer
+	if err := r.WriteJUnit(&buf, "test"); err != nil {
+		t.Fatalf("Report.WriteJUnit() error = %v", err)
+	}
+	for _, want := range []string{
+		`<testsuite name="test" tests="2" failures="1">`,
+		`<testcase name="a.b" classname="/3D/3dmodel.model/model">`,
+		`<failure message="foo" type="a.b">error: foo (chapter 4)</failure>`,
+		`<system-out>warning: bar</system-out>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Report.WriteJUnit() = %s, want %s", buf.String(), want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Severity defines how serious is the violation of a rule.
+type Severity int
+
+// Supported severities.
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+var severityNames = [...]string{"error", "warning", "info"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// MarshalText encodes the severity as its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(severityNames) {
+		return nil, fmt.Errorf("go3mf: invalid severity %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name.
+func (s *Severity) UnmarshalText(b []byte) error {
+	for i, name := range severityNames {
+		if name == string(b) {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("go3mf: invalid severity %s", b)
+}
+
+// Rule describes a spec requirement checked by the validators.
+//
+// ID is a stable identifier with the form <spec>.<element>.<name>,
+// Chapter is the section of the spec that defines the requirement
+// and Err is the error returned when the rule is violated.
+type Rule struct {
+	ID       string
+	Severity Severity
+	Chapter  string
+	Err      error
+}
+
+// Rules used for the errors without a registered rule.
+var (
+	RuleMissingField = Rule{ID: "schema.missing-field", Severity: SeverityError}
+	RuleParseAttr    = Rule{ID: "schema.parse-attribute", Severity: SeverityError}
+	RuleUnknown      = Rule{ID: "unknown", Severity: SeverityError}
+)
+
+var (
+	ruleMu sync.RWMutex
+	rules  = make(map[error]Rule)
+)
+
+func init() {
+	for _, r := range []Rule{
+		{"core.resource.missing-id", SeverityError, "3.4.2", ErrMissingID},
+		{"core.resource.duplicated-id", SeverityError, "3.4.2", ErrDuplicatedID},
+		{"core.resource.missing", SeverityError, "3.4.2", ErrMissingResource},
+		{"core.resource.empty-properties", SeverityError, "3.4.2", ErrEmptyResourceProps},
+		{"core.resource.index-out-of-bounds", SeverityError, "3.4.2", ErrIndexOutOfBounds},
+		{"core.mesh.duplicated-indices", SeverityError, "4.1.2", ErrDuplicatedIndices},
+		{"core.mesh.insufficient-vertices", SeverityError, "4.1", ErrInsufficientVertices},
+		{"core.mesh.insufficient-triangles", SeverityError, "4.1", ErrInsufficientTriangles},
+		{"core.mesh.consistency", SeverityError, "4.1", ErrMeshConsistency},
+		{"core.object.components-pid", SeverityError, "4.2", ErrComponentsPID},
+		{"core.object.recursion", SeverityError, "4.2", ErrRecursion},
+		{"core.object.invalid", SeverityError, "4", ErrInvalidObject},
+		{"core.object.non-object", SeverityError, "4.2", ErrNonObject},
+		{"core.build.item-other", SeverityError, "3.4.3", ErrOtherItem},
+		{"core.metadata.name", SeverityError, "3.4.1", ErrMetadataName},
+		{"core.metadata.namespace", SeverityError, "3.4.1", ErrMetadataNamespace},
+		{"core.metadata.duplicated", SeverityError, "3.4.1", ErrMetadataDuplicated},
+		{"core.model.required-extension", SeverityError, "3.4", ErrRequiredExt},
+		{"core.opc.part-name", SeverityError, "2", ErrOPCPartName},
+		{"core.opc.relationship-target", SeverityError, "2", ErrOPCRelTarget},
+		{"core.opc.duplicated-relationship", SeverityError, "2", ErrOPCDuplicatedRel},
+		{"core.opc.content-type", SeverityError, "2", ErrOPCContentType},
+		{"core.opc.duplicated-printticket", SeverityError, "2.4", ErrOPCDuplicatedTicket},
+		{"core.opc.duplicated-model-name", SeverityError, "2", ErrOPCDuplicatedModelName},
+		{"core.opc.model-location", SeverityWarning, "2.1", ErrModelLocation},
+		{"core.opc.thumbnail-location", SeverityWarning, "2.3", ErrThumbnailLocation},
+		{"core.opc.printticket-location", SeverityWarning, "2.4", ErrPrintTicketLocation},
+		{"core.opc.thumbnail-format", SeverityWarning, "2.3", ErrThumbnailFormat},
+		{"core.metadata.type", SeverityWarning, "3.4.1", ErrMetadataType},
+		{"core.metadata.date", SeverityWarning, "3.4.1", ErrMetadataDate},
+		{"core.build.item-outside-volume", SeverityWarning, "3.4.3", ErrItemOutsideVolume},
+		{"schema.element.unexpected", SeverityError, "", ErrSchemaElement},
+		{"schema.element.missing", SeverityError, "", ErrSchemaMissingElement},
+		{"schema.attribute.unexpected", SeverityError, "", ErrSchemaAttr},
+		{"schema.attribute.missing", SeverityError, "", ErrSchemaMissingAttr},
+		{"schema.attribute.value", SeverityError, "", ErrSchemaAttrValue},
+		{"schema.text.unexpected", SeverityError, "", ErrSchemaText},
+	} {
+		RegisterRule(r)
+	}
+}
+
+// RegisterRule makes r available to RuleOf.
+// If RegisterRule is called twice with the same error the last rule wins.
+func RegisterRule(r Rule) {
+	ruleMu.Lock()
+	rules[r.Err] = r
+	ruleMu.Unlock()
+}
+
+// Rules returns the registered rules sorted by ID.
+func Rules() []Rule {
+	ruleMu.RLock()
+	rs := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		rs = append(rs, r)
+	}
+	ruleMu.RUnlock()
+	sort.Slice(rs, func(i, j int) bool {
+		return rs[i].ID < rs[j].ID
+	})
+	return rs
+}
+
+// RuleOf returns the rule of the first error in the chain of err
+// that has been registered with RegisterRule.
+//
+// MissingFieldError and ParseAttrError are reported with RuleMissingField
+// and RuleParseAttr, and any other error with RuleUnknown.
+func RuleOf(err error) (Rule, bool) {
+	ruleMu.RLock()
+	defer ruleMu.RUnlock()
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if !reflect.TypeOf(e).Comparable() {
+			continue
+		}
+		if r, ok := rules[e]; ok {
+			return r, true
+		}
+	}
+	var (
+		missing *MissingFieldError
+		parse   *ParseAttrError
+	)
+	switch {
+	case errors.As(err, &missing):
+		return RuleMissingField, true
+	case errors.As(err, &parse):
+		return RuleParseAttr, true
+	}
+	return RuleUnknown, false
+}
@@ -0,0 +1,524 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package amf
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+)
+
+var checkEveryFaces = 1000
+
+// Errors returned when decoding malformed files.
+var (
+	ErrUnits   = errors.New("unsupported unit")
+	ErrNoModel = errors.New("archive does not contain an amf file")
+)
+
+const zipMagic = "PK\x03\x04"
+
+// Decoder can decode an AMF file, plain or zipped.
+//
+// Each object is decoded as a mesh object, merging its volumes, and each constellation
+// as a components object. The objects and constellations that are not instantiated by
+// any constellation are referenced by a new build item.
+//
+// The colors of the materials referenced by the volumes are decoded into a single go3mf.BaseMaterials.
+// The triangle, vertex, volume and object colors, in order of precedence, are decoded into
+// a materials.ColorGroup, with the material color as fallback. Color formulas are not supported
+// and are ignored.
+//
+// The model metadata name, description, author, copyright and cad are decoded as the
+// Title, Description, Designer, Copyright and Application metadata. Other metadata is ignored.
+//
+// If ConvertUnits is true the coordinates are scaled from the AMF unit to the model units,
+// else the model units are set to the AMF unit.
+type Decoder struct {
+	ConvertUnits bool
+	r            io.Reader
+}
+
+// NewDecoder creates a new decoder.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: r,
+	}
+}
+
+// Decode creates a model from a read stream.
+func (d *Decoder) Decode(m *go3mf.Model) error {
+	return d.DecodeContext(context.Background(), m)
+}
+
+// DecodeContext creates a model from a read stream.
+func (d *Decoder) DecodeContext(ctx context.Context, m *go3mf.Model) error {
+	r, err := d.open()
+	if err != nil {
+		return fmt.Errorf("amf: %w", err)
+	}
+	var f amfFile
+	err = xml.NewDecoder(r).Decode(&f)
+	r.Close()
+	if err != nil {
+		return fmt.Errorf("amf: %w", err)
+	}
+	scale, err := d.scale(m, f.Unit)
+	if err != nil {
+		return fmt.Errorf("amf: %w", err)
+	}
+	ad := amfDecoder{
+		m:              m,
+		scale:          scale,
+		nextFaceCheck:  checkEveryFaces,
+		ids:            make(map[string]uint32),
+		materials:      make(map[string]*amfMaterial),
+		baseIndices:    make(map[string]uint32),
+		colorIndices:   make(map[color.RGBA]uint32),
+		constellations: make(map[string]*amfConstellation),
+		referenced:     make(map[string]bool),
+		visiting:       make(map[string]bool),
+	}
+	if err := ad.decode(ctx, &f); err != nil {
+		return fmt.Errorf("amf: %w", err)
+	}
+	return nil
+}
+
+// open returns the amf file, extracting it from the zip archive if needed.
+func (d *Decoder) open() (io.ReadCloser, error) {
+	b := bufio.NewReader(d.r)
+	if magic, _ := b.Peek(len(zipMagic)); string(magic) != zipMagic {
+		return ioutil.NopCloser(b), nil
+	}
+	data, err := ioutil.ReadAll(b)
+	if err != nil {
+		return nil, err
+	}
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var model *zip.File
+	for _, f := range z.File {
+		if strings.EqualFold(path.Ext(f.Name), ".amf") {
+			model = f
+			break
+		}
+	}
+	if model == nil {
+		return nil, ErrNoModel
+	}
+	return model.Open()
+}
+
+// scale returns the factor to apply to the coordinates in unit.
+func (d *Decoder) scale(m *go3mf.Model, unit string) (float32, error) {
+	u, ok := newUnits(unit)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnits, unit)
+	}
+	if !d.ConvertUnits {
+		m.Units = u
+		return 1, nil
+	}
+	return float32(u.Millimeters() / m.Units.Millimeters()), nil
+}
+
+func newUnits(s string) (u go3mf.Units, ok bool) {
+	u, ok = map[string]go3mf.Units{
+		"":           go3mf.UnitMillimeter,
+		"millimeter": go3mf.UnitMillimeter,
+		"micron":     go3mf.UnitMicrometer,
+		"micrometer": go3mf.UnitMicrometer,
+		"centimeter": go3mf.UnitCentimeter,
+		"inch":       go3mf.UnitInch,
+		"feet":       go3mf.UnitFoot,
+		"foot":       go3mf.UnitFoot,
+		"meter":      go3mf.UnitMeter,
+	}[strings.ToLower(strings.TrimSpace(s))]
+	return
+}
+
+// metadataNames maps the amf metadata types to the 3mf metadata names.
+var metadataNames = map[string]string{
+	"name":        "Title",
+	"description": "Description",
+	"author":      "Designer",
+	"copyright":   "Copyright",
+	"cad":         "Application",
+}
+
+type amfFile struct {
+	Unit           string             `xml:"unit,attr"`
+	Metadata       []amfMetadata      `xml:"metadata"`
+	Objects        []amfObject        `xml:"object"`
+	Materials      []amfMaterial      `xml:"material"`
+	Constellations []amfConstellation `xml:"constellation"`
+}
+
+type amfMetadata struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type amfColor struct {
+	R string `xml:"r"`
+	G string `xml:"g"`
+	B string `xml:"b"`
+	A string `xml:"a"`
+}
+
+type amfObject struct {
+	ID       string        `xml:"id,attr"`
+	Metadata []amfMetadata `xml:"metadata"`
+	Color    *amfColor     `xml:"color"`
+	Vertices []amfVertex   `xml:"mesh>vertices>vertex"`
+	Volumes  []amfVolume   `xml:"mesh>volume"`
+}
+
+type amfVertex struct {
+	X     float32   `xml:"coordinates>x"`
+	Y     float32   `xml:"coordinates>y"`
+	Z     float32   `xml:"coordinates>z"`
+	Color *amfColor `xml:"color"`
+}
+
+type amfVolume struct {
+	MaterialID string        `xml:"materialid,attr"`
+	Color      *amfColor     `xml:"color"`
+	Triangles  []amfTriangle `xml:"triangle"`
+}
+
+type amfTriangle struct {
+	V1    uint32    `xml:"v1"`
+	V2    uint32    `xml:"v2"`
+	V3    uint32    `xml:"v3"`
+	Color *amfColor `xml:"color"`
+}
+
+type amfMaterial struct {
+	ID       string        `xml:"id,attr"`
+	Metadata []amfMetadata `xml:"metadata"`
+	Color    *amfColor     `xml:"color"`
+}
+
+type amfConstellation struct {
+	ID        string        `xml:"id,attr"`
+	Metadata  []amfMetadata `xml:"metadata"`
+	Instances []amfInstance `xml:"instance"`
+}
+
+type amfInstance struct {
+	ObjectID string  `xml:"objectid,attr"`
+	DeltaX   float32 `xml:"deltax"`
+	DeltaY   float32 `xml:"deltay"`
+	DeltaZ   float32 `xml:"deltaz"`
+	RX       float32 `xml:"rx"`
+	RY       float32 `xml:"ry"`
+	RZ       float32 `xml:"rz"`
+}
+
+type amfDecoder struct {
+	m              *go3mf.Model
+	scale          float32
+	nextFaceCheck  int
+	faceCount      int
+	ids            map[string]uint32 // amf object and constellation id -> object id
+	materials      map[string]*amfMaterial
+	base           *go3mf.BaseMaterials
+	baseIndices    map[string]uint32 // amf material id -> base index
+	colors         *materials.ColorGroup
+	colorIndices   map[color.RGBA]uint32
+	constellations map[string]*amfConstellation
+	referenced     map[string]bool
+	visiting       map[string]bool
+}
+
+func (d *amfDecoder) decode(ctx context.Context, f *amfFile) error {
+	for _, md := range f.Metadata {
+		if name, ok := metadataNames[md.Type]; ok {
+			d.m.Metadata = append(d.m.Metadata, go3mf.Metadata{Name: xml.Name{Local: name}, Value: strings.TrimSpace(md.Value)})
+		}
+	}
+	for i := range f.Materials {
+		d.materials[f.Materials[i].ID] = &f.Materials[i]
+	}
+	for i := range f.Objects {
+		if err := d.addObject(ctx, &f.Objects[i]); err != nil {
+			return fmt.Errorf("object %s: %w", f.Objects[i].ID, err)
+		}
+	}
+	for i := range f.Constellations {
+		c := &f.Constellations[i]
+		if _, ok := d.constellations[c.ID]; ok || d.ids[c.ID] != 0 {
+			return fmt.Errorf("constellation %s: %w", c.ID, specerr.ErrDuplicatedID)
+		}
+		d.constellations[c.ID] = c
+		for _, inst := range c.Instances {
+			d.referenced[inst.ObjectID] = true
+		}
+	}
+	for _, c := range f.Constellations {
+		if err := d.addConstellation(c.ID); err != nil {
+			return fmt.Errorf("constellation %s: %w", c.ID, err)
+		}
+	}
+	for _, obj := range f.Objects {
+		d.addItem(obj.ID)
+	}
+	for _, c := range f.Constellations {
+		d.addItem(c.ID)
+	}
+	return nil
+}
+
+func (d *amfDecoder) addItem(id string) {
+	if !d.referenced[id] {
+		d.m.Build.Items = append(d.m.Build.Items, &go3mf.Item{ObjectID: d.ids[id]})
+	}
+}
+
+func (d *amfDecoder) newObject(id string, md []amfMetadata) (*go3mf.Object, error) {
+	if _, ok := d.ids[id]; ok {
+		return nil, specerr.ErrDuplicatedID
+	}
+	obj := &go3mf.Object{ID: d.m.Resources.UnusedID(), Name: metadataValue(md, "name")}
+	d.ids[id] = obj.ID
+	d.m.Resources.Objects = append(d.m.Resources.Objects, obj)
+	return obj, nil
+}
+
+func (d *amfDecoder) addObject(ctx context.Context, o *amfObject) error {
+	obj, err := d.newObject(o.ID, o.Metadata)
+	if err != nil {
+		return err
+	}
+	obj.Mesh = new(go3mf.Mesh)
+	obj.Mesh.Vertices.Vertex = make([]go3mf.Point3D, len(o.Vertices))
+	for i, v := range o.Vertices {
+		obj.Mesh.Vertices.Vertex[i] = go3mf.Point3D{v.X * d.scale, v.Y * d.scale, v.Z * d.scale}
+	}
+	objColor, hasObjColor := o.Color.rgba()
+	for i, vol := range o.Volumes {
+		volColor, hasVolColor := vol.Color.rgba()
+		if !hasVolColor {
+			volColor, hasVolColor = objColor, hasObjColor
+		}
+		if err := d.addVolume(ctx, obj.Mesh, o.Vertices, &vol, volColor, hasVolColor); err != nil {
+			return fmt.Errorf("volume %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// addVolume appends the triangles of vol to mesh.
+// The triangles with colors reference the model color group,
+// and the other triangles the base material of the volume, if any.
+func (d *amfDecoder) addVolume(ctx context.Context, mesh *go3mf.Mesh, vertices []amfVertex, vol *amfVolume, volColor color.RGBA, hasVolColor bool) error {
+	var (
+		matColor    color.RGBA
+		hasMatColor bool
+		pid, pindex uint32
+	)
+	if vol.MaterialID != "" {
+		mat, ok := d.materials[vol.MaterialID]
+		if !ok {
+			return fmt.Errorf("material %s: %w", vol.MaterialID, specerr.ErrMissingResource)
+		}
+		if matColor, hasMatColor = mat.Color.rgba(); hasMatColor {
+			pid, pindex = d.baseMaterial(mat, matColor)
+		}
+	}
+	nv := uint32(len(vertices))
+	for i, tri := range vol.Triangles {
+		if tri.V1 >= nv || tri.V2 >= nv || tri.V3 >= nv {
+			return fmt.Errorf("triangle %d: %w", i, specerr.ErrIndexOutOfBounds)
+		}
+		t := go3mf.Triangle{V1: tri.V1, V2: tri.V2, V3: tri.V3, PID: pid, P1: pindex, P2: pindex, P3: pindex}
+		triColor, hasTriColor := tri.Color.rgba()
+		var (
+			corners  [3]color.RGBA
+			hasColor bool
+		)
+		for j, v := range [3]uint32{tri.V1, tri.V2, tri.V3} {
+			c, ok := triColor, hasTriColor
+			if !ok {
+				c, ok = vertices[v].Color.rgba()
+			}
+			if !ok {
+				c, ok = volColor, hasVolColor
+			}
+			if ok {
+				hasColor = true
+			} else if hasMatColor {
+				c = matColor
+			} else {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			corners[j] = c
+		}
+		if hasColor {
+			t.PID = d.colorGroup()
+			t.P1, t.P2, t.P3 = d.color(corners[0]), d.color(corners[1]), d.color(corners[2])
+		}
+		mesh.Triangles.Triangle = append(mesh.Triangles.Triangle, t)
+		d.faceCount++
+		if d.faceCount > d.nextFaceCheck {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default: // Default is must to avoid blocking
+			}
+			d.nextFaceCheck += checkEveryFaces
+		}
+	}
+	return nil
+}
+
+// addConstellation adds the components object of the constellation id
+// after the constellations it instantiates.
+func (d *amfDecoder) addConstellation(id string) error {
+	if _, ok := d.ids[id]; ok {
+		return nil
+	}
+	if d.visiting[id] {
+		return specerr.ErrRecursion
+	}
+	c := d.constellations[id]
+	d.visiting[id] = true
+	defer delete(d.visiting, id)
+	for i, inst := range c.Instances {
+		if _, ok := d.constellations[inst.ObjectID]; ok {
+			if err := d.addConstellation(inst.ObjectID); err != nil {
+				return fmt.Errorf("instance %d: %w", i, err)
+			}
+		}
+	}
+	obj, err := d.newObject(id, c.Metadata)
+	if err != nil {
+		return err
+	}
+	obj.Components = new(go3mf.Components)
+	for i, inst := range c.Instances {
+		objectID, ok := d.ids[inst.ObjectID]
+		if !ok {
+			return fmt.Errorf("instance %d: %w", i, specerr.ErrMissingResource)
+		}
+		obj.Components.Component = append(obj.Components.Component, &go3mf.Component{
+			ObjectID:  objectID,
+			Transform: d.transform(inst),
+		})
+	}
+	return nil
+}
+
+// transform returns the instance transform, which rotates around
+// the x, y and z axes, in that order, and then translates.
+func (d *amfDecoder) transform(inst amfInstance) go3mf.Matrix {
+	if inst == (amfInstance{ObjectID: inst.ObjectID}) {
+		return go3mf.Matrix{}
+	}
+	t := go3mf.Identity().Translate(inst.DeltaX*d.scale, inst.DeltaY*d.scale, inst.DeltaZ*d.scale)
+	return t.Mul(rotation(2, inst.RZ)).Mul(rotation(1, inst.RY)).Mul(rotation(0, inst.RX))
+}
+
+// rotation returns the rotation of deg degrees around the axis, where 0 is x, 1 is y and 2 is z.
+func rotation(axis int, deg float32) go3mf.Matrix {
+	m := go3mf.Identity()
+	if deg == 0 {
+		return m
+	}
+	rad := float64(deg) * math.Pi / 180
+	sin, cos := float32(math.Sin(rad)), float32(math.Cos(rad))
+	j, k := (axis+1)%3, (axis+2)%3
+	m[4*j+j], m[4*j+k] = cos, sin
+	m[4*k+j], m[4*k+k] = -sin, cos
+	return m
+}
+
+// baseMaterial adds the material to the base materials of the model,
+// which are created the first time, and returns their ID and the material index.
+func (d *amfDecoder) baseMaterial(mat *amfMaterial, c color.RGBA) (uint32, uint32) {
+	if d.base == nil {
+		d.base = &go3mf.BaseMaterials{ID: d.m.Resources.UnusedID()}
+		d.m.Resources.Assets = append(d.m.Resources.Assets, d.base)
+	}
+	index, ok := d.baseIndices[mat.ID]
+	if !ok {
+		name := metadataValue(mat.Metadata, "name")
+		if name == "" {
+			name = "material" + mat.ID
+		}
+		index = uint32(len(d.base.Materials))
+		d.baseIndices[mat.ID] = index
+		d.base.Materials = append(d.base.Materials, go3mf.Base{Name: name, Color: c})
+	}
+	return d.base.ID, index
+}
+
+// colorGroup returns the ID of the model color group, which is created the first time.
+func (d *amfDecoder) colorGroup() uint32 {
+	if d.colors == nil {
+		d.colors = &materials.ColorGroup{ID: d.m.Resources.UnusedID()}
+		d.m.Resources.Assets = append(d.m.Resources.Assets, d.colors)
+		materials.Declare(d.m)
+	}
+	return d.colors.ID
+}
+
+func (d *amfDecoder) color(c color.RGBA) uint32 {
+	index, ok := d.colorIndices[c]
+	if !ok {
+		index = uint32(len(d.colors.Colors))
+		d.colorIndices[c] = index
+		d.colors.Colors = append(d.colors.Colors, c)
+	}
+	return index
+}
+
+// rgba returns the color, which is not ok if c is nil or its channels are not numbers.
+func (c *amfColor) rgba() (color.RGBA, bool) {
+	if c == nil {
+		return color.RGBA{}, false
+	}
+	var ch [4]uint8
+	for i, s := range [4]string{c.R, c.G, c.B, c.A} {
+		s = strings.TrimSpace(s)
+		if s == "" && i == 3 {
+			ch[i] = 0xff
+			continue
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return color.RGBA{}, false
+		}
+		ch[i] = uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+	return color.RGBA{R: ch[0], G: ch[1], B: ch[2], A: ch[3]}, true
+}
+
+func metadataValue(md []amfMetadata, typ string) string {
+	for _, m := range md {
+		if m.Type == typ {
+			return strings.TrimSpace(m.Value)
+		}
+	}
+	return ""
+}
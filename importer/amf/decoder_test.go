@@ -0,0 +1,251 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package amf
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"image/color"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+)
+
+const amfTetrahedron = `<?xml version="1.0" encoding="UTF-8"?>
+<amf unit="inch" version="1.1">
+  <metadata type="name">Tetrahedron</metadata>
+  <metadata type="author">HP</metadata>
+  <metadata type="volume">10</metadata>
+  <material id="2">
+    <metadata type="name">Red</metadata>
+    <color><r>1</r><g>0</g><b>0</b></color>
+  </material>
+  <object id="1">
+    <metadata type="name">part</metadata>
+    <mesh>
+      <vertices>
+        <vertex><coordinates><x>0</x><y>0</y><z>0</z></coordinates></vertex>
+        <vertex><coordinates><x>1</x><y>0</y><z>0</z></coordinates></vertex>
+        <vertex><coordinates><x>0</x><y>1</y><z>0</z></coordinates></vertex>
+        <vertex><coordinates><x>0</x><y>0</y><z>1</z></coordinates></vertex>
+      </vertices>
+      <volume materialid="2">
+        <triangle><v1>0</v1><v2>2</v2><v3>1</v3></triangle>
+        <triangle><v1>0</v1><v2>3</v2><v3>2</v3></triangle>
+      </volume>
+      <volume>
+        <color><r>0</r><g>1</g><b>0</b></color>
+        <triangle><v1>0</v1><v2>1</v2><v3>3</v3></triangle>
+        <triangle><color><r>0</r><g>0</g><b>1</b></color><v1>1</v1><v2>2</v2><v3>3</v3></triangle>
+      </volume>
+    </mesh>
+  </object>
+  <constellation id="3">
+    <instance objectid="1"/>
+    <instance objectid="1"><deltax>2</deltax><rz>90</rz></instance>
+  </constellation>
+</amf>`
+
+var (
+	colorRed       = color.RGBA{R: 255, A: 255}
+	colorGreen     = color.RGBA{G: 255, A: 255}
+	colorBlue      = color.RGBA{B: 255, A: 255}
+	colorBlueAlpha = color.RGBA{B: 255, A: 128}
+)
+
+func TestNewDecoder(t *testing.T) {
+	r := new(bytes.Buffer)
+	if got := NewDecoder(r); !reflect.DeepEqual(got, &Decoder{r: r}) {
+		t.Errorf("NewDecoder() = %v", got)
+	}
+}
+
+func zipped(name, content string) []byte {
+	var b bytes.Buffer
+	z := zip.NewWriter(&b)
+	z.Create("readme.txt")
+	if name != "" {
+		w, _ := z.Create(name)
+		io.WriteString(w, content)
+	}
+	z.Close()
+	return b.Bytes()
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	tests := []struct {
+		name string
+		r    io.Reader
+	}{
+		{"plain", strings.NewReader(amfTetrahedron)},
+		{"zip", bytes.NewReader(zipped("tetrahedron.amf", amfTetrahedron))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := new(go3mf.Model)
+			if err := NewDecoder(tt.r).Decode(got); err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			want := &go3mf.Model{
+				Units:      go3mf.UnitInch,
+				Extensions: []go3mf.Extension{materials.DefaultExtension},
+				Metadata: []go3mf.Metadata{
+					{Name: xml.Name{Local: "Title"}, Value: "Tetrahedron"},
+					{Name: xml.Name{Local: "Designer"}, Value: "HP"},
+				},
+			}
+			want.Resources.Assets = []go3mf.Asset{
+				&go3mf.BaseMaterials{ID: 2, Materials: []go3mf.Base{{Name: "Red", Color: colorRed}}},
+				&materials.ColorGroup{ID: 3, Colors: []color.RGBA{colorGreen, colorBlue}},
+			}
+			want.Resources.Objects = []*go3mf.Object{
+				{ID: 1, Name: "part", Mesh: &go3mf.Mesh{
+					Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}},
+					Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+						{V1: 0, V2: 2, V3: 1, PID: 2},
+						{V1: 0, V2: 3, V3: 2, PID: 2},
+						{V1: 0, V2: 1, V3: 3, PID: 3, P1: 0, P2: 0, P3: 0},
+						{V1: 1, V2: 2, V3: 3, PID: 3, P1: 1, P2: 1, P3: 1},
+					}},
+				}},
+				{ID: 4, Components: &go3mf.Components{Component: []*go3mf.Component{
+					{ObjectID: 1},
+					{ObjectID: 1, Transform: go3mf.Matrix{0, 1, 0, 0, -1, 0, 0, 0, 0, 0, 1, 0, 2, 0, 0, 1}},
+				}}},
+			}
+			want.Build.Items = []*go3mf.Item{{ObjectID: 4}}
+			deep.FloatPrecision = 6
+			defer func() { deep.FloatPrecision = 10 }()
+			if diff := deep.Equal(got, want); diff != nil {
+				t.Errorf("Decoder.Decode() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_colors(t *testing.T) {
+	src := `<amf>
+  <material id="5"><color><r>1</r><g>0</g><b>0</b></color></material>
+  <object id="1">
+    <color><r>0</r><g>1</g><b>0</b></color>
+    <mesh>
+      <vertices>
+        <vertex><coordinates><x>0</x><y>0</y><z>0</z></coordinates></vertex>
+        <vertex><coordinates><x>1</x><y>0</y><z>0</z></coordinates></vertex>
+        <vertex><coordinates><x>0</x><y>1</y><z>0</z></coordinates><color><r>0</r><g>0</g><b>1</b><a>0.5</a></color></vertex>
+      </vertices>
+      <volume><triangle><v1>0</v1><v2>1</v2><v3>2</v3></triangle></volume>
+    </mesh>
+  </object>
+  <object id="2">
+    <mesh>
+      <vertices>
+        <vertex><coordinates><x>0</x><y>0</y><z>0</z></coordinates></vertex>
+        <vertex><coordinates><x>1</x><y>0</y><z>0</z></coordinates></vertex>
+        <vertex><coordinates><x>0</x><y>1</y><z>0</z></coordinates><color><r>0</r><g>0</g><b>1</b><a>0.5</a></color></vertex>
+      </vertices>
+      <volume materialid="5">
+        <triangle><v1>0</v1><v2>1</v2><v3>2</v3></triangle>
+        <triangle><color><r>sin(x)</r><g>0</g><b>0</b></color><v1>0</v1><v2>1</v2><v3>2</v3></triangle>
+      </volume>
+    </mesh>
+  </object>
+</amf>`
+	got := new(go3mf.Model)
+	if err := NewDecoder(strings.NewReader(src)).Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	// The vertex colors take precedence over the object color,
+	// and the material color is used for the corners without colors.
+	want := []go3mf.Asset{
+		&materials.ColorGroup{ID: 2, Colors: []color.RGBA{colorGreen, colorBlueAlpha, colorRed}},
+		&go3mf.BaseMaterials{ID: 4, Materials: []go3mf.Base{{Name: "material5", Color: colorRed}}},
+	}
+	if diff := deep.Equal(got.Resources.Assets, want); diff != nil {
+		t.Errorf("Decoder.Decode() assets = %v", diff)
+	}
+	wantTriangles := [][]go3mf.Triangle{
+		{{V1: 0, V2: 1, V3: 2, PID: 2, P1: 0, P2: 0, P3: 1}},
+		{{V1: 0, V2: 1, V3: 2, PID: 2, P1: 2, P2: 2, P3: 1}, {V1: 0, V2: 1, V3: 2, PID: 2, P1: 2, P2: 2, P3: 1}},
+	}
+	for i, obj := range got.Resources.Objects {
+		if diff := deep.Equal(obj.Mesh.Triangles.Triangle, wantTriangles[i]); diff != nil {
+			t.Errorf("Decoder.Decode() object %d = %v", i, diff)
+		}
+	}
+	if len(got.Build.Items) != 2 || got.Units != go3mf.UnitMillimeter {
+		t.Errorf("Decoder.Decode() items = %v, units = %v", got.Build.Items, got.Units)
+	}
+}
+
+func TestDecoder_Decode_convertUnits(t *testing.T) {
+	got := &go3mf.Model{Units: go3mf.UnitMillimeter}
+	d := NewDecoder(strings.NewReader(amfTetrahedron))
+	d.ConvertUnits = true
+	if err := d.Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	if got.Units != go3mf.UnitMillimeter {
+		t.Errorf("Decoder.Decode() units = %v", got.Units)
+	}
+	want := []go3mf.Point3D{{0, 0, 0}, {25.4, 0, 0}, {0, 25.4, 0}, {0, 0, 25.4}}
+	if diff := deep.Equal(got.Resources.Objects[0].Mesh.Vertices.Vertex, want); diff != nil {
+		t.Errorf("Decoder.Decode() vertices = %v", diff)
+	}
+	if p := got.Resources.Objects[1].Components.Component[1].Transform.Mul3D(go3mf.Point3D{}); p != (go3mf.Point3D{50.8, 0, 0}) {
+		t.Errorf("Decoder.Decode() translation = %v", p)
+	}
+}
+
+func TestDecoder_Decode_error(t *testing.T) {
+	checkEveryFaces = 0
+	defer func() { checkEveryFaces = 1000 }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	object := func(id, volume string) string {
+		return `<object id="` + id + `"><mesh><vertices>
+<vertex><coordinates><x>0</x><y>0</y><z>0</z></coordinates></vertex>
+<vertex><coordinates><x>1</x><y>0</y><z>0</z></coordinates></vertex>
+<vertex><coordinates><x>0</x><y>1</y><z>0</z></coordinates></vertex>
+</vertices>` + volume + `</mesh></object>`
+	}
+	triangle := `<volume><triangle><v1>0</v1><v2>1</v2><v3>2</v3></triangle></volume>`
+	tests := []struct {
+		name    string
+		r       io.Reader
+		ctx     context.Context
+		wantErr error
+	}{
+		{"units", strings.NewReader(`<amf unit="parsec"/>`), context.Background(), ErrUnits},
+		{"emptyZip", bytes.NewReader(zipped("", "")), context.Background(), ErrNoModel},
+		{"material", strings.NewReader(`<amf>` + object("1", `<volume materialid="2"/>`) + `</amf>`), context.Background(), specerr.ErrMissingResource},
+		{"index", strings.NewReader(`<amf>` + object("1", `<volume><triangle><v1>0</v1><v2>1</v2><v3>3</v3></triangle></volume>`) + `</amf>`), context.Background(), specerr.ErrIndexOutOfBounds},
+		{"duplicated", strings.NewReader(`<amf>` + object("1", "") + `<constellation id="1"/></amf>`), context.Background(), specerr.ErrDuplicatedID},
+		{"instance", strings.NewReader(`<amf><constellation id="1"><instance objectid="2"/></constellation></amf>`), context.Background(), specerr.ErrMissingResource},
+		{"recursive", strings.NewReader(`<amf><constellation id="1"><instance objectid="2"/></constellation><constellation id="2"><instance objectid="1"/></constellation></amf>`), context.Background(), specerr.ErrRecursion},
+		{"cancel", strings.NewReader(`<amf>` + object("1", triangle) + `</amf>`), ctx, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewDecoder(tt.r).DecodeContext(tt.ctx, new(go3mf.Model)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decoder.DecodeContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_invalid(t *testing.T) {
+	if err := NewDecoder(strings.NewReader(`<amf><object id="1"><mesh><vertices><vertex><coordinates><x>a</x></coordinates></vertex></vertices></mesh></object></amf>`)).Decode(new(go3mf.Model)); err == nil {
+		t.Error("Decoder.Decode() expected error")
+	}
+}
@@ -0,0 +1,56 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package amf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/importer"
+)
+
+func init() {
+	importer.Register(importer.Format{
+		Name:       "amf",
+		Extensions: []string{".amf"},
+		Sniff:      sniff,
+		Decode: func(ctx context.Context, r io.Reader, _ importer.OpenFunc, m *go3mf.Model) error {
+			return NewDecoder(r).DecodeContext(ctx, m)
+		},
+	})
+}
+
+// sniff reports whether the root element of b, after the XML prolog,
+// is an amf element, or b is a zip archive containing an amf file.
+func sniff(b []byte) bool {
+	if importer.IsZip(b, func(name string) bool {
+		return strings.EqualFold(path.Ext(name), ".amf")
+	}) {
+		return true
+	}
+	if len(b) > 1024 {
+		b = b[:1024]
+	}
+	x := xml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))))
+	x.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+	for {
+		t, err := x.RawToken()
+		if err != nil {
+			return false
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			return t.Name.Local == "amf"
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) != 0 {
+				return false
+			}
+		}
+	}
+}
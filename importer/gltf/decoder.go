@@ -0,0 +1,777 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package gltf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+)
+
+var checkEveryFaces = 1000
+
+// Decoder can decode a glTF 2.0, either binary (GLB) or JSON.
+//
+// Each mesh is decoded as a mesh object, merging its triangle primitives and the vertices
+// with the same position. Each node with children is decoded as a components object,
+// and the nodes of the scene are referenced by new build items.
+//
+// The base color texture of the materials is attached to the model, in go3mf.Default3DTexturesDir,
+// as a materials.Texture2D, and the texture coordinates are decoded into a materials.Texture2DGroup.
+// Else the vertex colors are decoded into a materials.ColorGroup, and else the base color factor
+// is decoded into a single go3mf.BaseMaterials.
+//
+// Open is used to read the buffers and images that are not embedded.
+// If it is nil only embedded data is supported.
+type Decoder struct {
+	Open func(name string) (io.ReadCloser, error)
+	r    io.Reader
+}
+
+// NewDecoder creates a new decoder.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: r,
+	}
+}
+
+// Decode creates a model from a read stream.
+func (d *Decoder) Decode(m *go3mf.Model) error {
+	return d.DecodeContext(context.Background(), m)
+}
+
+// DecodeContext creates a model from a read stream.
+func (d *Decoder) DecodeContext(ctx context.Context, m *go3mf.Model) error {
+	b, err := ioutil.ReadAll(d.r)
+	if err != nil {
+		return fmt.Errorf("gltf: %w", err)
+	}
+	js, bin := b, []byte(nil)
+	if len(b) >= 4 && binary.LittleEndian.Uint32(b) == glbMagic {
+		if js, bin, err = readGLB(b); err != nil {
+			return fmt.Errorf("gltf: %w", err)
+		}
+	}
+	gd := gltfDecoder{
+		d:             d,
+		m:             m,
+		nextFaceCheck: checkEveryFaces,
+		meshes:        make(map[int]uint32),
+		baseIndices:   make(map[int]uint32),
+		colorIndices:  make(map[color.RGBA]uint32),
+		textures:      make(map[int]*textureGroup),
+		visiting:      make(map[int]bool),
+	}
+	if err := json.Unmarshal(js, &gd.doc); err != nil {
+		return fmt.Errorf("gltf: %w", err)
+	}
+	if !strings.HasPrefix(gd.doc.Asset.Version, "2.") {
+		return fmt.Errorf("gltf: %w", ErrVersion)
+	}
+	if err := gd.loadBuffers(bin); err != nil {
+		return fmt.Errorf("gltf: %w", err)
+	}
+	if err := gd.decode(ctx); err != nil {
+		return fmt.Errorf("gltf: %w", err)
+	}
+	return nil
+}
+
+type textureGroup struct {
+	group  *materials.Texture2DGroup
+	coords map[materials.TextureCoord]uint32
+}
+
+type gltfDecoder struct {
+	d             *Decoder
+	m             *go3mf.Model
+	doc           document
+	buffers       [][]byte
+	nextFaceCheck int
+	faceCount     int
+	meshes        map[int]uint32 // mesh index -> object id
+	base          *go3mf.BaseMaterials
+	baseIndices   map[int]uint32 // material index -> base index
+	colors        *materials.ColorGroup
+	colorIndices  map[color.RGBA]uint32
+	textures      map[int]*textureGroup // texture index -> group
+	visiting      map[int]bool
+}
+
+func (d *gltfDecoder) loadBuffers(bin []byte) error {
+	d.buffers = make([][]byte, len(d.doc.Buffers))
+	for i, b := range d.doc.Buffers {
+		if b.URI == "" {
+			if i != 0 || bin == nil {
+				return fmt.Errorf("buffer %d: %w", i, ErrURI)
+			}
+			d.buffers[i] = bin
+			continue
+		}
+		data, _, err := d.readURI(b.URI)
+		if err != nil {
+			return fmt.Errorf("buffer %d: %w", i, err)
+		}
+		if len(data) < b.ByteLength {
+			return fmt.Errorf("buffer %d: %w", i, io.ErrUnexpectedEOF)
+		}
+		d.buffers[i] = data
+	}
+	return nil
+}
+
+// readURI returns the data referenced by uri, which is either a data uri
+// or a file opened with Open, and the media type of the data uris.
+func (d *gltfDecoder) readURI(uri string) ([]byte, string, error) {
+	if strings.HasPrefix(uri, "data:") {
+		comma := strings.IndexByte(uri, ',')
+		if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+			return nil, "", ErrURI
+		}
+		data, err := base64.StdEncoding.DecodeString(uri[comma+1:])
+		return data, strings.TrimSuffix(uri[len("data:"):comma], ";base64"), err
+	}
+	if d.d.Open == nil {
+		return nil, "", ErrURI
+	}
+	name, err := url.PathUnescape(uri)
+	if err != nil {
+		return nil, "", ErrURI
+	}
+	f, err := d.d.Open(name)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	return data, "", err
+}
+
+func (d *gltfDecoder) decode(ctx context.Context) error {
+	var roots []int
+	if len(d.doc.Scenes) > 0 {
+		scene := 0
+		if d.doc.Scene != nil {
+			scene = *d.doc.Scene
+		}
+		if scene < 0 || scene >= len(d.doc.Scenes) {
+			return fmt.Errorf("scene %d: %w", scene, ErrIndex)
+		}
+		roots = d.doc.Scenes[scene].Nodes
+	} else {
+		roots = d.rootNodes()
+	}
+	root := rootInverse(d.m.Units)
+	for _, n := range roots {
+		id, transform, ok, err := d.addNode(ctx, n)
+		if err != nil {
+			return fmt.Errorf("node %d: %w", n, err)
+		}
+		if ok {
+			d.m.Build.Items = append(d.m.Build.Items, &go3mf.Item{ObjectID: id, Transform: root.Mul(transform)})
+		}
+	}
+	return nil
+}
+
+// rootNodes returns the nodes that are not children of other nodes.
+func (d *gltfDecoder) rootNodes() []int {
+	child := make(map[int]bool)
+	for _, n := range d.doc.Nodes {
+		for _, c := range n.Children {
+			child[c] = true
+		}
+	}
+	var roots []int
+	for i := range d.doc.Nodes {
+		if !child[i] {
+			roots = append(roots, i)
+		}
+	}
+	return roots
+}
+
+// addNode adds the objects of the node index and returns the id of its object and its transform.
+// It is not ok if the node and its children do not have any mesh.
+func (d *gltfDecoder) addNode(ctx context.Context, index int) (uint32, go3mf.Matrix, bool, error) {
+	if index < 0 || index >= len(d.doc.Nodes) {
+		return 0, go3mf.Matrix{}, false, ErrIndex
+	}
+	if d.visiting[index] {
+		return 0, go3mf.Matrix{}, false, specerr.ErrRecursion
+	}
+	d.visiting[index] = true
+	defer delete(d.visiting, index)
+	n := &d.doc.Nodes[index]
+	transform := nodeMatrix(n)
+	var components []*go3mf.Component
+	if n.Mesh != nil {
+		id, ok, err := d.addMesh(ctx, *n.Mesh)
+		if err != nil {
+			return 0, transform, false, fmt.Errorf("mesh %d: %w", *n.Mesh, err)
+		}
+		if ok && len(n.Children) == 0 {
+			return id, transform, true, nil
+		}
+		if ok {
+			components = append(components, &go3mf.Component{ObjectID: id})
+		}
+	}
+	for _, c := range n.Children {
+		id, ct, ok, err := d.addNode(ctx, c)
+		if err != nil {
+			return 0, transform, false, fmt.Errorf("node %d: %w", c, err)
+		}
+		if ok {
+			if ct == go3mf.Identity() {
+				ct = go3mf.Matrix{}
+			}
+			components = append(components, &go3mf.Component{ObjectID: id, Transform: ct})
+		}
+	}
+	if len(components) == 0 {
+		return 0, transform, false, nil
+	}
+	obj := &go3mf.Object{
+		ID:         d.m.Resources.UnusedID(),
+		Name:       n.Name,
+		Components: &go3mf.Components{Component: components},
+	}
+	d.m.Resources.Objects = append(d.m.Resources.Objects, obj)
+	return obj.ID, transform, true, nil
+}
+
+// nodeMatrix returns the matrix of n, composing the translation,
+// rotation and scale if the matrix is not defined.
+func nodeMatrix(n *node) go3mf.Matrix {
+	if len(n.Matrix) == 16 {
+		var m go3mf.Matrix
+		copy(m[:], n.Matrix)
+		return m
+	}
+	m := go3mf.Identity()
+	if len(n.Rotation) == 4 {
+		x, y, z, w := n.Rotation[0], n.Rotation[1], n.Rotation[2], n.Rotation[3]
+		m = go3mf.Matrix{
+			1 - 2*(y*y+z*z), 2 * (x*y + z*w), 2 * (x*z - y*w), 0,
+			2 * (x*y - z*w), 1 - 2*(x*x+z*z), 2 * (y*z + x*w), 0,
+			2 * (x*z + y*w), 2 * (y*z - x*w), 1 - 2*(x*x+y*y), 0,
+			0, 0, 0, 1,
+		}
+	}
+	if len(n.Scale) == 3 {
+		for c := 0; c < 3; c++ {
+			for r := 0; r < 3; r++ {
+				m[4*c+r] *= n.Scale[c]
+			}
+		}
+	}
+	if len(n.Translation) == 3 {
+		m = m.Translate(n.Translation[0], n.Translation[1], n.Translation[2])
+	}
+	return m
+}
+
+// addMesh adds the mesh object of the mesh index the first time and returns its id.
+// It is not ok if the mesh does not have any triangle.
+func (d *gltfDecoder) addMesh(ctx context.Context, index int) (uint32, bool, error) {
+	if id, ok := d.meshes[index]; ok {
+		return id, id != 0, nil
+	}
+	if index < 0 || index >= len(d.doc.Meshes) {
+		return 0, false, ErrIndex
+	}
+	msh := &d.doc.Meshes[index]
+	obj := &go3mf.Object{ID: d.m.Resources.UnusedID(), Name: msh.Name, Mesh: new(go3mf.Mesh)}
+	d.m.Resources.Objects = append(d.m.Resources.Objects, obj)
+	mb := go3mf.NewMeshBuilder(obj.Mesh)
+	for i := range msh.Primitives {
+		if err := d.addPrimitive(ctx, mb, &msh.Primitives[i]); err != nil {
+			return 0, false, fmt.Errorf("primitive %d: %w", i, err)
+		}
+	}
+	if len(obj.Mesh.Triangles.Triangle) == 0 {
+		d.m.Resources.Objects = d.m.Resources.Objects[:len(d.m.Resources.Objects)-1]
+		d.meshes[index] = 0
+		return 0, false, nil
+	}
+	d.meshes[index] = obj.ID
+	return obj.ID, true, nil
+}
+
+func (d *gltfDecoder) addPrimitive(ctx context.Context, mb *go3mf.MeshBuilder, p *primitive) error {
+	mode := modeTriangles
+	if p.Mode != nil {
+		mode = *p.Mode
+	}
+	if mode != modeTriangles && mode != modeTriangleStrip && mode != modeTriangleFan {
+		return nil
+	}
+	position, ok := p.Attributes["POSITION"]
+	if !ok {
+		return nil
+	}
+	positions, err := d.readAccessor(position, "VEC3")
+	if err != nil {
+		return fmt.Errorf("POSITION: %w", err)
+	}
+	count := len(positions) / 3
+	var indices []uint32
+	if p.Indices != nil {
+		values, err := d.readAccessor(*p.Indices, "SCALAR")
+		if err != nil {
+			return fmt.Errorf("indices: %w", err)
+		}
+		indices = make([]uint32, len(values))
+		for i, v := range values {
+			if v < 0 || int(v) >= count {
+				return fmt.Errorf("indices: %w", ErrIndex)
+			}
+			indices[i] = uint32(v)
+		}
+	} else {
+		indices = make([]uint32, count)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+	props, err := d.properties(p, count)
+	if err != nil {
+		return err
+	}
+	vertices := make([]uint32, count)
+	for i := range vertices {
+		vertices[i] = mb.AddVertex(go3mf.Point3D{float32(positions[3*i]), float32(positions[3*i+1]), float32(positions[3*i+2])})
+	}
+	mesh := mb.Mesh
+	for _, tri := range triangles(mode, indices) {
+		t := go3mf.Triangle{V1: vertices[tri[0]], V2: vertices[tri[1]], V3: vertices[tri[2]]}
+		if t.V1 == t.V2 || t.V1 == t.V3 || t.V2 == t.V3 {
+			continue
+		}
+		if props != nil {
+			t.PID, t.P1, t.P2, t.P3 = props.pid, props.indices[tri[0]], props.indices[tri[1]], props.indices[tri[2]]
+		}
+		mesh.Triangles.Triangle = append(mesh.Triangles.Triangle, t)
+		d.faceCount++
+		if d.faceCount > d.nextFaceCheck {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default: // Default is must to avoid blocking
+			}
+			d.nextFaceCheck += checkEveryFaces
+		}
+	}
+	return nil
+}
+
+// triangles returns the vertex indices of each triangle of a primitive.
+func triangles(mode int, indices []uint32) [][3]uint32 {
+	var tris [][3]uint32
+	switch mode {
+	case modeTriangleStrip:
+		for i := 2; i < len(indices); i++ {
+			if i%2 == 0 {
+				tris = append(tris, [3]uint32{indices[i-2], indices[i-1], indices[i]})
+			} else {
+				tris = append(tris, [3]uint32{indices[i-1], indices[i-2], indices[i]})
+			}
+		}
+	case modeTriangleFan:
+		for i := 2; i < len(indices); i++ {
+			tris = append(tris, [3]uint32{indices[0], indices[i-1], indices[i]})
+		}
+	default:
+		for i := 2; i < len(indices); i += 3 {
+			tris = append(tris, [3]uint32{indices[i-2], indices[i-1], indices[i]})
+		}
+	}
+	return tris
+}
+
+// vertexProperties are the property group and the property index of each vertex of a primitive.
+type vertexProperties struct {
+	pid     uint32
+	indices []uint32
+}
+
+// properties returns the vertex properties of p, or nil if it has no material nor vertex colors.
+func (d *gltfDecoder) properties(p *primitive, count int) (*vertexProperties, error) {
+	var (
+		mat    *material
+		factor = [4]float32{1, 1, 1, 1}
+	)
+	if p.Material != nil {
+		if *p.Material < 0 || *p.Material >= len(d.doc.Materials) {
+			return nil, fmt.Errorf("material: %w", ErrIndex)
+		}
+		mat = &d.doc.Materials[*p.Material]
+		if mat.PBR != nil && len(mat.PBR.BaseColorFactor) == 4 {
+			copy(factor[:], mat.PBR.BaseColorFactor)
+		}
+	}
+	if mat != nil && mat.PBR != nil && mat.PBR.BaseColorTexture != nil {
+		ref := mat.PBR.BaseColorTexture
+		if uv, ok := p.Attributes["TEXCOORD_"+strconv.Itoa(ref.TexCoord)]; ok {
+			tg, err := d.texture(ref.Index)
+			if err != nil {
+				return nil, fmt.Errorf("texture %d: %w", ref.Index, err)
+			}
+			if tg != nil {
+				uvs, err := d.readAccessor(uv, "VEC2")
+				if err != nil {
+					return nil, fmt.Errorf("TEXCOORD_%d: %w", ref.TexCoord, err)
+				}
+				if len(uvs) != 2*count {
+					return nil, fmt.Errorf("TEXCOORD_%d: %w", ref.TexCoord, ErrAccessor)
+				}
+				props := &vertexProperties{pid: tg.group.ID, indices: make([]uint32, count)}
+				for i := range props.indices {
+					// glTF texture coordinates start at the top left corner.
+					props.indices[i] = tg.coord(materials.TextureCoord{float32(uvs[2*i]), float32(1 - uvs[2*i+1])})
+				}
+				return props, nil
+			}
+		}
+	}
+	if c, ok := p.Attributes["COLOR_0"]; ok {
+		colors, size, err := d.readColors(c)
+		if err != nil {
+			return nil, fmt.Errorf("COLOR_0: %w", err)
+		}
+		if len(colors) != size*count {
+			return nil, fmt.Errorf("COLOR_0: %w", ErrAccessor)
+		}
+		props := &vertexProperties{pid: d.colorGroup(), indices: make([]uint32, count)}
+		for i := range props.indices {
+			rgba := [4]float32{1, 1, 1, 1}
+			for j, v := range colors[size*i : size*i+size] {
+				rgba[j] = float32(v)
+			}
+			props.indices[i] = d.color(color.RGBA{
+				R: toSRGB(rgba[0] * factor[0]),
+				G: toSRGB(rgba[1] * factor[1]),
+				B: toSRGB(rgba[2] * factor[2]),
+				A: uint8(math.Round(float64(clamp(rgba[3]*factor[3]) * 255))),
+			})
+		}
+		return props, nil
+	}
+	if mat == nil {
+		return nil, nil
+	}
+	pid, index := d.baseMaterial(*p.Material, mat, factor)
+	props := &vertexProperties{pid: pid, indices: make([]uint32, count)}
+	for i := range props.indices {
+		props.indices[i] = index
+	}
+	return props, nil
+}
+
+func clamp(v float32) float32 {
+	return float32(math.Max(0, math.Min(1, float64(v))))
+}
+
+// readColors reads the vertex colors accessor, which can be VEC3 or VEC4,
+// and returns the number of channels.
+func (d *gltfDecoder) readColors(index int) ([]float64, int, error) {
+	if index < 0 || index >= len(d.doc.Accessors) {
+		return nil, 0, ErrIndex
+	}
+	typ := d.doc.Accessors[index].Type
+	if typ != "VEC3" {
+		typ = "VEC4"
+	}
+	values, err := d.readAccessor(index, typ)
+	return values, typeSize(typ), err
+}
+
+// baseMaterial adds the material index to the base materials of the model,
+// which are created the first time, and returns their ID and the base index.
+func (d *gltfDecoder) baseMaterial(index int, mat *material, factor [4]float32) (uint32, uint32) {
+	if d.base == nil {
+		d.base = &go3mf.BaseMaterials{ID: d.m.Resources.UnusedID()}
+		d.m.Resources.Assets = append(d.m.Resources.Assets, d.base)
+	}
+	baseIndex, ok := d.baseIndices[index]
+	if !ok {
+		name := mat.Name
+		if name == "" {
+			name = "material" + strconv.Itoa(index)
+		}
+		baseIndex = uint32(len(d.base.Materials))
+		d.baseIndices[index] = baseIndex
+		d.base.Materials = append(d.base.Materials, go3mf.Base{Name: name, Color: color.RGBA{
+			R: toSRGB(factor[0]),
+			G: toSRGB(factor[1]),
+			B: toSRGB(factor[2]),
+			A: uint8(math.Round(float64(clamp(factor[3]) * 255))),
+		}})
+	}
+	return d.base.ID, baseIndex
+}
+
+// colorGroup returns the ID of the model color group, which is created the first time.
+func (d *gltfDecoder) colorGroup() uint32 {
+	if d.colors == nil {
+		d.colors = &materials.ColorGroup{ID: d.m.Resources.UnusedID()}
+		d.m.Resources.Assets = append(d.m.Resources.Assets, d.colors)
+		materials.Declare(d.m)
+	}
+	return d.colors.ID
+}
+
+func (d *gltfDecoder) color(c color.RGBA) uint32 {
+	index, ok := d.colorIndices[c]
+	if !ok {
+		index = uint32(len(d.colors.Colors))
+		d.colorIndices[c] = index
+		d.colors.Colors = append(d.colors.Colors, c)
+	}
+	return index
+}
+
+func (tg *textureGroup) coord(c materials.TextureCoord) uint32 {
+	if index, ok := tg.coords[c]; ok {
+		return index
+	}
+	index := uint32(len(tg.group.Coords))
+	tg.group.Coords = append(tg.group.Coords, c)
+	tg.coords[c] = index
+	return index
+}
+
+// texture returns the texture group of the texture index,
+// attaching its image to the model the first time it is used.
+// It returns nil if the image format is not supported.
+func (d *gltfDecoder) texture(index int) (*textureGroup, error) {
+	if tg, ok := d.textures[index]; ok {
+		return tg, nil
+	}
+	if index < 0 || index >= len(d.doc.Textures) {
+		return nil, ErrIndex
+	}
+	tex := d.doc.Textures[index]
+	if tex.Source == nil {
+		d.textures[index] = nil
+		return nil, nil
+	}
+	if *tex.Source < 0 || *tex.Source >= len(d.doc.Images) {
+		return nil, ErrIndex
+	}
+	img := d.doc.Images[*tex.Source]
+	var (
+		data      []byte
+		mediaType = img.MimeType
+		err       error
+	)
+	if img.BufferView != nil {
+		data, err = d.readBufferView(*img.BufferView)
+	} else {
+		var uriType string
+		data, uriType, err = d.readURI(img.URI)
+		if mediaType == "" {
+			mediaType = uriType
+		}
+		if mediaType == "" {
+			mediaType = map[string]string{".png": "image/png", ".jpg": "image/jpeg", ".jpeg": "image/jpeg"}[strings.ToLower(path.Ext(img.URI))]
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var contentType materials.Texture2DType
+	switch mediaType {
+	case "image/png":
+		contentType = materials.TextureTypePNG
+	case "image/jpeg":
+		contentType = materials.TextureTypeJPEG
+	default:
+		d.textures[index] = nil
+		return nil, nil
+	}
+	rs := &d.m.Resources
+	t2d := &materials.Texture2D{ID: rs.UnusedID(), Path: d.texturePath(*tex.Source, contentType), ContentType: contentType}
+	if tex.Sampler != nil {
+		if *tex.Sampler < 0 || *tex.Sampler >= len(d.doc.Samplers) {
+			return nil, ErrIndex
+		}
+		s := d.doc.Samplers[*tex.Sampler]
+		t2d.TileStyleU, t2d.TileStyleV = tileStyle(s.WrapS), tileStyle(s.WrapT)
+		switch s.MagFilter {
+		case filterNearest:
+			t2d.Filter = materials.TextureFilterNearest
+		case filterLinear:
+			t2d.Filter = materials.TextureFilterLinear
+		}
+	}
+	rs.Assets = append(rs.Assets, t2d)
+	tg := &textureGroup{
+		group:  &materials.Texture2DGroup{ID: rs.UnusedID(), TextureID: t2d.ID},
+		coords: make(map[materials.TextureCoord]uint32),
+	}
+	rs.Assets = append(rs.Assets, tg.group)
+	d.m.Attachments = append(d.m.Attachments, go3mf.Attachment{
+		Stream:      bytes.NewBuffer(data),
+		Path:        t2d.Path,
+		ContentType: contentType.String(),
+	})
+	materials.Declare(d.m)
+	d.textures[index] = tg
+	return tg, nil
+}
+
+// texturePath returns a unique attachment path for the image index.
+func (d *gltfDecoder) texturePath(index int, contentType materials.Texture2DType) string {
+	ext := ".png"
+	if contentType == materials.TextureTypeJPEG {
+		ext = ".jpg"
+	}
+	base := "texture" + strconv.Itoa(index)
+	p := go3mf.Default3DTexturesDir + base + ext
+	for i := 1; d.hasAttachment(p); i++ {
+		p = go3mf.Default3DTexturesDir + base + "_" + strconv.Itoa(i) + ext
+	}
+	return p
+}
+
+func (d *gltfDecoder) hasAttachment(p string) bool {
+	for _, a := range d.m.Attachments {
+		if strings.EqualFold(a.Path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func tileStyle(wrap int) materials.TileStyle {
+	switch wrap {
+	case wrapMirroredRepeat:
+		return materials.TileMirror
+	case wrapClampToEdge:
+		return materials.TileClamp
+	}
+	return materials.TileWrap
+}
+
+func (d *gltfDecoder) readBufferView(index int) ([]byte, error) {
+	if index < 0 || index >= len(d.doc.BufferViews) {
+		return nil, ErrIndex
+	}
+	v := d.doc.BufferViews[index]
+	if v.Buffer < 0 || v.Buffer >= len(d.buffers) {
+		return nil, ErrIndex
+	}
+	b := d.buffers[v.Buffer]
+	if v.ByteOffset < 0 || v.ByteLength < 0 || v.ByteOffset+v.ByteLength > len(b) {
+		return nil, ErrAccessor
+	}
+	return b[v.ByteOffset : v.ByteOffset+v.ByteLength], nil
+}
+
+// readAccessor returns the components of each element of the accessor index,
+// which must be of type typ. Normalized integers are converted to the range from 0 to 1.
+func (d *gltfDecoder) readAccessor(index int, typ string) ([]float64, error) {
+	if index < 0 || index >= len(d.doc.Accessors) {
+		return nil, ErrIndex
+	}
+	a := d.doc.Accessors[index]
+	n := typeSize(a.Type)
+	size := componentSize(a.ComponentType)
+	if a.Type != typ || n == 0 || size == 0 || a.Count < 0 || len(a.Sparse) > 0 {
+		return nil, ErrAccessor
+	}
+	if a.BufferView == nil {
+		if a.Count > maxAccessorCount {
+			return nil, ErrAccessor
+		}
+		return make([]float64, a.Count*n), nil
+	}
+	data, err := d.readBufferView(*a.BufferView)
+	if err != nil {
+		return nil, err
+	}
+	stride := d.doc.BufferViews[*a.BufferView].ByteStride
+	if stride == 0 {
+		stride = n * size
+	} else if stride < minByteStride || stride > maxByteStride || stride < n*size {
+		return nil, ErrAccessor
+	}
+	// Compare against the available elements instead of computing the end
+	// offset, which could overflow for huge counts.
+	if a.Count > 0 && (a.ByteOffset < 0 || a.ByteOffset+n*size > len(data) ||
+		a.Count-1 > (len(data)-a.ByteOffset-n*size)/stride) {
+		return nil, ErrAccessor
+	}
+	values := make([]float64, a.Count*n)
+	for i := 0; i < a.Count; i++ {
+		for j := 0; j < n; j++ {
+			values[i*n+j] = readComponent(data[a.ByteOffset+i*stride+j*size:], a.ComponentType, a.Normalized)
+		}
+	}
+	return values, nil
+}
+
+func typeSize(typ string) int {
+	return map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4}[typ]
+}
+
+func componentSize(componentType int) int {
+	switch componentType {
+	case componentByte, componentUnsignedByte:
+		return 1
+	case componentShort, componentUnsignedShort:
+		return 2
+	case componentUnsignedInt, componentFloat:
+		return 4
+	}
+	return 0
+}
+
+func readComponent(b []byte, componentType int, normalized bool) float64 {
+	switch componentType {
+	case componentByte:
+		if normalized {
+			return math.Max(float64(int8(b[0]))/127, -1)
+		}
+		return float64(int8(b[0]))
+	case componentUnsignedByte:
+		if normalized {
+			return float64(b[0]) / 255
+		}
+		return float64(b[0])
+	case componentShort:
+		v := float64(int16(binary.LittleEndian.Uint16(b)))
+		if normalized {
+			return math.Max(v/32767, -1)
+		}
+		return v
+	case componentUnsignedShort:
+		v := float64(binary.LittleEndian.Uint16(b))
+		if normalized {
+			return v / 65535
+		}
+		return v
+	case componentUnsignedInt:
+		return float64(binary.LittleEndian.Uint32(b))
+	default:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+}
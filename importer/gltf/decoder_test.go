@@ -0,0 +1,223 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package gltf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+)
+
+func TestNewDecoder(t *testing.T) {
+	r := new(bytes.Buffer)
+	if got := NewDecoder(r); !reflect.DeepEqual(got, &Decoder{r: r}) {
+		t.Errorf("NewDecoder() = %v", got)
+	}
+}
+
+func TestDecoder_Decode_roundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(newEncoderTestModel()); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	got := &go3mf.Model{Units: go3mf.UnitCentimeter}
+	if err := NewDecoder(&buf).Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	want := &go3mf.Model{
+		Units:       go3mf.UnitCentimeter,
+		Extensions:  []go3mf.Extension{materials.DefaultExtension},
+		Attachments: []go3mf.Attachment{{Path: "/3D/Textures/texture0.png", ContentType: "image/png", Stream: bytes.NewBufferString("png")}},
+	}
+	want.Resources.Assets = []go3mf.Asset{
+		&go3mf.BaseMaterials{ID: 2, Materials: []go3mf.Base{{Name: "red", Color: color.RGBA{R: 255, A: 255}}}},
+		&materials.ColorGroup{ID: 3, Colors: []color.RGBA{{G: 255, A: 255}, {B: 255, A: 255}}},
+		&materials.Texture2D{ID: 4, Path: "/3D/Textures/texture0.png", ContentType: materials.TextureTypePNG, TileStyleV: materials.TileClamp},
+		&materials.Texture2DGroup{ID: 5, TextureID: 4, Coords: []materials.TextureCoord{{0, 0}, {1, 0}, {0, 1}}},
+	}
+	want.Resources.Objects = []*go3mf.Object{
+		{ID: 1, Name: "part", Mesh: &go3mf.Mesh{
+			Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {0, 0, 1}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+				{V1: 0, V2: 1, V3: 2},
+				{V1: 0, V2: 2, V3: 3, PID: 2},
+				{V1: 0, V2: 3, V3: 1, PID: 3, P1: 0, P2: 1, P3: 1},
+				{V1: 2, V2: 1, V3: 3, PID: 5, P1: 0, P2: 1, P3: 2},
+			}},
+		}},
+		{ID: 6, Name: "assembly", Components: &go3mf.Components{Component: []*go3mf.Component{
+			{ObjectID: 1},
+			{ObjectID: 1, Transform: go3mf.Identity().Translate(2, 0, 0)},
+		}}},
+		{ID: 7, Name: "root", Components: &go3mf.Components{Component: []*go3mf.Component{
+			{ObjectID: 6, Transform: go3mf.Identity().Translate(0, 0, 1)},
+		}}},
+	}
+	want.Build.Items = []*go3mf.Item{{ObjectID: 7, Transform: go3mf.Identity()}}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("Decoder.Decode() = %v", diff)
+	}
+}
+
+// gltfStrip is a triangle strip with normalized vertex colors and a
+// node whose rotation maps the glTF Y-up axis to the 3MF Z-up axis.
+const gltfStrip = `{
+  "asset": {"version": "2.0"},
+  "nodes": [{"name": "strip", "mesh": 0, "rotation": [-0.7071068, 0, 0, 0.7071068], "scale": [0.001, 0.001, 0.001], "translation": [0, 0, 0]}],
+  "meshes": [{"primitives": [{"attributes": {"POSITION": 0, "COLOR_0": 1}, "mode": 5, "material": 0}]}],
+  "materials": [{"pbrMetallicRoughness": {"baseColorFactor": [1, 1, 1, 0.5]}}],
+  "accessors": [
+    {"bufferView": 0, "componentType": 5126, "count": 4, "type": "VEC3"},
+    {"bufferView": 1, "componentType": 5121, "normalized": true, "count": 4, "type": "VEC4"}
+  ],
+  "bufferViews": [
+    {"buffer": 0, "byteLength": 48},
+    {"buffer": 0, "byteOffset": 48, "byteLength": 16}
+  ],
+  "buffers": [{"byteLength": 64, "uri": "data:application/octet-stream;base64,`
+
+func stripBuffer() string {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0, 1, 1, 0})
+	b.Write([]byte{255, 0, 0, 255, 255, 0, 0, 255, 0, 0, 255, 255, 0, 0, 255, 255})
+	return base64.StdEncoding.EncodeToString(b.Bytes())
+}
+
+func TestDecoder_Decode_json(t *testing.T) {
+	got := new(go3mf.Model)
+	if err := NewDecoder(strings.NewReader(gltfStrip + stripBuffer() + `"}]}`)).Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	want := &go3mf.Mesh{
+		Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}}},
+		Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+			{V1: 0, V2: 1, V3: 2, PID: 2, P1: 0, P2: 0, P3: 1},
+			{V1: 2, V2: 1, V3: 3, PID: 2, P1: 1, P2: 0, P3: 1},
+		}},
+	}
+	if diff := deep.Equal(got.Resources.Objects[0].Mesh, want); diff != nil {
+		t.Errorf("Decoder.Decode() mesh = %v", diff)
+	}
+	wantAssets := []go3mf.Asset{&materials.ColorGroup{ID: 2, Colors: []color.RGBA{{R: 255, A: 128}, {B: 255, A: 128}}}}
+	if diff := deep.Equal(got.Resources.Assets, wantAssets); diff != nil {
+		t.Errorf("Decoder.Decode() assets = %v", diff)
+	}
+	// The node transform cancels the root conversion.
+	p := got.Build.Items[0].Transform.Mul3D(go3mf.Point3D{1, 2, 3})
+	for i, v := range []float32{1, 2, 3} {
+		if d := p[i] - v; d > 1e-3 || d < -1e-3 {
+			t.Errorf("Decoder.Decode() transform = %v", p)
+			break
+		}
+	}
+}
+
+func TestDecoder_Decode_open(t *testing.T) {
+	src := `{
+  "asset": {"version": "2.0"},
+  "scene": 0,
+  "scenes": [{"nodes": [0]}],
+  "nodes": [{"mesh": 0}, {"mesh": 0}],
+  "meshes": [{"primitives": [{"attributes": {"POSITION": 0, "TEXCOORD_0": 1}, "indices": 2, "material": 0}]}],
+  "materials": [{"pbrMetallicRoughness": {"baseColorTexture": {"index": 0}}}],
+  "textures": [{"source": 0, "sampler": 0}],
+  "samplers": [{"magFilter": 9728, "wrapS": 33648}],
+  "images": [{"uri": "my%20tex.jpg"}],
+  "accessors": [
+    {"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+    {"bufferView": 0, "byteOffset": 12, "componentType": 5126, "count": 3, "type": "VEC2"},
+    {"bufferView": 1, "componentType": 5123, "count": 3, "type": "SCALAR"}
+  ],
+  "bufferViews": [
+    {"buffer": 0, "byteLength": 60, "byteStride": 20},
+    {"buffer": 0, "byteOffset": 60, "byteLength": 6}
+  ],
+  "buffers": [{"byteLength": 66, "uri": "data.bin"}]
+}`
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, []float32{0, 0, 0, 0, 1, 1, 0, 0, 1, 0, 0, 1, 0, 1, 0})
+	binary.Write(&data, binary.LittleEndian, []uint16{0, 1, 2})
+	files := map[string][]byte{"data.bin": data.Bytes(), "my tex.jpg": []byte("jpg")}
+	d := NewDecoder(strings.NewReader(src))
+	d.Open = func(name string) (io.ReadCloser, error) {
+		if b, ok := files[name]; ok {
+			return ioutil.NopCloser(bytes.NewReader(b)), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	got := new(go3mf.Model)
+	if err := d.Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	wantAssets := []go3mf.Asset{
+		&materials.Texture2D{ID: 2, Path: "/3D/Textures/texture0.jpg", ContentType: materials.TextureTypeJPEG, TileStyleU: materials.TileMirror, Filter: materials.TextureFilterNearest},
+		&materials.Texture2DGroup{ID: 3, TextureID: 2, Coords: []materials.TextureCoord{{0, 0}, {1, 1}}},
+	}
+	if diff := deep.Equal(got.Resources.Assets, wantAssets); diff != nil {
+		t.Errorf("Decoder.Decode() assets = %v", diff)
+	}
+	wantTriangles := []go3mf.Triangle{{V1: 0, V2: 1, V3: 2, PID: 3, P1: 0, P2: 1, P3: 1}}
+	if diff := deep.Equal(got.Resources.Objects[0].Mesh.Triangles.Triangle, wantTriangles); diff != nil {
+		t.Errorf("Decoder.Decode() triangles = %v", diff)
+	}
+	if len(got.Build.Items) != 1 || len(got.Attachments) != 1 {
+		t.Errorf("Decoder.Decode() items = %v, attachments = %v", got.Build.Items, got.Attachments)
+	}
+}
+
+func TestDecoder_Decode_error(t *testing.T) {
+	checkEveryFaces = 0
+	defer func() { checkEveryFaces = 1000 }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	strip := gltfStrip + stripBuffer() + `"}]}`
+	glb := func(version uint32) io.Reader {
+		var b bytes.Buffer
+		binary.Write(&b, binary.LittleEndian, []uint32{glbMagic, version, 20, 0, chunkBIN})
+		return &b
+	}
+	tests := []struct {
+		name    string
+		src     io.Reader
+		ctx     context.Context
+		wantErr error
+	}{
+		{"glbVersion", glb(1), context.Background(), ErrVersion},
+		{"glbJSON", glb(2), context.Background(), ErrHeader},
+		{"version", strings.NewReader(`{"asset": {"version": "1.0"}}`), context.Background(), ErrVersion},
+		{"uri", strings.NewReader(`{"asset": {"version": "2.0"}, "buffers": [{"byteLength": 1, "uri": "a.bin"}]}`), context.Background(), ErrURI},
+		{"scene", strings.NewReader(`{"asset": {"version": "2.0"}, "scene": 1, "scenes": [{}]}`), context.Background(), ErrIndex},
+		{"node", strings.NewReader(`{"asset": {"version": "2.0"}, "scenes": [{"nodes": [1]}]}`), context.Background(), ErrIndex},
+		{"recursive", strings.NewReader(`{"asset": {"version": "2.0"}, "scenes": [{"nodes": [0]}], "nodes": [{"children": [0]}]}`), context.Background(), specerr.ErrRecursion},
+		{"accessor", strings.NewReader(strings.Replace(strip, `"count": 4, "type": "VEC3"`, `"count": 5, "type": "VEC3"`, 1)), context.Background(), ErrAccessor},
+		{"accessorStride", strings.NewReader(strings.Replace(strip, `{"buffer": 0, "byteLength": 48}`, `{"buffer": 0, "byteLength": 48, "byteStride": -4}`, 1)), context.Background(), ErrAccessor},
+		{"accessorShortStride", strings.NewReader(strings.Replace(strip, `{"buffer": 0, "byteLength": 48}`, `{"buffer": 0, "byteLength": 48, "byteStride": 8}`, 1)), context.Background(), ErrAccessor},
+		{"accessorCount", strings.NewReader(strings.Replace(strip, `"count": 4, "type": "VEC3"`, `"count": 1000000000000000, "type": "VEC3"`, 1)), context.Background(), ErrAccessor},
+		{"accessorCountNoView", strings.NewReader(strings.Replace(strip, `{"bufferView": 0, "componentType": 5126, "count": 4, "type": "VEC3"}`, `{"componentType": 5126, "count": 1000000000000000, "type": "VEC3"}`, 1)), context.Background(), ErrAccessor},
+		{"accessorType", strings.NewReader(strings.Replace(strip, `"count": 4, "type": "VEC3"`, `"count": 4, "type": "VEC2"`, 1)), context.Background(), ErrAccessor},
+		{"material", strings.NewReader(strings.Replace(strip, `"material": 0`, `"material": 1`, 1)), context.Background(), ErrIndex},
+		{"cancel", strings.NewReader(strip), ctx, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewDecoder(tt.src).DecodeContext(tt.ctx, new(go3mf.Model)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decoder.DecodeContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package gltf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+)
+
+// Encoder can encode a binary glTF (GLB).
+//
+// Each build item and component is encoded as a node with its transform,
+// and each mesh object as a mesh shared by all the nodes referencing it.
+// The triangles are grouped into primitives by their properties:
+//   - Triangles with the same base material use a material with its color.
+//   - Triangles with the same texture use a material with the embedded texture image
+//     and the texture coordinates of the materials.Texture2DGroup.
+//   - Other triangles with properties use vertex colors evaluated with a materials.ColorEvaluator.
+//   - Triangles without properties use the glTF default material.
+//
+// The vertices are only shared by the faces with the same normal, so the meshes keep a flat shading.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder creates a new encoder.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: w,
+	}
+}
+
+// Encode writes the build items of m.
+func (e *Encoder) Encode(m *go3mf.Model) error {
+	ge := &gltfEncoder{
+		m:         m,
+		resolvers: make(map[string]*materials.Resolver),
+		evaluator: materials.NewColorEvaluator(m),
+		visiting:  make(map[*go3mf.Object]bool),
+		meshes:    make(map[*go3mf.Object]int),
+		materials: make(map[primitiveKey]int),
+		textures:  make(map[*materials.Texture2D]int),
+	}
+	root := 0
+	ge.doc.Asset = asset{Version: "2.0", Generator: "go3mf"}
+	ge.doc.Scene = &root
+	ge.doc.Scenes = []scene{{Nodes: []int{root}}}
+	rm := rootMatrix(m.Units)
+	ge.doc.Nodes = []node{{Name: "root", Matrix: rm[:]}}
+	for i, item := range m.Build.Items {
+		child, err := ge.addItem(item)
+		if err != nil {
+			return specerr.WrapIndex(err, "item", i)
+		}
+		ge.doc.Nodes[root].Children = append(ge.doc.Nodes[root].Children, child)
+	}
+	if ge.bin.Len() > 0 {
+		ge.doc.Buffers = []buffer{{ByteLength: ge.bin.Len()}}
+	}
+	return writeGLB(e.w, &ge.doc, ge.bin.Bytes())
+}
+
+type primitiveKind uint8
+
+const (
+	kindNone primitiveKind = iota
+	kindBase
+	kindTexture
+	kindColor
+)
+
+// primitiveKey identifies the triangles that share a primitive and a material.
+// Base materials are identified by the path of their model part, as resource IDs
+// are only unique inside a part, and vertex colors by whether they are translucent.
+type primitiveKey struct {
+	kind    primitiveKind
+	path    string
+	pid     uint32
+	index   uint32
+	texture *materials.Texture2D
+	blend   bool
+}
+
+type vertexKey struct {
+	index  uint32
+	normal go3mf.Point3D
+	color  color.RGBA
+	uv     materials.TextureCoord
+}
+
+type primitiveBuilder struct {
+	key       primitiveKey
+	base      go3mf.Base
+	positions []go3mf.Point3D
+	normals   []go3mf.Point3D
+	colors    [][4]float32
+	uvs       []materials.TextureCoord
+	indices   []uint32
+	vertices  map[vertexKey]uint32
+}
+
+type gltfEncoder struct {
+	m         *go3mf.Model
+	doc       document
+	bin       bytes.Buffer
+	resolvers map[string]*materials.Resolver
+	evaluator *materials.ColorEvaluator
+	visiting  map[*go3mf.Object]bool
+	meshes    map[*go3mf.Object]int
+	materials map[primitiveKey]int
+	textures  map[*materials.Texture2D]int
+}
+
+func (e *gltfEncoder) addItem(item *go3mf.Item) (int, error) {
+	obj, ok := e.m.FindObject(item.ObjectPath(), item.ObjectID)
+	if !ok {
+		return 0, specerr.ErrMissingResource
+	}
+	return e.addNode(item.ObjectPath(), obj, item.Transform)
+}
+
+// addNode adds the node of obj, and of its components, and returns its index.
+func (e *gltfEncoder) addNode(path string, obj *go3mf.Object, transform go3mf.Matrix) (int, error) {
+	if e.visiting[obj] {
+		return 0, specerr.ErrRecursion
+	}
+	index := len(e.doc.Nodes)
+	n := node{Name: obj.Name}
+	if transform != (go3mf.Matrix{}) && transform != go3mf.Identity() {
+		n.Matrix = transform[:]
+	}
+	e.doc.Nodes = append(e.doc.Nodes, n)
+	if obj.Mesh != nil {
+		m, err := e.mesh(path, obj)
+		if err != nil {
+			return 0, err
+		}
+		e.doc.Nodes[index].Mesh = m
+	}
+	if obj.Components == nil {
+		return index, nil
+	}
+	e.visiting[obj] = true
+	defer delete(e.visiting, obj)
+	for i, comp := range obj.Components.Component {
+		cpath := comp.ObjectPath(path)
+		cobj, ok := e.m.FindObject(cpath, comp.ObjectID)
+		if !ok {
+			return 0, specerr.WrapIndex(specerr.ErrMissingResource, "component", i)
+		}
+		child, err := e.addNode(cpath, cobj, comp.Transform)
+		if err != nil {
+			return 0, specerr.WrapIndex(err, "component", i)
+		}
+		e.doc.Nodes[index].Children = append(e.doc.Nodes[index].Children, child)
+	}
+	return index, nil
+}
+
+// mesh returns the index of the mesh of obj, which is added the first time.
+// It returns nil if obj has no triangles, as glTF meshes cannot be empty.
+func (e *gltfEncoder) mesh(path string, obj *go3mf.Object) (*int, error) {
+	if index, ok := e.meshes[obj]; ok {
+		return &index, nil
+	}
+	r, ok := e.resolvers[path]
+	if !ok {
+		r = materials.NewResolver(e.m, path)
+		e.resolvers[path] = r
+	}
+	var (
+		builders []*primitiveBuilder
+		byKey    = make(map[primitiveKey]*primitiveBuilder)
+		vertices = obj.Mesh.Vertices.Vertex
+		nv       = uint32(len(vertices))
+	)
+	for i := range obj.Mesh.Triangles.Triangle {
+		t := &obj.Mesh.Triangles.Triangle[i]
+		if t.V1 >= nv || t.V2 >= nv || t.V3 >= nv {
+			return nil, specerr.WrapIndex(specerr.ErrIndexOutOfBounds, "triangle", i)
+		}
+		props, err := r.Triangle(obj, t)
+		if err != nil {
+			return nil, specerr.WrapIndex(err, "triangle", i)
+		}
+		key := triangleKey(path, props)
+		var colors [3]color.RGBA
+		if key.kind == kindColor {
+			for j := range colors {
+				var bary [3]float32
+				bary[j] = 1
+				if colors[j], err = e.evaluator.ColorAt(props, bary); err != nil {
+					return nil, specerr.WrapIndex(err, "triangle", i)
+				}
+				key.blend = key.blend || colors[j].A < 0xff
+			}
+		}
+		b, ok := byKey[key]
+		if !ok {
+			b = &primitiveBuilder{key: key, vertices: make(map[vertexKey]uint32)}
+			if key.kind == kindBase {
+				b.base = props[0].(*materials.BaseProperty).Base
+			}
+			byKey[key] = b
+			builders = append(builders, b)
+		}
+		corners := [3]uint32{t.V1, t.V2, t.V3}
+		normal := faceNormal(vertices[t.V1], vertices[t.V2], vertices[t.V3])
+		for j, v := range corners {
+			vk := vertexKey{index: v, normal: normal}
+			switch key.kind {
+			case kindTexture:
+				tp := props[j].(*materials.TextureProperty)
+				// glTF texture coordinates start at the top left corner.
+				vk.uv = materials.TextureCoord{tp.Coord[0], 1 - tp.Coord[1]}
+			case kindColor:
+				vk.color = colors[j]
+			}
+			b.addVertex(vk, vertices[v])
+		}
+	}
+	if len(builders) == 0 {
+		return nil, nil
+	}
+	m := mesh{Name: obj.Name, Primitives: make([]primitive, 0, len(builders))}
+	for _, b := range builders {
+		p, err := e.primitive(b)
+		if err != nil {
+			return nil, err
+		}
+		m.Primitives = append(m.Primitives, p)
+	}
+	index := len(e.doc.Meshes)
+	e.doc.Meshes = append(e.doc.Meshes, m)
+	e.meshes[obj] = index
+	return &index, nil
+}
+
+// triangleKey returns the primitive key of a triangle of the model part path with the properties props.
+func triangleKey(path string, props [3]materials.Property) primitiveKey {
+	if props[0] == nil {
+		return primitiveKey{kind: kindNone}
+	}
+	b0, ok0 := props[0].(*materials.BaseProperty)
+	b1, ok1 := props[1].(*materials.BaseProperty)
+	b2, ok2 := props[2].(*materials.BaseProperty)
+	if ok0 && ok1 && ok2 && b0.PID == b1.PID && b0.PID == b2.PID && b0.Index == b1.Index && b0.Index == b2.Index {
+		return primitiveKey{kind: kindBase, path: path, pid: b0.PID, index: b0.Index}
+	}
+	t0, ok0 := props[0].(*materials.TextureProperty)
+	t1, ok1 := props[1].(*materials.TextureProperty)
+	t2, ok2 := props[2].(*materials.TextureProperty)
+	if ok0 && ok1 && ok2 && t0.Texture == t1.Texture && t0.Texture == t2.Texture {
+		return primitiveKey{kind: kindTexture, texture: t0.Texture}
+	}
+	return primitiveKey{kind: kindColor}
+}
+
+func (b *primitiveBuilder) addVertex(vk vertexKey, position go3mf.Point3D) {
+	index, ok := b.vertices[vk]
+	if !ok {
+		index = uint32(len(b.positions))
+		b.vertices[vk] = index
+		b.positions = append(b.positions, position)
+		b.normals = append(b.normals, vk.normal)
+		switch b.key.kind {
+		case kindTexture:
+			b.uvs = append(b.uvs, vk.uv)
+		case kindColor:
+			b.colors = append(b.colors, [4]float32{
+				toLinear(vk.color.R), toLinear(vk.color.G), toLinear(vk.color.B), float32(vk.color.A) / 255,
+			})
+		}
+	}
+	b.indices = append(b.indices, index)
+}
+
+// primitive writes the vertex attributes of b and returns its primitive.
+func (e *gltfEncoder) primitive(b *primitiveBuilder) (primitive, error) {
+	p := primitive{Attributes: make(map[string]int)}
+	min, max := newBounds(b.positions)
+	p.Attributes["POSITION"] = e.accessor(b.positions, componentFloat, "VEC3", len(b.positions), targetArrayBuffer, min, max)
+	p.Attributes["NORMAL"] = e.accessor(b.normals, componentFloat, "VEC3", len(b.normals), targetArrayBuffer, nil, nil)
+	if len(b.colors) > 0 {
+		p.Attributes["COLOR_0"] = e.accessor(b.colors, componentFloat, "VEC4", len(b.colors), targetArrayBuffer, nil, nil)
+	}
+	if len(b.uvs) > 0 {
+		p.Attributes["TEXCOORD_0"] = e.accessor(b.uvs, componentFloat, "VEC2", len(b.uvs), targetArrayBuffer, nil, nil)
+	}
+	indices := e.accessor(b.indices, componentUnsignedInt, "SCALAR", len(b.indices), targetElementArrayBuffer, nil, nil)
+	p.Indices = &indices
+	if b.key.kind == kindNone {
+		return p, nil
+	}
+	mat, err := e.material(b)
+	if err != nil {
+		return p, err
+	}
+	p.Material = &mat
+	return p, nil
+}
+
+// material returns the index of the material of b, which is added the first time.
+func (e *gltfEncoder) material(b *primitiveBuilder) (int, error) {
+	if index, ok := e.materials[b.key]; ok {
+		return index, nil
+	}
+	var metallic float32
+	mat := material{PBR: &pbrMetallicRoughness{MetallicFactor: &metallic}}
+	switch b.key.kind {
+	case kindBase:
+		c := b.base.Color
+		mat.Name = b.base.Name
+		mat.PBR.BaseColorFactor = []float32{toLinear(c.R), toLinear(c.G), toLinear(c.B), float32(c.A) / 255}
+		if c.A < 0xff {
+			mat.AlphaMode = "BLEND"
+		}
+	case kindTexture:
+		tex, err := e.texture(b.key.texture)
+		if err != nil {
+			return 0, err
+		}
+		mat.PBR.BaseColorTexture = &textureRef{Index: tex}
+	case kindColor:
+		mat.Name = "vertex colors"
+		if b.key.blend {
+			mat.AlphaMode = "BLEND"
+		}
+	}
+	index := len(e.doc.Materials)
+	e.doc.Materials = append(e.doc.Materials, mat)
+	e.materials[b.key] = index
+	return index, nil
+}
+
+// texture returns the index of the texture t, embedding its image the first time.
+func (e *gltfEncoder) texture(t *materials.Texture2D) (int, error) {
+	if index, ok := e.textures[t]; ok {
+		return index, nil
+	}
+	data, err := t.Data(e.m)
+	if err != nil {
+		return 0, err
+	}
+	view := e.bufferView(data, 0)
+	e.doc.Images = append(e.doc.Images, image{BufferView: &view, MimeType: t.ContentType.String()})
+	source := len(e.doc.Images) - 1
+	e.doc.Samplers = append(e.doc.Samplers, newSampler(t))
+	smp := len(e.doc.Samplers) - 1
+	index := len(e.doc.Textures)
+	e.doc.Textures = append(e.doc.Textures, texture{Sampler: &smp, Source: &source})
+	e.textures[t] = index
+	return index, nil
+}
+
+func newSampler(t *materials.Texture2D) sampler {
+	s := sampler{WrapS: wrapMode(t.TileStyleU), WrapT: wrapMode(t.TileStyleV)}
+	switch t.Filter {
+	case materials.TextureFilterLinear:
+		s.MagFilter, s.MinFilter = filterLinear, filterLinear
+	case materials.TextureFilterNearest:
+		s.MagFilter, s.MinFilter = filterNearest, filterNearest
+	}
+	return s
+}
+
+func wrapMode(t materials.TileStyle) int {
+	switch t {
+	case materials.TileMirror:
+		return wrapMirroredRepeat
+	case materials.TileClamp, materials.TileNone:
+		return wrapClampToEdge
+	}
+	return wrapRepeat
+}
+
+// accessor writes data in the binary buffer and returns its accessor index.
+func (e *gltfEncoder) accessor(data interface{}, componentType int, typ string, count, target int, min, max []float32) int {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, data)
+	view := e.bufferView(b.Bytes(), target)
+	e.doc.Accessors = append(e.doc.Accessors, accessor{
+		BufferView:    &view,
+		ComponentType: componentType,
+		Count:         count,
+		Type:          typ,
+		Min:           min,
+		Max:           max,
+	})
+	return len(e.doc.Accessors) - 1
+}
+
+// bufferView writes data in the binary buffer, aligned to 4 bytes, and returns its buffer view index.
+func (e *gltfEncoder) bufferView(data []byte, target int) int {
+	offset := e.bin.Len()
+	e.bin.Write(data)
+	for e.bin.Len()%4 != 0 {
+		e.bin.WriteByte(0)
+	}
+	e.doc.BufferViews = append(e.doc.BufferViews, bufferView{ByteOffset: offset, ByteLength: len(data), Target: target})
+	return len(e.doc.BufferViews) - 1
+}
+
+func newBounds(points []go3mf.Point3D) ([]float32, []float32) {
+	min := []float32{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+	max := []float32{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	for _, p := range points {
+		for i := range p {
+			min[i] = float32(math.Min(float64(min[i]), float64(p[i])))
+			max[i] = float32(math.Max(float64(max[i]), float64(p[i])))
+		}
+	}
+	return min, max
+}
+
+// faceNormal returns the unit normal of the triangle v1, v2, v3,
+// or the Z axis if the triangle is degenerated.
+func faceNormal(v1, v2, v3 go3mf.Point3D) go3mf.Point3D {
+	a := [3]float64{float64(v2[0] - v1[0]), float64(v2[1] - v1[1]), float64(v2[2] - v1[2])}
+	b := [3]float64{float64(v3[0] - v1[0]), float64(v3[1] - v1[1]), float64(v3[2] - v1[2])}
+	n := [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+	l := math.Sqrt(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])
+	if l == 0 {
+		return go3mf.Point3D{0, 0, 1}
+	}
+	// Adding 0 avoids negative zeros.
+	return go3mf.Point3D{float32(n[0]/l) + 0, float32(n[1]/l) + 0, float32(n[2]/l) + 0}
+}
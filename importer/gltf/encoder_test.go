@@ -0,0 +1,248 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package gltf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+	"github.com/hpinc/go3mf/production"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func newEncoderTestModel() *go3mf.Model {
+	m := &go3mf.Model{Units: go3mf.UnitCentimeter}
+	m.Attachments = []go3mf.Attachment{{Path: "/3D/Textures/tex.png", Stream: strings.NewReader("png")}}
+	m.Resources.Assets = []go3mf.Asset{
+		&go3mf.BaseMaterials{ID: 1, Materials: []go3mf.Base{{Name: "red", Color: color.RGBA{R: 255, A: 255}}}},
+		&materials.ColorGroup{ID: 2, Colors: []color.RGBA{{G: 255, A: 255}, {B: 255, A: 255}}},
+		&materials.Texture2D{ID: 3, Path: "/3D/Textures/tex.png", ContentType: materials.TextureTypePNG, TileStyleV: materials.TileClamp},
+		&materials.Texture2DGroup{ID: 4, TextureID: 3, Coords: []materials.TextureCoord{{0, 0}, {1, 0}, {0, 1}}},
+	}
+	m.Resources.Objects = []*go3mf.Object{
+		{ID: 5, Name: "part", Mesh: &go3mf.Mesh{
+			Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+				{V1: 0, V2: 2, V3: 1},
+				{V1: 0, V2: 1, V3: 3, PID: 1},
+				{V1: 0, V2: 3, V3: 2, PID: 2, P1: 0, P2: 1, P3: 1},
+				{V1: 1, V2: 2, V3: 3, PID: 4, P1: 0, P2: 1, P3: 2},
+			}},
+		}},
+		{ID: 6, Name: "assembly", Components: &go3mf.Components{Component: []*go3mf.Component{
+			{ObjectID: 5},
+			{ObjectID: 5, Transform: go3mf.Identity().Translate(2, 0, 0)},
+		}}},
+	}
+	m.Build.Items = []*go3mf.Item{{ObjectID: 6, Transform: go3mf.Identity().Translate(0, 0, 1)}}
+	return m
+}
+
+// readTestGLB returns the JSON document and the binary buffer of a glb.
+func readTestGLB(t *testing.T, b []byte) (*document, []byte) {
+	t.Helper()
+	var header [5]uint32
+	if err := binary.Read(bytes.NewReader(b), binary.LittleEndian, &header); err != nil {
+		t.Fatal(err)
+	}
+	if header[0] != glbMagic || header[1] != glbVersion || int(header[2]) != len(b) || header[4] != chunkJSON {
+		t.Fatalf("invalid glb header %v", header)
+	}
+	js := b[20 : 20+header[3]]
+	var doc document
+	if err := json.Unmarshal(js, &doc); err != nil {
+		t.Fatal(err)
+	}
+	bin := b[20+header[3]:]
+	if len(bin) > 0 {
+		bin = bin[8:]
+	}
+	return &doc, bin
+}
+
+// readFloats returns the float values of the accessor.
+func readFloats(doc *document, bin []byte, index int) []float32 {
+	a := doc.Accessors[index]
+	view := doc.BufferViews[*a.BufferView]
+	values := make([]float32, view.ByteLength/4)
+	binary.Read(bytes.NewReader(bin[view.ByteOffset:view.ByteOffset+view.ByteLength]), binary.LittleEndian, values)
+	return values
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(newEncoderTestModel()); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	doc, bin := readTestGLB(t, buf.Bytes())
+	if len(bin) != doc.Buffers[0].ByteLength || len(bin)%4 != 0 {
+		t.Errorf("Encoder.Encode() buffer length = %d, want %d", len(bin), doc.Buffers[0].ByteLength)
+	}
+	mesh0 := 0
+	wantNodes := []node{
+		{Name: "root", Children: []int{1}, Matrix: []float32{0.01, 0, 0, 0, 0, 0, -0.01, 0, 0, 0.01, 0, 0, 0, 0, 0, 1}},
+		{Name: "assembly", Children: []int{2, 3}, Matrix: []float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 1, 1}},
+		{Name: "part", Mesh: &mesh0},
+		{Name: "part", Mesh: &mesh0, Matrix: []float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 2, 0, 0, 1}},
+	}
+	if diff := deep.Equal(doc.Nodes, wantNodes); diff != nil {
+		t.Errorf("Encoder.Encode() nodes = %v", diff)
+	}
+	if len(doc.Meshes) != 1 || len(doc.Meshes[0].Primitives) != 4 {
+		t.Fatalf("Encoder.Encode() meshes = %v", doc.Meshes)
+	}
+	wantAttributes := [][]string{
+		{"NORMAL", "POSITION"},
+		{"NORMAL", "POSITION"},
+		{"COLOR_0", "NORMAL", "POSITION"},
+		{"NORMAL", "POSITION", "TEXCOORD_0"},
+	}
+	for i, p := range doc.Meshes[0].Primitives {
+		var names []string
+		for _, name := range []string{"COLOR_0", "NORMAL", "POSITION", "TEXCOORD_0"} {
+			if _, ok := p.Attributes[name]; ok {
+				names = append(names, name)
+			}
+		}
+		if diff := deep.Equal(names, wantAttributes[i]); diff != nil {
+			t.Errorf("Encoder.Encode() primitive %d attributes = %v", i, diff)
+		}
+		if (p.Material == nil) != (i == 0) {
+			t.Errorf("Encoder.Encode() primitive %d material = %v", i, p.Material)
+		}
+	}
+	var metallic float32
+	wantMaterials := []material{
+		{Name: "red", PBR: &pbrMetallicRoughness{BaseColorFactor: []float32{1, 0, 0, 1}, MetallicFactor: &metallic}},
+		{Name: "vertex colors", PBR: &pbrMetallicRoughness{MetallicFactor: &metallic}},
+		{PBR: &pbrMetallicRoughness{BaseColorTexture: &textureRef{Index: 0}, MetallicFactor: &metallic}},
+	}
+	if diff := deep.Equal(doc.Materials, wantMaterials); diff != nil {
+		t.Errorf("Encoder.Encode() materials = %v", diff)
+	}
+	if diff := deep.Equal(doc.Samplers, []sampler{{WrapS: wrapRepeat, WrapT: wrapClampToEdge}}); diff != nil {
+		t.Errorf("Encoder.Encode() samplers = %v", diff)
+	}
+	image := doc.Images[0]
+	view := doc.BufferViews[*image.BufferView]
+	if got := string(bin[view.ByteOffset : view.ByteOffset+view.ByteLength]); got != "png" || image.MimeType != "image/png" {
+		t.Errorf("Encoder.Encode() image = %s %s", got, image.MimeType)
+	}
+	uvs := readFloats(doc, bin, doc.Meshes[0].Primitives[3].Attributes["TEXCOORD_0"])
+	if diff := deep.Equal(uvs, []float32{0, 1, 1, 1, 0, 0}); diff != nil {
+		t.Errorf("Encoder.Encode() uvs = %v", diff)
+	}
+	colors := readFloats(doc, bin, doc.Meshes[0].Primitives[2].Attributes["COLOR_0"])
+	if diff := deep.Equal(colors, []float32{0, 1, 0, 1, 0, 0, 1, 1, 0, 0, 1, 1}); diff != nil {
+		t.Errorf("Encoder.Encode() colors = %v", diff)
+	}
+	normals := readFloats(doc, bin, doc.Meshes[0].Primitives[0].Attributes["NORMAL"])
+	if diff := deep.Equal(normals, []float32{0, 0, -1, 0, 0, -1, 0, 0, -1}); diff != nil {
+		t.Errorf("Encoder.Encode() normals = %v", diff)
+	}
+}
+
+func TestEncoder_Encode_materialKeys(t *testing.T) {
+	triangle := func(pid, p uint32) *go3mf.Mesh {
+		return &go3mf.Mesh{
+			Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2, PID: pid, P1: p, P2: p, P3: p}}},
+		}
+	}
+	part := func(c color.RGBA) *go3mf.ChildModel {
+		return &go3mf.ChildModel{Resources: go3mf.Resources{
+			Assets:  []go3mf.Asset{&go3mf.BaseMaterials{ID: 1, Materials: []go3mf.Base{{Name: "base", Color: c}}}},
+			Objects: []*go3mf.Object{{ID: 1, Mesh: triangle(1, 0)}},
+		}}
+	}
+	m := &go3mf.Model{Childs: map[string]*go3mf.ChildModel{
+		"/3D/a.model": part(color.RGBA{R: 255, A: 255}),
+		"/3D/b.model": part(color.RGBA{B: 255, A: 255}),
+	}}
+	m.Resources.Assets = []go3mf.Asset{&materials.ColorGroup{ID: 2, Colors: []color.RGBA{{G: 255, A: 255}, {G: 255, A: 0}}}}
+	m.Resources.Objects = []*go3mf.Object{
+		{ID: 3, Mesh: triangle(2, 0)},
+		{ID: 4, Mesh: triangle(2, 1)},
+	}
+	m.Build.Items = []*go3mf.Item{
+		{ObjectID: 3},
+		{ObjectID: 4},
+		{ObjectID: 1, AnyAttr: spec.AnyAttr{&production.ItemAttr{Path: "/3D/a.model"}}},
+		{ObjectID: 1, AnyAttr: spec.AnyAttr{&production.ItemAttr{Path: "/3D/b.model"}}},
+	}
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	doc, _ := readTestGLB(t, buf.Bytes())
+	var metallic float32
+	want := []material{
+		{Name: "vertex colors", PBR: &pbrMetallicRoughness{MetallicFactor: &metallic}},
+		{Name: "vertex colors", AlphaMode: "BLEND", PBR: &pbrMetallicRoughness{MetallicFactor: &metallic}},
+		{Name: "base", PBR: &pbrMetallicRoughness{BaseColorFactor: []float32{1, 0, 0, 1}, MetallicFactor: &metallic}},
+		{Name: "base", PBR: &pbrMetallicRoughness{BaseColorFactor: []float32{0, 0, 1, 1}, MetallicFactor: &metallic}},
+	}
+	if diff := deep.Equal(doc.Materials, want); diff != nil {
+		t.Errorf("Encoder.Encode() materials = %v", diff)
+	}
+}
+
+func TestEncoder_Encode_empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(new(go3mf.Model)); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	doc, bin := readTestGLB(t, buf.Bytes())
+	if len(bin) != 0 || len(doc.Buffers) != 0 || len(doc.Nodes) != 1 {
+		t.Errorf("Encoder.Encode() = %v", doc)
+	}
+}
+
+func TestEncoder_Encode_error(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*go3mf.Model)
+		wantErr error
+	}{
+		{"missingItem", func(m *go3mf.Model) { m.Build.Items[0].ObjectID = 10 }, specerr.ErrMissingResource},
+		{"missingComponent", func(m *go3mf.Model) { m.Resources.Objects[1].Components.Component[1].ObjectID = 10 }, specerr.ErrMissingResource},
+		{"recursive", func(m *go3mf.Model) { m.Resources.Objects[1].Components.Component[1].ObjectID = 6 }, specerr.ErrRecursion},
+		{"index", func(m *go3mf.Model) { m.Resources.Objects[0].Mesh.Triangles.Triangle[0].V1 = 10 }, specerr.ErrIndexOutOfBounds},
+		{"property", func(m *go3mf.Model) { m.Resources.Objects[0].Mesh.Triangles.Triangle[0].PID = 10 }, specerr.ErrMissingResource},
+		{"texture", func(m *go3mf.Model) { m.Attachments = nil }, materials.ErrMissingTexturePart},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newEncoderTestModel()
+			tt.modify(m)
+			if err := NewEncoder(new(bytes.Buffer)).Encode(m); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Encoder.Encode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_toLinear(t *testing.T) {
+	tests := []struct {
+		c    uint8
+		want float32
+	}{
+		{0, 0}, {10, 0.003035}, {128, 0.215861}, {255, 1},
+	}
+	for _, tt := range tests {
+		if got := toLinear(tt.c); math.Abs(float64(got-tt.want)) > 1e-6 {
+			t.Errorf("toLinear(%d) = %v, want %v", tt.c, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package gltf
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/importer"
+)
+
+func init() {
+	importer.Register(importer.Format{
+		Name:       "glb",
+		Extensions: []string{".glb"},
+		Sniff: func(b []byte) bool {
+			return len(b) >= 4 && binary.LittleEndian.Uint32(b) == glbMagic
+		},
+		Decode: decode,
+		Encode: func(w io.Writer, m *go3mf.Model) error {
+			return NewEncoder(w).Encode(m)
+		},
+	})
+	importer.Register(importer.Format{
+		Name:       "gltf",
+		Extensions: []string{".gltf"},
+		Sniff:      sniffJSON,
+		Decode:     decode,
+	})
+}
+
+func decode(ctx context.Context, r io.Reader, open importer.OpenFunc, m *go3mf.Model) error {
+	d := NewDecoder(r)
+	d.Open = open
+	return d.DecodeContext(ctx, m)
+}
+
+// sniffJSON reports whether b is a JSON glTF with an asset version.
+func sniffJSON(b []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		return false
+	}
+	var doc struct {
+		Asset *asset `json:"asset"`
+	}
+	return json.Unmarshal(b, &doc) == nil && doc.Asset != nil && doc.Asset.Version != ""
+}
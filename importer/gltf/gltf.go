@@ -0,0 +1,286 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+// Package gltf converts models to and from glTF 2.0.
+//
+// glTF is a Y-up format in meters while 3MF is Z-up in the model units,
+// so the build is wrapped in a root node whose matrix converts between both.
+package gltf
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/hpinc/go3mf"
+)
+
+// Errors returned when decoding malformed files.
+var (
+	ErrHeader   = errors.New("invalid glb header")
+	ErrVersion  = errors.New("unsupported glTF version")
+	ErrAccessor = errors.New("invalid accessor")
+	ErrIndex    = errors.New("index out of bounds")
+	ErrURI      = errors.New("unsupported uri")
+)
+
+const (
+	glbMagic     = 0x46546C67 // glTF
+	glbVersion   = 2
+	chunkJSON    = 0x4E4F534A // JSON
+	chunkBIN     = 0x004E4942 // BIN
+	glbHeaderLen = 12
+	chunkHeadLen = 8
+)
+
+// Accessor component types.
+const (
+	componentByte          = 5120
+	componentUnsignedByte  = 5121
+	componentShort         = 5122
+	componentUnsignedShort = 5123
+	componentUnsignedInt   = 5125
+	componentFloat         = 5126
+)
+
+// Accessor limits. Accessors without buffer view are not bounded by any
+// buffer, so their count is capped to keep allocations sane.
+const (
+	minByteStride    = 4
+	maxByteStride    = 252
+	maxAccessorCount = 1 << 24
+)
+
+// Buffer view targets.
+const (
+	targetArrayBuffer        = 34962
+	targetElementArrayBuffer = 34963
+)
+
+// Sampler filters and wrap modes.
+const (
+	filterNearest      = 9728
+	filterLinear       = 9729
+	wrapClampToEdge    = 33071
+	wrapMirroredRepeat = 33648
+	wrapRepeat         = 10497
+)
+
+// Primitive modes.
+const (
+	modeTriangles     = 4
+	modeTriangleStrip = 5
+	modeTriangleFan   = 6
+)
+
+type document struct {
+	Asset       asset        `json:"asset"`
+	Scene       *int         `json:"scene,omitempty"`
+	Scenes      []scene      `json:"scenes,omitempty"`
+	Nodes       []node       `json:"nodes,omitempty"`
+	Meshes      []mesh       `json:"meshes,omitempty"`
+	Materials   []material   `json:"materials,omitempty"`
+	Textures    []texture    `json:"textures,omitempty"`
+	Images      []image      `json:"images,omitempty"`
+	Samplers    []sampler    `json:"samplers,omitempty"`
+	Accessors   []accessor   `json:"accessors,omitempty"`
+	BufferViews []bufferView `json:"bufferViews,omitempty"`
+	Buffers     []buffer     `json:"buffers,omitempty"`
+}
+
+type asset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type scene struct {
+	Name  string `json:"name,omitempty"`
+	Nodes []int  `json:"nodes,omitempty"`
+}
+
+type node struct {
+	Name        string    `json:"name,omitempty"`
+	Mesh        *int      `json:"mesh,omitempty"`
+	Children    []int     `json:"children,omitempty"`
+	Matrix      []float32 `json:"matrix,omitempty"`
+	Translation []float32 `json:"translation,omitempty"`
+	Rotation    []float32 `json:"rotation,omitempty"`
+	Scale       []float32 `json:"scale,omitempty"`
+}
+
+type mesh struct {
+	Name       string      `json:"name,omitempty"`
+	Primitives []primitive `json:"primitives"`
+}
+
+type primitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices,omitempty"`
+	Material   *int           `json:"material,omitempty"`
+	Mode       *int           `json:"mode,omitempty"`
+}
+
+type material struct {
+	Name        string                `json:"name,omitempty"`
+	PBR         *pbrMetallicRoughness `json:"pbrMetallicRoughness,omitempty"`
+	AlphaMode   string                `json:"alphaMode,omitempty"`
+	DoubleSided bool                  `json:"doubleSided,omitempty"`
+}
+
+type pbrMetallicRoughness struct {
+	BaseColorFactor  []float32   `json:"baseColorFactor,omitempty"`
+	BaseColorTexture *textureRef `json:"baseColorTexture,omitempty"`
+	MetallicFactor   *float32    `json:"metallicFactor,omitempty"`
+}
+
+type textureRef struct {
+	Index    int `json:"index"`
+	TexCoord int `json:"texCoord,omitempty"`
+}
+
+type texture struct {
+	Sampler *int `json:"sampler,omitempty"`
+	Source  *int `json:"source,omitempty"`
+}
+
+type image struct {
+	Name       string `json:"name,omitempty"`
+	URI        string `json:"uri,omitempty"`
+	BufferView *int   `json:"bufferView,omitempty"`
+	MimeType   string `json:"mimeType,omitempty"`
+}
+
+type sampler struct {
+	MagFilter int `json:"magFilter,omitempty"`
+	MinFilter int `json:"minFilter,omitempty"`
+	WrapS     int `json:"wrapS,omitempty"`
+	WrapT     int `json:"wrapT,omitempty"`
+}
+
+type accessor struct {
+	BufferView    *int            `json:"bufferView,omitempty"`
+	ByteOffset    int             `json:"byteOffset,omitempty"`
+	ComponentType int             `json:"componentType"`
+	Normalized    bool            `json:"normalized,omitempty"`
+	Count         int             `json:"count"`
+	Type          string          `json:"type"`
+	Min           []float32       `json:"min,omitempty"`
+	Max           []float32       `json:"max,omitempty"`
+	Sparse        json.RawMessage `json:"sparse,omitempty"`
+}
+
+type bufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset,omitempty"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride,omitempty"`
+	Target     int `json:"target,omitempty"`
+}
+
+type buffer struct {
+	URI        string `json:"uri,omitempty"`
+	ByteLength int    `json:"byteLength"`
+}
+
+// rootMatrix returns the matrix that converts Z-up coordinates in units
+// to Y-up coordinates in meters.
+func rootMatrix(units go3mf.Units) go3mf.Matrix {
+	s := float32(units.Millimeters() / 1000)
+	return go3mf.Matrix{s, 0, 0, 0, 0, 0, -s, 0, 0, s, 0, 0, 0, 0, 0, 1}
+}
+
+// rootInverse returns the inverse of rootMatrix, which converts Y-up coordinates
+// in meters to Z-up coordinates in units.
+func rootInverse(units go3mf.Units) go3mf.Matrix {
+	s := float32(1000 / units.Millimeters())
+	return go3mf.Matrix{s, 0, 0, 0, 0, 0, s, 0, 0, -s, 0, 0, 0, 0, 0, 1}
+}
+
+// toLinear converts an sRGB channel to a linear channel from 0 to 1,
+// as the glTF colors factors and vertex colors are linear.
+func toLinear(c uint8) float32 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return float32(v / 12.92)
+	}
+	return float32(math.Pow((v+0.055)/1.055, 2.4))
+}
+
+// writeGLB writes a binary glTF with the JSON and BIN chunks.
+// The BIN chunk is omitted if bin is empty.
+func writeGLB(w io.Writer, doc *document, bin []byte) error {
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	length := glbHeaderLen + chunkHeadLen + len(js)
+	if len(bin) > 0 {
+		length += chunkHeadLen + len(bin)
+	}
+	header := []uint32{glbMagic, glbVersion, uint32(length), uint32(len(js)), chunkJSON}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	if _, err := w.Write(js); err != nil {
+		return err
+	}
+	if len(bin) == 0 {
+		return nil
+	}
+	if err := binary.Write(w, binary.LittleEndian, []uint32{uint32(len(bin)), chunkBIN}); err != nil {
+		return err
+	}
+	_, err = w.Write(bin)
+	return err
+}
+
+// toSRGB converts a linear channel from 0 to 1 to an sRGB channel.
+func toSRGB(v float32) uint8 {
+	f := math.Max(0, math.Min(1, float64(v)))
+	if f <= 0.0031308 {
+		f *= 12.92
+	} else {
+		f = 1.055*math.Pow(f, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(f * 255))
+}
+
+// readGLB returns the JSON and BIN chunks of a binary glTF.
+// The BIN chunk is nil if it is not present.
+func readGLB(b []byte) ([]byte, []byte, error) {
+	if len(b) < glbHeaderLen+chunkHeadLen {
+		return nil, nil, ErrHeader
+	}
+	if binary.LittleEndian.Uint32(b[4:]) != glbVersion {
+		return nil, nil, ErrVersion
+	}
+	length := int(binary.LittleEndian.Uint32(b[8:]))
+	if length > len(b) {
+		return nil, nil, ErrHeader
+	}
+	var js, bin []byte
+	for offset := glbHeaderLen; offset+chunkHeadLen <= length; {
+		size := int(binary.LittleEndian.Uint32(b[offset:]))
+		typ := binary.LittleEndian.Uint32(b[offset+4:])
+		offset += chunkHeadLen
+		if size < 0 || offset+size > length {
+			return nil, nil, ErrHeader
+		}
+		switch {
+		case typ == chunkJSON && js == nil:
+			js = b[offset : offset+size]
+		case typ == chunkBIN && bin == nil:
+			bin = b[offset : offset+size]
+		}
+		offset += size
+	}
+	if js == nil {
+		return nil, nil, ErrHeader
+	}
+	return js, bin, nil
+}
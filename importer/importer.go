@@ -0,0 +1,222 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+// Package importer decodes and encodes models in any registered format.
+//
+// The 3MF format is always registered. The other formats are registered
+// by the importer subpackages, which have to be imported for their side effects:
+//
+//	import _ "github.com/hpinc/go3mf/importer/stl"
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hpinc/go3mf"
+)
+
+// Errors returned when the format of a file is not registered.
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	ErrNoEncoder     = errors.New("format does not support encoding")
+)
+
+// OpenFunc opens the files referenced by a decoded file, such as textures or material libraries.
+type OpenFunc func(name string) (io.ReadCloser, error)
+
+// Format describes a model format.
+//
+// Sniff reports whether b, the whole content of a file, is encoded in the format.
+// It may be nil if the format can only be detected by its extensions,
+// which must be lower case and include the leading dot.
+//
+// Decode decodes r into m. open, which may be nil, opens the files referenced by r.
+// Encode writes m to w and it is nil if the format can't be encoded into a single stream.
+type Format struct {
+	Name       string
+	Extensions []string
+	Sniff      func(b []byte) bool
+	Decode     func(ctx context.Context, r io.Reader, open OpenFunc, m *go3mf.Model) error
+	Encode     func(w io.Writer, m *go3mf.Model) error
+}
+
+var (
+	formatMu sync.RWMutex
+	formats  = make(map[string]Format)
+)
+
+func init() {
+	Register(Format{
+		Name:       "3mf",
+		Extensions: []string{".3mf"},
+		Sniff:      sniff3MF,
+		Decode:     decode3MF,
+		Encode: func(w io.Writer, m *go3mf.Model) error {
+			return go3mf.NewEncoder(w).Encode(m)
+		},
+	})
+}
+
+// Register makes a format available by the provided name.
+// If Register is called twice with the same name the last format wins.
+func Register(f Format) {
+	formatMu.Lock()
+	defer formatMu.Unlock()
+	formats[f.Name] = f
+}
+
+// Lookup returns the format registered with name.
+func Lookup(name string) (Format, bool) {
+	formatMu.RLock()
+	f, ok := formats[name]
+	formatMu.RUnlock()
+	return f, ok
+}
+
+// Formats returns the registered formats sorted by name.
+func Formats() []Format {
+	formatMu.RLock()
+	fs := make([]Format, 0, len(formats))
+	for _, f := range formats {
+		fs = append(fs, f)
+	}
+	formatMu.RUnlock()
+	sort.Slice(fs, func(i, j int) bool {
+		return fs[i].Name < fs[j].Name
+	})
+	return fs
+}
+
+// ByExtension returns the format whose extensions include the extension of the file name.
+func ByExtension(name string) (Format, bool) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" {
+		return Format{}, false
+	}
+	for _, f := range Formats() {
+		for _, e := range f.Extensions {
+			if e == ext {
+				return f, true
+			}
+		}
+	}
+	return Format{}, false
+}
+
+// Detect returns the first format, in name order, whose sniffer matches b.
+func Detect(b []byte) (Format, bool) {
+	for _, f := range Formats() {
+		if f.Sniff != nil && f.Sniff(b) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Decode detects the format of r and decodes it into m.
+func Decode(r io.Reader, m *go3mf.Model) error {
+	return DecodeContext(context.Background(), r, m)
+}
+
+// DecodeContext detects the format of r and decodes it into m.
+//
+// r is read into memory before decoding,
+// as some formats, such as 3MF, require random access.
+func DecodeContext(ctx context.Context, r io.Reader, m *go3mf.Model) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	f, ok := Detect(b)
+	if !ok {
+		return ErrUnknownFormat
+	}
+	return f.Decode(ctx, bytes.NewReader(b), nil, m)
+}
+
+// DecodeFile decodes the file name into m.
+// The format is detected from its content, falling back to its extension,
+// and the files it references are opened relative to its directory.
+func DecodeFile(name string, m *go3mf.Model) error {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	f, ok := Detect(b)
+	if !ok {
+		if f, ok = ByExtension(name); !ok {
+			return fmt.Errorf("%s: %w", name, ErrUnknownFormat)
+		}
+	}
+	dir := filepath.Dir(name)
+	open := func(ref string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, filepath.FromSlash(ref)))
+	}
+	return f.Decode(context.Background(), bytes.NewReader(b), open, m)
+}
+
+// EncodeFile encodes m into the file name, using the format of its extension.
+func EncodeFile(name string, m *go3mf.Model) error {
+	f, ok := ByExtension(name)
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrUnknownFormat)
+	}
+	if f.Encode == nil {
+		return fmt.Errorf("%s: %w", f.Name, ErrNoEncoder)
+	}
+	w, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err = f.Encode(w, m); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// IsZip reports whether b is a zip archive containing a file that matches fn.
+func IsZip(b []byte, fn func(name string) bool) bool {
+	if !bytes.HasPrefix(b, []byte("PK\x03\x04")) {
+		return false
+	}
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return false
+	}
+	for _, f := range z.File {
+		if fn(f.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func sniff3MF(b []byte) bool {
+	return IsZip(b, func(name string) bool {
+		return strings.EqualFold(name, "[Content_Types].xml")
+	})
+}
+
+func decode3MF(ctx context.Context, r io.Reader, _ OpenFunc, m *go3mf.Model) error {
+	ra, ok := r.(*bytes.Reader)
+	if !ok {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		ra = bytes.NewReader(b)
+	}
+	return go3mf.NewDecoder(ra, ra.Size()).DecodeContext(ctx, m)
+}
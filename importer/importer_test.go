@@ -0,0 +1,201 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package importer_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/importer"
+	_ "github.com/hpinc/go3mf/importer/amf"
+	_ "github.com/hpinc/go3mf/importer/gltf"
+	_ "github.com/hpinc/go3mf/importer/obj"
+	_ "github.com/hpinc/go3mf/importer/ply"
+	_ "github.com/hpinc/go3mf/importer/stl"
+)
+
+func newZip(name, content string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, _ := w.Create(name)
+	f.Write([]byte(content))
+	w.Close()
+	return buf.Bytes()
+}
+
+func newBinarySTL(triangles uint32) []byte {
+	b := make([]byte, 84+50*triangles)
+	binary.LittleEndian.PutUint32(b[80:], triangles)
+	return b
+}
+
+func TestDetect(t *testing.T) {
+	threeMF, _ := ioutil.ReadFile("../testdata/cube.3mf")
+	tests := []struct {
+		name string
+		b    []byte
+		want string
+	}{
+		{"3mf", threeMF, "3mf"},
+		{"amf", []byte(`<?xml version="1.0"?><amf unit="millimeter"></amf>`), "amf"},
+		{"amfZip", newZip("model.amf", "<amf/>"), "amf"},
+		{"amfProlog", []byte("\xef\xbb\xbf<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<!-- exported -->\n<!DOCTYPE amf>\n<amf>"), "amf"},
+		{"objAMFComment", []byte("# converted from <amf>\nv 0 0 0\n"), "obj"},
+		{"stlAMFName", []byte("solid <amf>\nfacet normal 0 0 1\n"), "stl"},
+		{"otherXML", []byte(`<?xml version="1.0"?><model><amf/></model>`), ""},
+		{"glb", []byte("glTF\x02\x00\x00\x00"), "glb"},
+		{"gltf", []byte(` {"asset": {"version": "2.0"}}`), "gltf"},
+		{"obj", []byte("# comment\n\nmtllib a.mtl\nv 0 0 0\n"), "obj"},
+		{"ply", []byte("ply\nformat ascii 1.0\n"), "ply"},
+		{"asciiSTL", []byte("  solid cube\nfacet normal 0 0 1\n"), "stl"},
+		{"binarySTL", newBinarySTL(2), "stl"},
+		{"emptyBinarySTL", newBinarySTL(0), "stl"},
+		{"otherZip", newZip("a.txt", "a"), ""},
+		{"json", []byte(`{"a": 1}`), ""},
+		{"text", []byte("hello world"), ""},
+		{"truncatedSTL", newBinarySTL(2)[:150], ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := importer.Detect(tt.b)
+			if ok != (tt.want != "") || got.Name != tt.want {
+				t.Errorf("Detect() = %v, %v, want %v", got.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestByExtension(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"a/b.3MF", "3mf"},
+		{"b.stl", "stl"},
+		{"b.glb", "glb"},
+		{"b.gltf", "gltf"},
+		{"b.obj", "obj"},
+		{"b.ply", "ply"},
+		{"b.amf", "amf"},
+		{"b.txt", ""},
+		{"stl", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := importer.ByExtension(tt.name)
+			if ok != (tt.want != "") || got.Name != tt.want {
+				t.Errorf("ByExtension() = %v, %v, want %v", got.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormats(t *testing.T) {
+	var names []string
+	for _, f := range importer.Formats() {
+		names = append(names, f.Name)
+	}
+	want := []string{"3mf", "amf", "glb", "gltf", "obj", "ply", "stl"}
+	if diff := deep.Equal(names, want); diff != nil {
+		t.Errorf("Formats() = %v", diff)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		want    int
+		wantErr error
+	}{
+		{"stl", newBinarySTL(1), 1, nil},
+		{"ply", []byte("ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n1 0 0\n0 1 0\n3 0 1 2\n"), 1, nil},
+		{"unknown", []byte("hello world"), 0, importer.ErrUnknownFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m go3mf.Model
+			err := importer.Decode(bytes.NewReader(tt.b), &m)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(m.Resources.Objects) != tt.want {
+				t.Errorf("Decode() objects = %d, want %d", len(m.Resources.Objects), tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	m := &go3mf.Model{
+		Resources: go3mf.Resources{Objects: []*go3mf.Object{{ID: 1, Mesh: &go3mf.Mesh{
+			Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+		}}}},
+		Build: go3mf.Build{Items: []*go3mf.Item{{ObjectID: 1}}},
+	}
+	for _, name := range []string{"model.3mf", "model.stl", "model.ply", "model.glb"} {
+		t.Run(name, func(t *testing.T) {
+			name := filepath.Join(dir, name)
+			if err := importer.EncodeFile(name, m); err != nil {
+				t.Fatalf("EncodeFile() error = %v", err)
+			}
+			var got go3mf.Model
+			if err := importer.DecodeFile(name, &got); err != nil {
+				t.Fatalf("DecodeFile() error = %v", err)
+			}
+			if len(got.Build.Items) != 1 {
+				t.Errorf("DecodeFile() items = %v", got.Build.Items)
+			}
+		})
+	}
+	if err := importer.EncodeFile(filepath.Join(dir, "model.obj"), m); !errors.Is(err, importer.ErrNoEncoder) {
+		t.Errorf("EncodeFile() error = %v, want %v", err, importer.ErrNoEncoder)
+	}
+	if err := importer.EncodeFile(filepath.Join(dir, "model.txt"), m); !errors.Is(err, importer.ErrUnknownFormat) {
+		t.Errorf("EncodeFile() error = %v, want %v", err, importer.ErrUnknownFormat)
+	}
+}
+
+func TestDecodeFile_open(t *testing.T) {
+	dir, err := ioutil.TempDir("", "importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"model.obj": "mtllib model.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nusemtl red\nf 1 2 3\n",
+		"model.mtl": "newmtl red\nKd 1 0 0\n",
+		"model.dat": "v 0 0 0\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var m go3mf.Model
+	if err := importer.DecodeFile(filepath.Join(dir, "model.obj"), &m); err != nil {
+		t.Fatalf("DecodeFile() error = %v", err)
+	}
+	if len(m.Resources.Assets) != 1 {
+		t.Errorf("DecodeFile() assets = %v", m.Resources.Assets)
+	}
+	if err := importer.DecodeFile(filepath.Join(dir, "missing.obj"), &m); !os.IsNotExist(err) {
+		t.Errorf("DecodeFile() error = %v, want not exist", err)
+	}
+}
@@ -0,0 +1,359 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package obj
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/materials"
+)
+
+var checkEveryFaces = 1000
+
+// Errors returned when decoding malformed files.
+var (
+	ErrIndex  = errors.New("index out of bounds")
+	ErrNumber = errors.New("invalid number")
+	ErrFace   = errors.New("face MUST have at least 3 vertices")
+)
+
+// Decoder can decode a Wavefront obj file and its mtl material libraries.
+//
+// Each object (o) and group (g) is decoded as a new object referenced by a new build item.
+// Polygons are triangulated as a fan, so they are expected to be convex.
+//
+// Open is used to read the material libraries (mtllib) and the diffuse textures (map_Kd).
+// If it is nil the materials are ignored.
+// The diffuse colors (Kd) are decoded into a single go3mf.BaseMaterials.
+// The diffuse textures are attached to the model, in go3mf.Default3DTexturesDir, as a materials.Texture2D,
+// and the texture coordinates (vt) of the faces using them are decoded into a materials.Texture2DGroup.
+type Decoder struct {
+	Open func(name string) (io.ReadCloser, error)
+	r    io.Reader
+}
+
+// NewDecoder creates a new decoder.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: r,
+	}
+}
+
+// Decode creates a model from a read stream.
+func (d *Decoder) Decode(m *go3mf.Model) error {
+	return d.DecodeContext(context.Background(), m)
+}
+
+// DecodeContext creates a model from a read stream.
+func (d *Decoder) DecodeContext(ctx context.Context, m *go3mf.Model) error {
+	s := objDecoder{
+		d:         d,
+		m:         m,
+		materials: make(map[string]*material),
+		textures:  make(map[string]*textureGroup),
+	}
+	s.newObject("")
+	if err := s.decode(ctx, d.r); err != nil {
+		return err
+	}
+	for _, obj := range s.objs {
+		if len(obj.Mesh.Triangles.Triangle) == 0 {
+			continue
+		}
+		obj.ID = m.Resources.UnusedID()
+		m.Resources.Objects = append(m.Resources.Objects, obj)
+		m.Build.Items = append(m.Build.Items, &go3mf.Item{ObjectID: obj.ID})
+	}
+	return nil
+}
+
+type material struct {
+	name      string
+	color     [4]float32
+	hasColor  bool
+	texture   string
+	baseIndex uint32
+	hasBase   bool
+}
+
+type textureGroup struct {
+	group  *materials.Texture2DGroup
+	coords map[materials.TextureCoord]uint32
+}
+
+type objDecoder struct {
+	d         *Decoder
+	m         *go3mf.Model
+	objs      []*go3mf.Object
+	obj       *go3mf.Object
+	vertexMap map[int]uint32
+	vertices  []go3mf.Point3D
+	uvs       []materials.TextureCoord
+	materials map[string]*material
+	textures  map[string]*textureGroup
+	current   *material
+	base      *go3mf.BaseMaterials
+}
+
+func (s *objDecoder) newObject(name string) {
+	s.obj = &go3mf.Object{Name: name, Mesh: new(go3mf.Mesh)}
+	s.objs = append(s.objs, s.obj)
+	s.vertexMap = make(map[int]uint32)
+}
+
+func (s *objDecoder) decode(ctx context.Context, r io.Reader) error {
+	var (
+		nextFaceCheck = checkEveryFaces
+		faceCount     int
+		lineNumber    int
+		line          string
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNumber++
+		line += scanner.Text()
+		if strings.HasSuffix(line, "\\") {
+			line = line[:len(line)-1]
+			continue
+		}
+		fields := strings.Fields(line)
+		line = ""
+		if len(fields) == 0 {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "v":
+			var p [3]float32
+			if p, err = parseFloats(fields[1:], 3); err == nil {
+				s.vertices = append(s.vertices, go3mf.Point3D(p))
+			}
+		case "vt":
+			// v is optional and defaults to 0, w is ignored.
+			n := 2
+			if len(fields) < 3 {
+				n = 1
+			}
+			var p [3]float32
+			if p, err = parseFloats(fields[1:], n); err == nil {
+				s.uvs = append(s.uvs, materials.TextureCoord{p[0], p[1]})
+			}
+		case "f":
+			err = s.face(fields[1:])
+			faceCount++
+		case "o", "g":
+			s.newObject(strings.Join(fields[1:], " "))
+		case "usemtl":
+			s.current = s.materials[strings.Join(fields[1:], " ")]
+		case "mtllib":
+			err = s.materialLibraries(fields[1:])
+		}
+		if err == nil && faceCount > nextFaceCheck {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+			default: // Default is must to avoid blocking
+			}
+			nextFaceCheck += checkEveryFaces
+		}
+		if err != nil {
+			return fmt.Errorf("obj: line %d: %w", lineNumber, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// face adds the polygon defined by fields to the current object,
+// triangulated as a fan from the first vertex.
+func (s *objDecoder) face(fields []string) error {
+	if len(fields) < 3 {
+		return ErrFace
+	}
+	var (
+		vertices = make([]uint32, len(fields))
+		uvs      = make([]int, len(fields))
+		hasUV    = true
+	)
+	for i, f := range fields {
+		refs := strings.Split(f, "/")
+		v, err := s.index(refs[0], len(s.vertices))
+		if err != nil {
+			return err
+		}
+		vertices[i] = s.vertex(v)
+		if len(refs) > 1 && refs[1] != "" {
+			if uvs[i], err = s.index(refs[1], len(s.uvs)); err != nil {
+				return err
+			}
+		} else {
+			hasUV = false
+		}
+	}
+	var tg *textureGroup
+	if s.current != nil && s.current.texture != "" && hasUV {
+		var err error
+		if tg, err = s.texture(s.current.texture); err != nil {
+			return err
+		}
+	}
+	mesh := s.obj.Mesh
+	for i := 2; i < len(vertices); i++ {
+		t := go3mf.Triangle{V1: vertices[0], V2: vertices[i-1], V3: vertices[i]}
+		if s.current != nil {
+			if tg != nil {
+				t.PID = tg.group.ID
+				t.P1, t.P2, t.P3 = tg.coord(s.uvs[uvs[0]]), tg.coord(s.uvs[uvs[i-1]]), tg.coord(s.uvs[uvs[i]])
+			} else if s.current.hasColor {
+				t.PID = s.baseMaterials(s.current)
+				t.P1, t.P2, t.P3 = s.current.baseIndex, s.current.baseIndex, s.current.baseIndex
+			}
+		}
+		mesh.Triangles.Triangle = append(mesh.Triangles.Triangle, t)
+	}
+	return nil
+}
+
+// index returns the zero based index referenced by field,
+// which can also be relative to the end of a list of length n.
+func (s *objDecoder) index(field string, n int) (int, error) {
+	i, err := strconv.Atoi(field)
+	if err != nil {
+		return 0, ErrNumber
+	}
+	if i < 0 {
+		i += n
+	} else {
+		i--
+	}
+	if i < 0 || i >= n {
+		return 0, ErrIndex
+	}
+	return i, nil
+}
+
+// vertex returns the index of the global vertex v in the current object.
+func (s *objDecoder) vertex(v int) uint32 {
+	if index, ok := s.vertexMap[v]; ok {
+		return index
+	}
+	mesh := s.obj.Mesh
+	index := uint32(len(mesh.Vertices.Vertex))
+	mesh.Vertices.Vertex = append(mesh.Vertices.Vertex, s.vertices[v])
+	s.vertexMap[v] = index
+	return index
+}
+
+// baseMaterials adds the color of mat to the base materials of the model,
+// which are created the first time, and returns their ID.
+func (s *objDecoder) baseMaterials(mat *material) uint32 {
+	if s.base == nil {
+		s.base = &go3mf.BaseMaterials{ID: s.m.Resources.UnusedID()}
+		s.m.Resources.Assets = append(s.m.Resources.Assets, s.base)
+	}
+	if !mat.hasBase {
+		mat.baseIndex = uint32(len(s.base.Materials))
+		mat.hasBase = true
+		s.base.Materials = append(s.base.Materials, go3mf.Base{Name: mat.name, Color: toRGBA(mat.color)})
+	}
+	return s.base.ID
+}
+
+func (tg *textureGroup) coord(c materials.TextureCoord) uint32 {
+	if index, ok := tg.coords[c]; ok {
+		return index
+	}
+	index := uint32(len(tg.group.Coords))
+	tg.group.Coords = append(tg.group.Coords, c)
+	tg.coords[c] = index
+	return index
+}
+
+// texture returns the texture group of the texture name,
+// attaching the texture to the model the first time it is used.
+// It returns nil if the texture format is not supported.
+func (s *objDecoder) texture(name string) (*textureGroup, error) {
+	if tg, ok := s.textures[name]; ok {
+		return tg, nil
+	}
+	var contentType materials.Texture2DType
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png":
+		contentType = materials.TextureTypePNG
+	case ".jpg", ".jpeg":
+		contentType = materials.TextureTypeJPEG
+	default:
+		s.textures[name] = nil
+		return nil, nil
+	}
+	f, err := s.d.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	rs := &s.m.Resources
+	tex := &materials.Texture2D{ID: rs.UnusedID(), Path: s.texturePath(name), ContentType: contentType}
+	rs.Assets = append(rs.Assets, tex)
+	tg := &textureGroup{
+		group:  &materials.Texture2DGroup{ID: rs.UnusedID(), TextureID: tex.ID},
+		coords: make(map[materials.TextureCoord]uint32),
+	}
+	rs.Assets = append(rs.Assets, tg.group)
+	s.m.Attachments = append(s.m.Attachments, go3mf.Attachment{
+		Stream:      &buf,
+		Path:        tex.Path,
+		ContentType: contentType.String(),
+	})
+	materials.Declare(s.m)
+	s.textures[name] = tg
+	return tg, nil
+}
+
+// texturePath returns a unique attachment path for the texture name.
+func (s *objDecoder) texturePath(name string) string {
+	base := path.Base(strings.Replace(name, "\\", "/", -1))
+	ext := path.Ext(base)
+	p := go3mf.Default3DTexturesDir + base
+	for i := 1; s.hasAttachment(p); i++ {
+		p = go3mf.Default3DTexturesDir + strings.TrimSuffix(base, ext) + "_" + strconv.Itoa(i) + ext
+	}
+	return p
+}
+
+func (s *objDecoder) hasAttachment(p string) bool {
+	for _, a := range s.m.Attachments {
+		if strings.EqualFold(a.Path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFloats parses the first n fields, which must exist.
+func parseFloats(fields []string, n int) (p [3]float32, err error) {
+	if len(fields) < n {
+		return p, ErrNumber
+	}
+	for i := 0; i < n; i++ {
+		f, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return p, ErrNumber
+		}
+		p[i] = float32(f)
+	}
+	return p, nil
+}
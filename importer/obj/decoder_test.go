@@ -0,0 +1,172 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package obj
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/materials"
+)
+
+// openFiles returns a function that opens the files defined in files.
+func openFiles(files map[string]string) func(string) (io.ReadCloser, error) {
+	return func(name string) (io.ReadCloser, error) {
+		if s, ok := files[name]; ok {
+			return ioutil.NopCloser(strings.NewReader(s)), nil
+		}
+		return nil, os.ErrNotExist
+	}
+}
+
+func TestNewDecoder(t *testing.T) {
+	r := new(bytes.Buffer)
+	if got := NewDecoder(r); !reflect.DeepEqual(got, &Decoder{r: r}) {
+		t.Errorf("NewDecoder() = %v", got)
+	}
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	square := "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\n"
+	tests := []struct {
+		name string
+		src  string
+		want []*go3mf.Object
+	}{
+		{"quad", square + "f 1 2 3 4\n", []*go3mf.Object{
+			{ID: 1, Mesh: &go3mf.Mesh{
+				Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}},
+				Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}, {V1: 0, V2: 2, V3: 3}}},
+			}},
+		}},
+		{"objects", square + "o first\nf 1//1 2//1 3//1\ng second \\\n  group\nf -4 -2 -1\no empty\n", []*go3mf.Object{
+			{ID: 1, Name: "first", Mesh: &go3mf.Mesh{
+				Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}}},
+				Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+			}},
+			{ID: 2, Name: "second group", Mesh: &go3mf.Mesh{
+				Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 1, 0}, {0, 1, 0}}},
+				Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+			}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := new(go3mf.Model)
+			if err := NewDecoder(strings.NewReader(tt.src)).Decode(got); err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			if diff := deep.Equal(got.Resources.Objects, tt.want); diff != nil {
+				t.Errorf("Decoder.Decode() = %v", diff)
+			}
+			if len(got.Build.Items) != len(tt.want) {
+				t.Errorf("Decoder.Decode() items = %d, want %d", len(got.Build.Items), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_materials(t *testing.T) {
+	src := `mtllib a.mtl
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+vt 0 0
+vt 1
+vt 1 1 0
+usemtl red
+f 1 2 3
+usemtl textured
+f 1/1 3/3 4/1
+f 1/1 2/2 3/3
+usemtl textured
+f 1 2 4
+usemtl unknown
+f 2 3 4
+`
+	mtl := `newmtl red
+Kd 1 0 0
+d 0.5
+newmtl textured
+Kd 0 0 1
+map_Kd -s 1 1 1 tex.png
+`
+	d := NewDecoder(strings.NewReader(src))
+	d.Open = openFiles(map[string]string{"a.mtl": mtl, "tex.png": "png"})
+	got := new(go3mf.Model)
+	if err := d.Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	wantAssets := []go3mf.Asset{
+		&go3mf.BaseMaterials{ID: 1, Materials: []go3mf.Base{
+			{Name: "red", Color: color.RGBA{R: 255, A: 128}},
+			{Name: "textured", Color: color.RGBA{B: 255, A: 255}},
+		}},
+		&materials.Texture2D{ID: 2, Path: "/3D/Textures/tex.png", ContentType: materials.TextureTypePNG},
+		&materials.Texture2DGroup{ID: 3, TextureID: 2, Coords: []materials.TextureCoord{{0, 0}, {1, 1}, {1, 0}}},
+	}
+	if diff := deep.Equal(got.Resources.Assets, wantAssets); diff != nil {
+		t.Errorf("Decoder.Decode() assets = %v", diff)
+	}
+	wantTriangles := []go3mf.Triangle{
+		{V1: 0, V2: 1, V3: 2, PID: 1},
+		{V1: 0, V2: 2, V3: 3, PID: 3, P1: 0, P2: 1, P3: 0},
+		{V1: 0, V2: 1, V3: 2, PID: 3, P1: 0, P2: 2, P3: 1},
+		{V1: 0, V2: 1, V3: 3, PID: 1, P1: 1, P2: 1, P3: 1},
+		{V1: 1, V2: 2, V3: 3},
+	}
+	if diff := deep.Equal(got.Resources.Objects[0].Mesh.Triangles.Triangle, wantTriangles); diff != nil {
+		t.Errorf("Decoder.Decode() triangles = %v", diff)
+	}
+	if len(got.Attachments) != 1 || got.Attachments[0].Path != "/3D/Textures/tex.png" || got.Attachments[0].ContentType != "image/png" {
+		t.Errorf("Decoder.Decode() attachments = %v", got.Attachments)
+	}
+	if diff := deep.Equal(got.Extensions, []go3mf.Extension{materials.DefaultExtension}); diff != nil {
+		t.Errorf("Decoder.Decode() extensions = %v", diff)
+	}
+}
+
+func TestDecoder_Decode_error(t *testing.T) {
+	checkEveryFaces = 1
+	defer func() { checkEveryFaces = 1000 }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		src     string
+		ctx     context.Context
+		wantErr error
+	}{
+		{"index", "v 0 0 0\nf 1 2 3\n", context.Background(), ErrIndex},
+		{"zeroIndex", "v 0 0 0\nf 0 1 1\n", context.Background(), ErrIndex},
+		{"uvIndex", "v 0 0 0\nf 1/1 1/1 1/1\n", context.Background(), ErrIndex},
+		{"number", "v 0 a 0\n", context.Background(), ErrNumber},
+		{"uvNumber", "vt\n", context.Background(), ErrNumber},
+		{"face", "v 0 0 0\nf 1 1\n", context.Background(), ErrFace},
+		{"mtl", "mtllib b.mtl\n", context.Background(), os.ErrNotExist},
+		{"mtlNumber", "mtllib a.mtl\n", context.Background(), ErrNumber},
+		{"cancel", "v 0 0 0\nf 1 1 1\nf 1 1 1\n", ctx, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tt.src))
+			d.Open = openFiles(map[string]string{"a.mtl": "newmtl a\nKd 1 1\n"})
+			err := d.DecodeContext(tt.ctx, new(go3mf.Model))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decoder.DecodeContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
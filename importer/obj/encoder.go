@@ -0,0 +1,387 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package obj
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+)
+
+const defaultMaterial = "default"
+
+// FileCreator creates the files written by an Encoder.
+type FileCreator interface {
+	Create(name string) (io.WriteCloser, error)
+}
+
+// Dir is a FileCreator that creates the files in a directory of the local file system.
+type Dir string
+
+// Create creates the file name in the directory.
+func (d Dir) Create(name string) (io.WriteCloser, error) {
+	return os.Create(filepath.Join(string(d), name))
+}
+
+// Files is a FileCreator that keeps the files in memory.
+type Files map[string]*bytes.Buffer
+
+// Create creates the file name, replacing any previous file with the same name.
+func (f Files) Create(name string) (io.WriteCloser, error) {
+	b := new(bytes.Buffer)
+	f[name] = b
+	return nopCloser{b}, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// Encoder can encode a Wavefront obj file, its mtl material library and its textures.
+//
+// The build items are flattened, applying the component and build item transforms,
+// and each mesh is written as an object (o) named after the go3mf.Object.
+//
+// The triangles whose vertices reference the same texture are written with texture
+// coordinates (vt) and a material with the texture as diffuse map (map_Kd).
+// The textures are written as files named after the texture path.
+// The other triangles are written with a material whose diffuse color (Kd)
+// is the color of the first vertex, evaluated with a materials.ColorEvaluator,
+// so only colors defined per triangle are preserved.
+type Encoder struct {
+	// Name is the file name, without extension, of the obj and mtl files.
+	Name string
+	fc   FileCreator
+}
+
+// NewEncoder creates a new encoder that writes the files to fc.
+func NewEncoder(fc FileCreator) *Encoder {
+	return &Encoder{
+		Name: "model",
+		fc:   fc,
+	}
+}
+
+// Encode writes the build items of m.
+func (e *Encoder) Encode(m *go3mf.Model) error {
+	f, err := e.fc.Create(e.Name + ".obj")
+	if err != nil {
+		return err
+	}
+	s := &objEncoder{
+		m:         m,
+		w:         bufio.NewWriter(f),
+		resolvers: make(map[string]*materials.Resolver),
+		evaluator: materials.NewColorEvaluator(m),
+		matIndex:  make(map[matKey]int),
+		matNames:  make(map[string]bool),
+		textures:  make(map[string]string),
+	}
+	s.w.WriteString("mtllib " + e.Name + ".mtl\n")
+	for i, item := range m.Build.Items {
+		if err = m.WalkItemMeshes(item, s.writeMesh); err != nil {
+			err = specerr.WrapIndex(err, "item", i)
+			break
+		}
+	}
+	if err == nil {
+		err = s.w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err = e.writeMaterials(s.mats); err != nil {
+		return err
+	}
+	return e.writeTextures(m, s.mats)
+}
+
+func (e *Encoder) writeMaterials(mats []*mtlMaterial) error {
+	f, err := e.fc.Create(e.Name + ".mtl")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, mat := range mats {
+		fmt.Fprintf(w, "newmtl %s\nKd %s %s %s\n", mat.name, formatChannel(mat.color.R), formatChannel(mat.color.G), formatChannel(mat.color.B))
+		if mat.color.A != 0xff {
+			fmt.Fprintf(w, "d %s\n", formatChannel(mat.color.A))
+		}
+		if mat.texture != nil {
+			fmt.Fprintf(w, "map_Kd %s\n", mat.file)
+		}
+	}
+	err = w.Flush()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (e *Encoder) writeTextures(m *go3mf.Model, mats []*mtlMaterial) error {
+	written := make(map[string]bool)
+	for _, mat := range mats {
+		if mat.texture == nil || written[mat.file] {
+			continue
+		}
+		written[mat.file] = true
+		data, err := mat.texture.Data(m)
+		if err != nil {
+			return err
+		}
+		f, err := e.fc.Create(mat.file)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type matKey struct {
+	path       string
+	pid, index uint32
+	texture    bool
+}
+
+type mtlMaterial struct {
+	name    string
+	color   color.RGBA
+	texture *materials.Texture2D
+	file    string
+}
+
+type objFace struct {
+	v   [3]int
+	vt  [3]int
+	mat int
+}
+
+// objEncoder writes the obj file.
+// current is the index of the material in use plus one,
+// or zero if the default material is in use.
+type objEncoder struct {
+	m         *go3mf.Model
+	w         *bufio.Writer
+	resolvers map[string]*materials.Resolver
+	evaluator *materials.ColorEvaluator
+	mats      []*mtlMaterial
+	matIndex  map[matKey]int
+	matNames  map[string]bool
+	textures  map[string]string
+	vertices  int
+	uvs       int
+	current   int
+}
+
+// writeMesh writes the mesh of obj transformed by transform as a new object.
+// Transforms with a negative determinant mirror the mesh, so the vertex order
+// is reversed to keep the faces facing outwards.
+func (s *objEncoder) writeMesh(path string, obj *go3mf.Object, transform go3mf.Matrix) error {
+	r, ok := s.resolvers[path]
+	if !ok {
+		r = materials.NewResolver(s.m, path)
+		s.resolvers[path] = r
+	}
+	var (
+		mirror   = transform.Determinant() < 0
+		vertices = obj.Mesh.Vertices.Vertex
+		nv       = uint32(len(vertices))
+		faces    = make([]objFace, 0, len(obj.Mesh.Triangles.Triangle))
+		uvs      []materials.TextureCoord
+	)
+	for i := range obj.Mesh.Triangles.Triangle {
+		t := &obj.Mesh.Triangles.Triangle[i]
+		if t.V1 >= nv || t.V2 >= nv || t.V3 >= nv {
+			return specerr.WrapIndex(specerr.ErrIndexOutOfBounds, "triangle", i)
+		}
+		props, err := r.Triangle(obj, t)
+		if err != nil {
+			return specerr.WrapIndex(err, "triangle", i)
+		}
+		face := objFace{v: [3]int{int(t.V1), int(t.V2), int(t.V3)}, mat: -1}
+		if tex, coords, ok := textureCoords(props); ok {
+			face.mat = s.textureMaterial(path, tex)
+			for j, c := range coords {
+				uvs = append(uvs, c)
+				face.vt[j] = s.uvs + len(uvs)
+			}
+		} else if props[0] != nil {
+			if face.mat, err = s.colorMaterial(path, props[0]); err != nil {
+				return specerr.WrapIndex(err, "triangle", i)
+			}
+		}
+		if mirror {
+			face.v[1], face.v[2] = face.v[2], face.v[1]
+			face.vt[1], face.vt[2] = face.vt[2], face.vt[1]
+		}
+		faces = append(faces, face)
+	}
+	name := obj.Name
+	if name == "" {
+		name = "object" + strconv.FormatUint(uint64(obj.ID), 10)
+	}
+	s.w.WriteString("o " + name + "\n")
+	for _, v := range vertices {
+		p := transform.Mul3D(v)
+		s.writeFloats("v", p[:])
+	}
+	for _, c := range uvs {
+		s.writeFloats("vt", c[:])
+	}
+	for _, f := range faces {
+		s.writeFace(f)
+	}
+	s.vertices += len(vertices)
+	s.uvs += len(uvs)
+	return nil
+}
+
+func (s *objEncoder) writeFloats(prefix string, fs []float32) {
+	s.w.WriteString(prefix)
+	for _, f := range fs {
+		s.w.WriteByte(' ')
+		s.w.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	s.w.WriteByte('\n')
+}
+
+func (s *objEncoder) writeFace(f objFace) {
+	if mat := f.mat + 1; mat != s.current {
+		s.current = mat
+		if f.mat < 0 {
+			s.w.WriteString("usemtl " + s.defaultMaterial() + "\n")
+		} else {
+			s.w.WriteString("usemtl " + s.mats[f.mat].name + "\n")
+		}
+	}
+	s.w.WriteByte('f')
+	for j, v := range f.v {
+		s.w.WriteByte(' ')
+		s.w.WriteString(strconv.Itoa(s.vertices + v + 1))
+		if f.vt[j] != 0 {
+			s.w.WriteByte('/')
+			s.w.WriteString(strconv.Itoa(f.vt[j]))
+		}
+	}
+	s.w.WriteByte('\n')
+}
+
+// defaultMaterial returns the name of the material used by the triangles without properties.
+func (s *objEncoder) defaultMaterial() string {
+	index, ok := s.matIndex[matKey{}]
+	if !ok {
+		index = s.addMaterial(matKey{}, &mtlMaterial{name: defaultMaterial, color: color.RGBA{R: 204, G: 204, B: 204, A: 255}})
+	}
+	return s.mats[index].name
+}
+
+func (s *objEncoder) colorMaterial(path string, p materials.Property) (int, error) {
+	pid, index := p.Identify()
+	key := matKey{path: path, pid: pid, index: index}
+	if i, ok := s.matIndex[key]; ok {
+		return i, nil
+	}
+	c, err := s.evaluator.Color(p)
+	if err != nil {
+		return 0, err
+	}
+	name := fmt.Sprintf("material%d_%d", pid, index)
+	if base, ok := p.(*materials.BaseProperty); ok && base.Base.Name != "" {
+		name = base.Base.Name
+	}
+	return s.addMaterial(key, &mtlMaterial{name: name, color: c}), nil
+}
+
+func (s *objEncoder) textureMaterial(path string, tex *materials.Texture2D) int {
+	key := matKey{path: path, pid: tex.ID, texture: true}
+	if i, ok := s.matIndex[key]; ok {
+		return i
+	}
+	return s.addMaterial(key, &mtlMaterial{
+		name:    fmt.Sprintf("texture%d", tex.ID),
+		color:   color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		texture: tex,
+		file:    s.textureFile(tex.Path),
+	})
+}
+
+// addMaterial adds mat, making its name unique and valid, and returns its index.
+func (s *objEncoder) addMaterial(key matKey, mat *mtlMaterial) int {
+	name := strings.Join(strings.Fields(mat.name), "_")
+	mat.name = name
+	for i := 1; s.matNames[mat.name]; i++ {
+		mat.name = name + "_" + strconv.Itoa(i)
+	}
+	s.matNames[mat.name] = true
+	s.mats = append(s.mats, mat)
+	s.matIndex[key] = len(s.mats) - 1
+	return len(s.mats) - 1
+}
+
+// textureFile returns the file name of the texture with path p,
+// which is unique among the textures written.
+func (s *objEncoder) textureFile(p string) string {
+	if file, ok := s.textures[p]; ok {
+		return file
+	}
+	file := strings.Join(strings.Fields(path.Base(p)), "_")
+	used := func(name string) bool {
+		for _, f := range s.textures {
+			if f == name {
+				return true
+			}
+		}
+		return false
+	}
+	ext := path.Ext(file)
+	name := file
+	for i := 1; used(name); i++ {
+		name = strings.TrimSuffix(file, ext) + "_" + strconv.Itoa(i) + ext
+	}
+	s.textures[p] = name
+	return name
+}
+
+// textureCoords returns the texture and the texture coordinates of props
+// if all of them reference the same texture.
+func textureCoords(props [3]materials.Property) (*materials.Texture2D, [3]materials.TextureCoord, bool) {
+	var coords [3]materials.TextureCoord
+	var tex *materials.Texture2D
+	for i, p := range props {
+		tp, ok := p.(*materials.TextureProperty)
+		if !ok || (tex != nil && tp.Texture != tex) {
+			return nil, coords, false
+		}
+		tex = tp.Texture
+		coords[i] = tp.Coord
+	}
+	return tex, coords, true
+}
+
+func formatChannel(c uint8) string {
+	return strconv.FormatFloat(float64(c)/255, 'g', 4, 64)
+}
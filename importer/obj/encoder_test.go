@@ -0,0 +1,163 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package obj
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+)
+
+func newEncoderTestModel() *go3mf.Model {
+	m := &go3mf.Model{Attachments: []go3mf.Attachment{{Path: "/3D/Textures/tex.png", Stream: strings.NewReader("png")}}}
+	m.Resources.Assets = []go3mf.Asset{
+		&go3mf.BaseMaterials{ID: 1, Materials: []go3mf.Base{{Name: "red plastic", Color: color.RGBA{R: 255, A: 255}}}},
+		&materials.Texture2D{ID: 2, Path: "/3D/Textures/tex.png", ContentType: materials.TextureTypePNG},
+		&materials.Texture2DGroup{ID: 3, TextureID: 2, Coords: []materials.TextureCoord{{0, 0}, {1, 0}, {0, 1}}},
+	}
+	m.Resources.Objects = []*go3mf.Object{
+		{ID: 4, Name: "part", PID: 1, Mesh: &go3mf.Mesh{
+			Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+				{V1: 0, V2: 1, V3: 2},
+				{V1: 1, V2: 3, V3: 2, PID: 3, P1: 0, P2: 1, P3: 2},
+			}},
+		}},
+		{ID: 5, Mesh: &go3mf.Mesh{
+			Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+		}},
+		{ID: 6, Components: &go3mf.Components{Component: []*go3mf.Component{
+			{ObjectID: 4, Transform: go3mf.Identity().Translate(0, 0, 1)},
+			{ObjectID: 5},
+		}}},
+	}
+	m.Build.Items = []*go3mf.Item{{ObjectID: 6, Transform: go3mf.Identity().Translate(1, 0, 0)}}
+	return m
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	files := make(Files)
+	if err := NewEncoder(files).Encode(newEncoderTestModel()); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	want := map[string]string{
+		"model.obj": `mtllib model.mtl
+o part
+v 1 0 1
+v 2 0 1
+v 1 1 1
+v 2 1 1
+vt 0 0
+vt 1 0
+vt 0 1
+usemtl red_plastic
+f 1 2 3
+usemtl texture2
+f 2/1 4/2 3/3
+o object5
+v 1 0 0
+v 2 0 0
+v 1 1 0
+usemtl default
+f 5 6 7
+`,
+		"model.mtl": `newmtl red_plastic
+Kd 1 0 0
+newmtl texture2
+Kd 1 1 1
+map_Kd tex.png
+newmtl default
+Kd 0.8 0.8 0.8
+`,
+		"tex.png": "png",
+	}
+	got := make(map[string]string, len(files))
+	for name, b := range files {
+		got[name] = b.String()
+	}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("Encoder.Encode() = %v", diff)
+	}
+}
+
+func TestEncoder_Encode_roundTrip(t *testing.T) {
+	files := make(Files)
+	if err := NewEncoder(files).Encode(newEncoderTestModel()); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	d := NewDecoder(files["model.obj"])
+	d.Open = func(name string) (io.ReadCloser, error) {
+		if b, ok := files[name]; ok {
+			return ioutil.NopCloser(bytes.NewReader(b.Bytes())), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	got := new(go3mf.Model)
+	if err := d.Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	if len(got.Resources.Objects) != 2 || got.Resources.Objects[0].Name != "part" {
+		t.Fatalf("Decoder.Decode() objects = %v", got.Resources.Objects)
+	}
+	wantTriangles := []go3mf.Triangle{
+		{V1: 0, V2: 1, V3: 2, PID: 1},
+		{V1: 1, V2: 3, V3: 2, PID: 3, P1: 0, P2: 1, P3: 2},
+	}
+	if diff := deep.Equal(got.Resources.Objects[0].Mesh.Triangles.Triangle, wantTriangles); diff != nil {
+		t.Errorf("Decoder.Decode() triangles = %v", diff)
+	}
+}
+
+func TestEncoder_Encode_error(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*go3mf.Model)
+		wantErr error
+	}{
+		{"missingItem", func(m *go3mf.Model) { m.Build.Items[0].ObjectID = 10 }, specerr.ErrMissingResource},
+		{"missingComponent", func(m *go3mf.Model) { m.Resources.Objects[2].Components.Component[1].ObjectID = 10 }, specerr.ErrMissingResource},
+		{"recursive", func(m *go3mf.Model) { m.Resources.Objects[2].Components.Component[1].ObjectID = 6 }, specerr.ErrRecursion},
+		{"index", func(m *go3mf.Model) { m.Resources.Objects[1].Mesh.Triangles.Triangle[0].V1 = 10 }, specerr.ErrIndexOutOfBounds},
+		{"texture", func(m *go3mf.Model) { m.Attachments = nil }, materials.ErrMissingTexturePart},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newEncoderTestModel()
+			tt.modify(m)
+			if err := NewEncoder(make(Files)).Encode(m); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Encoder.Encode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDir_Create(t *testing.T) {
+	dir, err := ioutil.TempDir("", "obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	e := NewEncoder(Dir(dir))
+	e.Name = "out"
+	if err := e.Encode(newEncoderTestModel()); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	for _, name := range []string{"out.obj", "out.mtl", "tex.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Dir.Create() %s error = %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package obj
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/importer"
+)
+
+// The obj format is not encoded through the importer package,
+// as an Encoder writes several files.
+func init() {
+	importer.Register(importer.Format{
+		Name:       "obj",
+		Extensions: []string{".obj"},
+		Sniff:      sniff,
+		Decode: func(ctx context.Context, r io.Reader, open importer.OpenFunc, m *go3mf.Model) error {
+			d := NewDecoder(r)
+			d.Open = open
+			return d.DecodeContext(ctx, m)
+		},
+	})
+}
+
+var sniffKeywords = map[string]bool{
+	"v": true, "vt": true, "vn": true, "f": true, "o": true, "g": true,
+	"s": true, "mtllib": true, "usemtl": true,
+}
+
+// sniff reports whether the first statement of b, skipping comments, is an obj keyword.
+func sniff(b []byte) bool {
+	if len(b) > 4096 {
+		b = b[:4096]
+	}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		return sniffKeywords[fields[0]] && len(fields) > 1
+	}
+	return false
+}
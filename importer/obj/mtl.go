@@ -0,0 +1,94 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+// materialLibraries decodes the mtl files names.
+// It does nothing if the decoder cannot open files.
+func (s *objDecoder) materialLibraries(names []string) error {
+	if s.d.Open == nil {
+		return nil
+	}
+	for _, name := range names {
+		f, err := s.d.Open(name)
+		if err != nil {
+			return err
+		}
+		err = s.decodeMaterials(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// decodeMaterials decodes the diffuse color and texture of each material in r.
+// The dissolve (d) and transparency (Tr) statements are decoded as the color alpha.
+func (s *objDecoder) decodeMaterials(r io.Reader) error {
+	var (
+		mat        *material
+		lineNumber int
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "newmtl" {
+			mat = &material{name: strings.Join(fields[1:], " "), color: [4]float32{1, 1, 1, 1}}
+			s.materials[mat.name] = mat
+			continue
+		}
+		if mat == nil {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "Kd":
+			var p [3]float32
+			if p, err = parseFloats(fields[1:], 3); err == nil {
+				mat.color[0], mat.color[1], mat.color[2] = p[0], p[1], p[2]
+				mat.hasColor = true
+			}
+		case "d":
+			var p [3]float32
+			if p, err = parseFloats(fields[1:], 1); err == nil {
+				mat.color[3] = p[0]
+			}
+		case "Tr":
+			var p [3]float32
+			if p, err = parseFloats(fields[1:], 1); err == nil {
+				mat.color[3] = 1 - p[0]
+			}
+		case "map_Kd":
+			// The options precede the file name, which is the last field.
+			if len(fields) > 1 {
+				mat.texture = fields[len(fields)-1]
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func toRGBA(c [4]float32) color.RGBA {
+	var rgba [4]uint8
+	for i, f := range c {
+		rgba[i] = uint8(math.Round(float64(255 * math.Max(0, math.Min(1, float64(f))))))
+	}
+	return color.RGBA{R: rgba[0], G: rgba[1], B: rgba[2], A: rgba[3]}
+}
@@ -0,0 +1,427 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package ply
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/materials"
+)
+
+var checkEveryFaces = 1000
+
+// Errors returned when decoding malformed files.
+var (
+	ErrHeader = errors.New("invalid header")
+	ErrFormat = errors.New("unsupported format")
+	ErrType   = errors.New("unsupported property type")
+	ErrIndex  = errors.New("vertex index out of bounds")
+	ErrNumber = errors.New("invalid number")
+)
+
+const (
+	formatASCII           = "ascii"
+	formatBinaryLittle    = "binary_little_endian"
+	formatBinaryBigEndian = "binary_big_endian"
+)
+
+// Decoder can decode a ply.
+// It supports ascii and binary, little and big endian, encodings.
+//
+// The faces are triangulated as a fan, so they are expected to be convex.
+// The vertex colors (red, green, blue and the optional alpha) are decoded
+// into a materials.ColorGroup referenced by the triangles.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder creates a new decoder.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: r,
+	}
+}
+
+// Decode creates a mesh from a read stream.
+func (d *Decoder) Decode(m *go3mf.Model) error {
+	return d.DecodeContext(context.Background(), m)
+}
+
+// DecodeContext creates a mesh from a read stream.
+func (d *Decoder) DecodeContext(ctx context.Context, m *go3mf.Model) error {
+	b := bufio.NewReader(d.r)
+	h, err := readHeader(b)
+	if err != nil {
+		return fmt.Errorf("ply: %w", err)
+	}
+	var vr valueReader
+	switch h.format {
+	case formatASCII:
+		s := bufio.NewScanner(b)
+		s.Split(bufio.ScanWords)
+		vr = &asciiReader{s: s}
+	case formatBinaryLittle:
+		vr = &binaryReader{r: b, order: binary.LittleEndian}
+	case formatBinaryBigEndian:
+		vr = &binaryReader{r: b, order: binary.BigEndian}
+	default:
+		return fmt.Errorf("ply: %w", ErrFormat)
+	}
+	pd := plyDecoder{r: vr, mesh: new(go3mf.Mesh)}
+	for _, e := range h.elements {
+		switch e.name {
+		case "vertex":
+			err = pd.decodeVertices(e)
+		case "face":
+			err = pd.decodeFaces(ctx, e)
+		default:
+			err = pd.skip(e)
+		}
+		if err != nil {
+			return fmt.Errorf("ply: element %s: %w", e.name, err)
+		}
+	}
+	obj := &go3mf.Object{ID: m.Resources.UnusedID(), Mesh: pd.mesh}
+	m.Resources.Objects = append(m.Resources.Objects, obj)
+	m.Build.Items = append(m.Build.Items, &go3mf.Item{ObjectID: obj.ID})
+	if len(pd.colors) > 0 {
+		addColorGroup(m, obj, pd.colors)
+	}
+	return nil
+}
+
+type property struct {
+	name      string
+	typ       string
+	countType string // not empty for list properties
+}
+
+type element struct {
+	name       string
+	count      int
+	properties []property
+}
+
+type header struct {
+	format   string
+	elements []element
+}
+
+func readHeader(r *bufio.Reader) (*header, error) {
+	h := new(header)
+	first := true
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, ErrHeader
+		}
+		fields := strings.Fields(line)
+		if first {
+			if len(fields) != 1 || fields[0] != "ply" {
+				return nil, ErrHeader
+			}
+			first = false
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return nil, ErrHeader
+			}
+			h.format = fields[1]
+		case "element":
+			if len(fields) != 3 {
+				return nil, ErrHeader
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return nil, ErrHeader
+			}
+			h.elements = append(h.elements, element{name: fields[1], count: count})
+		case "property":
+			if len(h.elements) == 0 {
+				return nil, ErrHeader
+			}
+			var p property
+			if len(fields) == 5 && fields[1] == "list" {
+				p = property{name: fields[4], typ: fields[3], countType: fields[2]}
+			} else if len(fields) == 3 {
+				p = property{name: fields[2], typ: fields[1]}
+			} else {
+				return nil, ErrHeader
+			}
+			if typeSize(p.typ) == 0 || (p.countType != "" && typeSize(p.countType) == 0) {
+				return nil, ErrType
+			}
+			e := &h.elements[len(h.elements)-1]
+			e.properties = append(e.properties, p)
+		case "end_header":
+			return h, nil
+		}
+	}
+}
+
+// typeSize returns the size in bytes of typ, or 0 if it is not supported.
+func typeSize(typ string) int {
+	switch typ {
+	case "char", "int8", "uchar", "uint8":
+		return 1
+	case "short", "int16", "ushort", "uint16":
+		return 2
+	case "int", "int32", "uint", "uint32", "float", "float32":
+		return 4
+	case "double", "float64":
+		return 8
+	}
+	return 0
+}
+
+type valueReader interface {
+	read(typ string) (float64, error)
+}
+
+type asciiReader struct {
+	s *bufio.Scanner
+}
+
+func (r *asciiReader) read(string) (float64, error) {
+	if !r.s.Scan() {
+		if err := r.s.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+	v, err := strconv.ParseFloat(r.s.Text(), 64)
+	if err != nil {
+		return 0, ErrNumber
+	}
+	return v, nil
+}
+
+type binaryReader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (r *binaryReader) read(typ string) (float64, error) {
+	b := r.buf[:typeSize(typ)]
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	switch typ {
+	case "char", "int8":
+		return float64(int8(b[0])), nil
+	case "uchar", "uint8":
+		return float64(b[0]), nil
+	case "short", "int16":
+		return float64(int16(r.order.Uint16(b))), nil
+	case "ushort", "uint16":
+		return float64(r.order.Uint16(b)), nil
+	case "int", "int32":
+		return float64(int32(r.order.Uint32(b))), nil
+	case "uint", "uint32":
+		return float64(r.order.Uint32(b)), nil
+	case "float", "float32":
+		return float64(math.Float32frombits(r.order.Uint32(b))), nil
+	default:
+		return math.Float64frombits(r.order.Uint64(b)), nil
+	}
+}
+
+type plyDecoder struct {
+	r      valueReader
+	mesh   *go3mf.Mesh
+	colors []color.RGBA
+}
+
+func (d *plyDecoder) decodeVertices(e element) error {
+	var (
+		coords   = [3]int{-1, -1, -1}
+		channels = [4]int{-1, -1, -1, -1}
+	)
+	for i, p := range e.properties {
+		if p.countType != "" {
+			continue
+		}
+		switch p.name {
+		case "x":
+			coords[0] = i
+		case "y":
+			coords[1] = i
+		case "z":
+			coords[2] = i
+		case "red", "r", "diffuse_red":
+			channels[0] = i
+		case "green", "g", "diffuse_green":
+			channels[1] = i
+		case "blue", "b", "diffuse_blue":
+			channels[2] = i
+		case "alpha", "a":
+			channels[3] = i
+		}
+	}
+	hasColor := channels[0] >= 0 && channels[1] >= 0 && channels[2] >= 0
+	values := make([]float64, len(e.properties))
+	for i := 0; i < e.count; i++ {
+		if err := d.readElement(e, values); err != nil {
+			return err
+		}
+		var v go3mf.Point3D
+		for j, index := range coords {
+			if index >= 0 {
+				v[j] = float32(values[index])
+			}
+		}
+		d.mesh.Vertices.Vertex = append(d.mesh.Vertices.Vertex, v)
+		if hasColor {
+			c := [4]uint8{0, 0, 0, 0xff}
+			for j, index := range channels {
+				if index >= 0 {
+					c[j] = toChannel(values[index], e.properties[index].typ)
+				}
+			}
+			d.colors = append(d.colors, color.RGBA{R: c[0], G: c[1], B: c[2], A: c[3]})
+		}
+	}
+	return nil
+}
+
+// toChannel converts v to a color channel.
+// Floating point channels go from 0 to 1 and integer channels from 0 to 255.
+func toChannel(v float64, typ string) uint8 {
+	if typ == "float" || typ == "float32" || typ == "double" || typ == "float64" {
+		v *= 255
+	}
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+func (d *plyDecoder) decodeFaces(ctx context.Context, e element) error {
+	nextFaceCheck := checkEveryFaces
+	indices := -1
+	for i, p := range e.properties {
+		if p.countType != "" && (p.name == "vertex_indices" || p.name == "vertex_index") {
+			indices = i
+		}
+	}
+	nv := uint32(len(d.mesh.Vertices.Vertex))
+	var face []uint32
+	for i := 0; i < e.count; i++ {
+		for j, p := range e.properties {
+			if p.countType == "" {
+				if _, err := d.r.read(p.typ); err != nil {
+					return err
+				}
+				continue
+			}
+			n, err := d.r.read(p.countType)
+			if err != nil {
+				return err
+			}
+			face = face[:0]
+			for k := 0; k < int(n); k++ {
+				v, err := d.r.read(p.typ)
+				if err != nil {
+					return err
+				}
+				if j == indices {
+					if v < 0 || uint32(v) >= nv {
+						return ErrIndex
+					}
+					face = append(face, uint32(v))
+				}
+			}
+			for k := 2; k < len(face); k++ {
+				d.mesh.Triangles.Triangle = append(d.mesh.Triangles.Triangle, go3mf.Triangle{V1: face[0], V2: face[k-1], V3: face[k]})
+			}
+		}
+		if len(d.mesh.Triangles.Triangle) > nextFaceCheck {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default: // Default is must to avoid blocking
+			}
+			nextFaceCheck += checkEveryFaces
+		}
+	}
+	return nil
+}
+
+// readElement reads the scalar properties of e into values.
+// List properties are skipped.
+func (d *plyDecoder) readElement(e element, values []float64) error {
+	for j, p := range e.properties {
+		if p.countType == "" {
+			v, err := d.r.read(p.typ)
+			if err != nil {
+				return err
+			}
+			values[j] = v
+			continue
+		}
+		n, err := d.r.read(p.countType)
+		if err != nil {
+			return err
+		}
+		for k := 0; k < int(n); k++ {
+			if _, err := d.r.read(p.typ); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *plyDecoder) skip(e element) error {
+	values := make([]float64, len(e.properties))
+	for i := 0; i < e.count; i++ {
+		if err := d.readElement(e, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColorGroup adds a materials.ColorGroup to m with the vertex colors
+// and references it from the triangles of obj.
+func addColorGroup(m *go3mf.Model, obj *go3mf.Object, colors []color.RGBA) {
+	group := &materials.ColorGroup{ID: m.Resources.UnusedID()}
+	indices := make(map[color.RGBA]uint32)
+	index := func(v uint32) uint32 {
+		c := colors[v]
+		i, ok := indices[c]
+		if !ok {
+			i = uint32(len(group.Colors))
+			indices[c] = i
+			group.Colors = append(group.Colors, c)
+		}
+		return i
+	}
+	tris := obj.Mesh.Triangles.Triangle
+	for i := range tris {
+		t := &tris[i]
+		t.PID, t.P1, t.P2, t.P3 = group.ID, index(t.V1), index(t.V2), index(t.V3)
+	}
+	if len(group.Colors) == 0 {
+		return
+	}
+	m.Resources.Assets = append(m.Resources.Assets, group)
+	materials.Declare(m)
+}
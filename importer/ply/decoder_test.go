@@ -0,0 +1,151 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package ply
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image/color"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/materials"
+)
+
+var (
+	colorRed  = color.RGBA{R: 255, A: 255}
+	colorBlue = color.RGBA{B: 255, A: 255}
+)
+
+const asciiQuad = `ply
+format ascii 1.0
+comment quad with colors
+element vertex 4
+property float x
+property float y
+property float z
+property uchar red
+property uchar green
+property uchar blue
+element face 1
+property uchar flags
+property list uchar int vertex_indices
+element other 1
+property list uchar float values
+end_header
+0 0 0 255 0 0
+1 0 0 0 0 255
+1 1 0 255 0 0
+0 1 0 0 0 255
+7 4 0 1 2 3
+2 0.5 0.5
+`
+
+func TestNewDecoder(t *testing.T) {
+	r := new(bytes.Buffer)
+	if got := NewDecoder(r); !reflect.DeepEqual(got, &Decoder{r: r}) {
+		t.Errorf("NewDecoder() = %v", got)
+	}
+}
+
+// binaryTriangle returns a binary ply with a triangle and float colors.
+func binaryTriangle(order binary.ByteOrder, format string) []byte {
+	var b bytes.Buffer
+	b.WriteString("ply\nformat " + format + " 1.0\nelement vertex 3\n" +
+		"property double x\nproperty double y\nproperty double z\n" +
+		"property float r\nproperty float g\nproperty float b\n" +
+		"element face 1\nproperty list uchar ushort vertex_index\nend_header\n")
+	for _, v := range [][6]float64{{0, 0, 0, 1, 0, 0}, {1, 0, 0, 1, 0, 0}, {0, 1, 0, 0, 0, 1}} {
+		for i := 0; i < 3; i++ {
+			binary.Write(&b, order, math.Float64bits(v[i]))
+		}
+		for i := 3; i < 6; i++ {
+			binary.Write(&b, order, float32(v[i]))
+		}
+	}
+	b.WriteByte(3)
+	binary.Write(&b, order, []uint16{0, 1, 2})
+	return b.Bytes()
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	triangle := &go3mf.Mesh{
+		Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+		Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2, PID: 2, P1: 0, P2: 0, P3: 1}}},
+	}
+	tests := []struct {
+		name string
+		r    io.Reader
+		want *go3mf.Mesh
+	}{
+		{"ascii", strings.NewReader(asciiQuad), &go3mf.Mesh{
+			Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+				{V1: 0, V2: 1, V3: 2, PID: 2, P1: 0, P2: 1, P3: 0},
+				{V1: 0, V2: 2, V3: 3, PID: 2, P1: 0, P2: 0, P3: 1},
+			}},
+		}},
+		{"little", bytes.NewReader(binaryTriangle(binary.LittleEndian, "binary_little_endian")), triangle},
+		{"big", bytes.NewReader(binaryTriangle(binary.BigEndian, "binary_big_endian")), triangle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := new(go3mf.Model)
+			if err := NewDecoder(tt.r).Decode(got); err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			if diff := deep.Equal(got.Resources.Objects, []*go3mf.Object{{ID: 1, Mesh: tt.want}}); diff != nil {
+				t.Errorf("Decoder.Decode() = %v", diff)
+			}
+			want := []go3mf.Asset{&materials.ColorGroup{ID: 2, Colors: []color.RGBA{colorRed, colorBlue}}}
+			if diff := deep.Equal(got.Resources.Assets, want); diff != nil {
+				t.Errorf("Decoder.Decode() assets = %v", diff)
+			}
+			if len(got.Build.Items) != 1 || len(got.Extensions) != 1 {
+				t.Errorf("Decoder.Decode() items = %v, extensions = %v", got.Build.Items, got.Extensions)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_error(t *testing.T) {
+	checkEveryFaces = 0
+	defer func() { checkEveryFaces = 1000 }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	header := "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n"
+	tests := []struct {
+		name    string
+		src     string
+		ctx     context.Context
+		wantErr error
+	}{
+		{"empty", "", context.Background(), ErrHeader},
+		{"magic", "plx\nend_header\n", context.Background(), ErrHeader},
+		{"noEnd", "ply\nformat ascii 1.0\n", context.Background(), ErrHeader},
+		{"element", "ply\nelement vertex\nend_header\n", context.Background(), ErrHeader},
+		{"property", "ply\nproperty float x\nend_header\n", context.Background(), ErrHeader},
+		{"type", "ply\nelement vertex 1\nproperty half x\nend_header\n", context.Background(), ErrType},
+		{"format", "ply\nformat binary 1.0\nend_header\n", context.Background(), ErrFormat},
+		{"number", header + "a\n", context.Background(), ErrNumber},
+		{"eof", header + "1\n", context.Background(), io.ErrUnexpectedEOF},
+		{"eofBinary", "ply\nformat binary_little_endian 1.0\nelement vertex 1\nproperty float x\nend_header\n", context.Background(), io.ErrUnexpectedEOF},
+		{"index", header + "1\n3 0 0 1\n", context.Background(), ErrIndex},
+		{"cancel", header + "1\n3 0 0 0\n", ctx, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewDecoder(strings.NewReader(tt.src)).DecodeContext(tt.ctx, new(go3mf.Model)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decoder.DecodeContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
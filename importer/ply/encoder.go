@@ -0,0 +1,157 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package ply
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+)
+
+// defaultColor is the color of the vertices without properties
+// when other vertices have colors.
+var defaultColor = color.RGBA{R: 204, G: 204, B: 204, A: 255}
+
+// Encoder can encode a binary little endian ply.
+//
+// The build items are flattened into a single mesh, applying the component and build item transforms.
+// If any triangle has properties the vertex colors are written, evaluated at each triangle corner
+// with a materials.ColorEvaluator, and the vertices are duplicated when their colors differ.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder creates a new encoder.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: w,
+	}
+}
+
+// Encode writes the build items of m.
+func (e *Encoder) Encode(m *go3mf.Model) error {
+	c := &meshCollector{
+		m:         m,
+		resolvers: make(map[string]*materials.Resolver),
+		evaluator: materials.NewColorEvaluator(m),
+	}
+	for i, item := range m.Build.Items {
+		if err := m.WalkItemMeshes(item, c.addMesh); err != nil {
+			return specerr.WrapIndex(err, "item", i)
+		}
+	}
+	w := bufio.NewWriter(e.w)
+	fmt.Fprintf(w, "ply\nformat %s 1.0\ncomment written by go3mf\n", formatBinaryLittle)
+	fmt.Fprintf(w, "element vertex %d\nproperty float x\nproperty float y\nproperty float z\n", len(c.vertices))
+	if c.hasColor {
+		w.WriteString("property uchar red\nproperty uchar green\nproperty uchar blue\nproperty uchar alpha\n")
+	}
+	fmt.Fprintf(w, "element face %d\nproperty list uchar uint vertex_indices\nend_header\n", len(c.faces))
+	var buf [16]byte
+	for i, v := range c.vertices {
+		for j := range v {
+			binary.LittleEndian.PutUint32(buf[4*j:], math.Float32bits(v[j]))
+		}
+		n := 12
+		if c.hasColor {
+			clr := defaultColor
+			if c.colors[i] != nil {
+				clr = *c.colors[i]
+			}
+			buf[12], buf[13], buf[14], buf[15] = clr.R, clr.G, clr.B, clr.A
+			n = 16
+		}
+		w.Write(buf[:n])
+	}
+	buf[0] = 3
+	for _, f := range c.faces {
+		for j := range f {
+			binary.LittleEndian.PutUint32(buf[1+4*j:], f[j])
+		}
+		w.Write(buf[:13])
+	}
+	return w.Flush()
+}
+
+type vertexKey struct {
+	index uint32
+	color color.RGBA
+	ok    bool
+}
+
+// meshCollector flattens objects into a single mesh with optional vertex colors.
+type meshCollector struct {
+	m         *go3mf.Model
+	resolvers map[string]*materials.Resolver
+	evaluator *materials.ColorEvaluator
+	vertices  []go3mf.Point3D
+	colors    []*color.RGBA
+	faces     [][3]uint32
+	hasColor  bool
+}
+
+// addMesh appends the mesh of obj transformed by transform.
+// Transforms with a negative determinant mirror the mesh, so the vertex order
+// is reversed to keep the faces facing outwards.
+func (c *meshCollector) addMesh(path string, obj *go3mf.Object, transform go3mf.Matrix) error {
+	r, ok := c.resolvers[path]
+	if !ok {
+		r = materials.NewResolver(c.m, path)
+		c.resolvers[path] = r
+	}
+	var (
+		mirror   = transform.Determinant() < 0
+		vertices = obj.Mesh.Vertices.Vertex
+		nv       = uint32(len(vertices))
+		indices  = make(map[vertexKey]uint32)
+	)
+	for i := range obj.Mesh.Triangles.Triangle {
+		t := &obj.Mesh.Triangles.Triangle[i]
+		if t.V1 >= nv || t.V2 >= nv || t.V3 >= nv {
+			return specerr.WrapIndex(specerr.ErrIndexOutOfBounds, "triangle", i)
+		}
+		props, err := r.Triangle(obj, t)
+		if err != nil {
+			return specerr.WrapIndex(err, "triangle", i)
+		}
+		var face [3]uint32
+		for j, v := range [3]uint32{t.V1, t.V2, t.V3} {
+			key := vertexKey{index: v}
+			if props[0] != nil {
+				var bary [3]float32
+				bary[j] = 1
+				if key.color, err = c.evaluator.ColorAt(props, bary); err != nil {
+					return specerr.WrapIndex(err, "triangle", i)
+				}
+				key.ok = true
+				c.hasColor = true
+			}
+			index, ok := indices[key]
+			if !ok {
+				index = uint32(len(c.vertices))
+				indices[key] = index
+				c.vertices = append(c.vertices, transform.Mul3D(vertices[v]))
+				if key.ok {
+					clr := key.color
+					c.colors = append(c.colors, &clr)
+				} else {
+					c.colors = append(c.colors, nil)
+				}
+			}
+			face[j] = index
+		}
+		if mirror {
+			face[1], face[2] = face[2], face[1]
+		}
+		c.faces = append(c.faces, face)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package ply
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+)
+
+func newEncoderTestModel() *go3mf.Model {
+	m := new(go3mf.Model)
+	m.Resources.Assets = []go3mf.Asset{&materials.ColorGroup{ID: 1, Colors: []color.RGBA{colorRed, colorBlue}}}
+	m.Resources.Objects = []*go3mf.Object{
+		{ID: 2, Mesh: &go3mf.Mesh{
+			Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+				{V1: 0, V2: 1, V3: 2, PID: 1, P1: 0, P2: 0, P3: 1},
+				{V1: 1, V2: 3, V3: 2, PID: 1, P1: 1, P2: 1, P3: 1},
+			}},
+		}},
+	}
+	m.Build.Items = []*go3mf.Item{{ObjectID: 2, Transform: go3mf.Identity().Translate(0, 0, 1)}}
+	return m
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(newEncoderTestModel()); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	got := new(go3mf.Model)
+	if err := NewDecoder(&buf).Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	// The vertex 1 is duplicated because it is red in the first triangle and blue in the second.
+	want := &go3mf.Mesh{
+		Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 1}, {1, 0, 1}, {0, 1, 1}, {1, 0, 1}, {1, 1, 1}}},
+		Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+			{V1: 0, V2: 1, V3: 2, PID: 2, P1: 0, P2: 0, P3: 1},
+			{V1: 3, V2: 4, V3: 2, PID: 2, P1: 1, P2: 1, P3: 1},
+		}},
+	}
+	if diff := deep.Equal(got.Resources.Objects[0].Mesh, want); diff != nil {
+		t.Errorf("Encoder.Encode() = %v", diff)
+	}
+	wantAssets := []go3mf.Asset{&materials.ColorGroup{ID: 2, Colors: []color.RGBA{colorRed, colorBlue}}}
+	if diff := deep.Equal(got.Resources.Assets, wantAssets); diff != nil {
+		t.Errorf("Encoder.Encode() assets = %v", diff)
+	}
+}
+
+func TestEncoder_Encode_noColors(t *testing.T) {
+	m := newEncoderTestModel()
+	mirror := go3mf.Identity()
+	mirror[0] = -1
+	m.Build.Items[0].Transform = mirror
+	for i := range m.Resources.Objects[0].Mesh.Triangles.Triangle {
+		m.Resources.Objects[0].Mesh.Triangles.Triangle[i].PID = 0
+	}
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "red") {
+		t.Error("Encoder.Encode() wrote colors")
+	}
+	got := new(go3mf.Model)
+	if err := NewDecoder(&buf).Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	want := &go3mf.Mesh{
+		Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {-1, 1, 0}}},
+		Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+			{V1: 0, V2: 2, V3: 1},
+			{V1: 1, V2: 2, V3: 3},
+		}},
+	}
+	if diff := deep.Equal(got.Resources.Objects[0].Mesh, want); diff != nil {
+		t.Errorf("Encoder.Encode() = %v", diff)
+	}
+	if len(got.Resources.Assets) != 0 {
+		t.Errorf("Encoder.Encode() assets = %v", got.Resources.Assets)
+	}
+}
+
+func TestEncoder_Encode_error(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*go3mf.Model)
+		wantErr error
+	}{
+		{"missingItem", func(m *go3mf.Model) { m.Build.Items[0].ObjectID = 10 }, specerr.ErrMissingResource},
+		{"missingComponent", func(m *go3mf.Model) {
+			m.Resources.Objects[0].Components = &go3mf.Components{Component: []*go3mf.Component{{ObjectID: 10}}}
+		}, specerr.ErrMissingResource},
+		{"recursive", func(m *go3mf.Model) {
+			m.Resources.Objects[0].Components = &go3mf.Components{Component: []*go3mf.Component{{ObjectID: 2}}}
+		}, specerr.ErrRecursion},
+		{"index", func(m *go3mf.Model) { m.Resources.Objects[0].Mesh.Triangles.Triangle[0].V1 = 10 }, specerr.ErrIndexOutOfBounds},
+		{"property", func(m *go3mf.Model) { m.Resources.Objects[0].Mesh.Triangles.Triangle[0].PID = 10 }, specerr.ErrMissingResource},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newEncoderTestModel()
+			tt.modify(m)
+			if err := NewEncoder(new(bytes.Buffer)).Encode(m); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Encoder.Encode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package ply
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/importer"
+)
+
+func init() {
+	importer.Register(importer.Format{
+		Name:       "ply",
+		Extensions: []string{".ply"},
+		Sniff: func(b []byte) bool {
+			return bytes.HasPrefix(b, []byte("ply\n")) || bytes.HasPrefix(b, []byte("ply\r\n"))
+		},
+		Decode: func(ctx context.Context, r io.Reader, _ importer.OpenFunc, m *go3mf.Model) error {
+			return NewDecoder(r).DecodeContext(ctx, m)
+		},
+		Encode: func(w io.Writer, m *go3mf.Model) error {
+			return NewEncoder(w).Encode(m)
+		},
+	})
+}
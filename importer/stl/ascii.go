@@ -14,21 +14,48 @@ import (
 )
 
 // asciiDecoder can create a Model from a Read stream that is feeded with a ASCII STL.
+// Each solid is decoded as a new object named after the solid,
+// unless merge is true, in which case all the solids are merged into a single unnamed object.
 type asciiDecoder struct {
 	r     io.Reader
 	units float32
+	merge bool
 }
 
-func (d *asciiDecoder) decode(ctx context.Context, m *go3mf.Mesh) (err error) {
-	mb := go3mf.NewMeshBuilder(m)
+func (d *asciiDecoder) decode(ctx context.Context) (objs []*go3mf.Object, err error) {
+	var (
+		mb    *go3mf.MeshBuilder
+		mesh  *go3mf.Mesh
+		nodes [3]uint32
+	)
+	newObject := func(name string) {
+		mesh = new(go3mf.Mesh)
+		mb = go3mf.NewMeshBuilder(mesh)
+		objs = append(objs, &go3mf.Object{Name: name, Mesh: mesh})
+	}
+	if d.merge {
+		newObject("")
+	}
 	position := 0
 	nextFaceCheck := checkEveryFaces
-	var nodes [3]uint32
+	faceCount := 0
 	scanner := bufio.NewScanner(d.r)
 	for scanner.Scan() {
-		line := scanner.Text()
-		fields := strings.Fields(line)
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "solid" {
+			if !d.merge {
+				newObject(strings.Join(fields[1:], " "))
+			}
+			position = 0
+			continue
+		}
 		if len(fields) == 4 && fields[0] == "vertex" {
+			if mb == nil {
+				newObject("")
+			}
 			var f [3]float64
 			f[0], _ = strconv.ParseFloat(fields[1], 32)
 			f[1], _ = strconv.ParseFloat(fields[2], 32)
@@ -38,12 +65,12 @@ func (d *asciiDecoder) decode(ctx context.Context, m *go3mf.Mesh) (err error) {
 
 			if position == 3 {
 				position = 0
-				m.Triangles.Triangle = append(m.Triangles.Triangle, go3mf.Triangle{V1: nodes[0], V2: nodes[1], V3: nodes[2]})
-				if len(m.Triangles.Triangle) > nextFaceCheck {
+				mesh.Triangles.Triangle = append(mesh.Triangles.Triangle, go3mf.Triangle{V1: nodes[0], V2: nodes[1], V3: nodes[2]})
+				faceCount++
+				if faceCount > nextFaceCheck {
 					select {
 					case <-ctx.Done():
 						err = ctx.Err()
-						break
 					default: // Default is must to avoid blocking
 					}
 					nextFaceCheck += checkEveryFaces
@@ -51,8 +78,8 @@ func (d *asciiDecoder) decode(ctx context.Context, m *go3mf.Mesh) (err error) {
 			}
 		}
 		if err != nil {
-			return err
+			return objs, err
 		}
 	}
-	return scanner.Err()
+	return objs, scanner.Err()
 }
@@ -6,6 +6,7 @@ package stl
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -19,21 +20,33 @@ func Test_asciiDecoder_decode(t *testing.T) {
 	cancel()
 	checkEveryFaces = 1
 	triangle := createASCIITriangle()
+	multi := "solid a\n" + createASCIIFacet(0, 0, 0) + "endsolid a\nsolid b c\n" +
+		createASCIIFacet(1, 0, 0) + createASCIIFacet(2, 0, 0) + "endsolid b c\n"
 	tests := []struct {
 		name    string
 		d       *asciiDecoder
 		ctx     context.Context
-		want    *go3mf.Mesh
+		want    []*go3mf.Object
 		wantErr bool
 	}{
-		{"eof", &asciiDecoder{r: bytes.NewReader(make([]byte, 0))}, context.Background(), new(go3mf.Mesh), false},
-		{"base", &asciiDecoder{r: bytes.NewBufferString(triangle)}, context.Background(), createMeshTriangle(0).Mesh, false},
-		{"cancel", &asciiDecoder{r: bytes.NewBufferString(triangle)}, ctx, createMeshTriangle(0).Mesh, true},
+		{"eof", &asciiDecoder{r: bytes.NewReader(make([]byte, 0))}, context.Background(), nil, false},
+		{"eofMerge", &asciiDecoder{r: bytes.NewReader(make([]byte, 0)), merge: true}, context.Background(), []*go3mf.Object{{Mesh: new(go3mf.Mesh)}}, false},
+		{"base", &asciiDecoder{r: bytes.NewBufferString(triangle)}, context.Background(), []*go3mf.Object{createMeshTriangle(0)}, false},
+		{"cancel", &asciiDecoder{r: bytes.NewBufferString(triangle)}, ctx, nil, true},
+		{"noSolid", &asciiDecoder{r: bytes.NewBufferString(createASCIIFacet(0, 0, 0))}, context.Background(), []*go3mf.Object{
+			{Mesh: createFacetMesh(0)},
+		}, false},
+		{"multi", &asciiDecoder{r: bytes.NewBufferString(multi)}, context.Background(), []*go3mf.Object{
+			{Name: "a", Mesh: createFacetMesh(0)},
+			{Name: "b c", Mesh: createFacetMesh(1, 2)},
+		}, false},
+		{"multiMerge", &asciiDecoder{r: bytes.NewBufferString(multi), merge: true}, context.Background(), []*go3mf.Object{
+			{Mesh: createFacetMesh(0, 1, 2)},
+		}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := new(go3mf.Mesh)
-			err := tt.d.decode(tt.ctx, got)
+			got, err := tt.d.decode(tt.ctx)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("asciiDecoder.decode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -48,6 +61,33 @@ func Test_asciiDecoder_decode(t *testing.T) {
 	}
 }
 
+// createASCIIFacet returns a facet with a unit triangle translated x, y, z.
+func createASCIIFacet(x, y, z float32) string {
+	return fmt.Sprintf(`facet normal 0 0 1
+  outer loop
+    vertex %[1]g %[2]g %[3]g
+    vertex %[4]g %[2]g %[3]g
+    vertex %[1]g %[5]g %[3]g
+  endloop
+endfacet
+`, x, y, z, x+1, y+1)
+}
+
+// createFacetMesh returns the mesh of the facets created by createASCIIFacet
+// with the given x translations.
+func createFacetMesh(xs ...float32) *go3mf.Mesh {
+	m := new(go3mf.Mesh)
+	mb := go3mf.NewMeshBuilder(m)
+	for _, x := range xs {
+		m.Triangles.Triangle = append(m.Triangles.Triangle, go3mf.Triangle{
+			V1: mb.AddVertex(go3mf.Point3D{x, 0, 0}),
+			V2: mb.AddVertex(go3mf.Point3D{x + 1, 0, 0}),
+			V3: mb.AddVertex(go3mf.Point3D{x, 1, 0}),
+		})
+	}
+	return m
+}
+
 func createASCIITriangle() string {
 	return `solid 
   		facet normal 0 0 0
@@ -12,19 +12,23 @@ import (
 )
 
 type binaryHeader struct {
-	_         [80]byte
+	Header    [80]byte
 	FaceCount uint32
 }
 
 type binaryFace struct {
-	_        [3]float32
-	Vertices [3][3]float32
-	_        uint16
+	Normal    [3]float32
+	Vertices  [3][3]float32
+	Attribute uint16
 }
 
 // binaryDecoder can create a Mesh from a Read stream that is feeded with a binary STL.
+// The header and the attribute bytes of each face are stored
+// so the colors can be decoded afterwards.
 type binaryDecoder struct {
-	r io.Reader
+	r          io.Reader
+	header     [80]byte
+	attributes []uint16
 }
 
 // decode loads a binary stl from a io.Reader.
@@ -35,6 +39,7 @@ func (d *binaryDecoder) decode(ctx context.Context, m *go3mf.Mesh) error {
 	if err != nil {
 		return err
 	}
+	d.header = header.Header
 	mb.Mesh.Triangles.Triangle = make([]go3mf.Triangle, 0, header.FaceCount)
 	nextFaceCheck := checkEveryFaces
 	var facet binaryFace
@@ -44,6 +49,7 @@ func (d *binaryDecoder) decode(ctx context.Context, m *go3mf.Mesh) error {
 			break
 		}
 		d.decodeFace(&facet, mb)
+		d.attributes = append(d.attributes, facet.Attribute)
 		if len(m.Triangles.Triangle) > nextFaceCheck {
 			select {
 			case <-ctx.Done():
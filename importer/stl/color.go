@@ -0,0 +1,131 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package stl
+
+import (
+	"bytes"
+	"image/color"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/materials"
+)
+
+// ColorFormat defines how the facet colors are stored in the
+// attribute bytes of a binary stl.
+type ColorFormat uint8
+
+// Supported color formats.
+//
+// ColorVisCAM is the format used by VisCAM and SolidView:
+// bit 15 is set when the facet has a color, which is
+// stored as 5 bits of blue, green and red starting from the lower bits.
+//
+// ColorMagics is the format used by Materialise Magics,
+// detected by the "COLOR=" header followed by the default RGBA color:
+// bit 15 is set when the facet uses the default color, else the color is
+// stored as 5 bits of red, green and blue starting from the lower bits.
+const (
+	ColorNone ColorFormat = iota
+	ColorVisCAM
+	ColorMagics
+)
+
+const (
+	colorHeaderMagics = "COLOR="
+	colorFlag         = 1 << 15
+)
+
+// detectColorFormat returns the color format used by a binary stl
+// with the given header, and the default color if defined.
+func detectColorFormat(header []byte) (ColorFormat, color.RGBA, bool) {
+	if i := bytes.Index(header, []byte(colorHeaderMagics)); i >= 0 && i+len(colorHeaderMagics)+4 <= len(header) {
+		c := header[i+len(colorHeaderMagics):]
+		return ColorMagics, color.RGBA{R: c[0], G: c[1], B: c[2], A: c[3]}, true
+	}
+	return ColorVisCAM, color.RGBA{}, false
+}
+
+// decodeColor returns the color stored in attr.
+// It returns false if the facet has no color or uses the default color.
+func decodeColor(format ColorFormat, attr uint16) (color.RGBA, bool) {
+	switch format {
+	case ColorVisCAM:
+		if attr&colorFlag == 0 {
+			return color.RGBA{}, false
+		}
+		return color.RGBA{R: expand5(attr >> 10), G: expand5(attr >> 5), B: expand5(attr), A: 0xff}, true
+	case ColorMagics:
+		if attr&colorFlag != 0 {
+			return color.RGBA{}, false
+		}
+		return color.RGBA{R: expand5(attr), G: expand5(attr >> 5), B: expand5(attr >> 10), A: 0xff}, true
+	}
+	return color.RGBA{}, false
+}
+
+// encodeColor returns the attribute bytes of a facet with color c.
+// ok is false if the facet has no color, which for ColorMagics
+// means that it uses the default color.
+func encodeColor(format ColorFormat, c color.RGBA, ok bool) uint16 {
+	switch format {
+	case ColorVisCAM:
+		if !ok {
+			return 0
+		}
+		return colorFlag | uint16(c.R>>3)<<10 | uint16(c.G>>3)<<5 | uint16(c.B>>3)
+	case ColorMagics:
+		if !ok {
+			return colorFlag
+		}
+		return uint16(c.B>>3)<<10 | uint16(c.G>>3)<<5 | uint16(c.R>>3)
+	}
+	return 0
+}
+
+// expand5 converts the lower 5 bits of v to an 8 bits channel.
+func expand5(v uint16) uint8 {
+	v &= 0x1f
+	return uint8(v<<3 | v>>2)
+}
+
+// addColorGroup adds a materials.ColorGroup to m with the colors
+// of each facet of obj, which are stored in attrs.
+// It does nothing if no facet has a color.
+func addColorGroup(m *go3mf.Model, obj *go3mf.Object, header []byte, attrs []uint16) {
+	rs := &m.Resources
+	format, defColor, hasDefault := detectColorFormat(header)
+	var (
+		group   *materials.ColorGroup
+		indices = make(map[color.RGBA]uint32)
+	)
+	index := func(c color.RGBA) uint32 {
+		if group == nil {
+			group = &materials.ColorGroup{ID: rs.UnusedID()}
+		}
+		i, ok := indices[c]
+		if !ok {
+			i = uint32(len(group.Colors))
+			indices[c] = i
+			group.Colors = append(group.Colors, c)
+		}
+		return i
+	}
+	tris := obj.Mesh.Triangles.Triangle
+	for i, attr := range attrs {
+		if i >= len(tris) {
+			break
+		}
+		if c, ok := decodeColor(format, attr); ok {
+			pindex := index(c)
+			tris[i].PID, tris[i].P1, tris[i].P2, tris[i].P3 = group.ID, pindex, pindex, pindex
+		} else if format == ColorMagics && hasDefault {
+			obj.PIndex = index(defColor)
+			obj.PID = group.ID
+		}
+	}
+	if group != nil {
+		rs.Assets = append(rs.Assets, group)
+		materials.Declare(m)
+	}
+}
@@ -0,0 +1,119 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package stl
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/materials"
+)
+
+var (
+	colorRed   = color.RGBA{R: 255, A: 255}
+	colorBlue  = color.RGBA{B: 255, A: 255}
+	colorWhite = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+func Test_decodeColor(t *testing.T) {
+	tests := []struct {
+		name   string
+		format ColorFormat
+		attr   uint16
+		want   color.RGBA
+		wantOK bool
+	}{
+		{"none", ColorNone, 0xffff, color.RGBA{}, false},
+		{"viscamEmpty", ColorVisCAM, 0x7fff, color.RGBA{}, false},
+		{"viscamRed", ColorVisCAM, 0xfc00, colorRed, true},
+		{"viscamBlue", ColorVisCAM, 0x801f, colorBlue, true},
+		{"viscamMixed", ColorVisCAM, 0x8000 | 16<<10 | 8<<5 | 1, color.RGBA{R: 132, G: 66, B: 8, A: 255}, true},
+		{"magicsDefault", ColorMagics, 0x8000, color.RGBA{}, false},
+		{"magicsRed", ColorMagics, 0x001f, colorRed, true},
+		{"magicsBlue", ColorMagics, 0x7c00, colorBlue, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := decodeColor(tt.format, tt.attr)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("decodeColor() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+			if ok {
+				if attr := encodeColor(tt.format, got, true); attr != tt.attr {
+					t.Errorf("encodeColor() = %x, want %x", attr, tt.attr)
+				}
+			}
+		})
+	}
+}
+
+func Test_detectColorFormat(t *testing.T) {
+	var header [80]byte
+	copy(header[:], "some header COLOR=\x10\x20\x30\xff")
+	format, c, ok := detectColorFormat(header[:])
+	if format != ColorMagics || !ok || c != (color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}) {
+		t.Errorf("detectColorFormat() = %v, %v, %v", format, c, ok)
+	}
+	if format, _, ok := detectColorFormat(make([]byte, 80)); format != ColorVisCAM || ok {
+		t.Errorf("detectColorFormat() = %v, %v", format, ok)
+	}
+}
+
+func TestEncoder_Colors(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     ColorFormat
+		wantColors []color.RGBA
+		wantPID    uint32
+		wantPIndex uint32
+	}{
+		{"viscam", ColorVisCAM, []color.RGBA{colorRed, colorBlue}, 0, 0},
+		{"magics", ColorMagics, []color.RGBA{colorRed, colorBlue, colorWhite}, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := createMeshTriangle(1)
+			obj.Mesh.Triangles.Triangle[0].PID = 5
+			obj.Mesh.Triangles.Triangle[1].PID = 5
+			obj.Mesh.Triangles.Triangle[1].P1 = 1
+			obj.Mesh.Triangles.Triangle[1].P2 = 1
+			obj.Mesh.Triangles.Triangle[1].P3 = 1
+			m := new(go3mf.Model)
+			m.Resources.Assets = append(m.Resources.Assets, &materials.ColorGroup{ID: 5, Colors: []color.RGBA{colorRed, colorBlue}})
+			m.Resources.Objects = append(m.Resources.Objects, obj)
+			m.Build.Items = append(m.Build.Items, &go3mf.Item{ObjectID: 1})
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.Colors = tt.format
+			if err := e.Encode(m); err != nil {
+				t.Fatalf("Encoder.Encode() error = %v", err)
+			}
+			got := new(go3mf.Model)
+			if err := NewDecoder(&buf).Decode(got); err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			want := &materials.ColorGroup{ID: 2, Colors: tt.wantColors}
+			if diff := deep.Equal(got.Resources.Assets, []go3mf.Asset{want}); diff != nil {
+				t.Errorf("Decoder.Decode() = %v", diff)
+			}
+			if diff := deep.Equal(got.Extensions, []go3mf.Extension{materials.DefaultExtension}); diff != nil {
+				t.Errorf("Decoder.Decode() extensions = %v", diff)
+			}
+			gotObj := got.Resources.Objects[0]
+			if gotObj.PID != tt.wantPID || gotObj.PIndex != tt.wantPIndex {
+				t.Errorf("Decoder.Decode() object property = %d %d, want %d %d", gotObj.PID, gotObj.PIndex, tt.wantPID, tt.wantPIndex)
+			}
+			wantTris := createMeshTriangle(1).Mesh.Triangles.Triangle
+			wantTris[0].PID = 2
+			wantTris[1].PID = 2
+			wantTris[1].P1, wantTris[1].P2, wantTris[1].P3 = 1, 1, 1
+			if diff := deep.Equal(gotObj.Mesh.Triangles.Triangle, wantTris); diff != nil {
+				t.Errorf("Decoder.Decode() triangles = %v", diff)
+			}
+		})
+	}
+}
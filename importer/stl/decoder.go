@@ -19,8 +19,15 @@ const sizeOfHeader = 300 // minimum size of a closed mesh in binary is 384 bytes
 
 // Decoder can decode a stl.
 // It supports automatic detection of binary or ascii stl encoding.
+// The facet colors of binary stl files are decoded into a materials.ColorGroup,
+// see ColorFormat for the supported formats.
+//
+// Each solid of an ascii stl is decoded as a new object named after the solid
+// and referenced by a new build item.
+// If MergeSolids is true all the solids are merged into a single object.
 type Decoder struct {
-	r io.Reader
+	MergeSolids bool
+	r           io.Reader
 }
 
 // NewDecoder creates a new decoder.
@@ -42,34 +49,39 @@ func (d *Decoder) DecodeContext(ctx context.Context, m *go3mf.Model) error {
 	if err != nil {
 		return err
 	}
-	newMesh := &go3mf.Object{Mesh: new(go3mf.Mesh)}
 	if isASCII {
-		decoder := asciiDecoder{r: b}
-		err = decoder.decode(ctx, newMesh.Mesh)
-	} else {
-		decoder := binaryDecoder{r: b}
-		err = decoder.decode(ctx, newMesh.Mesh)
+		decoder := asciiDecoder{r: b, merge: d.MergeSolids}
+		objs, err := decoder.decode(ctx)
+		if err == nil {
+			for _, obj := range objs {
+				addObject(m, obj)
+			}
+		}
+		return err
 	}
+	newMesh := &go3mf.Object{Mesh: new(go3mf.Mesh)}
+	decoder := binaryDecoder{r: b}
+	err = decoder.decode(ctx, newMesh.Mesh)
 	if err == nil {
-		newMesh.ID = m.Resources.UnusedID()
-		m.Resources.Objects = append(m.Resources.Objects, newMesh)
-		m.Build.Items = append(m.Build.Items, &go3mf.Item{ObjectID: newMesh.ID})
+		addObject(m, newMesh)
+		addColorGroup(m, newMesh, decoder.header[:], decoder.attributes)
 	}
 	return err
 }
 
+// addObject adds obj to m with a new ID and a build item referencing it.
+func addObject(m *go3mf.Model, obj *go3mf.Object) {
+	obj.ID = m.Resources.UnusedID()
+	m.Resources.Objects = append(m.Resources.Objects, obj)
+	m.Build.Items = append(m.Build.Items, &go3mf.Item{ObjectID: obj.ID})
+}
+
 func (d *Decoder) isASCII(r *bufio.Reader) (bool, error) {
-	var header string
-	for {
-		buff, err := r.Peek(sizeOfHeader)
-		if err == io.EOF {
-			return false, err
-		}
-		if len(buff) >= sizeOfHeader {
-			header = strings.ToLower(string(buff))
-			break
-		}
+	buff, err := r.Peek(sizeOfHeader)
+	if err != nil && (err != io.EOF || len(buff) == 0) {
+		return false, err
 	}
+	header := strings.ToLower(string(buff))
 	return strings.HasPrefix(header, "solid") && isASCII(header), nil
 }
 
@@ -41,6 +41,11 @@ func TestDecoder_Decode(t *testing.T) {
 	triangle[2] = 0x6c
 	triangle[3] = 0x69
 	triangle[4] = 0x64
+	short := append([]byte(nil), triangle[:134]...)
+	short[80] = 0x01
+	shortMesh := createMeshTriangle(1)
+	shortMesh.Mesh.Vertices.Vertex = shortMesh.Mesh.Vertices.Vertex[:3]
+	shortMesh.Mesh.Triangles.Triangle = shortMesh.Mesh.Triangles.Triangle[:1]
 	tests := []struct {
 		name    string
 		d       *Decoder
@@ -50,6 +55,7 @@ func TestDecoder_Decode(t *testing.T) {
 		{"empty", NewDecoder(new(bytes.Buffer)), nil, true},
 		{"binary", NewDecoder(bytes.NewReader(triangle)), createMeshTriangle(1), false},
 		{"ascii", NewDecoder(bytes.NewBufferString(triangleASCII)), createMeshTriangle(1), false},
+		{"short", NewDecoder(bytes.NewReader(short)), shortMesh, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -68,3 +74,34 @@ func TestDecoder_Decode(t *testing.T) {
 		})
 	}
 }
+
+func TestDecoder_Decode_solids(t *testing.T) {
+	multi := "solid a\n" + createASCIIFacet(0, 0, 0) + "endsolid a\nsolid b\n" +
+		createASCIIFacet(1, 0, 0) + createASCIIFacet(2, 0, 0) + "endsolid b\n"
+	tests := []struct {
+		name      string
+		merge     bool
+		wantNames []string
+	}{
+		{"split", false, []string{"a", "b"}},
+		{"merge", true, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := new(go3mf.Model)
+			d := NewDecoder(bytes.NewBufferString(multi))
+			d.MergeSolids = tt.merge
+			if err := d.Decode(got); err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			if len(got.Resources.Objects) != len(tt.wantNames) || len(got.Build.Items) != len(tt.wantNames) {
+				t.Fatalf("Decoder.Decode() objects = %d, items = %d, want %d", len(got.Resources.Objects), len(got.Build.Items), len(tt.wantNames))
+			}
+			for i, obj := range got.Resources.Objects {
+				if obj.Name != tt.wantNames[i] || obj.ID != uint32(i+1) || got.Build.Items[i].ObjectID != obj.ID {
+					t.Errorf("Decoder.Decode() object %d = %s %d", i, obj.Name, obj.ID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package stl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/materials"
+)
+
+const binaryHeaderText = "binary stl written by go3mf"
+
+// Format defines the STL encoding.
+type Format uint8
+
+// Supported STL encodings.
+const (
+	FormatBinary Format = iota
+	FormatASCII
+)
+
+// Encoder can encode a stl.
+// The objects are flattened, applying the component and build item transforms,
+// and the facet normals are computed from the transformed vertices.
+//
+// If Colors is not ColorNone the color of each facet is evaluated at its centroid
+// with a materials.ColorEvaluator and stored in the attribute bytes of binary stl files.
+// The default color of ColorMagics is white.
+type Encoder struct {
+	Format Format
+	Colors ColorFormat
+	w      io.Writer
+}
+
+// NewEncoder creates a new binary encoder.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: w,
+	}
+}
+
+// Encode writes all the build items of m as a single solid.
+func (e *Encoder) Encode(m *go3mf.Model) error {
+	c := newFacetCollector(m, e.Colors)
+	for i, item := range m.Build.Items {
+		if err := m.WalkItemMeshes(item, c.addMesh); err != nil {
+			return specerr.WrapIndex(err, "item", i)
+		}
+	}
+	return e.encode("", c)
+}
+
+// EncodeItem writes the build item as a single solid named after the referenced object.
+func (e *Encoder) EncodeItem(m *go3mf.Model, item *go3mf.Item) error {
+	c := newFacetCollector(m, e.Colors)
+	if err := m.WalkItemMeshes(item, c.addMesh); err != nil {
+		return err
+	}
+	var name string
+	if obj, ok := m.FindObject(item.ObjectPath(), item.ObjectID); ok {
+		name = obj.Name
+	}
+	return e.encode(name, c)
+}
+
+// EncodeObject writes obj, defined in the model part path, as a single solid.
+// The components of obj are resolved against m and no build item transform is applied.
+func (e *Encoder) EncodeObject(m *go3mf.Model, path string, obj *go3mf.Object) error {
+	c := newFacetCollector(m, e.Colors)
+	if err := m.WalkObjectMeshes(path, obj, go3mf.Identity(), c.addMesh); err != nil {
+		return err
+	}
+	return e.encode(obj.Name, c)
+}
+
+// EncodeItems writes each build item of m to its own stream.
+// create is called once per item and the returned stream is closed after the item is written.
+func EncodeItems(m *go3mf.Model, format Format, colors ColorFormat, create func(int, *go3mf.Item) (io.WriteCloser, error)) error {
+	for i, item := range m.Build.Items {
+		w, err := create(i, item)
+		if err != nil {
+			return err
+		}
+		e := NewEncoder(w)
+		e.Format = format
+		e.Colors = colors
+		err = e.EncodeItem(m, item)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return specerr.WrapIndex(err, "item", i)
+		}
+	}
+	return nil
+}
+
+func (e *Encoder) encode(name string, c *facetCollector) error {
+	if e.Format == FormatASCII {
+		return e.encodeASCII(name, c.facets)
+	}
+	return e.encodeBinary(c)
+}
+
+func (e *Encoder) encodeBinary(c *facetCollector) error {
+	w := bufio.NewWriter(e.w)
+	header := binaryHeader{FaceCount: uint32(len(c.facets))}
+	n := copy(header.Header[:], binaryHeaderText)
+	if e.Colors == ColorMagics {
+		n += copy(header.Header[n:], " "+colorHeaderMagics)
+		copy(header.Header[n:], []byte{0xff, 0xff, 0xff, 0xff})
+	}
+	if err := binary.Write(w, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	var face binaryFace
+	for i, f := range c.facets {
+		face.Normal = facetNormal(f)
+		for j := range f {
+			face.Vertices[j] = f[j]
+		}
+		if e.Colors != ColorNone {
+			face.Attribute = encodeColor(e.Colors, c.colors[i], c.hasColor[i])
+		}
+		if err := binary.Write(w, binary.LittleEndian, &face); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func (e *Encoder) encodeASCII(name string, facets [][3]go3mf.Point3D) error {
+	w := bufio.NewWriter(e.w)
+	w.WriteString(strings.TrimSpace("solid "+name) + "\n")
+	buf := make([]byte, 0, 64)
+	writePoint := func(prefix string, p go3mf.Point3D) {
+		buf = append(buf[:0], prefix...)
+		for i := range p {
+			buf = append(buf, ' ')
+			buf = strconv.AppendFloat(buf, float64(p[i]), 'e', -1, 32)
+		}
+		buf = append(buf, '\n')
+		w.Write(buf)
+	}
+	for _, f := range facets {
+		writePoint("  facet normal", facetNormal(f))
+		w.WriteString("    outer loop\n")
+		for _, v := range f {
+			writePoint("      vertex", v)
+		}
+		w.WriteString("    endloop\n")
+		w.WriteString("  endfacet\n")
+	}
+	w.WriteString(strings.TrimSpace("endsolid "+name) + "\n")
+	return w.Flush()
+}
+
+// facetCollector flattens objects into a list of transformed facets.
+// If the color format is not ColorNone the facet colors are also collected.
+type facetCollector struct {
+	m         *go3mf.Model
+	format    ColorFormat
+	facets    [][3]go3mf.Point3D
+	colors    []color.RGBA
+	hasColor  []bool
+	resolvers map[string]*materials.Resolver
+	evaluator *materials.ColorEvaluator
+}
+
+func newFacetCollector(m *go3mf.Model, format ColorFormat) *facetCollector {
+	c := &facetCollector{m: m, format: format}
+	if format != ColorNone {
+		c.resolvers = make(map[string]*materials.Resolver)
+		c.evaluator = materials.NewColorEvaluator(m)
+	}
+	return c
+}
+
+// addMesh appends the mesh triangles of obj transformed by transform.
+// Transforms with a negative determinant mirror the mesh, so the vertex order
+// is reversed to keep the facets facing outwards.
+func (c *facetCollector) addMesh(path string, obj *go3mf.Object, transform go3mf.Matrix) error {
+	mirror := transform.Determinant() < 0
+	vertices := obj.Mesh.Vertices.Vertex
+	nv := uint32(len(vertices))
+	for i := range obj.Mesh.Triangles.Triangle {
+		t := &obj.Mesh.Triangles.Triangle[i]
+		if t.V1 >= nv || t.V2 >= nv || t.V3 >= nv {
+			continue
+		}
+		f := [3]go3mf.Point3D{
+			transform.Mul3D(vertices[t.V1]),
+			transform.Mul3D(vertices[t.V2]),
+			transform.Mul3D(vertices[t.V3]),
+		}
+		if mirror {
+			f[1], f[2] = f[2], f[1]
+		}
+		c.facets = append(c.facets, f)
+		if c.format != ColorNone {
+			clr, ok, err := c.facetColor(path, obj, t)
+			if err != nil {
+				return specerr.WrapIndex(err, "triangle", i)
+			}
+			c.colors = append(c.colors, clr)
+			c.hasColor = append(c.hasColor, ok)
+		}
+	}
+	return nil
+}
+
+// facetColor returns the color at the centroid of t,
+// or false if t has no properties.
+func (c *facetCollector) facetColor(path string, obj *go3mf.Object, t *go3mf.Triangle) (color.RGBA, bool, error) {
+	r, ok := c.resolvers[path]
+	if !ok {
+		r = materials.NewResolver(c.m, path)
+		c.resolvers[path] = r
+	}
+	props, err := r.Triangle(obj, t)
+	if err != nil || props[0] == nil {
+		return color.RGBA{}, false, err
+	}
+	clr, err := c.evaluator.ColorAt(props, [3]float32{1.0 / 3, 1.0 / 3, 1.0 / 3})
+	return clr, err == nil, err
+}
+
+// facetNormal returns the unit normal of f following the right-hand rule,
+// or a zero vector if f is degenerated.
+func facetNormal(f [3]go3mf.Point3D) go3mf.Point3D {
+	a := go3mf.Point3D{f[1][0] - f[0][0], f[1][1] - f[0][1], f[1][2] - f[0][2]}
+	b := go3mf.Point3D{f[2][0] - f[0][0], f[2][1] - f[0][1], f[2][2] - f[0][2]}
+	n := go3mf.Point3D{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+	l := float32(math.Sqrt(float64(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])))
+	if l == 0 {
+		return go3mf.Point3D{}
+	}
+	for i := range n {
+		// Adding zero avoids writing negative zeros.
+		n[i] = n[i]/l + 0
+	}
+	return n
+}
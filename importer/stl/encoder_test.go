@@ -0,0 +1,165 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package stl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+	}{
+		{"binary", FormatBinary},
+		{"ascii", FormatASCII},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(go3mf.Model)
+			m.Resources.Objects = append(m.Resources.Objects, createMeshTriangle(1))
+			m.Build.Items = append(m.Build.Items, &go3mf.Item{ObjectID: 1})
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.Format = tt.format
+			if err := e.Encode(m); err != nil {
+				t.Fatalf("Encoder.Encode() error = %v", err)
+			}
+			got := new(go3mf.Model)
+			if err := NewDecoder(&buf).Decode(got); err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			if diff := deep.Equal(got.Resources.Objects[0], createMeshTriangle(1)); diff != nil {
+				t.Errorf("Encoder.Encode() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestEncoder_EncodeItem(t *testing.T) {
+	m := new(go3mf.Model)
+	m.Resources.Objects = append(m.Resources.Objects,
+		&go3mf.Object{ID: 1, Mesh: &go3mf.Mesh{
+			Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+		}},
+		&go3mf.Object{ID: 2, Name: "parent", Components: &go3mf.Components{Component: []*go3mf.Component{
+			{ObjectID: 1, Transform: go3mf.Identity().Translate(0, 0, 1)},
+		}}},
+	)
+	mirror := go3mf.Identity()
+	mirror[0] = -1
+	tests := []struct {
+		name string
+		item *go3mf.Item
+		want string
+	}{
+		{"components", &go3mf.Item{ObjectID: 2, Transform: go3mf.Identity().Translate(1, 0, 0)}, `solid parent
+  facet normal 0e+00 0e+00 1e+00
+    outer loop
+      vertex 1e+00 0e+00 1e+00
+      vertex 2e+00 0e+00 1e+00
+      vertex 1e+00 1e+00 1e+00
+    endloop
+  endfacet
+endsolid parent
+`},
+		{"mirror", &go3mf.Item{ObjectID: 1, Transform: mirror}, `solid
+  facet normal 0e+00 0e+00 1e+00
+    outer loop
+      vertex 0e+00 0e+00 0e+00
+      vertex 0e+00 1e+00 0e+00
+      vertex -1e+00 0e+00 0e+00
+    endloop
+  endfacet
+endsolid
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.Format = FormatASCII
+			if err := e.EncodeItem(m, tt.item); err != nil {
+				t.Fatalf("Encoder.EncodeItem() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Encoder.EncodeItem() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_EncodeObject(t *testing.T) {
+	m := new(go3mf.Model)
+	m.Resources.Objects = append(m.Resources.Objects,
+		&go3mf.Object{ID: 1, Components: &go3mf.Components{Component: []*go3mf.Component{{ObjectID: 2}}}},
+		&go3mf.Object{ID: 2, Components: &go3mf.Components{Component: []*go3mf.Component{{ObjectID: 1}}}},
+		&go3mf.Object{ID: 3, Components: &go3mf.Components{Component: []*go3mf.Component{{ObjectID: 10}}}},
+	)
+	tests := []struct {
+		name    string
+		obj     *go3mf.Object
+		wantErr error
+	}{
+		{"recursive", m.Resources.Objects[0], specerr.ErrRecursion},
+		{"missing", m.Resources.Objects[2], specerr.ErrMissingResource},
+		{"base", createMeshTriangle(1), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := NewEncoder(&buf).EncodeObject(m, "", tt.obj)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Encoder.EncodeObject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && buf.Len() != 84+6*50 {
+				t.Errorf("Encoder.EncodeObject() size = %d", buf.Len())
+			}
+		})
+	}
+}
+
+func TestEncodeItems(t *testing.T) {
+	m := new(go3mf.Model)
+	m.Resources.Objects = append(m.Resources.Objects, createMeshTriangle(1))
+	m.Build.Items = append(m.Build.Items, &go3mf.Item{ObjectID: 1}, &go3mf.Item{ObjectID: 1, Transform: go3mf.Identity().Translate(100, 0, 0)})
+	var files []*bytes.Buffer
+	err := EncodeItems(m, FormatASCII, ColorNone, func(i int, _ *go3mf.Item) (io.WriteCloser, error) {
+		files = append(files, new(bytes.Buffer))
+		return nopWriteCloser{files[i]}, nil
+	})
+	if err != nil {
+		t.Fatalf("EncodeItems() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("EncodeItems() files = %d, want 2", len(files))
+	}
+	for i, f := range files {
+		if got := strings.Count(f.String(), "endfacet"); got != 6 {
+			t.Errorf("EncodeItems() file %d facets = %d, want 6", i, got)
+		}
+	}
+	m.Build.Items = append(m.Build.Items, &go3mf.Item{ObjectID: 5})
+	err = EncodeItems(m, FormatBinary, ColorNone, func(int, *go3mf.Item) (io.WriteCloser, error) {
+		return nopWriteCloser{new(bytes.Buffer)}, nil
+	})
+	if !errors.Is(err, specerr.ErrMissingResource) {
+		t.Errorf("EncodeItems() error = %v, want %v", err, specerr.ErrMissingResource)
+	}
+}
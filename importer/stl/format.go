@@ -0,0 +1,43 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package stl
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/importer"
+)
+
+func init() {
+	importer.Register(importer.Format{
+		Name:       "stl",
+		Extensions: []string{".stl"},
+		Sniff:      sniff,
+		Decode: func(ctx context.Context, r io.Reader, _ importer.OpenFunc, m *go3mf.Model) error {
+			return NewDecoder(r).DecodeContext(ctx, m)
+		},
+		Encode: func(w io.Writer, m *go3mf.Model) error {
+			return NewEncoder(w).Encode(m)
+		},
+	})
+}
+
+// sniff reports whether b is a binary stl, whose length matches its triangle count,
+// or an ascii stl starting with the solid keyword.
+func sniff(b []byte) bool {
+	if len(b) >= 84 && int64(len(b)) == 84+50*int64(binary.LittleEndian.Uint32(b[80:])) {
+		return true
+	}
+	header := b
+	if len(header) > sizeOfHeader {
+		header = header[:sizeOfHeader]
+	}
+	s := strings.ToLower(string(bytes.TrimLeft(header, " \t\r\n")))
+	return strings.HasPrefix(s, "solid") && isASCII(s)
+}
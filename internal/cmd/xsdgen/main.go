@@ -0,0 +1,60 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+// Command xsdgen writes a Go file declaring a string constant with the content of an XSD file,
+// so the published schemas are bundled verbatim without requiring embed.
+//
+// Usage:
+//
+//	xsdgen -pkg name -const name -o file.go schema.xsd
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("xsdgen: ")
+	pkg := flag.String("pkg", "", "package name")
+	name := flag.String("const", "schema", "constant name")
+	out := flag.String("o", "schema_gen.go", "output file")
+	flag.Parse()
+	if *pkg == "" || flag.NArg() != 1 {
+		flag.Usage()
+		log.Fatal("missing package name or input file")
+	}
+	in := flag.Arg(0)
+	b, err := ioutil.ReadFile(in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(*pkg, *name, filepath.ToSlash(in), b)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generate(pkg, name, in string, b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by xsdgen from %s. DO NOT EDIT.\n\n", in)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "// %s is the content of %s.\n", name, in)
+	lit := strconv.Quote(string(b))
+	if !strings.ContainsAny(string(b), "`\r") {
+		lit = "`" + string(b) + "`"
+	}
+	fmt.Fprintf(&buf, "const %s = %s\n", name, lit)
+	return format.Source(buf.Bytes())
+}
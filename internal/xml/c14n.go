@@ -0,0 +1,185 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ErrElementNotFound is returned by Canonicalize when no element matches.
+var ErrElementNotFound = errors.New("xml: element to canonicalize not found")
+
+// Canonicalize returns the Canonical XML 1.0 (without comments) serialization
+// of the first element of data, and its descendants, for which match returns true.
+// match is called with the element name and attributes, both with their
+// name spaces already resolved.
+//
+// Namespace declarations in scope from the element ancestors
+// are rendered in the element, as required by inclusive canonicalization.
+func Canonicalize(data []byte, match func(xml.Name, []xml.Attr) bool) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		scopes   = []map[string]string{{"xml": nsXML}}
+		rendered []map[string]string
+		out      bytes.Buffer
+		depth    int
+	)
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			scope := make(map[string]string, len(scopes[len(scopes)-1]))
+			for k, v := range scopes[len(scopes)-1] {
+				scope[k] = v
+			}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" {
+					scope[a.Name.Local] = a.Value
+				} else if a.Name.Space == "" && a.Name.Local == "xmlns" {
+					scope[""] = a.Value
+				}
+			}
+			scopes = append(scopes, scope)
+			if depth == 0 {
+				name, attrs := resolveNames(t, scope)
+				if !match(name, attrs) {
+					continue
+				}
+				rendered = []map[string]string{{"xml": nsXML}}
+			}
+			depth++
+			writeCanonicalStart(&out, t, scope, rendered[len(rendered)-1])
+			rendered = append(rendered, scope)
+		case xml.EndElement:
+			scopes = scopes[:len(scopes)-1]
+			if depth == 0 {
+				continue
+			}
+			depth--
+			rendered = rendered[:len(rendered)-1]
+			out.WriteString("</")
+			writeRawName(&out, t.Name)
+			out.WriteByte('>')
+			if depth == 0 {
+				return out.Bytes(), nil
+			}
+		case xml.CharData:
+			if depth > 0 {
+				escapeCanonicalText(&out, string(t))
+			}
+		case xml.ProcInst:
+			if depth > 0 {
+				out.WriteString("<?")
+				out.WriteString(t.Target)
+				if len(t.Inst) > 0 {
+					out.WriteByte(' ')
+					out.Write(t.Inst)
+				}
+				out.WriteString("?>")
+			}
+		}
+	}
+	return nil, ErrElementNotFound
+}
+
+func resolveNames(t xml.StartElement, scope map[string]string) (xml.Name, []xml.Attr) {
+	name := xml.Name{Space: scope[t.Name.Space], Local: t.Name.Local}
+	attrs := make([]xml.Attr, 0, len(t.Attr))
+	for _, a := range t.Attr {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+			continue
+		}
+		n := a.Name
+		if n.Space != "" {
+			n.Space = scope[n.Space]
+		}
+		attrs = append(attrs, xml.Attr{Name: n, Value: a.Value})
+	}
+	return name, attrs
+}
+
+func writeCanonicalStart(out *bytes.Buffer, t xml.StartElement, scope, parent map[string]string) {
+	out.WriteByte('<')
+	writeRawName(out, t.Name)
+	prefixes := make([]string, 0, len(scope))
+	for prefix, uri := range scope {
+		if prefix == "xml" {
+			continue
+		}
+		if parent[prefix] == uri || (prefix != "" && uri == "") {
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			out.WriteString(` xmlns="`)
+		} else {
+			out.WriteString(" xmlns:")
+			out.WriteString(prefix)
+			out.WriteString(`="`)
+		}
+		escapeCanonicalAttr(out, scope[prefix])
+		out.WriteByte('"')
+	}
+	type attr struct {
+		ns  string
+		raw xml.Attr
+	}
+	attrs := make([]attr, 0, len(t.Attr))
+	for _, a := range t.Attr {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+			continue
+		}
+		var ns string
+		if a.Name.Space != "" {
+			ns = scope[a.Name.Space]
+		}
+		attrs = append(attrs, attr{ns, a})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].ns != attrs[j].ns {
+			return attrs[i].ns < attrs[j].ns
+		}
+		return attrs[i].raw.Name.Local < attrs[j].raw.Name.Local
+	})
+	for _, a := range attrs {
+		out.WriteByte(' ')
+		writeRawName(out, a.raw.Name)
+		out.WriteString(`="`)
+		escapeCanonicalAttr(out, a.raw.Value)
+		out.WriteByte('"')
+	}
+	out.WriteByte('>')
+}
+
+func writeRawName(out *bytes.Buffer, name xml.Name) {
+	if name.Space != "" {
+		out.WriteString(name.Space)
+		out.WriteByte(':')
+	}
+	out.WriteString(name.Local)
+}
+
+var (
+	canonicalTextReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	canonicalAttrReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
+
+func escapeCanonicalText(out *bytes.Buffer, s string) {
+	canonicalTextReplacer.WriteString(out, s)
+}
+
+func escapeCanonicalAttr(out *bytes.Buffer, s string) {
+	canonicalAttrReplacer.WriteString(out, s)
+}
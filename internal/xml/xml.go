@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/xml"
 	goxml "encoding/xml"
+	"errors"
 	"io"
 	"strconv"
 	"strings"
@@ -83,12 +84,23 @@ func (b *bufioReader) fill() {
 
 const nameCacheSize = 8
 
+// Limit errors.
+var (
+	ErrMaxDepth = errors.New("xml: element nesting depth limit exceeded")
+	ErrMaxAttrs = errors.New("xml: element attribute count limit exceeded")
+)
+
 // A Decoder represents an XML parser reading a particular input stream.
 // The parser assumes that its input is encoded in UTF-8.
+//
+// MaxDepth and MaxAttrs limit the element nesting depth and the
+// number of attributes per element. Zero means no limit.
 type Decoder struct {
-	OnStart func(StartElement)
-	OnEnd   func(xml.EndElement)
-	OnChar  func(xml.CharData)
+	OnStart  func(StartElement)
+	OnEnd    func(xml.EndElement)
+	OnChar   func(xml.CharData)
+	MaxDepth int
+	MaxAttrs int
 
 	names     map[[nameCacheSize]byte]string
 	r         *bufioReader
@@ -98,6 +110,8 @@ type Decoder struct {
 	needClose bool
 	toClose   goxml.Name
 	ns        map[string]string
+	depth     int
+	line      int
 	err       error
 	attrPool  []XMLAttr
 	strPool   []bytes.Buffer
@@ -141,6 +155,7 @@ func (d *Decoder) handleStartElement(t StartElement) {
 		d.translate(&t.Attr[i].Name, false)
 	}
 	d.pushElement(t.Name)
+	d.depth++
 	if d.OnStart != nil {
 		d.OnStart(t)
 	}
@@ -149,6 +164,7 @@ func (d *Decoder) handleStartElement(t StartElement) {
 func (d *Decoder) handleEndElement(t goxml.EndElement) bool {
 	d.translate(&t.Name, true)
 	if d.popElement(&t) {
+		d.depth--
 		if d.OnEnd != nil {
 			d.OnEnd(t)
 		}
@@ -423,6 +439,11 @@ func (d *Decoder) RawToken() error {
 		return d.err
 	}
 
+	if d.MaxDepth > 0 && d.depth >= d.MaxDepth {
+		d.err = ErrMaxDepth
+		return d.err
+	}
+
 	i := 0
 	for {
 		d.space()
@@ -469,6 +490,10 @@ func (d *Decoder) RawToken() error {
 			return d.err
 		}
 		i++
+		if d.MaxAttrs > 0 && i > d.MaxAttrs {
+			d.err = ErrMaxAttrs
+			return d.err
+		}
 		if len(d.attrPool) < i {
 			d.attrPool = append(d.attrPool, make([]XMLAttr, len(d.attrPool))...)
 			d.strPool = append(d.strPool, make([]bytes.Buffer, len(d.strPool))...)
@@ -518,12 +543,20 @@ func (d *Decoder) space() {
 	}
 }
 
+// InputLine returns the 1-based line of the end of the current token.
+func (d *Decoder) InputLine() int {
+	return d.line + 1
+}
+
 // Read a single byte.
 // If there is no byte to read, return ok==false
 // and leave the error in d.err.
 func (d *Decoder) getc() (b byte, ok bool) {
 	b, d.err = d.r.ReadByte()
 	ok = d.err == nil
+	if ok && b == '\n' {
+		d.line++
+	}
 	return
 }
 
@@ -540,6 +573,9 @@ func (d *Decoder) mustNotEOF() {
 
 // Unread a single byte.
 func (d *Decoder) ungetc(b byte) {
+	if b == '\n' {
+		d.line--
+	}
 	d.r.nextByte = int(b)
 }
 
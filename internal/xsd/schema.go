@@ -0,0 +1,368 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+// Package xsd implements the subset of XML Schema used by the 3MF specs.
+//
+// The supported constructs are global and local element declarations,
+// named and anonymous complex types with sequence and choice groups,
+// xs:any and xs:anyAttribute wildcards, simple content, global and local
+// attribute declarations and simple types derived by restriction, list or union.
+//
+// Type references without a prefix are resolved in the target namespace of
+// the schema document and the ones prefixed with xs: are built-in types.
+package xsd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	nsXSD = "http://www.w3.org/2001/XMLSchema"
+	nsXML = "http://www.w3.org/XML/1998/namespace"
+
+	unbounded = -1
+)
+
+// Schema is a set of compiled schema documents.
+type Schema struct {
+	elements map[xml.Name]*elementDecl
+	attrs    map[xml.Name]*attrDecl
+	simple   map[xml.Name]*simpleType
+	complex  map[xml.Name]*complexType
+	raw      map[xml.Name]*node
+	// interleaved are the elements whose children from other namespaces
+	// are not constrained by the order of their content model.
+	interleaved map[xml.Name]bool
+}
+
+type elementDecl struct {
+	name   xml.Name
+	typ    *complexType
+	simple *simpleType
+}
+
+type attrDecl struct {
+	name     xml.Name
+	typ      *simpleType
+	required bool
+}
+
+type complexType struct {
+	content *particle
+	attrs   []*attrDecl
+	anyAttr bool
+	text    bool
+	lang    bool
+}
+
+type particleKind int
+
+const (
+	particleElement particleKind = iota
+	particleSequence
+	particleChoice
+	particleAny
+)
+
+type particle struct {
+	kind     particleKind
+	elem     *elementDecl
+	min, max int
+	children []*particle
+	// space is the target namespace excluded by ##other wildcards.
+	space string
+	skip  bool
+}
+
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []node     `xml:",any"`
+}
+
+func (n *node) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *node) is(local string) bool {
+	return n.XMLName.Space == nsXSD && n.XMLName.Local == local
+}
+
+// Parse compiles the schema documents.
+// The documents may reference the global declarations of the other documents.
+func Parse(docs ...[]byte) (*Schema, error) {
+	s := &Schema{
+		elements: make(map[xml.Name]*elementDecl),
+		attrs:    make(map[xml.Name]*attrDecl),
+		simple:   make(map[xml.Name]*simpleType),
+		complex:  make(map[xml.Name]*complexType),
+		raw:      make(map[xml.Name]*node),
+
+		interleaved: make(map[xml.Name]bool),
+	}
+	type global struct {
+		tns string
+		n   *node
+	}
+	var (
+		elements, attrs []global
+		complexTypes    []global
+	)
+	for _, doc := range docs {
+		root := new(node)
+		if err := xml.Unmarshal(doc, root); err != nil {
+			return nil, err
+		}
+		if !root.is("schema") {
+			return nil, fmt.Errorf("xsd: unexpected root element %s", root.XMLName.Local)
+		}
+		tns := root.attr("targetNamespace")
+		for i := range root.Nodes {
+			n := &root.Nodes[i]
+			name := xml.Name{Space: tns, Local: n.attr("name")}
+			switch {
+			case n.is("element"):
+				s.elements[name] = &elementDecl{name: name}
+				elements = append(elements, global{tns, n})
+			case n.is("attribute"):
+				s.attrs[name] = &attrDecl{name: name}
+				attrs = append(attrs, global{tns, n})
+			case n.is("complexType"):
+				s.complex[name] = new(complexType)
+				complexTypes = append(complexTypes, global{tns, n})
+			case n.is("simpleType"):
+				s.raw[name] = n
+			}
+		}
+	}
+	for _, g := range complexTypes {
+		name := xml.Name{Space: g.tns, Local: g.n.attr("name")}
+		if err := s.fillComplex(s.complex[name], g.tns, g.n); err != nil {
+			return nil, err
+		}
+	}
+	for _, g := range elements {
+		e := s.elements[xml.Name{Space: g.tns, Local: g.n.attr("name")}]
+		if err := s.fillElement(e, g.tns, g.n); err != nil {
+			return nil, err
+		}
+	}
+	for _, g := range attrs {
+		a := s.attrs[xml.Name{Space: g.tns, Local: g.n.attr("name")}]
+		if err := s.fillAttr(a, g.tns, g.n); err != nil {
+			return nil, err
+		}
+	}
+	for name := range s.raw {
+		if _, err := s.simpleType(name); err != nil {
+			return nil, err
+		}
+	}
+	s.raw = nil
+	return s, nil
+}
+
+// Interleave makes the children of the element name that belong to other namespaces,
+// and so match a ##other wildcard of its type, valid in any position among
+// its other children instead of only where the wildcard appears in the content model.
+// They are still validated against their global declarations.
+//
+// This is an exception to XML Schema, and it must be called before validating any document.
+func (s *Schema) Interleave(name xml.Name) {
+	s.interleaved[name] = true
+}
+
+// qname resolves a type or element reference.
+func qname(tns, ref string) xml.Name {
+	if i := strings.IndexByte(ref, ':'); i >= 0 {
+		switch ref[:i] {
+		case "xs", "xsd":
+			return xml.Name{Space: nsXSD, Local: ref[i+1:]}
+		case "xml":
+			return xml.Name{Space: nsXML, Local: ref[i+1:]}
+		}
+		return xml.Name{Space: ref[:i], Local: ref[i+1:]}
+	}
+	return xml.Name{Space: tns, Local: ref}
+}
+
+func (s *Schema) fillElement(e *elementDecl, tns string, n *node) error {
+	if t := n.attr("type"); t != "" {
+		name := qname(tns, t)
+		if ct, ok := s.complex[name]; ok {
+			e.typ = ct
+			return nil
+		}
+		st, err := s.simpleType(name)
+		if err != nil {
+			return err
+		}
+		e.simple = st
+		return nil
+	}
+	for i := range n.Nodes {
+		c := &n.Nodes[i]
+		switch {
+		case c.is("complexType"):
+			e.typ = new(complexType)
+			return s.fillComplex(e.typ, tns, c)
+		case c.is("simpleType"):
+			st, err := s.parseSimple(tns, c)
+			e.simple = st
+			return err
+		}
+	}
+	// An element without type accepts any content.
+	e.typ = &complexType{content: &particle{kind: particleAny, min: 0, max: unbounded, skip: true}, anyAttr: true, text: true}
+	return nil
+}
+
+func (s *Schema) fillAttr(a *attrDecl, tns string, n *node) error {
+	a.required = n.attr("use") == "required"
+	if t := n.attr("type"); t != "" {
+		st, err := s.simpleType(qname(tns, t))
+		a.typ = st
+		return err
+	}
+	for i := range n.Nodes {
+		if c := &n.Nodes[i]; c.is("simpleType") {
+			st, err := s.parseSimple(tns, c)
+			a.typ = st
+			return err
+		}
+	}
+	a.typ = anySimpleType
+	return nil
+}
+
+func (s *Schema) fillComplex(ct *complexType, tns string, n *node) error {
+	ct.text = n.attr("mixed") == "true"
+	for i := range n.Nodes {
+		c := &n.Nodes[i]
+		switch {
+		case c.is("sequence"), c.is("choice"):
+			p, err := s.parseParticle(tns, c)
+			if err != nil {
+				return err
+			}
+			ct.content = p
+		case c.is("simpleContent"):
+			ct.text = true
+			for j := range c.Nodes {
+				if ext := &c.Nodes[j]; ext.is("extension") || ext.is("restriction") {
+					if err := s.parseAttrs(ct, tns, ext.Nodes); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return s.parseAttrs(ct, tns, n.Nodes)
+}
+
+func (s *Schema) parseAttrs(ct *complexType, tns string, nodes []node) error {
+	for i := range nodes {
+		c := &nodes[i]
+		switch {
+		case c.is("attribute"):
+			if ref := c.attr("ref"); ref != "" {
+				name := qname(tns, ref)
+				if name == (xml.Name{Space: nsXML, Local: "lang"}) {
+					ct.lang = true
+					continue
+				}
+				a, ok := s.attrs[name]
+				if !ok {
+					return fmt.Errorf("xsd: undefined attribute %s", ref)
+				}
+				ct.attrs = append(ct.attrs, &attrDecl{name: a.name, typ: a.typ, required: c.attr("use") == "required"})
+				continue
+			}
+			if c.attr("use") == "prohibited" {
+				continue
+			}
+			a := &attrDecl{name: xml.Name{Local: c.attr("name")}}
+			if err := s.fillAttr(a, tns, c); err != nil {
+				return err
+			}
+			ct.attrs = append(ct.attrs, a)
+		case c.is("anyAttribute"):
+			ct.anyAttr = true
+		}
+	}
+	return nil
+}
+
+func parseOccurs(n *node) (min, max int, err error) {
+	min, max = 1, 1
+	if v := n.attr("minOccurs"); v != "" {
+		if min, err = strconv.Atoi(v); err != nil {
+			return
+		}
+	}
+	switch v := n.attr("maxOccurs"); v {
+	case "":
+	case "unbounded", "2147483647":
+		max = unbounded
+	default:
+		max, err = strconv.Atoi(v)
+	}
+	return
+}
+
+func (s *Schema) parseParticle(tns string, n *node) (*particle, error) {
+	min, max, err := parseOccurs(n)
+	if err != nil {
+		return nil, err
+	}
+	p := &particle{min: min, max: max}
+	switch {
+	case n.is("element"):
+		p.kind = particleElement
+		if ref := n.attr("ref"); ref != "" {
+			e, ok := s.elements[qname(tns, ref)]
+			if !ok {
+				return nil, fmt.Errorf("xsd: undefined element %s", ref)
+			}
+			p.elem = e
+		} else {
+			p.elem = &elementDecl{name: xml.Name{Space: tns, Local: n.attr("name")}}
+			if err := s.fillElement(p.elem, tns, n); err != nil {
+				return nil, err
+			}
+		}
+	case n.is("any"):
+		p.kind = particleAny
+		p.skip = n.attr("processContents") == "skip"
+		if n.attr("namespace") == "##other" {
+			p.space = tns
+		}
+	case n.is("sequence"), n.is("choice"):
+		p.kind = particleSequence
+		if n.is("choice") {
+			p.kind = particleChoice
+		}
+		for i := range n.Nodes {
+			c := &n.Nodes[i]
+			if !c.is("element") && !c.is("any") && !c.is("sequence") && !c.is("choice") {
+				continue
+			}
+			cp, err := s.parseParticle(tns, c)
+			if err != nil {
+				return nil, err
+			}
+			p.children = append(p.children, cp)
+		}
+	default:
+		return nil, fmt.Errorf("xsd: unsupported particle %s", n.XMLName.Local)
+	}
+	return p, nil
+}
@@ -0,0 +1,90 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package xsd
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const head = `<xs:schema xmlns="http://example.com/test" xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/test">`
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr bool
+	}{
+		{"base", head + `<xs:element name="a"/></xs:schema>`, false},
+		{"invalid", `<xs:schema`, true},
+		{"root", `<schema/>`, true},
+		{"undefinedElement", head + `<xs:complexType name="CT_A"><xs:sequence><xs:element ref="b"/></xs:sequence></xs:complexType></xs:schema>`, true},
+		{"undefinedAttr", head + `<xs:complexType name="CT_A"><xs:attribute ref="b"/></xs:complexType></xs:schema>`, true},
+		{"undefinedType", head + `<xs:element name="a" type="ST_A"/></xs:schema>`, true},
+		{"builtin", head + `<xs:element name="a" type="xs:dateTime"/></xs:schema>`, true},
+		{"occurs", head + `<xs:complexType name="CT_A"><xs:sequence minOccurs="a"/></xs:complexType></xs:schema>`, true},
+		{"pattern", head + `<xs:simpleType name="ST_A"><xs:restriction base="xs:string"><xs:pattern value="("/></xs:restriction></xs:simpleType><xs:attribute name="a" type="ST_A"/></xs:schema>`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.doc)); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_simpleType_valid(t *testing.T) {
+	s, err := Parse([]byte(`<xs:schema xmlns="http://example.com/test" xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/test">
+	<xs:simpleType name="ST_Number">
+		<xs:restriction base="xs:double">
+			<xs:minInclusive value="0"/>
+			<xs:maxExclusive value="1"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_Color">
+		<xs:restriction base="xs:string">
+			<xs:pattern value="#[0-9A-F]{6}"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_Index">
+		<xs:restriction base="xs:nonNegativeInteger"/>
+	</xs:simpleType>
+	<xs:simpleType name="ST_Any">
+		<xs:union memberTypes="ST_Color xs:boolean"/>
+	</xs:simpleType>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tests := []struct {
+		typ  string
+		v    string
+		want bool
+	}{
+		{"ST_Number", "0", true},
+		{"ST_Number", "0.5e-1", true},
+		{"ST_Number", "1", false},
+		{"ST_Number", "-0.1", false},
+		{"ST_Number", "a", false},
+		{"ST_Color", "#FF00AA", true},
+		{"ST_Color", "#FF00AA00", false},
+		{"ST_Index", "0", true},
+		{"ST_Index", "-1", false},
+		{"ST_Index", "1.5", false},
+		{"ST_Any", "true", true},
+		{"ST_Any", "#000000", true},
+		{"ST_Any", "yes", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ+"_"+tt.v, func(t *testing.T) {
+			st, err := s.simpleType(xml.Name{Space: "http://example.com/test", Local: tt.typ})
+			if err != nil {
+				t.Fatalf("Schema.simpleType() error = %v", err)
+			}
+			if got := st.valid(tt.v); got != tt.want {
+				t.Errorf("simpleType.valid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
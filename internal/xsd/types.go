@@ -0,0 +1,206 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package xsd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type builtin int
+
+const (
+	builtinString builtin = iota
+	builtinBoolean
+	builtinDouble
+	builtinInteger
+	builtinNonNegativeInteger
+	builtinPositiveInteger
+)
+
+var builtins = map[string]builtin{
+	"string": builtinString, "normalizedString": builtinString, "token": builtinString,
+	"anyURI": builtinString, "QName": builtinString, "language": builtinString,
+	"ID": builtinString, "NCName": builtinString, "anySimpleType": builtinString,
+	"boolean": builtinBoolean,
+	"double":  builtinDouble, "float": builtinDouble, "decimal": builtinDouble,
+	"integer": builtinInteger, "int": builtinInteger, "long": builtinInteger,
+	"nonNegativeInteger": builtinNonNegativeInteger, "unsignedInt": builtinNonNegativeInteger,
+	"positiveInteger": builtinPositiveInteger,
+}
+
+// simpleType is a built-in type restricted by facets,
+// a list of items of a simple type or a union of simple types.
+type simpleType struct {
+	base     builtin
+	list     *simpleType
+	union    []*simpleType
+	enum     []string
+	patterns []*regexp.Regexp
+	min, max float64
+	minExcl  bool
+	maxExcl  bool
+	hasMin   bool
+	hasMax   bool
+}
+
+var anySimpleType = &simpleType{base: builtinString}
+
+func (s *Schema) simpleType(name xml.Name) (*simpleType, error) {
+	if st, ok := s.simple[name]; ok {
+		return st, nil
+	}
+	if name.Space == nsXSD {
+		b, ok := builtins[name.Local]
+		if !ok {
+			return nil, fmt.Errorf("xsd: unsupported built-in type %s", name.Local)
+		}
+		st := &simpleType{base: b}
+		s.simple[name] = st
+		return st, nil
+	}
+	n, ok := s.raw[name]
+	if !ok {
+		return nil, fmt.Errorf("xsd: undefined type %s", name.Local)
+	}
+	st, err := s.parseSimple(name.Space, n)
+	if err != nil {
+		return nil, err
+	}
+	s.simple[name] = st
+	return st, nil
+}
+
+func (s *Schema) parseSimple(tns string, n *node) (*simpleType, error) {
+	for i := range n.Nodes {
+		c := &n.Nodes[i]
+		switch {
+		case c.is("restriction"):
+			base, err := s.simpleType(qname(tns, c.attr("base")))
+			if err != nil {
+				return nil, err
+			}
+			st := *base
+			st.enum = nil
+			st.patterns = append([]*regexp.Regexp(nil), base.patterns...)
+			for j := range c.Nodes {
+				if err := st.addFacet(&c.Nodes[j]); err != nil {
+					return nil, err
+				}
+			}
+			return &st, nil
+		case c.is("list"):
+			item, err := s.simpleType(qname(tns, c.attr("itemType")))
+			if err != nil {
+				return nil, err
+			}
+			return &simpleType{list: item}, nil
+		case c.is("union"):
+			st := new(simpleType)
+			for _, m := range strings.Fields(c.attr("memberTypes")) {
+				mt, err := s.simpleType(qname(tns, m))
+				if err != nil {
+					return nil, err
+				}
+				st.union = append(st.union, mt)
+			}
+			return st, nil
+		}
+	}
+	return nil, fmt.Errorf("xsd: simple type %s without derivation", n.attr("name"))
+}
+
+func (st *simpleType) addFacet(n *node) error {
+	v := n.attr("value")
+	var err error
+	switch n.XMLName.Local {
+	case "enumeration":
+		st.enum = append(st.enum, v)
+	case "pattern":
+		var re *regexp.Regexp
+		if re, err = regexp.Compile("^(?:" + v + ")$"); err == nil {
+			st.patterns = append(st.patterns, re)
+		}
+	case "minInclusive", "minExclusive":
+		st.min, err = strconv.ParseFloat(v, 64)
+		st.hasMin, st.minExcl = true, n.XMLName.Local == "minExclusive"
+	case "maxInclusive", "maxExclusive":
+		st.max, err = strconv.ParseFloat(v, 64)
+		st.hasMax, st.maxExcl = true, n.XMLName.Local == "maxExclusive"
+	}
+	return err
+}
+
+// valid reports whether v is a valid lexical value of the type.
+func (st *simpleType) valid(v string) bool {
+	if st.list != nil {
+		for _, item := range strings.Fields(v) {
+			if !st.list.valid(item) {
+				return false
+			}
+		}
+		return true
+	}
+	if st.union != nil {
+		for _, m := range st.union {
+			if m.valid(v) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(st.enum) > 0 {
+		var found bool
+		for _, e := range st.enum {
+			if e == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, re := range st.patterns {
+		if !re.MatchString(v) {
+			return false
+		}
+	}
+	var f float64
+	switch st.base {
+	case builtinString:
+		return true
+	case builtinBoolean:
+		switch v {
+		case "true", "false", "1", "0":
+			return true
+		}
+		return false
+	case builtinDouble:
+		var err error
+		if f, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil && !math.IsInf(f, 0) {
+			return false
+		}
+	default:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return false
+		}
+		if (st.base == builtinNonNegativeInteger && i < 0) || (st.base == builtinPositiveInteger && i <= 0) {
+			return false
+		}
+		f = float64(i)
+	}
+	if st.hasMin && (f < st.min || (st.minExcl && f == st.min)) {
+		return false
+	}
+	if st.hasMax && (f > st.max || (st.maxExcl && f == st.max)) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,488 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package xsd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	specerr "github.com/hpinc/go3mf/errors"
+	xml3mf "github.com/hpinc/go3mf/internal/xml"
+)
+
+// Validator checks a stream of XML tokens against a Schema.
+//
+// The content of the elements that match a skip wildcard or
+// a lax wildcard without a global declaration is not validated.
+type Validator struct {
+	schema *Schema
+	stack  []frame
+	errs   []*specerr.SchemaError
+}
+
+// run is a sequence of consecutive children with the same name.
+// The i-th child of the run is in the line line+i*step.
+type run struct {
+	name  xml.Name
+	count int
+	line  int
+	step  int
+}
+
+type frame struct {
+	name   xml.Name
+	index  int
+	typ    *complexType
+	simple *simpleType
+	skip   bool
+	single bool
+	runs   []run
+	text   []byte
+	hasErr bool
+}
+
+// NewValidator returns a validator for a document of the schema.
+func (s *Schema) NewValidator() *Validator {
+	return &Validator{schema: s}
+}
+
+// Errors returns the schema errors found so far.
+func (v *Validator) Errors() []*specerr.SchemaError {
+	return v.errs
+}
+
+func (v *Validator) xpath(child *xml.Name, index int) string {
+	var sb strings.Builder
+	for i := range v.stack {
+		f := &v.stack[i]
+		sb.WriteByte('/')
+		sb.WriteString(f.name.Local)
+		if i > 0 && !f.single {
+			sb.WriteString("[" + strconv.Itoa(f.index) + "]")
+		}
+	}
+	if child != nil {
+		sb.WriteByte('/')
+		sb.WriteString(child.Local)
+		if index >= 0 {
+			sb.WriteString("[" + strconv.Itoa(index) + "]")
+		}
+	}
+	return sb.String()
+}
+
+func (v *Validator) addErr(line int, xpath string, err error, detail string) {
+	v.errs = append(v.errs, &specerr.SchemaError{Line: line, XPath: xpath, Err: fmt.Errorf("%w: %s", err, detail)})
+}
+
+func (v *Validator) push() *frame {
+	if len(v.stack) < cap(v.stack) {
+		v.stack = v.stack[:len(v.stack)+1]
+	} else {
+		v.stack = append(v.stack, frame{})
+	}
+	f := &v.stack[len(v.stack)-1]
+	*f = frame{runs: f.runs[:0], text: f.text[:0]}
+	return f
+}
+
+// Start validates a start element and its attributes.
+func (v *Validator) Start(name xml.Name, attrs []xml3mf.XMLAttr, line int) {
+	var (
+		decl   *elementDecl
+		index  int
+		single bool
+	)
+	if len(v.stack) == 0 {
+		decl = v.schema.elements[name]
+		if decl == nil {
+			v.addErr(line, "/"+name.Local, specerr.ErrSchemaElement, name.Local)
+		}
+	} else {
+		parent := &v.stack[len(v.stack)-1]
+		if parent.skip {
+			f := v.push()
+			f.name, f.skip = name, true
+			return
+		}
+		index = parent.addChild(name, line)
+		if parent.typ != nil && parent.typ.content != nil {
+			decl, single = v.schema.lookup(parent.typ.content, name, true)
+		}
+	}
+	f := v.push()
+	f.name, f.index, f.single = name, index, single
+	if decl == nil {
+		f.skip = true
+		return
+	}
+	f.typ, f.simple = decl.typ, decl.simple
+	if f.typ != nil {
+		v.validateAttrs(f.typ, name, attrs, line)
+	} else if len(attrs) > 0 {
+		v.validateAttrs(new(complexType), name, attrs, line)
+	}
+}
+
+// End validates the content of the current element.
+func (v *Validator) End(line int) {
+	if len(v.stack) == 0 {
+		return
+	}
+	f := &v.stack[len(v.stack)-1]
+	if !f.skip {
+		if f.simple != nil {
+			if !f.simple.valid(string(bytes.TrimSpace(f.text))) {
+				v.addErr(line, v.xpath(nil, 0), specerr.ErrSchemaAttrValue, f.name.Local)
+			}
+		} else if f.typ != nil {
+			v.validateContent(f, line)
+		}
+	}
+	v.stack = v.stack[:len(v.stack)-1]
+}
+
+// CharData validates the text of the current element.
+// line is the line of the end of the text.
+func (v *Validator) CharData(b []byte, line int) {
+	if len(v.stack) == 0 {
+		return
+	}
+	f := &v.stack[len(v.stack)-1]
+	if f.skip || f.hasErr {
+		return
+	}
+	if f.simple != nil {
+		f.text = append(f.text, b...)
+		return
+	}
+	if f.typ == nil || f.typ.text {
+		return
+	}
+	// Report the line of the first non-space character.
+	if i := bytes.IndexFunc(b, func(r rune) bool { return !unicode.IsSpace(r) }); i >= 0 {
+		f.hasErr = true
+		line -= bytes.Count(b[i:], []byte{'\n'})
+		v.addErr(line, v.xpath(nil, 0), specerr.ErrSchemaText, f.name.Local)
+	}
+}
+
+// addChild records a child element and returns its index among the siblings with the same name.
+func (f *frame) addChild(name xml.Name, line int) int {
+	var index int
+	for i := range f.runs {
+		if f.runs[i].name == name {
+			index += f.runs[i].count
+		}
+	}
+	if n := len(f.runs); n > 0 {
+		r := &f.runs[n-1]
+		if r.name == name && (r.count == 1 || line == r.lineOf(r.count)) {
+			if r.count == 1 {
+				r.step = line - r.line
+			}
+			r.count++
+			return index
+		}
+	}
+	f.runs = append(f.runs, run{name: name, count: 1, line: line})
+	return index
+}
+
+func (r *run) lineOf(i int) int {
+	return r.line + i*r.step
+}
+
+// lookup returns the declaration of the element name allowed by p, if any,
+// and whether the element can only occur once in its parent.
+func (s *Schema) lookup(p *particle, name xml.Name, single bool) (*elementDecl, bool) {
+	single = single && p.max == 1
+	switch p.kind {
+	case particleElement:
+		if p.elem.name == name {
+			return p.elem, single
+		}
+	case particleAny:
+		if !p.skip && p.matches(name) {
+			return s.elements[name], false
+		}
+	default:
+		for _, c := range p.children {
+			if e, single := s.lookup(c, name, single); e != nil {
+				return e, single
+			}
+		}
+	}
+	return nil, false
+}
+
+func (p *particle) matches(name xml.Name) bool {
+	switch p.kind {
+	case particleElement:
+		return p.elem.name == name
+	case particleAny:
+		return p.space == "" || (name.Space != "" && name.Space != p.space)
+	}
+	return false
+}
+
+func (v *Validator) validateAttrs(ct *complexType, name xml.Name, attrs []xml3mf.XMLAttr, line int) {
+	for _, a := range attrs {
+		switch {
+		case a.Name.Space == "xmlns", a.Name.Space == "" && a.Name.Local == "xmlns":
+			continue
+		case a.Name.Space == nsXML && a.Name.Local == "lang" && ct.lang:
+			continue
+		}
+		decl := ct.attr(a.Name)
+		if decl == nil && a.Name.Space != "" && a.Name.Space != name.Space && ct.anyAttr {
+			if decl = v.schema.attrs[a.Name]; decl == nil {
+				continue
+			}
+		}
+		if decl == nil {
+			v.addErr(line, v.xpath(nil, 0), specerr.ErrSchemaAttr, a.Name.Local)
+		} else if !decl.typ.validBytes(a.Value) {
+			v.addErr(line, v.xpath(nil, 0), specerr.ErrSchemaAttrValue, fmt.Sprintf("%s=%q", a.Name.Local, a.Value))
+		}
+	}
+	for _, decl := range ct.attrs {
+		if !decl.required {
+			continue
+		}
+		var found bool
+		for _, a := range attrs {
+			if a.Name == decl.name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.addErr(line, v.xpath(nil, 0), specerr.ErrSchemaMissingAttr, decl.name.Local)
+		}
+	}
+}
+
+func (ct *complexType) attr(name xml.Name) *attrDecl {
+	for _, a := range ct.attrs {
+		if a.name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func (st *simpleType) validBytes(b []byte) bool {
+	if st.base == builtinString && st.list == nil && st.union == nil && len(st.enum) == 0 && len(st.patterns) == 0 {
+		return true
+	}
+	return st.valid(string(b))
+}
+
+func (v *Validator) validateContent(f *frame, line int) {
+	content := f.typ.content
+	if content == nil {
+		content = &particle{kind: particleSequence, min: 1, max: 1}
+	}
+	runs := f.runs
+	if v.schema.interleaved[f.name] {
+		runs = make([]run, 0, len(f.runs))
+		for _, r := range f.runs {
+			if r.name.Space == "" || r.name.Space == f.name.Space {
+				runs = append(runs, r)
+			}
+		}
+	}
+	m := newMatcher(runs)
+	if m.next(content, posSet{{0, 0}}).contains(m.n) {
+		return
+	}
+	if m.far < m.n {
+		k := m.runAt(m.far)
+		r := runs[k]
+		index := m.far - m.starts[k]
+		line := r.lineOf(index)
+		for i := 0; i < k; i++ {
+			if runs[i].name == r.name {
+				index += runs[i].count
+			}
+		}
+		if _, single := v.schema.lookup(content, r.name, true); single {
+			index = -1
+		}
+		v.addErr(line, v.xpath(&r.name, index), specerr.ErrSchemaElement, r.name.Local)
+	} else {
+		v.addErr(line, v.xpath(nil, 0), specerr.ErrSchemaMissingElement, f.name.Local)
+	}
+}
+
+// posSet is a sorted list of disjoint intervals of child positions.
+type posSet []interval
+
+type interval struct{ lo, hi int }
+
+func (s posSet) contains(i int) bool {
+	for _, in := range s {
+		if in.lo <= i && i <= in.hi {
+			return true
+		}
+	}
+	return false
+}
+
+func (s posSet) union(o posSet) posSet {
+	if len(o) == 0 {
+		return s
+	}
+	all := make(posSet, 0, len(s)+len(o))
+	all = append(append(all, s...), o...)
+	sort.Slice(all, func(i, j int) bool { return all[i].lo < all[j].lo })
+	out := all[:1]
+	for _, in := range all[1:] {
+		last := &out[len(out)-1]
+		if in.lo <= last.hi+1 {
+			if in.hi > last.hi {
+				last.hi = in.hi
+			}
+		} else {
+			out = append(out, in)
+		}
+	}
+	return out
+}
+
+// minus returns the positions of s which are not in o.
+func (s posSet) minus(o posSet) posSet {
+	var out posSet
+	for _, in := range s {
+		lo := in.lo
+		for _, x := range o {
+			if x.hi < lo || x.lo > in.hi {
+				continue
+			}
+			if x.lo > lo {
+				out = append(out, interval{lo, x.lo - 1})
+			}
+			lo = x.hi + 1
+		}
+		if lo <= in.hi {
+			out = append(out, interval{lo, in.hi})
+		}
+	}
+	return out
+}
+
+// matcher computes the child positions reachable by the particles.
+// The children are grouped in runs of consecutive elements with the same name.
+type matcher struct {
+	runs   []run
+	starts []int
+	n      int
+	far    int
+}
+
+func newMatcher(runs []run) *matcher {
+	m := &matcher{runs: runs, starts: make([]int, len(runs))}
+	for i, r := range runs {
+		m.starts[i] = m.n
+		m.n += r.count
+	}
+	return m
+}
+
+func (m *matcher) runAt(i int) int {
+	return sort.Search(len(m.starts), func(k int) bool { return m.starts[k] > i }) - 1
+}
+
+func (m *matcher) reached(s posSet) posSet {
+	if len(s) > 0 && s[len(s)-1].hi > m.far {
+		m.far = s[len(s)-1].hi
+	}
+	return s
+}
+
+// next returns the positions reachable from the positions in from
+// after matching p between its minimum and maximum occurrences.
+func (m *matcher) next(p *particle, from posSet) posSet {
+	if p.kind == particleElement || p.kind == particleAny {
+		return m.reached(m.nextLeaf(p, from))
+	}
+	cur := from
+	for i := 0; i < p.min && len(cur) > 0; i++ {
+		cur = m.once(p, cur)
+	}
+	seen := cur
+	for i := p.min; len(cur) > 0 && (p.max == unbounded || i < p.max); i++ {
+		cur = m.once(p, cur).minus(seen)
+		seen = seen.union(cur)
+	}
+	return m.reached(seen)
+}
+
+func (m *matcher) once(p *particle, from posSet) posSet {
+	if p.kind == particleSequence {
+		for _, c := range p.children {
+			if from = m.next(c, from); len(from) == 0 {
+				break
+			}
+		}
+		return from
+	}
+	var out posSet
+	for _, c := range p.children {
+		out = out.union(m.next(c, from))
+	}
+	return out
+}
+
+// nextLeaf matches an element or wildcard particle.
+// Consecutive matching runs form a chain, and from a start position i
+// in a chain that ends at r the positions i+min to min(i+max, r) are reachable.
+func (m *matcher) nextLeaf(p *particle, from posSet) posSet {
+	var out posSet
+	if p.min == 0 {
+		out = append(out, from...)
+	}
+	for _, in := range from {
+		for i := in.lo; i <= in.hi && i < m.n; {
+			k := m.runAt(i)
+			if !p.matches(m.runs[k].name) {
+				i = m.starts[k] + m.runs[k].count
+				continue
+			}
+			r := m.starts[k] + m.runs[k].count
+			for k++; k < len(m.runs) && p.matches(m.runs[k].name); k++ {
+				r += m.runs[k].count
+			}
+			last := in.hi
+			if last > r-p.min {
+				last = r - p.min
+			}
+			if p.min == 0 && last >= r {
+				last = r - 1
+			}
+			if i <= last {
+				hi := r
+				if p.max != unbounded && last+p.max < r {
+					hi = last + p.max
+				}
+				lo := i + p.min
+				if p.min == 0 {
+					lo = i + 1
+				}
+				out = out.union(posSet{{lo, hi}})
+			} else if r > m.far {
+				// The chain has less than min elements.
+				m.far = r
+			}
+			i = r
+		}
+	}
+	return out
+}
@@ -0,0 +1,216 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package xsd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+	specerr "github.com/hpinc/go3mf/errors"
+	xml3mf "github.com/hpinc/go3mf/internal/xml"
+)
+
+var testSchema = []byte(`<xs:schema xmlns="http://example.com/test" xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/test">
+	<xs:complexType name="CT_Root">
+		<xs:sequence>
+			<xs:element ref="meta" minOccurs="0" maxOccurs="unbounded"/>
+			<xs:element ref="items"/>
+			<xs:choice minOccurs="0">
+				<xs:element ref="a"/>
+				<xs:element ref="b"/>
+			</xs:choice>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="unbounded"/>
+		</xs:sequence>
+		<xs:attribute name="unit" type="ST_Unit"/>
+		<xs:attribute ref="xml:lang"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Items">
+		<xs:sequence>
+			<xs:element ref="item" minOccurs="2" maxOccurs="3"/>
+		</xs:sequence>
+	</xs:complexType>
+	<xs:complexType name="CT_Item">
+		<xs:attribute name="id" type="ST_ID" use="required"/>
+		<xs:attribute name="values" type="ST_Numbers"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Meta">
+		<xs:simpleContent>
+			<xs:extension base="xs:string">
+				<xs:attribute name="name" type="xs:string" use="required"/>
+			</xs:extension>
+		</xs:simpleContent>
+	</xs:complexType>
+	<xs:simpleType name="ST_Unit">
+		<xs:restriction base="xs:string">
+			<xs:enumeration value="mm"/>
+			<xs:enumeration value="cm"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_ID">
+		<xs:restriction base="xs:positiveInteger">
+			<xs:maxExclusive value="100"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_Numbers">
+		<xs:list itemType="xs:double"/>
+	</xs:simpleType>
+	<xs:element name="root" type="CT_Root"/>
+	<xs:element name="items" type="CT_Items"/>
+	<xs:element name="item" type="CT_Item"/>
+	<xs:element name="meta" type="CT_Meta"/>
+	<xs:element name="a"/>
+	<xs:element name="b"/>
+</xs:schema>`)
+
+var otherSchema = []byte(`<xs:schema xmlns="http://example.com/other" xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/other">
+	<xs:complexType name="CT_Ext">
+		<xs:sequence>
+			<xs:element ref="child" maxOccurs="unbounded"/>
+		</xs:sequence>
+	</xs:complexType>
+	<xs:element name="ext" type="CT_Ext"/>
+	<xs:element name="child"/>
+	<xs:attribute name="flag" type="xs:boolean"/>
+</xs:schema>`)
+
+func validate(t *testing.T, doc string, interleaved ...xml.Name) []string {
+	s, err := Parse(testSchema, otherSchema)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for _, name := range interleaved {
+		s.Interleave(name)
+	}
+	v := s.NewValidator()
+	x := xml3mf.NewDecoder(bytes.NewBufferString(doc))
+	x.OnStart = func(tp xml3mf.StartElement) {
+		v.Start(tp.Name, tp.Attr, x.InputLine())
+	}
+	x.OnEnd = func(_ xml.EndElement) {
+		v.End(x.InputLine())
+	}
+	x.OnChar = func(tp xml.CharData) {
+		v.CharData(tp, x.InputLine())
+	}
+	for x.RawToken() == nil {
+	}
+	var errs []string
+	for _, err := range v.Errors() {
+		errs = append(errs, err.Error())
+	}
+	return errs
+}
+
+func TestValidator(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"valid", `<root xmlns="http://example.com/test" xmlns:o="http://example.com/other" unit="mm" xml:lang="en" o:flag="true">
+			<meta name="a">text</meta>
+			<meta name="b"/>
+			<items><item id="1" values="1 2.5"/><item id="2"/></items>
+			<b/>
+			<o:ext><o:child/><o:child/></o:ext>
+			<o:unknown><skipped foo="bar"/></o:unknown>
+		</root>`, nil},
+		{"unknownRoot", `<foo xmlns="http://example.com/test"/>`, []string{
+			fmt.Sprintf("go3mf: Path:  Line: 1 XPath: /foo: %v: foo", specerr.ErrSchemaElement),
+		}},
+		{"attributes", `<root xmlns="http://example.com/test" xmlns:o="http://example.com/other" unit="km" foo="bar" o:flag="2" o:other="1">
+			<items>
+				<item values="1 a"/>
+				<item id="100"/>
+			</items>
+		</root>`, []string{
+			fmt.Sprintf(`go3mf: Path:  Line: 1 XPath: /root: %v: unit="km"`, specerr.ErrSchemaAttrValue),
+			fmt.Sprintf("go3mf: Path:  Line: 1 XPath: /root: %v: foo", specerr.ErrSchemaAttr),
+			fmt.Sprintf(`go3mf: Path:  Line: 1 XPath: /root: %v: flag="2"`, specerr.ErrSchemaAttrValue),
+			fmt.Sprintf(`go3mf: Path:  Line: 3 XPath: /root/items/item[0]: %v: values="1 a"`, specerr.ErrSchemaAttrValue),
+			fmt.Sprintf("go3mf: Path:  Line: 3 XPath: /root/items/item[0]: %v: id", specerr.ErrSchemaMissingAttr),
+			fmt.Sprintf(`go3mf: Path:  Line: 4 XPath: /root/items/item[1]: %v: id="100"`, specerr.ErrSchemaAttrValue),
+		}},
+		{"order", `<root xmlns="http://example.com/test">
+			<items><item id="1"/><item id="2"/></items>
+			<meta name="a"/>
+		</root>`, []string{
+			fmt.Sprintf("go3mf: Path:  Line: 3 XPath: /root/meta[0]: %v: meta", specerr.ErrSchemaElement),
+		}},
+		{"choice", `<root xmlns="http://example.com/test">
+			<items><item id="1"/><item id="2"/></items>
+			<a/>
+			<b/>
+		</root>`, []string{
+			fmt.Sprintf("go3mf: Path:  Line: 4 XPath: /root/b: %v: b", specerr.ErrSchemaElement),
+		}},
+		{"occurs", `<root xmlns="http://example.com/test">
+			<items><item id="1"/></items>
+			<items>
+				<item id="1"/><item id="2"/><item id="3"/>
+				<item id="4"/>
+			</items>
+		</root>`, []string{
+			fmt.Sprintf("go3mf: Path:  Line: 2 XPath: /root/items: %v: items", specerr.ErrSchemaMissingElement),
+			fmt.Sprintf("go3mf: Path:  Line: 5 XPath: /root/items/item[3]: %v: item", specerr.ErrSchemaElement),
+			fmt.Sprintf("go3mf: Path:  Line: 3 XPath: /root/items: %v: items", specerr.ErrSchemaElement),
+		}},
+		{"missing", `<root xmlns="http://example.com/test" xmlns:o="http://example.com/other">
+			<o:ext></o:ext>
+		</root>`, []string{
+			fmt.Sprintf("go3mf: Path:  Line: 2 XPath: /root/ext[0]: %v: ext", specerr.ErrSchemaMissingElement),
+			fmt.Sprintf("go3mf: Path:  Line: 2 XPath: /root/ext[0]: %v: ext", specerr.ErrSchemaElement),
+		}},
+		{"text", `<root xmlns="http://example.com/test">
+			<items>
+				a<item id="1"/>b<item id="2"/>
+			</items>
+		</root>`, []string{
+			fmt.Sprintf("go3mf: Path:  Line: 3 XPath: /root/items: %v: items", specerr.ErrSchemaText),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(validate(t, tt.doc), tt.want); diff != nil {
+				t.Errorf("Validator.Errors() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestSchema_Interleave(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"valid", `<root xmlns="http://example.com/test" xmlns:o="http://example.com/other">
+			<o:ext><o:child/></o:ext>
+			<meta name="a"/>
+			<o:unknown/>
+			<items><item id="1"/><item id="2"/></items>
+		</root>`, nil},
+		{"order", `<root xmlns="http://example.com/test" xmlns:o="http://example.com/other">
+			<o:ext><o:child/></o:ext>
+			<items><item id="1"/><item id="2"/></items>
+			<o:ext></o:ext>
+			<meta name="a"/>
+		</root>`, []string{
+			fmt.Sprintf("go3mf: Path:  Line: 4 XPath: /root/ext[1]: %v: ext", specerr.ErrSchemaMissingElement),
+			fmt.Sprintf("go3mf: Path:  Line: 5 XPath: /root/meta[0]: %v: meta", specerr.ErrSchemaElement),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validate(t, tt.doc, xml.Name{Space: "http://example.com/test", Local: "root"})
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Validator.Errors() = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,853 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/hpinc/go3mf/spec"
+)
+
+// Errors returned when decoding malformed JSON.
+var (
+	ErrJSONTokens = errors.New("go3mf: unbalanced xml tokens")
+	ErrJSONHook   = errors.New("go3mf: spec does not support json")
+)
+
+type jsonModel struct {
+	Path              string                `json:"path,omitempty"`
+	Language          string                `json:"language,omitempty"`
+	Units             string                `json:"units"`
+	Thumbnail         string                `json:"thumbnail,omitempty"`
+	Extensions        []jsonExtension       `json:"extensions,omitempty"`
+	Metadata          []jsonMetadata        `json:"metadata,omitempty"`
+	Resources         jsonResources         `json:"resources"`
+	Build             jsonBuild             `json:"build"`
+	Childs            map[string]*jsonChild `json:"childs,omitempty"`
+	Attachments       []jsonAttachment      `json:"attachments,omitempty"`
+	RootRelationships []jsonRelationship    `json:"rootRelationships,omitempty"`
+	Relationships     []jsonRelationship    `json:"relationships,omitempty"`
+	Any               []jsonElement         `json:"any,omitempty"`
+	AnyAttr           []jsonAttrGroup       `json:"anyAttr,omitempty"`
+}
+
+type jsonExtension struct {
+	Namespace  string `json:"namespace"`
+	LocalName  string `json:"localName"`
+	IsRequired bool   `json:"required,omitempty"`
+}
+
+type jsonMetadata struct {
+	Space    string `json:"space,omitempty"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Type     string `json:"type,omitempty"`
+	Preserve bool   `json:"preserve,omitempty"`
+}
+
+type jsonMetadataGroup struct {
+	Metadata []jsonMetadata  `json:"metadata,omitempty"`
+	AnyAttr  []jsonAttrGroup `json:"anyAttr,omitempty"`
+}
+
+type jsonAttachment struct {
+	Path        string `json:"path"`
+	ContentType string `json:"contentType"`
+}
+
+type jsonRelationship struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+}
+
+type jsonChild struct {
+	Resources     jsonResources      `json:"resources"`
+	Relationships []jsonRelationship `json:"relationships,omitempty"`
+	Any           []jsonElement      `json:"any,omitempty"`
+}
+
+type jsonResources struct {
+	Assets  []jsonAsset     `json:"assets,omitempty"`
+	Objects []jsonObject    `json:"objects,omitempty"`
+	AnyAttr []jsonAttrGroup `json:"anyAttr,omitempty"`
+}
+
+// jsonAsset contains either core base materials or an extension asset.
+type jsonAsset struct {
+	BaseMaterials *jsonBaseMaterials `json:"baseMaterials,omitempty"`
+	Element       *jsonElement       `json:"element,omitempty"`
+}
+
+type jsonBaseMaterials struct {
+	ID        uint32          `json:"id"`
+	Materials []jsonBase      `json:"materials,omitempty"`
+	AnyAttr   []jsonAttrGroup `json:"anyAttr,omitempty"`
+}
+
+type jsonBase struct {
+	Name    string          `json:"name"`
+	Color   string          `json:"color"`
+	AnyAttr []jsonAttrGroup `json:"anyAttr,omitempty"`
+}
+
+type jsonObject struct {
+	ID         uint32             `json:"id"`
+	Name       string             `json:"name,omitempty"`
+	PartNumber string             `json:"partNumber,omitempty"`
+	Thumbnail  string             `json:"thumbnail,omitempty"`
+	PID        uint32             `json:"pid,omitempty"`
+	PIndex     uint32             `json:"pindex,omitempty"`
+	Type       string             `json:"type"`
+	Metadata   *jsonMetadataGroup `json:"metadata,omitempty"`
+	Mesh       *jsonMesh          `json:"mesh,omitempty"`
+	Components *jsonComponents    `json:"components,omitempty"`
+	AnyAttr    []jsonAttrGroup    `json:"anyAttr,omitempty"`
+}
+
+type jsonMesh struct {
+	Vertices  jsonVertices    `json:"vertices"`
+	Triangles jsonTriangles   `json:"triangles"`
+	AnyAttr   []jsonAttrGroup `json:"anyAttr,omitempty"`
+	Any       []jsonElement   `json:"any,omitempty"`
+}
+
+type jsonVertices struct {
+	Vertex  []Point3D       `json:"vertex,omitempty"`
+	AnyAttr []jsonAttrGroup `json:"anyAttr,omitempty"`
+}
+
+// jsonTriangles encodes each triangle as [v1,v2,v3] or [v1,v2,v3,pid,p1,p2,p3]
+// and the triangle attributes by triangle index.
+type jsonTriangles struct {
+	Triangle        [][]uint32              `json:"triangle,omitempty"`
+	AnyAttr         []jsonAttrGroup         `json:"anyAttr,omitempty"`
+	TriangleAnyAttr map[int][]jsonAttrGroup `json:"triangleAnyAttr,omitempty"`
+}
+
+type jsonComponents struct {
+	Component []jsonComponent `json:"component,omitempty"`
+	AnyAttr   []jsonAttrGroup `json:"anyAttr,omitempty"`
+}
+
+type jsonComponent struct {
+	ObjectID  uint32          `json:"objectId"`
+	Transform []float32       `json:"transform,omitempty"`
+	AnyAttr   []jsonAttrGroup `json:"anyAttr,omitempty"`
+}
+
+type jsonBuild struct {
+	Items   []jsonItem      `json:"items,omitempty"`
+	AnyAttr []jsonAttrGroup `json:"anyAttr,omitempty"`
+}
+
+type jsonItem struct {
+	ObjectID   uint32             `json:"objectId"`
+	Transform  []float32          `json:"transform,omitempty"`
+	PartNumber string             `json:"partNumber,omitempty"`
+	Metadata   *jsonMetadataGroup `json:"metadata,omitempty"`
+	AnyAttr    []jsonAttrGroup    `json:"anyAttr,omitempty"`
+}
+
+// jsonAttrGroup contains the attributes of a namespace,
+// either as the spec JSON or as raw xml attributes.
+type jsonAttrGroup struct {
+	Namespace string          `json:"namespace"`
+	JSON      json.RawMessage `json:"json,omitempty"`
+	Attr      []jsonAttr      `json:"attr,omitempty"`
+}
+
+// jsonElement contains an extension element,
+// either as the spec JSON or as raw xml tokens.
+type jsonElement struct {
+	Space string          `json:"space,omitempty"`
+	Local string          `json:"local,omitempty"`
+	JSON  json.RawMessage `json:"json,omitempty"`
+	XML   []jsonToken     `json:"xml,omitempty"`
+}
+
+type jsonName struct {
+	Space string `json:"space,omitempty"`
+	Local string `json:"local"`
+}
+
+type jsonAttr struct {
+	Space string `json:"space,omitempty"`
+	Local string `json:"local"`
+	Value string `json:"value"`
+}
+
+// jsonToken contains one of a start element, with its attributes,
+// an end element or a text.
+type jsonToken struct {
+	Start *jsonName  `json:"start,omitempty"`
+	Attr  []jsonAttr `json:"attr,omitempty"`
+	End   *jsonName  `json:"end,omitempty"`
+	Text  *string    `json:"text,omitempty"`
+}
+
+// MarshalJSON returns the JSON encoding of m.
+//
+// The core elements are encoded as JSON objects. The extension elements and attributes
+// are encoded using their MarshalJSON method if they implement json.Marshaler,
+// else as the raw XML tokens returned by Marshal3MF.
+// The attachments are encoded by reference, without their content.
+func (m *Model) MarshalJSON() ([]byte, error) {
+	jm := jsonModel{
+		Path:      m.Path,
+		Language:  m.Language,
+		Units:     m.Units.String(),
+		Thumbnail: m.Thumbnail,
+		Metadata:  marshalMetadata(m.Metadata),
+	}
+	var err error
+	for _, ext := range m.Extensions {
+		jm.Extensions = append(jm.Extensions, jsonExtension(ext))
+	}
+	if jm.Resources, err = marshalResources(&m.Resources); err != nil {
+		return nil, err
+	}
+	if jm.Build.AnyAttr, err = marshalAnyAttr(m.Build.AnyAttr); err != nil {
+		return nil, err
+	}
+	for _, item := range m.Build.Items {
+		ji := jsonItem{ObjectID: item.ObjectID, Transform: marshalMatrix(item.Transform), PartNumber: item.PartNumber}
+		if ji.Metadata, err = marshalMetadataGroup(item.Metadata); err != nil {
+			return nil, err
+		}
+		if ji.AnyAttr, err = marshalAnyAttr(item.AnyAttr); err != nil {
+			return nil, err
+		}
+		jm.Build.Items = append(jm.Build.Items, ji)
+	}
+	for path, c := range m.Childs {
+		if jm.Childs == nil {
+			jm.Childs = make(map[string]*jsonChild, len(m.Childs))
+		}
+		jc := &jsonChild{Relationships: marshalRelationships(c.Relationships)}
+		if jc.Resources, err = marshalResources(&c.Resources); err != nil {
+			return nil, err
+		}
+		if jc.Any, err = marshalAny(c.Any); err != nil {
+			return nil, err
+		}
+		jm.Childs[path] = jc
+	}
+	for _, a := range m.Attachments {
+		jm.Attachments = append(jm.Attachments, jsonAttachment{Path: a.Path, ContentType: a.ContentType})
+	}
+	jm.RootRelationships = marshalRelationships(m.RootRelationships)
+	jm.Relationships = marshalRelationships(m.Relationships)
+	if jm.Any, err = marshalAny(m.Any); err != nil {
+		return nil, err
+	}
+	if jm.AnyAttr, err = marshalAnyAttr(m.AnyAttr); err != nil {
+		return nil, err
+	}
+	return json.Marshal(&jm)
+}
+
+// UnmarshalJSON decodes the JSON encoding of a model into m.
+//
+// The extension elements and attributes encoded as JSON are decoded
+// into the values returned by the registered specs.
+// The attachments are decoded without a Stream, which must be set before encoding m.
+func (m *Model) UnmarshalJSON(data []byte) error {
+	var jm jsonModel
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return err
+	}
+	*m = Model{
+		Path:      jm.Path,
+		Language:  jm.Language,
+		Thumbnail: jm.Thumbnail,
+		Metadata:  unmarshalMetadata(jm.Metadata),
+	}
+	var (
+		ok  bool
+		err error
+	)
+	if jm.Units != "" {
+		if m.Units, ok = newUnits(jm.Units); !ok {
+			return fmt.Errorf("go3mf: invalid units %s", jm.Units)
+		}
+	}
+	for _, ext := range jm.Extensions {
+		m.Extensions = append(m.Extensions, Extension(ext))
+	}
+	if err = unmarshalResources(m, &jm.Resources, &m.Resources); err != nil {
+		return err
+	}
+	if m.Build.AnyAttr, err = unmarshalAnyAttr(jm.Build.AnyAttr, attrBuild); err != nil {
+		return err
+	}
+	for _, ji := range jm.Build.Items {
+		item := &Item{ObjectID: ji.ObjectID, PartNumber: ji.PartNumber}
+		if item.Transform, err = unmarshalMatrix(ji.Transform); err != nil {
+			return err
+		}
+		if item.Metadata, err = unmarshalMetadataGroup(ji.Metadata); err != nil {
+			return err
+		}
+		if item.AnyAttr, err = unmarshalAnyAttr(ji.AnyAttr, attrItem); err != nil {
+			return err
+		}
+		m.Build.Items = append(m.Build.Items, item)
+	}
+	for path, jc := range jm.Childs {
+		if m.Childs == nil {
+			m.Childs = make(map[string]*ChildModel, len(jm.Childs))
+		}
+		c := &ChildModel{Relationships: unmarshalRelationships(jc.Relationships)}
+		if err = unmarshalResources(m, &jc.Resources, &c.Resources); err != nil {
+			return err
+		}
+		if c.Any, err = unmarshalAny(jc.Any); err != nil {
+			return err
+		}
+		m.Childs[path] = c
+	}
+	for _, a := range jm.Attachments {
+		m.Attachments = append(m.Attachments, Attachment{Path: a.Path, ContentType: a.ContentType})
+	}
+	m.RootRelationships = unmarshalRelationships(jm.RootRelationships)
+	m.Relationships = unmarshalRelationships(jm.Relationships)
+	if m.Any, err = unmarshalAny(jm.Any); err != nil {
+		return err
+	}
+	m.AnyAttr, err = unmarshalAnyAttr(jm.AnyAttr, attrModel)
+	return err
+}
+
+func marshalMetadata(md []Metadata) []jsonMetadata {
+	var jmd []jsonMetadata
+	for _, m := range md {
+		jmd = append(jmd, jsonMetadata{Space: m.Name.Space, Name: m.Name.Local, Value: m.Value, Type: m.Type, Preserve: m.Preserve})
+	}
+	return jmd
+}
+
+func unmarshalMetadata(jmd []jsonMetadata) []Metadata {
+	var md []Metadata
+	for _, m := range jmd {
+		md = append(md, Metadata{Name: xml.Name{Space: m.Space, Local: m.Name}, Value: m.Value, Type: m.Type, Preserve: m.Preserve})
+	}
+	return md
+}
+
+func marshalMetadataGroup(mg MetadataGroup) (*jsonMetadataGroup, error) {
+	if mg.Metadata == nil && mg.AnyAttr == nil {
+		return nil, nil
+	}
+	anyAttr, err := marshalAnyAttr(mg.AnyAttr)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonMetadataGroup{Metadata: marshalMetadata(mg.Metadata), AnyAttr: anyAttr}, nil
+}
+
+func unmarshalMetadataGroup(jmg *jsonMetadataGroup) (mg MetadataGroup, err error) {
+	if jmg == nil {
+		return
+	}
+	mg.Metadata = unmarshalMetadata(jmg.Metadata)
+	mg.AnyAttr, err = unmarshalAnyAttr(jmg.AnyAttr, attrMetadataGroup)
+	return
+}
+
+func marshalRelationships(rels []Relationship) []jsonRelationship {
+	var jrels []jsonRelationship
+	for _, r := range rels {
+		jrels = append(jrels, jsonRelationship(r))
+	}
+	return jrels
+}
+
+func unmarshalRelationships(jrels []jsonRelationship) []Relationship {
+	var rels []Relationship
+	for _, r := range jrels {
+		rels = append(rels, Relationship(r))
+	}
+	return rels
+}
+
+// marshalMatrix returns the 12 values of the matrix, as in the transform attribute,
+// or nil if the matrix is zero.
+func marshalMatrix(t Matrix) []float32 {
+	if t == (Matrix{}) {
+		return nil
+	}
+	return []float32{t[0], t[1], t[2], t[4], t[5], t[6], t[8], t[9], t[10], t[12], t[13], t[14]}
+}
+
+func unmarshalMatrix(v []float32) (Matrix, error) {
+	switch len(v) {
+	case 0:
+		return Matrix{}, nil
+	case 12:
+		return Matrix{v[0], v[1], v[2], 0, v[3], v[4], v[5], 0, v[6], v[7], v[8], 0, v[9], v[10], v[11], 1}, nil
+	}
+	return Matrix{}, fmt.Errorf("go3mf: transform must have 12 values, got %d", len(v))
+}
+
+func marshalResources(rs *Resources) (jrs jsonResources, err error) {
+	if jrs.AnyAttr, err = marshalAnyAttr(rs.AnyAttr); err != nil {
+		return
+	}
+	for _, a := range rs.Assets {
+		var ja jsonAsset
+		if ja, err = marshalAsset(a); err != nil {
+			return
+		}
+		if ja.BaseMaterials != nil || ja.Element != nil {
+			jrs.Assets = append(jrs.Assets, ja)
+		}
+	}
+	for _, o := range rs.Objects {
+		var jo jsonObject
+		if jo, err = marshalObject(o); err != nil {
+			return
+		}
+		jrs.Objects = append(jrs.Objects, jo)
+	}
+	return
+}
+
+func unmarshalResources(m *Model, jrs *jsonResources, rs *Resources) (err error) {
+	if rs.AnyAttr, err = unmarshalAnyAttr(jrs.AnyAttr, attrResources); err != nil {
+		return
+	}
+	for i := range jrs.Assets {
+		var a Asset
+		if a, err = unmarshalAsset(m, &jrs.Assets[i]); err != nil {
+			return
+		}
+		rs.Assets = append(rs.Assets, a)
+	}
+	for i := range jrs.Objects {
+		var o *Object
+		if o, err = unmarshalObject(&jrs.Objects[i]); err != nil {
+			return
+		}
+		rs.Objects = append(rs.Objects, o)
+	}
+	return
+}
+
+func marshalAsset(a Asset) (ja jsonAsset, err error) {
+	if bm, ok := a.(*BaseMaterials); ok {
+		jbm := &jsonBaseMaterials{ID: bm.ID}
+		if jbm.AnyAttr, err = marshalAnyAttr(bm.AnyAttr); err != nil {
+			return
+		}
+		for _, b := range bm.Materials {
+			jb := jsonBase{Name: b.Name, Color: spec.FormatRGBA(b.Color)}
+			if jb.AnyAttr, err = marshalAnyAttr(b.AnyAttr); err != nil {
+				return
+			}
+			jbm.Materials = append(jbm.Materials, jb)
+		}
+		ja.BaseMaterials = jbm
+		return
+	}
+	// Assets that can't be marshaled are not encoded, as in the XML encoder.
+	if e, ok := a.(spec.Marshaler); ok {
+		var je jsonElement
+		je, err = marshalElement(e)
+		ja.Element = &je
+	}
+	return
+}
+
+func unmarshalAsset(m *Model, ja *jsonAsset) (Asset, error) {
+	if jbm := ja.BaseMaterials; jbm != nil {
+		bm := &BaseMaterials{ID: jbm.ID}
+		var err error
+		if bm.AnyAttr, err = unmarshalAnyAttr(jbm.AnyAttr, attrBaseMaterials); err != nil {
+			return nil, err
+		}
+		for _, jb := range jbm.Materials {
+			b := Base{Name: jb.Name}
+			if b.Color, err = spec.ParseRGBA(jb.Color); err != nil {
+				return nil, err
+			}
+			if b.AnyAttr, err = unmarshalAnyAttr(jb.AnyAttr, attrBase); err != nil {
+				return nil, err
+			}
+			bm.Materials = append(bm.Materials, b)
+		}
+		return bm, nil
+	}
+	if ja.Element == nil {
+		return nil, errors.New("go3mf: empty asset")
+	}
+	var rs Resources
+	v, err := unmarshalElement(ja.Element, &resourceDecoder{resources: &rs, model: m}, func() interface{} {
+		if len(rs.Assets) == 0 {
+			return nil
+		}
+		return rs.Assets[0]
+	})
+	if err != nil {
+		return nil, err
+	}
+	a, ok := v.(Asset)
+	if !ok {
+		return nil, fmt.Errorf("go3mf: %s:%s is not an asset", ja.Element.Space, ja.Element.Local)
+	}
+	return a, nil
+}
+
+func marshalObject(o *Object) (jo jsonObject, err error) {
+	jo = jsonObject{
+		ID: o.ID, Name: o.Name, PartNumber: o.PartNumber, Thumbnail: o.Thumbnail,
+		PID: o.PID, PIndex: o.PIndex, Type: o.Type.String(),
+	}
+	if jo.Metadata, err = marshalMetadataGroup(o.Metadata); err != nil {
+		return
+	}
+	if jo.AnyAttr, err = marshalAnyAttr(o.AnyAttr); err != nil {
+		return
+	}
+	if o.Mesh != nil {
+		if jo.Mesh, err = marshalMesh(o.Mesh); err != nil {
+			return
+		}
+	}
+	if o.Components != nil {
+		jo.Components = new(jsonComponents)
+		if jo.Components.AnyAttr, err = marshalAnyAttr(o.Components.AnyAttr); err != nil {
+			return
+		}
+		for _, c := range o.Components.Component {
+			jc := jsonComponent{ObjectID: c.ObjectID, Transform: marshalMatrix(c.Transform)}
+			if jc.AnyAttr, err = marshalAnyAttr(c.AnyAttr); err != nil {
+				return
+			}
+			jo.Components.Component = append(jo.Components.Component, jc)
+		}
+	}
+	return
+}
+
+func unmarshalObject(jo *jsonObject) (*Object, error) {
+	o := &Object{
+		ID: jo.ID, Name: jo.Name, PartNumber: jo.PartNumber, Thumbnail: jo.Thumbnail,
+		PID: jo.PID, PIndex: jo.PIndex,
+	}
+	var (
+		ok  bool
+		err error
+	)
+	if jo.Type != "" {
+		if o.Type, ok = newObjectType(jo.Type); !ok {
+			return nil, fmt.Errorf("go3mf: invalid object type %s", jo.Type)
+		}
+	}
+	if o.Metadata, err = unmarshalMetadataGroup(jo.Metadata); err != nil {
+		return nil, err
+	}
+	if o.AnyAttr, err = unmarshalAnyAttr(jo.AnyAttr, attrObject); err != nil {
+		return nil, err
+	}
+	if jo.Mesh != nil {
+		if o.Mesh, err = unmarshalMesh(jo.Mesh); err != nil {
+			return nil, err
+		}
+	}
+	if jcs := jo.Components; jcs != nil {
+		o.Components = new(Components)
+		if o.Components.AnyAttr, err = unmarshalAnyAttr(jcs.AnyAttr, attrComponents); err != nil {
+			return nil, err
+		}
+		for _, jc := range jcs.Component {
+			c := &Component{ObjectID: jc.ObjectID}
+			if c.Transform, err = unmarshalMatrix(jc.Transform); err != nil {
+				return nil, err
+			}
+			if c.AnyAttr, err = unmarshalAnyAttr(jc.AnyAttr, attrComponent); err != nil {
+				return nil, err
+			}
+			o.Components.Component = append(o.Components.Component, c)
+		}
+	}
+	return o, nil
+}
+
+func marshalMesh(m *Mesh) (jm *jsonMesh, err error) {
+	jm = &jsonMesh{Vertices: jsonVertices{Vertex: m.Vertices.Vertex}}
+	if jm.Vertices.AnyAttr, err = marshalAnyAttr(m.Vertices.AnyAttr); err != nil {
+		return
+	}
+	if jm.Triangles.AnyAttr, err = marshalAnyAttr(m.Triangles.AnyAttr); err != nil {
+		return
+	}
+	if len(m.Triangles.Triangle) > 0 {
+		jm.Triangles.Triangle = make([][]uint32, len(m.Triangles.Triangle))
+	}
+	for i, t := range m.Triangles.Triangle {
+		if t.PID == 0 && t.P1 == 0 && t.P2 == 0 && t.P3 == 0 {
+			jm.Triangles.Triangle[i] = []uint32{t.V1, t.V2, t.V3}
+		} else {
+			jm.Triangles.Triangle[i] = []uint32{t.V1, t.V2, t.V3, t.PID, t.P1, t.P2, t.P3}
+		}
+		if t.AnyAttr != nil {
+			if jm.Triangles.TriangleAnyAttr == nil {
+				jm.Triangles.TriangleAnyAttr = make(map[int][]jsonAttrGroup)
+			}
+			if jm.Triangles.TriangleAnyAttr[i], err = marshalAnyAttr(t.AnyAttr); err != nil {
+				return
+			}
+		}
+	}
+	if jm.AnyAttr, err = marshalAnyAttr(m.AnyAttr); err != nil {
+		return
+	}
+	jm.Any, err = marshalAny(m.Any)
+	return
+}
+
+func unmarshalMesh(jm *jsonMesh) (m *Mesh, err error) {
+	m = &Mesh{Vertices: Vertices{Vertex: jm.Vertices.Vertex}}
+	if m.Vertices.AnyAttr, err = unmarshalAnyAttr(jm.Vertices.AnyAttr, attrVertices); err != nil {
+		return
+	}
+	if m.Triangles.AnyAttr, err = unmarshalAnyAttr(jm.Triangles.AnyAttr, attrTriangles); err != nil {
+		return
+	}
+	if len(jm.Triangles.Triangle) > 0 {
+		m.Triangles.Triangle = make([]Triangle, len(jm.Triangles.Triangle))
+	}
+	for i, v := range jm.Triangles.Triangle {
+		t := &m.Triangles.Triangle[i]
+		switch len(v) {
+		case 7:
+			t.PID, t.P1, t.P2, t.P3 = v[3], v[4], v[5], v[6]
+			fallthrough
+		case 3:
+			t.V1, t.V2, t.V3 = v[0], v[1], v[2]
+		default:
+			return nil, fmt.Errorf("go3mf: triangle must have 3 or 7 values, got %d", len(v))
+		}
+	}
+	for i, attrs := range jm.Triangles.TriangleAnyAttr {
+		if i < 0 || i >= len(m.Triangles.Triangle) {
+			return nil, fmt.Errorf("go3mf: triangle attributes index %d out of bounds", i)
+		}
+		if m.Triangles.Triangle[i].AnyAttr, err = unmarshalAnyAttr(attrs, attrTriangle); err != nil {
+			return
+		}
+	}
+	if m.AnyAttr, err = unmarshalAnyAttr(jm.AnyAttr, attrMesh); err != nil {
+		return
+	}
+	m.Any, err = unmarshalAny(jm.Any)
+	return
+}
+
+func marshalAnyAttr(attrs spec.AnyAttr) ([]jsonAttrGroup, error) {
+	var jattrs []jsonAttrGroup
+	for _, a := range attrs {
+		ja := jsonAttrGroup{Namespace: a.Namespace()}
+		if jm, ok := a.(json.Marshaler); ok {
+			b, err := jm.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			ja.JSON = b
+		} else {
+			var start xml.StartElement
+			if err := a.Marshal3MF(new(tokenRecorder), &start); err != nil {
+				return nil, err
+			}
+			for _, att := range start.Attr {
+				ja.Attr = append(ja.Attr, jsonAttr{Space: att.Name.Space, Local: att.Name.Local, Value: att.Value})
+			}
+		}
+		jattrs = append(jattrs, ja)
+	}
+	return jattrs, nil
+}
+
+func unmarshalAnyAttr(jattrs []jsonAttrGroup, parent string) (spec.AnyAttr, error) {
+	var attrs spec.AnyAttr
+	for _, ja := range jattrs {
+		a := spec.NewAttrGroup(ja.Namespace, xml.Name{Space: Namespace, Local: parent})
+		if ja.JSON != nil {
+			ju, ok := a.(json.Unmarshaler)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrJSONHook, ja.Namespace)
+			}
+			if err := ju.UnmarshalJSON(ja.JSON); err != nil {
+				return nil, err
+			}
+		}
+		for _, att := range ja.Attr {
+			if err := a.Unmarshal3MFAttr(spec.XMLAttr{Name: xml.Name{Space: att.Space, Local: att.Local}, Value: []byte(att.Value)}); err != nil {
+				return nil, err
+			}
+		}
+		attrs = append(attrs, a)
+	}
+	return attrs, nil
+}
+
+func marshalAny(any spec.Any) ([]jsonElement, error) {
+	var jany []jsonElement
+	for _, e := range any {
+		je, err := marshalElement(e)
+		if err != nil {
+			return nil, err
+		}
+		jany = append(jany, je)
+	}
+	return jany, nil
+}
+
+func unmarshalAny(jany []jsonElement) (spec.Any, error) {
+	var any spec.Any
+	for i := range jany {
+		var d anyDecoder
+		v, err := unmarshalElement(&jany[i], &d, func() interface{} { return d.element })
+		if err != nil {
+			return nil, err
+		}
+		e, ok := v.(spec.Marshaler)
+		if !ok {
+			return nil, fmt.Errorf("go3mf: %s:%s is not a marshaler", jany[i].Space, jany[i].Local)
+		}
+		any = append(any, e)
+	}
+	return any, nil
+}
+
+// marshalElement encodes e with its MarshalJSON method if it implements
+// json.Marshaler and has an XML name, else as XML tokens.
+func marshalElement(e spec.Marshaler) (je jsonElement, err error) {
+	if jm, ok := e.(json.Marshaler); ok {
+		if n, ok := e.(interface{ XMLName() xml.Name }); ok {
+			name := n.XMLName()
+			je = jsonElement{Space: name.Space, Local: name.Local}
+			je.JSON, err = jm.MarshalJSON()
+			return
+		}
+	}
+	var r tokenRecorder
+	if err = e.Marshal3MF(&r, &xml.StartElement{}); err != nil {
+		return
+	}
+	for _, t := range r.tokens {
+		var jt jsonToken
+		switch t := t.(type) {
+		case xml.StartElement:
+			jt.Start = &jsonName{Space: t.Name.Space, Local: t.Name.Local}
+			for _, att := range t.Attr {
+				jt.Attr = append(jt.Attr, jsonAttr{Space: att.Name.Space, Local: att.Name.Local, Value: att.Value})
+			}
+		case xml.EndElement:
+			jt.End = &jsonName{Space: t.Name.Space, Local: t.Name.Local}
+		case xml.CharData:
+			s := string(t)
+			jt.Text = &s
+		default:
+			continue
+		}
+		je.XML = append(je.XML, jt)
+	}
+	return
+}
+
+// unmarshalElement decodes je, either with the value returned by its spec
+// or by feeding its XML tokens to the child decoders of parent,
+// and then returns the value returned by element.
+func unmarshalElement(je *jsonElement, parent spec.ElementDecoder, element func() interface{}) (interface{}, error) {
+	if je.JSON != nil {
+		name := xml.Name{Space: je.Space, Local: je.Local}
+		ext, ok := spec.Load(name.Space)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrJSONHook, name.Space)
+		}
+		dec := ext.NewElementDecoder(name)
+		if dec == nil {
+			return nil, fmt.Errorf("%w: %s:%s", ErrJSONHook, name.Space, name.Local)
+		}
+		v := dec.Element()
+		if err := json.Unmarshal(je.JSON, v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	s := elementStack{current: parent}
+	var depth int
+	for _, t := range je.XML {
+		switch {
+		case t.Start != nil:
+			attrs := make([]spec.XMLAttr, len(t.Attr))
+			for i, att := range t.Attr {
+				attrs[i] = spec.XMLAttr{Name: xml.Name{Space: att.Space, Local: att.Local}, Value: []byte(att.Value)}
+			}
+			depth++
+			s.start(xml.Name{Space: t.Start.Space, Local: t.Start.Local}, attrs)
+		case t.End != nil:
+			if depth--; depth < 0 {
+				return nil, ErrJSONTokens
+			}
+			s.end(xml.EndElement{Name: xml.Name{Space: t.End.Space, Local: t.End.Local}})
+		case t.Text != nil:
+			s.charData(xml.CharData(*t.Text))
+		}
+	}
+	if depth != 0 {
+		return nil, ErrJSONTokens
+	}
+	if s.errs.Len() != 0 {
+		return nil, s.errs.Unwrap()
+	}
+	v := element()
+	if v == nil {
+		return nil, ErrJSONTokens
+	}
+	return v, nil
+}
+
+// anyDecoder decodes an extension element as the model and mesh decoders do.
+type anyDecoder struct {
+	baseDecoder
+	element interface{}
+}
+
+func (d *anyDecoder) Child(name xml.Name) (int, spec.ElementDecoder) {
+	dec := spec.NewElementDecoder(name)
+	if dec != nil {
+		d.element = dec.Element()
+	}
+	return -1, dec
+}
+
+// tokenRecorder is a spec.Encoder that records the encoded tokens.
+type tokenRecorder struct {
+	tokens    []xml.Token
+	autoClose bool
+}
+
+func (r *tokenRecorder) AddRelationship(spec.Relationship) {}
+
+// FloatPresicion returns -1 so floats are encoded with the minimum precision to be exact.
+func (r *tokenRecorder) FloatPresicion() int { return -1 }
+
+func (r *tokenRecorder) EncodeToken(t xml.Token) {
+	r.tokens = append(r.tokens, xml.CopyToken(t))
+	if start, ok := t.(xml.StartElement); ok && r.autoClose {
+		r.tokens = append(r.tokens, start.End())
+	}
+}
+
+func (r *tokenRecorder) Flush() error { return nil }
+
+func (r *tokenRecorder) SetAutoClose(autoClose bool) { r.autoClose = autoClose }
+
+func (r *tokenRecorder) SetSkipAttrEscape(bool) {}
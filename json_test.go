@@ -0,0 +1,117 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf/spec"
+)
+
+// fakeAttr implements the JSON hook only in the tests,
+// so the XML tests keep using the raw attributes.
+func (f fakeAttr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.Value)
+}
+
+func (f *fakeAttr) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &f.Value)
+}
+
+func TestModel_JSON(t *testing.T) {
+	spec.Register(fakeSpec.Namespace, new(qmExtension))
+	withChild := newTestModel()
+	withChild.Childs = map[string]*ChildModel{
+		"/3D/other.model": {
+			Resources: Resources{Objects: []*Object{{ID: 1, Mesh: &Mesh{
+				Vertices:  Vertices{Vertex: []Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+				Triangles: Triangles{Triangle: []Triangle{{V1: 0, V2: 1, V3: 2, AnyAttr: spec.AnyAttr{&fakeAttr{Value: "triangle_fake"}}}}},
+			}}}},
+			Relationships: []Relationship{{Path: "/Metadata/thumbnail.png", Type: "http://schemas.openxmlformats.org/package/2006/relationships/metadata/thumbnail", ID: "1"}},
+		},
+	}
+	withChild.Attachments = []Attachment{{Path: "/Metadata/thumbnail.png", ContentType: "image/png"}}
+	withChild.RootRelationships = []Relationship{{Path: "/Metadata/thumbnail.png", Type: "http://schemas.openxmlformats.org/package/2006/relationships/metadata/thumbnail"}}
+	tests := []struct {
+		name string
+		m    *Model
+	}{
+		{"empty", new(Model)},
+		{"base", newTestModel()},
+		{"child", withChild},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.m)
+			if err != nil {
+				t.Fatalf("Model.MarshalJSON() error = %v", err)
+			}
+			got := new(Model)
+			if err := json.Unmarshal(b, got); err != nil {
+				t.Fatalf("Model.UnmarshalJSON() error = %v, s = %s", err, b)
+			}
+			if diff := deep.Equal(tt.m, got); diff != nil {
+				t.Errorf("Model.UnmarshalJSON() = %v, s = %s", diff, b)
+			}
+		})
+	}
+}
+
+func TestModel_UnmarshalJSON_error(t *testing.T) {
+	spec.Register(fakeSpec.Namespace, new(qmExtension))
+	tests := []struct {
+		name    string
+		s       string
+		wantErr error
+	}{
+		{"syntax", `{`, nil},
+		{"units", `{"units":"foo"}`, nil},
+		{"objectType", `{"resources":{"objects":[{"id":1,"type":"foo"}]}}`, nil},
+		{"transform", `{"build":{"items":[{"objectId":1,"transform":[1,2,3]}]}}`, nil},
+		{"triangle", `{"resources":{"objects":[{"id":1,"mesh":{"triangles":{"triangle":[[0,1]]}}}]}}`, nil},
+		{"triangleAttr", `{"resources":{"objects":[{"id":1,"mesh":{"triangles":{"triangleAnyAttr":{"0":[{"namespace":"http://dummy.com/foo"}]}}}}]}}`, nil},
+		{"color", `{"resources":{"assets":[{"baseMaterials":{"id":1,"materials":[{"name":"a","color":"red"}]}}]}}`, nil},
+		{"emptyAsset", `{"resources":{"assets":[{}]}}`, nil},
+		{"unbalanced", `{"any":[{"xml":[{"start":{"local":"a"}}]}]}`, ErrJSONTokens},
+		{"unbalancedEnd", `{"any":[{"xml":[{"end":{"local":"a"}}]}]}`, ErrJSONTokens},
+		{"elementHook", `{"any":[{"space":"http://dummy.com/foo","local":"a","json":{}}]}`, ErrJSONHook},
+		{"attrHook", `{"anyAttr":[{"namespace":"http://dummy.com/foo","json":"a"}]}`, ErrJSONHook},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tt.s), new(Model))
+			if err == nil {
+				t.Fatal("Model.UnmarshalJSON() expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Model.UnmarshalJSON() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_marshalElement(t *testing.T) {
+	e := &spec.UnknownTokens{Token: []xml.Token{
+		xml.StartElement{Name: fooName, Attr: []xml.Attr{{Name: xml.Name{Local: "a"}, Value: "1"}}},
+		xml.CharData("text"),
+		xml.EndElement{Name: fooName},
+	}}
+	got, err := marshalElement(e)
+	if err != nil {
+		t.Fatalf("marshalElement() error = %v", err)
+	}
+	text := "text"
+	want := jsonElement{XML: []jsonToken{
+		{Start: &jsonName{Space: fooSpace, Local: "fooname"}, Attr: []jsonAttr{{Local: "a", Value: "1"}}},
+		{Text: &text},
+		{End: &jsonName{Space: fooSpace, Local: "fooname"}},
+	}}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("marshalElement() = %v", diff)
+	}
+}
@@ -0,0 +1,80 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"fmt"
+	"io"
+)
+
+// Limits defines the maximum amount of resources a Decoder
+// is allowed to consume. A zero value means no limit.
+//
+// Limits are checked while decoding and, when exceeded, the
+// decoding fails immediately with a *LimitError, regardless of
+// the Decoder Strict mode.
+type Limits struct {
+	MaxPartSize   int64 // Maximum uncompressed size of a part, in bytes.
+	MaxParts      int   // Maximum number of parts in the package.
+	MaxDepth      int   // Maximum XML element nesting depth.
+	MaxAttributes int   // Maximum number of attributes of an XML element.
+	MaxVertices   int   // Maximum number of vertices of a mesh.
+	MaxTriangles  int   // Maximum number of triangles of a mesh.
+}
+
+// Limit names used in LimitError.
+const (
+	LimitPartSize   = "MaxPartSize"
+	LimitParts      = "MaxParts"
+	LimitDepth      = "MaxDepth"
+	LimitAttributes = "MaxAttributes"
+	LimitVertices   = "MaxVertices"
+	LimitTriangles  = "MaxTriangles"
+)
+
+// A LimitError is returned when decoding a package exceeds one of the Decoder Limits.
+type LimitError struct {
+	Limit string // One of the Limit* constants.
+	Max   int64
+	Path  string // Part name, empty if the limit applies to the whole package.
+}
+
+func (e *LimitError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("go3mf: %s limit of %d exceeded", e.Limit, e.Max)
+	}
+	return fmt.Sprintf("go3mf: Path: %s: %s limit of %d exceeded", e.Path, e.Limit, e.Max)
+}
+
+// limitedReadCloser returns a *LimitError when reading more than max bytes.
+type limitedReadCloser struct {
+	io.ReadCloser
+	name string
+	max  int64
+	n    int64
+}
+
+func newLimitedReadCloser(r io.ReadCloser, name string, max int64) io.ReadCloser {
+	if max <= 0 {
+		return r
+	}
+	return &limitedReadCloser{ReadCloser: r, name: name, max: max}
+}
+
+func (l *limitedReadCloser) Read(p []byte) (int, error) {
+	if l.n >= l.max {
+		// Check if there is still data left before failing.
+		var b [1]byte
+		if n, _ := l.ReadCloser.Read(b[:]); n > 0 {
+			return 0, &LimitError{Limit: LimitPartSize, Max: l.max, Path: l.name}
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.max-l.n {
+		p = p[:l.max-l.n]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.n += int64(n)
+	return n, err
+}
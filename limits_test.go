@@ -0,0 +1,99 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDecoder_Limits(t *testing.T) {
+	m := &Model{
+		Attachments: []Attachment{
+			{ContentType: "image/png", Path: "/Metadata/thumbnail.png", Stream: bytes.NewBufferString("fake")},
+		},
+		RootRelationships: []Relationship{{Path: "/Metadata/thumbnail.png", Type: RelTypeThumbnail}},
+		Resources: Resources{Objects: []*Object{
+			{ID: 1, Mesh: &Mesh{
+				Vertices: Vertices{Vertex: []Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}},
+				Triangles: Triangles{Triangle: []Triangle{
+					{V1: 0, V2: 2, V3: 1}, {V1: 0, V2: 1, V3: 3}, {V1: 0, V2: 3, V3: 2}, {V1: 1, V2: 2, V3: 3},
+				}},
+			}},
+		}},
+		Build: Build{Items: []*Item{{ObjectID: 1}}},
+	}
+	buff := new(bytes.Buffer)
+	if err := NewEncoder(buff).Encode(m); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		limits Limits
+		want   *LimitError
+	}{
+		{"none", Limits{}, nil},
+		{"enough", Limits{MaxPartSize: 1 << 20, MaxParts: 10, MaxDepth: 10, MaxAttributes: 10, MaxVertices: 4, MaxTriangles: 4}, nil},
+		{"parts", Limits{MaxParts: 1}, &LimitError{Limit: LimitParts, Max: 1}},
+		{"partSize", Limits{MaxPartSize: 10}, &LimitError{Limit: LimitPartSize, Max: 10, Path: DefaultModelPath}},
+		{"depth", Limits{MaxDepth: 4}, &LimitError{Limit: LimitDepth, Max: 4, Path: DefaultModelPath}},
+		{"attributes", Limits{MaxAttributes: 2}, &LimitError{Limit: LimitAttributes, Max: 2, Path: DefaultModelPath}},
+		{"vertices", Limits{MaxVertices: 3}, &LimitError{Limit: LimitVertices, Max: 3, Path: DefaultModelPath}},
+		{"triangles", Limits{MaxTriangles: 3}, &LimitError{Limit: LimitTriangles, Max: 3, Path: DefaultModelPath}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+			d.Limits = tt.limits
+			d.Strict = false
+			err := d.Decode(new(Model))
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Decoder.Decode() error = %v", err)
+				}
+				return
+			}
+			var got *LimitError
+			if !errors.As(err, &got) {
+				t.Fatalf("Decoder.Decode() error = %v, want *LimitError", err)
+			}
+			if tt.want.Path == "" {
+				got.Path = ""
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Decoder.Decode() = %v", diff)
+			}
+		})
+	}
+}
+
+func Test_limitedReadCloser(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		max     int64
+		wantErr bool
+	}{
+		{"nolimit", "abcd", 0, false},
+		{"under", "abcd", 5, false},
+		{"exact", "abcd", 4, false},
+		{"over", "abcd", 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newLimitedReadCloser(ioutil.NopCloser(bytes.NewBufferString(tt.data)), "/a.xml", tt.max)
+			got, err := ioutil.ReadAll(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("limitedReadCloser.Read() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.data {
+				t.Errorf("limitedReadCloser.Read() = %s, want %s", got, tt.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+)
+
+// maxBakeSubdivisions limits the number of subdivision passes
+// done by BakeVertexColors.
+const maxBakeSubdivisions = 16
+
+// ColorEvaluator computes the display color of resolved properties.
+// Texture images are decoded once and cached.
+type ColorEvaluator struct {
+	model    *go3mf.Model
+	samplers map[*Texture2D]*TextureSampler
+}
+
+// NewColorEvaluator returns a ColorEvaluator for the properties of m.
+func NewColorEvaluator(m *go3mf.Model) *ColorEvaluator {
+	return &ColorEvaluator{model: m, samplers: make(map[*Texture2D]*TextureSampler)}
+}
+
+// Color returns the display color of p.
+//
+// Composite properties mix the colors of its base materials
+// weighted by the composite values, and multi properties blend
+// the color of each layer over the previous ones using the
+// layer blend method, which defaults to BlendMix.
+func (e *ColorEvaluator) Color(p Property) (color.RGBA, error) {
+	switch p := p.(type) {
+	case *BaseProperty:
+		return p.Base.Color, nil
+	case *ColorProperty:
+		return p.Color, nil
+	case *TextureProperty:
+		return e.sample(p.Texture, p.Coord)
+	case *CompositeProperty:
+		return mixComposite(p), nil
+	case *MultiProperty:
+		colors := make([]color.RGBA, len(p.Layers))
+		for i, l := range p.Layers {
+			var err error
+			if colors[i], err = e.Color(l); err != nil {
+				return color.RGBA{}, err
+			}
+		}
+		return blendLayers(p.BlendMethods, colors), nil
+	}
+	return color.RGBA{}, nil
+}
+
+// ColorAt returns the display color at the point with barycentric
+// coordinates bary of a triangle whose vertices have the properties props.
+// Texture coordinates are interpolated before sampling when the three
+// vertices use the same texture, else the vertex colors are interpolated.
+func (e *ColorEvaluator) ColorAt(props [3]Property, bary [3]float32) (color.RGBA, error) {
+	t0, ok0 := props[0].(*TextureProperty)
+	t1, ok1 := props[1].(*TextureProperty)
+	t2, ok2 := props[2].(*TextureProperty)
+	if ok0 && ok1 && ok2 && t0.Texture == t1.Texture && t0.Texture == t2.Texture {
+		var uv TextureCoord
+		for i, t := range [3]*TextureProperty{t0, t1, t2} {
+			uv[0] += bary[i] * t.Coord[0]
+			uv[1] += bary[i] * t.Coord[1]
+		}
+		return e.sample(t0.Texture, uv)
+	}
+	m0, ok0 := props[0].(*MultiProperty)
+	m1, ok1 := props[1].(*MultiProperty)
+	m2, ok2 := props[2].(*MultiProperty)
+	if ok0 && ok1 && ok2 && m0.PID == m1.PID && m0.PID == m2.PID {
+		colors := make([]color.RGBA, len(m0.Layers))
+		for i := range m0.Layers {
+			var err error
+			colors[i], err = e.ColorAt([3]Property{m0.Layers[i], m1.Layers[i], m2.Layers[i]}, bary)
+			if err != nil {
+				return color.RGBA{}, err
+			}
+		}
+		return blendLayers(m0.BlendMethods, colors), nil
+	}
+	var c [4]float32
+	for i, p := range props {
+		pc, err := e.Color(p)
+		if err != nil {
+			return color.RGBA{}, err
+		}
+		c[0] += bary[i] * float32(pc.R)
+		c[1] += bary[i] * float32(pc.G)
+		c[2] += bary[i] * float32(pc.B)
+		c[3] += bary[i] * float32(pc.A)
+	}
+	return color.RGBA{R: toUint8(c[0]), G: toUint8(c[1]), B: toUint8(c[2]), A: toUint8(c[3])}, nil
+}
+
+func (e *ColorEvaluator) sample(t *Texture2D, c TextureCoord) (color.RGBA, error) {
+	s, ok := e.samplers[t]
+	if !ok {
+		var err error
+		if s, err = NewTextureSampler(e.model, t); err != nil {
+			return color.RGBA{}, err
+		}
+		e.samplers[t] = s
+	}
+	return s.Sample(c), nil
+}
+
+func mixComposite(p *CompositeProperty) color.RGBA {
+	var (
+		c     [4]float32
+		total float32
+	)
+	for i, v := range p.Values {
+		mc := p.Materials[i].Color
+		c[0] += v * float32(mc.R)
+		c[1] += v * float32(mc.G)
+		c[2] += v * float32(mc.B)
+		c[3] += v * float32(mc.A)
+		total += v
+	}
+	if total == 0 {
+		return color.RGBA{}
+	}
+	return color.RGBA{R: toUint8(c[0] / total), G: toUint8(c[1] / total), B: toUint8(c[2] / total), A: toUint8(c[3] / total)}
+}
+
+// blendLayers blends each color over the previous ones.
+// methods[i-1] is the blend method of the i-th layer.
+func blendLayers(methods []BlendMethod, colors []color.RGBA) color.RGBA {
+	if len(colors) == 0 {
+		return color.RGBA{}
+	}
+	dst := colors[0]
+	for i := 1; i < len(colors); i++ {
+		method := BlendMix
+		if i-1 < len(methods) {
+			method = methods[i-1]
+		}
+		src := colors[i]
+		if method == BlendMultiply {
+			dst = color.RGBA{
+				R: uint8(uint16(dst.R) * uint16(src.R) / 255),
+				G: uint8(uint16(dst.G) * uint16(src.G) / 255),
+				B: uint8(uint16(dst.B) * uint16(src.B) / 255),
+				A: uint8(uint16(dst.A) * uint16(src.A) / 255),
+			}
+			continue
+		}
+		a := float32(src.A) / 255
+		dst = color.RGBA{
+			R: toUint8(float32(src.R)*a + float32(dst.R)*(1-a)),
+			G: toUint8(float32(src.G)*a + float32(dst.G)*(1-a)),
+			B: toUint8(float32(src.B)*a + float32(dst.B)*(1-a)),
+			A: toUint8(float32(src.A) + float32(dst.A)*(1-a)),
+		}
+	}
+	return dst
+}
+
+func toUint8(f float32) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(float64(f)))))
+}
+
+// bakeTriangle is a triangle of the baked mesh.
+// bary contains the barycentric coordinates of each vertex
+// relative to the original triangle.
+type bakeTriangle struct {
+	v    [3]uint32
+	bary [3][3]float32
+	orig int
+}
+
+// BakeVertexColors replaces the properties of the mesh of obj, which is
+// defined in the model part path, by vertex colors stored in a new ColorGroup,
+// which is added to the part resources and returned.
+//
+// Textures are sampled and composite and multi properties are evaluated
+// at each triangle vertex using a ColorEvaluator. Triangles without properties
+// are kept as they are. If maxEdgeLength is greater than zero, edges longer than
+// it are subdivided, so texture details are preserved in large triangles.
+func BakeVertexColors(m *go3mf.Model, path string, obj *go3mf.Object, maxEdgeLength float32) (*ColorGroup, error) {
+	if obj.Mesh == nil {
+		return nil, nil
+	}
+	rs, ok := m.FindResources(path)
+	if !ok {
+		return nil, specerr.ErrMissingResource
+	}
+	r := NewResolver(m, path)
+	props, err := r.Mesh(obj)
+	if err != nil {
+		return nil, err
+	}
+	mesh := obj.Mesh
+	tris := make([]bakeTriangle, len(mesh.Triangles.Triangle))
+	for i, t := range mesh.Triangles.Triangle {
+		tris[i] = bakeTriangle{
+			v:    [3]uint32{t.V1, t.V2, t.V3},
+			bary: [3][3]float32{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+			orig: i,
+		}
+	}
+	if maxEdgeLength > 0 {
+		tris = subdivide(mesh, tris, maxEdgeLength)
+	}
+	group := &ColorGroup{ID: rs.UnusedID()}
+	indices := make(map[color.RGBA]uint32)
+	colorIndex := func(c color.RGBA) uint32 {
+		index, ok := indices[c]
+		if !ok {
+			index = uint32(len(group.Colors))
+			indices[c] = index
+			group.Colors = append(group.Colors, c)
+		}
+		return index
+	}
+	e := NewColorEvaluator(m)
+	triangles := make([]go3mf.Triangle, len(tris))
+	for i, t := range tris {
+		orig := mesh.Triangles.Triangle[t.orig]
+		triangles[i] = go3mf.Triangle{V1: t.v[0], V2: t.v[1], V3: t.v[2], AnyAttr: orig.AnyAttr}
+		if props[t.orig][0] == nil {
+			continue
+		}
+		var p [3]uint32
+		for j := range p {
+			c, err := e.ColorAt(props[t.orig], t.bary[j])
+			if err != nil {
+				return nil, specerr.WrapIndex(err, "triangle", t.orig)
+			}
+			p[j] = colorIndex(c)
+		}
+		triangles[i].PID = group.ID
+		triangles[i].P1, triangles[i].P2, triangles[i].P3 = p[0], p[1], p[2]
+	}
+	if obj.PID != 0 {
+		p, err := r.Property(obj.PID, obj.PIndex)
+		if err != nil {
+			return nil, err
+		}
+		c, err := e.Color(p)
+		if err != nil {
+			return nil, err
+		}
+		obj.PID, obj.PIndex = group.ID, colorIndex(c)
+	}
+	mesh.Triangles.Triangle = triangles
+	if len(group.Colors) == 0 {
+		return nil, nil
+	}
+	rs.Assets = append(rs.Assets, group)
+	return group, nil
+}
+
+// subdivide splits the triangles with edges longer than maxEdgeLength.
+// Edges are split at their midpoint, which is shared by the adjacent triangles
+// so the mesh does not get T-junctions.
+func subdivide(mesh *go3mf.Mesh, tris []bakeTriangle, maxEdgeLength float32) []bakeTriangle {
+	maxSq := maxEdgeLength * maxEdgeLength
+	for pass := 0; pass < maxBakeSubdivisions; pass++ {
+		midpoints := make(map[[2]uint32]uint32)
+		split := func(a, b uint32) (uint32, bool) {
+			key := [2]uint32{a, b}
+			if a > b {
+				key = [2]uint32{b, a}
+			}
+			if mid, ok := midpoints[key]; ok {
+				return mid, true
+			}
+			va, vb := mesh.Vertices.Vertex[a], mesh.Vertices.Vertex[b]
+			dx, dy, dz := va[0]-vb[0], va[1]-vb[1], va[2]-vb[2]
+			if dx*dx+dy*dy+dz*dz <= maxSq {
+				return 0, false
+			}
+			mid := uint32(len(mesh.Vertices.Vertex))
+			mesh.Vertices.Vertex = append(mesh.Vertices.Vertex, go3mf.Point3D{
+				(va[0] + vb[0]) / 2, (va[1] + vb[1]) / 2, (va[2] + vb[2]) / 2,
+			})
+			midpoints[key] = mid
+			return mid, true
+		}
+		var (
+			out     = make([]bakeTriangle, 0, len(tris))
+			changed bool
+		)
+		for _, t := range tris {
+			var (
+				mids   [3]uint32
+				isMid  [3]bool
+				nsplit int
+			)
+			for i := range mids {
+				if mids[i], isMid[i] = split(t.v[i], t.v[(i+1)%3]); isMid[i] {
+					nsplit++
+				}
+			}
+			if nsplit == 0 {
+				out = append(out, t)
+				continue
+			}
+			changed = true
+			out = append(out, splitTriangle(t, mids, isMid)...)
+		}
+		tris = out
+		if !changed {
+			break
+		}
+	}
+	return tris
+}
+
+// splitTriangle splits t using the midpoints of the edges marked in isMid.
+// The edge i goes from the vertex i to the vertex (i+1)%3.
+func splitTriangle(t bakeTriangle, mids [3]uint32, isMid [3]bool) []bakeTriangle {
+	type corner struct {
+		v    uint32
+		bary [3]float32
+	}
+	vert := func(i int) corner { return corner{t.v[i], t.bary[i]} }
+	mid := func(i int) corner {
+		a, b := t.bary[i], t.bary[(i+1)%3]
+		return corner{mids[i], [3]float32{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2, (a[2] + b[2]) / 2}}
+	}
+	tri := func(c0, c1, c2 corner) bakeTriangle {
+		return bakeTriangle{v: [3]uint32{c0.v, c1.v, c2.v}, bary: [3][3]float32{c0.bary, c1.bary, c2.bary}, orig: t.orig}
+	}
+	if isMid[0] && isMid[1] && isMid[2] {
+		return []bakeTriangle{
+			tri(vert(0), mid(0), mid(2)),
+			tri(mid(0), vert(1), mid(1)),
+			tri(mid(2), mid(1), vert(2)),
+			tri(mid(0), mid(1), mid(2)),
+		}
+	}
+	// Rotate so the first split edge in winding order is the edge 0.
+	for i := 0; i < 3; i++ {
+		if isMid[i] && !isMid[(i+2)%3] {
+			if i != 0 {
+				t.v = [3]uint32{t.v[i], t.v[(i+1)%3], t.v[(i+2)%3]}
+				t.bary = [3][3]float32{t.bary[i], t.bary[(i+1)%3], t.bary[(i+2)%3]}
+				mids = [3]uint32{mids[i], mids[(i+1)%3], mids[(i+2)%3]}
+				isMid = [3]bool{isMid[i], isMid[(i+1)%3], isMid[(i+2)%3]}
+			}
+			break
+		}
+	}
+	if isMid[1] {
+		// Edges 0 and 1 are split.
+		return []bakeTriangle{
+			tri(vert(0), mid(0), vert(2)),
+			tri(mid(0), vert(1), mid(1)),
+			tri(mid(0), mid(1), vert(2)),
+		}
+	}
+	// Only edge 0 is split.
+	return []bakeTriangle{
+		tri(vert(0), mid(0), vert(2)),
+		tri(mid(0), vert(1), vert(2)),
+	}
+}
@@ -0,0 +1,238 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+)
+
+func TestColorEvaluator_Color(t *testing.T) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, newTestImage()); err != nil {
+		t.Fatal(err)
+	}
+	m := &go3mf.Model{Attachments: []go3mf.Attachment{{Path: "/a.png", Stream: &data}}}
+	tex := &Texture2D{Path: "/a.png", ContentType: TextureTypePNG, Filter: TextureFilterNearest}
+	half := color.RGBA{R: 255, A: 128}
+	tests := []struct {
+		name    string
+		p       Property
+		want    color.RGBA
+		wantErr bool
+	}{
+		{"base", &BaseProperty{Base: go3mf.Base{Color: texRed}}, texRed, false},
+		{"color", &ColorProperty{Color: texGreen}, texGreen, false},
+		{"texture", &TextureProperty{Texture: tex, Coord: TextureCoord{0.75, 0.25}}, texWhite, false},
+		{"textureErr", &TextureProperty{Texture: &Texture2D{Path: "/b.png"}}, color.RGBA{}, true},
+		{"composite", &CompositeProperty{Materials: []go3mf.Base{{Color: texRed}, {Color: texBlue}}, Values: []float32{0.25, 0.75}},
+			color.RGBA{R: 64, B: 191, A: 255}, false},
+		{"compositeUnnormalized", &CompositeProperty{Materials: []go3mf.Base{{Color: texRed}, {Color: texBlue}}, Values: []float32{1, 1}},
+			color.RGBA{R: 128, B: 128, A: 255}, false},
+		{"compositeZero", &CompositeProperty{Materials: []go3mf.Base{{Color: texRed}}, Values: []float32{0}}, color.RGBA{}, false},
+		{"multiMix", &MultiProperty{Layers: []Property{&ColorProperty{Color: texBlue}, &ColorProperty{Color: half}}},
+			color.RGBA{R: 128, B: 127, A: 255}, false},
+		{"multiMultiply", &MultiProperty{BlendMethods: []BlendMethod{BlendMultiply}, Layers: []Property{
+			&ColorProperty{Color: texWhite}, &ColorProperty{Color: color.RGBA{R: 255, G: 128, A: 255}},
+		}}, color.RGBA{R: 255, G: 128, A: 255}, false},
+		{"multiErr", &MultiProperty{Layers: []Property{&TextureProperty{Texture: &Texture2D{Path: "/b.png"}}}}, color.RGBA{}, true},
+	}
+	e := NewColorEvaluator(m)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.Color(tt.p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ColorEvaluator.Color() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ColorEvaluator.Color() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorEvaluator_ColorAt(t *testing.T) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, newTestImage()); err != nil {
+		t.Fatal(err)
+	}
+	m := &go3mf.Model{Attachments: []go3mf.Attachment{{Path: "/a.png", Stream: &data}}}
+	tex := &Texture2D{Path: "/a.png", ContentType: TextureTypePNG, Filter: TextureFilterNearest}
+	corners := [3]Property{
+		&TextureProperty{Texture: tex, Coord: TextureCoord{0.1, 0.1}},
+		&TextureProperty{Texture: tex, Coord: TextureCoord{0.9, 0.1}},
+		&TextureProperty{Texture: tex, Coord: TextureCoord{0.1, 0.9}},
+	}
+	tests := []struct {
+		name  string
+		props [3]Property
+		bary  [3]float32
+		want  color.RGBA
+	}{
+		{"textureCorner", corners, [3]float32{0, 1, 0}, texWhite},
+		{"textureInterpolated", corners, [3]float32{0.5, 0, 0.5}, texBlue},
+		{"colors", [3]Property{&ColorProperty{Color: texRed}, &ColorProperty{Color: texBlue}, &ColorProperty{Color: texBlue}},
+			[3]float32{0.5, 0.5, 0}, color.RGBA{R: 128, B: 128, A: 255}},
+		{"multi", [3]Property{
+			&MultiProperty{PID: 1, Layers: []Property{corners[0]}},
+			&MultiProperty{PID: 1, Layers: []Property{corners[1]}},
+			&MultiProperty{PID: 1, Layers: []Property{corners[2]}},
+		}, [3]float32{0, 0.5, 0.5}, texWhite},
+	}
+	e := NewColorEvaluator(m)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.ColorAt(tt.props, tt.bary)
+			if err != nil {
+				t.Fatalf("ColorEvaluator.ColorAt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ColorEvaluator.ColorAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newBakeTestModel(t *testing.T) (*go3mf.Model, *go3mf.Object) {
+	t.Helper()
+	var data bytes.Buffer
+	if err := png.Encode(&data, newTestImage()); err != nil {
+		t.Fatal(err)
+	}
+	obj := &go3mf.Object{ID: 1, Mesh: &go3mf.Mesh{
+		Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}, {2, 2, 0}}},
+		Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+			{V1: 0, V2: 1, V3: 2, PID: 3, P1: 0, P2: 1, P3: 2},
+			{V1: 1, V2: 3, V3: 2},
+		}},
+	}}
+	m := &go3mf.Model{Attachments: []go3mf.Attachment{{Path: "/a.png", Stream: &data}}}
+	m.Resources.Objects = []*go3mf.Object{obj}
+	m.Resources.Assets = []go3mf.Asset{
+		&Texture2D{ID: 2, Path: "/a.png", ContentType: TextureTypePNG, Filter: TextureFilterNearest},
+		&Texture2DGroup{ID: 3, TextureID: 2, Coords: []TextureCoord{{0.1, 0.1}, {0.9, 0.1}, {0.1, 0.9}}},
+	}
+	return m, obj
+}
+
+func TestBakeVertexColors(t *testing.T) {
+	m, obj := newBakeTestModel(t)
+	got, err := BakeVertexColors(m, "", obj, 0)
+	if err != nil {
+		t.Fatalf("BakeVertexColors() error = %v", err)
+	}
+	want := &ColorGroup{ID: 4, Colors: []color.RGBA{texBlue, texWhite, texRed}}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("BakeVertexColors() = %v", diff)
+	}
+	wantTriangles := []go3mf.Triangle{
+		{V1: 0, V2: 1, V3: 2, PID: 4, P1: 0, P2: 1, P3: 2},
+		{V1: 1, V2: 3, V3: 2},
+	}
+	if diff := deep.Equal(obj.Mesh.Triangles.Triangle, wantTriangles); diff != nil {
+		t.Errorf("BakeVertexColors() triangles = %v", diff)
+	}
+	if m.Resources.Assets[len(m.Resources.Assets)-1] != got {
+		t.Error("BakeVertexColors() color group not added to resources")
+	}
+	if got, err := BakeVertexColors(m, "", &go3mf.Object{}, 0); got != nil || err != nil {
+		t.Errorf("BakeVertexColors() = %v, %v, want nil", got, err)
+	}
+	if _, err := BakeVertexColors(m, "/other.model", obj, 0); err == nil {
+		t.Error("BakeVertexColors() expected error")
+	}
+}
+
+func TestBakeVertexColors_Object(t *testing.T) {
+	m, obj := newBakeTestModel(t)
+	obj.PID, obj.PIndex = 3, 1
+	obj.Mesh.Triangles.Triangle[0].PID = 0
+	got, err := BakeVertexColors(m, "", obj, 0)
+	if err != nil {
+		t.Fatalf("BakeVertexColors() error = %v", err)
+	}
+	if diff := deep.Equal(got.Colors, []color.RGBA{texWhite}); diff != nil {
+		t.Errorf("BakeVertexColors() = %v", diff)
+	}
+	if obj.PID != got.ID || obj.PIndex != 0 {
+		t.Errorf("BakeVertexColors() object property = %d %d", obj.PID, obj.PIndex)
+	}
+	for i, tri := range obj.Mesh.Triangles.Triangle {
+		if tri.PID != got.ID {
+			t.Errorf("BakeVertexColors() triangle %d PID = %d, want %d", i, tri.PID, got.ID)
+		}
+	}
+}
+
+func TestBakeVertexColors_Subdivide(t *testing.T) {
+	m, obj := newBakeTestModel(t)
+	got, err := BakeVertexColors(m, "", obj, 1.5)
+	if err != nil {
+		t.Fatalf("BakeVertexColors() error = %v", err)
+	}
+	// All the edges are longer than 1.5, so both triangles are split
+	// in four and the shared diagonal midpoint is reused.
+	if n := len(obj.Mesh.Vertices.Vertex); n != 9 {
+		t.Errorf("BakeVertexColors() vertices = %d, want 9", n)
+	}
+	tris := obj.Mesh.Triangles.Triangle
+	if len(tris) != 8 {
+		t.Fatalf("BakeVertexColors() triangles = %d, want 8", len(tris))
+	}
+	for i, tri := range tris[:4] {
+		if tri.PID != got.ID {
+			t.Errorf("BakeVertexColors() triangle %d PID = %d, want %d", i, tri.PID, got.ID)
+		}
+	}
+	for i, tri := range tris[4:] {
+		if tri.PID != 0 {
+			t.Errorf("BakeVertexColors() triangle %d PID = %d, want 0", i+4, tri.PID)
+		}
+	}
+	// The midpoint of the edge 0-1 is at the lower middle texel boundary,
+	// which the nearest filter resolves to the lower right texel.
+	if c := got.Colors[tris[0].P2]; c != texWhite {
+		t.Errorf("BakeVertexColors() midpoint color = %v, want %v", c, texWhite)
+	}
+	for i, tri := range tris {
+		for _, v := range [3]uint32{tri.V1, tri.V2, tri.V3} {
+			if int(v) >= len(obj.Mesh.Vertices.Vertex) {
+				t.Errorf("BakeVertexColors() triangle %d vertex out of bounds", i)
+			}
+		}
+	}
+}
+
+func Test_splitTriangle(t *testing.T) {
+	base := bakeTriangle{v: [3]uint32{0, 1, 2}, bary: [3][3]float32{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}}
+	tests := []struct {
+		name  string
+		isMid [3]bool
+		want  [][3]uint32
+	}{
+		{"edge0", [3]bool{true, false, false}, [][3]uint32{{0, 3, 2}, {3, 1, 2}}},
+		{"edge1", [3]bool{false, true, false}, [][3]uint32{{1, 4, 0}, {4, 2, 0}}},
+		{"edge2", [3]bool{false, false, true}, [][3]uint32{{2, 5, 1}, {5, 0, 1}}},
+		{"edges01", [3]bool{true, true, false}, [][3]uint32{{0, 3, 2}, {3, 1, 4}, {3, 4, 2}}},
+		{"edges12", [3]bool{false, true, true}, [][3]uint32{{1, 4, 0}, {4, 2, 5}, {4, 5, 0}}},
+		{"edges20", [3]bool{true, false, true}, [][3]uint32{{2, 5, 1}, {5, 0, 3}, {5, 3, 1}}},
+		{"all", [3]bool{true, true, true}, [][3]uint32{{0, 3, 5}, {3, 1, 4}, {5, 4, 2}, {3, 4, 5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][3]uint32
+			for _, tri := range splitTriangle(base, [3]uint32{3, 4, 5}, tt.isMid) {
+				got = append(got, tri.v)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("splitTriangle() = %v", diff)
+			}
+		})
+	}
+}
@@ -5,14 +5,30 @@ package materials
 
 import (
 	"encoding/xml"
+	"image/color"
 	"strconv"
 	"strings"
 
+	"github.com/hpinc/go3mf"
 	specerr "github.com/hpinc/go3mf/errors"
 	"github.com/hpinc/go3mf/spec"
 )
 
-func (Spec) NewAttrGroup(xml.Name) spec.AttrGroup {
+func (Spec) NewAttrGroup(parent xml.Name) spec.AttrGroup {
+	if parent.Space == go3mf.Namespace && parent.Local == "basematerials" {
+		return new(BaseMaterialsAttr)
+	}
+	return nil
+}
+
+func (u *BaseMaterialsAttr) Unmarshal3MFAttr(a spec.XMLAttr) error {
+	if a.Name.Local == attrDisplayPropertiesID {
+		val, err := strconv.ParseUint(string(a.Value), 10, 32)
+		if err != nil {
+			return specerr.NewParseAttrError(a.Name.Local, true)
+		}
+		u.DisplayPropertiesID = uint32(val)
+	}
 	return nil
 }
 
@@ -31,6 +47,16 @@ func (Spec) NewElementDecoder(name xml.Name) (child spec.GetterElementDecoder) {
 		child = new(compositeMaterialsDecoder)
 	case attrMultiProps:
 		child = new(multiPropertiesDecoder)
+	case attrPBSpecularDisplayProps:
+		child = new(pbSpecularDisplayPropsDecoder)
+	case attrPBMetallicDisplayProps:
+		child = new(pbMetallicDisplayPropsDecoder)
+	case attrPBSpecularTextureDisplayProps:
+		child = new(pbSpecularTextureDisplayPropsDecoder)
+	case attrPBMetallicTextureDisplayProps:
+		child = new(pbMetallicTextureDisplayPropsDecoder)
+	case attrTranslucentDisplayProps:
+		child = new(translucentDisplayPropsDecoder)
 	}
 	return
 }
@@ -56,13 +82,22 @@ func (d *colorGroupDecoder) Child(name xml.Name) (i int, child spec.ElementDecod
 func (d *colorGroupDecoder) Start(attrs []spec.XMLAttr) (errs error) {
 	d.colorDecoder.resource = &d.resource
 	for _, a := range attrs {
-		if a.Name.Space == "" && a.Name.Local == attrID {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrID:
 			id, err := strconv.ParseUint(string(a.Value), 10, 32)
 			if err != nil {
 				errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, true))
 			}
 			d.resource.ID = uint32(id)
-			break
+		case attrDisplayPropertiesID:
+			val, err := strconv.ParseUint(string(a.Value), 10, 32)
+			if err != nil {
+				errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, true))
+			}
+			d.resource.DisplayPropertiesID = uint32(val)
 		}
 	}
 	return
@@ -157,6 +192,12 @@ func (d *tex2DGroupDecoder) Start(attrs []spec.XMLAttr) error {
 				errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, true))
 			}
 			d.resource.TextureID = uint32(val)
+		case attrDisplayPropertiesID:
+			val, err := strconv.ParseUint(string(a.Value), 10, 32)
+			if err != nil {
+				errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, true))
+			}
+			d.resource.DisplayPropertiesID = uint32(val)
 		}
 	}
 	return errs
@@ -237,6 +278,12 @@ func (d *compositeMaterialsDecoder) Start(attrs []spec.XMLAttr) error {
 				errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, true))
 			}
 			d.resource.MaterialID = uint32(val)
+		case attrDisplayPropertiesID:
+			val, err := strconv.ParseUint(string(a.Value), 10, 32)
+			if err != nil {
+				errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, true))
+			}
+			d.resource.DisplayPropertiesID = uint32(val)
 		case attrMatIndices:
 			for _, f := range strings.Fields(string(a.Value)) {
 				val, err := strconv.ParseUint(f, 10, 32)
@@ -350,6 +397,301 @@ func (d *multiDecoder) Start(attrs []spec.XMLAttr) error {
 	return errs
 }
 
+type pbSpecularDisplayPropsDecoder struct {
+	baseDecoder
+	resource          PBSpecularDisplayProperties
+	pbSpecularDecoder pbSpecularDecoder
+}
+
+func (d *pbSpecularDisplayPropsDecoder) Element() interface{} {
+	return &d.resource
+}
+
+func (d *pbSpecularDisplayPropsDecoder) Child(name xml.Name) (i int, child spec.ElementDecoder) {
+	if name.Space == Namespace && name.Local == attrPBSpecular {
+		child = &d.pbSpecularDecoder
+		i = len(d.resource.Properties)
+	}
+	return
+}
+
+func (d *pbSpecularDisplayPropsDecoder) Start(attrs []spec.XMLAttr) error {
+	d.pbSpecularDecoder.resource = &d.resource
+	return parseID(attrs, &d.resource.ID)
+}
+
+type pbSpecularDecoder struct {
+	baseDecoder
+	resource *PBSpecularDisplayProperties
+}
+
+func (d *pbSpecularDecoder) Start(attrs []spec.XMLAttr) error {
+	var (
+		prop = PBSpecular{SpecularColor: color.RGBA{R: 0x38, G: 0x38, B: 0x38, A: 0xff}}
+		errs error
+	)
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		var err error
+		switch a.Name.Local {
+		case attrName:
+			prop.Name = string(a.Value)
+		case attrSpecularColor:
+			prop.SpecularColor, err = spec.ParseRGBA(string(a.Value))
+		case attrGlossiness:
+			prop.Glossiness, err = parseFloat32(a.Value)
+		}
+		if err != nil {
+			errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, false))
+		}
+	}
+	d.resource.Properties = append(d.resource.Properties, prop)
+	return errs
+}
+
+type pbMetallicDisplayPropsDecoder struct {
+	baseDecoder
+	resource          PBMetallicDisplayProperties
+	pbMetallicDecoder pbMetallicDecoder
+}
+
+func (d *pbMetallicDisplayPropsDecoder) Element() interface{} {
+	return &d.resource
+}
+
+func (d *pbMetallicDisplayPropsDecoder) Child(name xml.Name) (i int, child spec.ElementDecoder) {
+	if name.Space == Namespace && name.Local == attrPBMetallic {
+		child = &d.pbMetallicDecoder
+		i = len(d.resource.Properties)
+	}
+	return
+}
+
+func (d *pbMetallicDisplayPropsDecoder) Start(attrs []spec.XMLAttr) error {
+	d.pbMetallicDecoder.resource = &d.resource
+	return parseID(attrs, &d.resource.ID)
+}
+
+type pbMetallicDecoder struct {
+	baseDecoder
+	resource *PBMetallicDisplayProperties
+}
+
+func (d *pbMetallicDecoder) Start(attrs []spec.XMLAttr) error {
+	var (
+		prop = PBMetallic{Roughness: 1}
+		errs error
+	)
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		var err error
+		switch a.Name.Local {
+		case attrName:
+			prop.Name = string(a.Value)
+		case attrMetallicness:
+			prop.Metallicness, err = parseFloat32(a.Value)
+		case attrRoughness:
+			prop.Roughness, err = parseFloat32(a.Value)
+		}
+		if err != nil {
+			errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, false))
+		}
+	}
+	d.resource.Properties = append(d.resource.Properties, prop)
+	return errs
+}
+
+type pbSpecularTextureDisplayPropsDecoder struct {
+	baseDecoder
+	resource PBSpecularTextureDisplayProperties
+}
+
+func (d *pbSpecularTextureDisplayPropsDecoder) Element() interface{} {
+	return &d.resource
+}
+
+func (d *pbSpecularTextureDisplayPropsDecoder) Start(attrs []spec.XMLAttr) error {
+	var errs error
+	d.resource.DiffuseFactor = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	d.resource.SpecularFactor = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	d.resource.GlossinessFactor = 1
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		var (
+			err      error
+			required bool
+		)
+		switch a.Name.Local {
+		case attrID:
+			required = true
+			err = parseUint32(a.Value, &d.resource.ID)
+		case attrName:
+			d.resource.Name = string(a.Value)
+		case attrSpecularTextureID:
+			required = true
+			err = parseUint32(a.Value, &d.resource.SpecularTextureID)
+		case attrGlossinessTextureID:
+			required = true
+			err = parseUint32(a.Value, &d.resource.GlossinessTextureID)
+		case attrDiffuseFactor:
+			d.resource.DiffuseFactor, err = spec.ParseRGBA(string(a.Value))
+		case attrSpecularFactor:
+			d.resource.SpecularFactor, err = spec.ParseRGBA(string(a.Value))
+		case attrGlossinessFactor:
+			d.resource.GlossinessFactor, err = parseFloat32(a.Value)
+		}
+		if err != nil {
+			errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, required))
+		}
+	}
+	return errs
+}
+
+type pbMetallicTextureDisplayPropsDecoder struct {
+	baseDecoder
+	resource PBMetallicTextureDisplayProperties
+}
+
+func (d *pbMetallicTextureDisplayPropsDecoder) Element() interface{} {
+	return &d.resource
+}
+
+func (d *pbMetallicTextureDisplayPropsDecoder) Start(attrs []spec.XMLAttr) error {
+	var errs error
+	d.resource.MetallicFactor = 1
+	d.resource.RoughnessFactor = 1
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		var (
+			err      error
+			required bool
+		)
+		switch a.Name.Local {
+		case attrID:
+			required = true
+			err = parseUint32(a.Value, &d.resource.ID)
+		case attrName:
+			d.resource.Name = string(a.Value)
+		case attrMetallicTextureID:
+			required = true
+			err = parseUint32(a.Value, &d.resource.MetallicTextureID)
+		case attrRoughnessTextureID:
+			required = true
+			err = parseUint32(a.Value, &d.resource.RoughnessTextureID)
+		case attrMetallicFactor:
+			d.resource.MetallicFactor, err = parseFloat32(a.Value)
+		case attrRoughnessFactor:
+			d.resource.RoughnessFactor, err = parseFloat32(a.Value)
+		}
+		if err != nil {
+			errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, required))
+		}
+	}
+	return errs
+}
+
+type translucentDisplayPropsDecoder struct {
+	baseDecoder
+	resource           TranslucentDisplayProperties
+	translucentDecoder translucentDecoder
+}
+
+func (d *translucentDisplayPropsDecoder) Element() interface{} {
+	return &d.resource
+}
+
+func (d *translucentDisplayPropsDecoder) Child(name xml.Name) (i int, child spec.ElementDecoder) {
+	if name.Space == Namespace && name.Local == attrTranslucent {
+		child = &d.translucentDecoder
+		i = len(d.resource.Properties)
+	}
+	return
+}
+
+func (d *translucentDisplayPropsDecoder) Start(attrs []spec.XMLAttr) error {
+	d.translucentDecoder.resource = &d.resource
+	return parseID(attrs, &d.resource.ID)
+}
+
+type translucentDecoder struct {
+	baseDecoder
+	resource *TranslucentDisplayProperties
+}
+
+func (d *translucentDecoder) Start(attrs []spec.XMLAttr) error {
+	var (
+		prop = Translucent{RefractiveIndex: [3]float32{1, 1, 1}}
+		errs error
+	)
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		var err error
+		switch a.Name.Local {
+		case attrName:
+			prop.Name = string(a.Value)
+		case attrAttenuation:
+			prop.Attenuation, err = parseFloat32Triplet(a.Value)
+		case attrRefractiveIndex:
+			prop.RefractiveIndex, err = parseFloat32Triplet(a.Value)
+		case attrRoughness:
+			prop.Roughness, err = parseFloat32(a.Value)
+		}
+		if err != nil {
+			errs = specerr.Append(errs, specerr.NewParseAttrError(a.Name.Local, a.Name.Local == attrAttenuation))
+		}
+	}
+	d.resource.Properties = append(d.resource.Properties, prop)
+	return errs
+}
+
+func parseID(attrs []spec.XMLAttr, id *uint32) error {
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == attrID {
+			if err := parseUint32(a.Value, id); err != nil {
+				return specerr.NewParseAttrError(a.Name.Local, true)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func parseUint32(s []byte, v *uint32) error {
+	val, err := strconv.ParseUint(string(s), 10, 32)
+	*v = uint32(val)
+	return err
+}
+
+func parseFloat32(s []byte) (float32, error) {
+	val, err := strconv.ParseFloat(string(s), 32)
+	return float32(val), err
+}
+
+func parseFloat32Triplet(s []byte) (v [3]float32, err error) {
+	fields := strings.Fields(string(s))
+	if len(fields) != 3 {
+		return v, strconv.ErrSyntax
+	}
+	for i, f := range fields {
+		var val float64
+		if val, err = strconv.ParseFloat(f, 32); err != nil {
+			return
+		}
+		v[i] = float32(val)
+	}
+	return
+}
+
 type baseDecoder struct {
 }
 
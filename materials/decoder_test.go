@@ -11,6 +11,7 @@ import (
 	"github.com/go-test/deep"
 	"github.com/hpinc/go3mf"
 	"github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
 )
 
 func TestDecode(t *testing.T) {
@@ -19,18 +20,36 @@ func TestDecode(t *testing.T) {
 	texGroup := &Texture2DGroup{ID: 2, TextureID: 6, Coords: []TextureCoord{{0.3, 0.5}, {0.3, 0.8}, {0.5, 0.8}, {0.5, 0.5}}}
 	compositeGroup := &CompositeMaterials{ID: 4, MaterialID: 5, Indices: []uint32{1, 2}, Composites: []Composite{{Values: []float32{0.5, 0.5}}, {Values: []float32{0.2, 0.8}}}}
 	multiGroup := &MultiProperties{ID: 9, BlendMethods: []BlendMethod{BlendMultiply}, PIDs: []uint32{5, 2}, Multis: []Multi{{PIndices: []uint32{0, 0}}, {PIndices: []uint32{1, 0}}, {PIndices: []uint32{2, 3}}}}
+	specularGroup := &PBSpecularDisplayProperties{ID: 10, Properties: []PBSpecular{
+		{Name: "Shiny", SpecularColor: color.RGBA{R: 255, G: 255, B: 255, A: 255}, Glossiness: 0.8},
+		{Name: "Default", SpecularColor: color.RGBA{R: 56, G: 56, B: 56, A: 255}},
+	}}
+	metallicGroup := &PBMetallicDisplayProperties{ID: 11, Properties: []PBMetallic{
+		{Name: "Metal", Metallicness: 1, Roughness: 0.2},
+		{Name: "Default", Roughness: 1},
+	}}
+	specularTexture := &PBSpecularTextureDisplayProperties{ID: 12, Name: "Tex", SpecularTextureID: 6, GlossinessTextureID: 6,
+		DiffuseFactor: color.RGBA{R: 255, G: 255, B: 255, A: 255}, SpecularFactor: color.RGBA{R: 255, A: 255}, GlossinessFactor: 1}
+	metallicTexture := &PBMetallicTextureDisplayProperties{ID: 13, Name: "Tex", MetallicTextureID: 6, RoughnessTextureID: 6, MetallicFactor: 0.5, RoughnessFactor: 1}
+	translucentGroup := &TranslucentDisplayProperties{ID: 14, Properties: []Translucent{
+		{Name: "Glass", Attenuation: [3]float32{0.1, 0.2, 0.3}, RefractiveIndex: [3]float32{1.5, 1.5, 1.5}, Roughness: 0.1},
+		{Name: "Default", RefractiveIndex: [3]float32{1, 1, 1}},
+	}}
+	colorGroup.DisplayPropertiesID = 10
+	baseMaterials := &go3mf.BaseMaterials{ID: 15, Materials: []go3mf.Base{{Name: "a", Color: color.RGBA{R: 255, A: 255}}}, AnyAttr: spec.AnyAttr{&BaseMaterialsAttr{DisplayPropertiesID: 11}}}
 	want := &go3mf.Model{
 		Path:       "/3D/3dmodel.model",
 		Extensions: []go3mf.Extension{DefaultExtension},
 	}
-	want.Resources.Assets = append(want.Resources.Assets, baseTexture, colorGroup, texGroup, compositeGroup, multiGroup)
+	want.Resources.Assets = append(want.Resources.Assets, baseTexture, colorGroup, texGroup, compositeGroup, multiGroup,
+		specularGroup, metallicGroup, specularTexture, metallicTexture, translucentGroup, baseMaterials)
 	got := new(go3mf.Model)
 	got.Path = "/3D/3dmodel.model"
 	rootFile := `
 	<model xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02" xmlns:m="http://schemas.microsoft.com/3dmanufacturing/material/2015/02">
 		<resources>
 			<m:texture2d id="6" path="/3D/Texture/msLogo.png" contenttype="image/png" tilestyleu="wrap" tilestylev="mirror" filter="auto" />
-			<m:colorgroup id="1">
+			<m:colorgroup id="1" displaypropertiesid="10">
 				<m:color color="#FFFFFF" /> <m:color color="#000000" /> <m:color color="#1AB567" /> <m:color color="#DF045A" />
 			</m:colorgroup>
 			<m:texture2dgroup id="2" texid="6">
@@ -45,6 +64,23 @@ func TestDecode(t *testing.T) {
 				<m:multi pindices="1 0" />
 				<m:multi pindices="2 3" />
 			</m:multiproperties>
+			<m:pbspeculardisplayproperties id="10">
+				<m:pbspecular name="Shiny" specularcolor="#FFFFFF" glossiness="0.8" />
+				<m:pbspecular name="Default" />
+			</m:pbspeculardisplayproperties>
+			<m:pbmetallicdisplayproperties id="11">
+				<m:pbmetallic name="Metal" metallicness="1" roughness="0.2" />
+				<m:pbmetallic name="Default" />
+			</m:pbmetallicdisplayproperties>
+			<m:pbspeculartexturedisplayproperties id="12" name="Tex" speculartextureid="6" glossinesstextureid="6" specularfactor="#FF0000" />
+			<m:pbmetallictexturedisplayproperties id="13" name="Tex" metallictextureid="6" roughnesstextureid="6" metallicfactor="0.5" />
+			<m:translucentdisplayproperties id="14">
+				<m:translucent name="Glass" attenuation="0.1 0.2 0.3" refractiveindex="1.5 1.5 1.5" roughness="0.1" />
+				<m:translucent name="Default" attenuation="0 0 0" />
+			</m:translucentdisplayproperties>
+			<basematerials id="15" m:displaypropertiesid="11">
+				<base name="a" displaycolor="#FF0000" />
+			</basematerials>
 		</resources>
 		<build>
 		</build>
@@ -71,6 +107,10 @@ func TestDecode_warns(t *testing.T) {
 		fmt.Sprintf("go3mf: XPath: /model/resources/compositematerials[4]: %v", errors.NewParseAttrError("matid", true)),
 		fmt.Sprintf("go3mf: XPath: /model/resources/compositematerials[4]/composite[1]: %v", errors.NewParseAttrError("values", true)),
 		fmt.Sprintf("go3mf: XPath: /model/resources/multiproperties[5]: %v", errors.NewParseAttrError("pids", true)),
+		fmt.Sprintf("go3mf: XPath: /model/resources/pbspeculardisplayproperties[7]/pbspecular[0]: %v", errors.NewParseAttrError("glossiness", false)),
+		fmt.Sprintf("go3mf: XPath: /model/resources/pbmetallictexturedisplayproperties[8]: %v", errors.NewParseAttrError("metallictextureid", true)),
+		fmt.Sprintf("go3mf: XPath: /model/resources/translucentdisplayproperties[9]/translucent[0]: %v", errors.NewParseAttrError("attenuation", true)),
+		fmt.Sprintf("go3mf: XPath: /model/resources/basematerials[10]: %v", errors.NewParseAttrError("displaypropertiesid", true)),
 	}
 	got := new(go3mf.Model)
 	got.Path = "/3D/3dmodel.model"
@@ -93,6 +133,14 @@ func TestDecode_warns(t *testing.T) {
 				<m:multi />
 			</m:multiproperties>
 			<m:multiproperties id="19" />
+			<m:pbspeculardisplayproperties id="20">
+				<m:pbspecular name="a" glossiness="a" />
+			</m:pbspeculardisplayproperties>
+			<m:pbmetallictexturedisplayproperties id="21" name="a" metallictextureid="a" roughnesstextureid="6" />
+			<m:translucentdisplayproperties id="22">
+				<m:translucent name="a" attenuation="0 0" />
+			</m:translucentdisplayproperties>
+			<basematerials id="23" m:displaypropertiesid="a" />
 			<object id="8" name="Box 1" pid="5" pindex="0" type="model">
 				<mesh>
 					<vertices>
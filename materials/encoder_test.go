@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-test/deep"
 	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/spec"
 )
 
 func TestMarshalModel(t *testing.T) {
@@ -17,8 +18,19 @@ func TestMarshalModel(t *testing.T) {
 	texGroup := &Texture2DGroup{ID: 2, TextureID: 6, Coords: []TextureCoord{{0.3, 0.5}, {0.3, 0.8}, {0.5, 0.8}, {0.5, 0.5}}}
 	compositeGroup := &CompositeMaterials{ID: 4, MaterialID: 5, Indices: []uint32{1, 2}, Composites: []Composite{{Values: []float32{0.5, 0.5}}, {Values: []float32{0.2, 0.8}}}}
 	multiGroup := &MultiProperties{ID: 9, BlendMethods: []BlendMethod{BlendMultiply}, PIDs: []uint32{5, 2}, Multis: []Multi{{PIndices: []uint32{0, 0}}, {PIndices: []uint32{1, 0}}, {PIndices: []uint32{2, 3}}}}
+	specularGroup := &PBSpecularDisplayProperties{ID: 10, Properties: []PBSpecular{{Name: "Shiny", SpecularColor: color.RGBA{R: 255, G: 255, B: 255, A: 255}, Glossiness: 0.8}}}
+	metallicGroup := &PBMetallicDisplayProperties{ID: 11, Properties: []PBMetallic{{Name: "Metal", Metallicness: 1, Roughness: 0.2}}}
+	specularTexture := &PBSpecularTextureDisplayProperties{ID: 12, Name: "Tex", SpecularTextureID: 6, GlossinessTextureID: 6,
+		DiffuseFactor: color.RGBA{R: 255, G: 255, B: 255, A: 255}, SpecularFactor: color.RGBA{R: 255, A: 255}, GlossinessFactor: 1}
+	metallicTexture := &PBMetallicTextureDisplayProperties{ID: 13, Name: "Tex", MetallicTextureID: 6, RoughnessTextureID: 6, MetallicFactor: 0.5, RoughnessFactor: 1}
+	translucentGroup := &TranslucentDisplayProperties{ID: 14, Properties: []Translucent{{Name: "Glass", Attenuation: [3]float32{0.1, 0.2, 0.3}, RefractiveIndex: [3]float32{1.5, 1.5, 1.5}, Roughness: 0.1}}}
+	baseMaterials := &go3mf.BaseMaterials{ID: 15, Materials: []go3mf.Base{{Name: "a", Color: color.RGBA{R: 255, A: 255}}}, AnyAttr: spec.AnyAttr{&BaseMaterialsAttr{DisplayPropertiesID: 11}}}
+	colorGroup.DisplayPropertiesID = 14
+	texGroup.DisplayPropertiesID = 12
+	compositeGroup.DisplayPropertiesID = 11
 	m := &go3mf.Model{Path: "/3D/3dmodel.model"}
-	m.Resources.Assets = append(m.Resources.Assets, baseTexture, colorGroup, texGroup, compositeGroup, multiGroup)
+	m.Resources.Assets = append(m.Resources.Assets, baseTexture, colorGroup, texGroup, compositeGroup, multiGroup,
+		specularGroup, metallicGroup, specularTexture, metallicTexture, translucentGroup, baseMaterials)
 	m.Extensions = []go3mf.Extension{DefaultExtension}
 	t.Run("base", func(t *testing.T) {
 		b, err := go3mf.MarshalModel(m)
@@ -0,0 +1,419 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+
+	"github.com/hpinc/go3mf/spec"
+)
+
+type jsonBaseMaterialsAttr struct {
+	DisplayPropertiesID uint32 `json:"displayPropertiesId,omitempty"`
+}
+
+type jsonTexture2D struct {
+	ID          uint32 `json:"id"`
+	Path        string `json:"path"`
+	ContentType string `json:"contentType,omitempty"`
+	TileStyleU  string `json:"tileStyleU,omitempty"`
+	TileStyleV  string `json:"tileStyleV,omitempty"`
+	Filter      string `json:"filter,omitempty"`
+}
+
+type jsonTexture2DGroup struct {
+	ID                  uint32         `json:"id"`
+	TextureID           uint32         `json:"textureId"`
+	DisplayPropertiesID uint32         `json:"displayPropertiesId,omitempty"`
+	Coords              []TextureCoord `json:"coords,omitempty"`
+}
+
+type jsonColorGroup struct {
+	ID                  uint32   `json:"id"`
+	DisplayPropertiesID uint32   `json:"displayPropertiesId,omitempty"`
+	Colors              []string `json:"colors,omitempty"`
+}
+
+type jsonCompositeMaterials struct {
+	ID                  uint32      `json:"id"`
+	MaterialID          uint32      `json:"materialId"`
+	DisplayPropertiesID uint32      `json:"displayPropertiesId,omitempty"`
+	Indices             []uint32    `json:"indices,omitempty"`
+	Composites          [][]float32 `json:"composites,omitempty"`
+}
+
+type jsonMultiProperties struct {
+	ID           uint32     `json:"id"`
+	PIDs         []uint32   `json:"pids,omitempty"`
+	BlendMethods []string   `json:"blendMethods,omitempty"`
+	Multis       [][]uint32 `json:"multis,omitempty"`
+}
+
+type jsonPBSpecular struct {
+	Name          string  `json:"name"`
+	SpecularColor string  `json:"specularColor"`
+	Glossiness    float32 `json:"glossiness"`
+}
+
+type jsonPBSpecularDisplayProperties struct {
+	ID         uint32           `json:"id"`
+	Properties []jsonPBSpecular `json:"properties,omitempty"`
+}
+
+type jsonPBMetallic struct {
+	Name         string  `json:"name"`
+	Metallicness float32 `json:"metallicness"`
+	Roughness    float32 `json:"roughness"`
+}
+
+type jsonPBMetallicDisplayProperties struct {
+	ID         uint32           `json:"id"`
+	Properties []jsonPBMetallic `json:"properties,omitempty"`
+}
+
+type jsonPBSpecularTextureDisplayProperties struct {
+	ID                  uint32  `json:"id"`
+	Name                string  `json:"name"`
+	SpecularTextureID   uint32  `json:"specularTextureId"`
+	GlossinessTextureID uint32  `json:"glossinessTextureId"`
+	DiffuseFactor       string  `json:"diffuseFactor"`
+	SpecularFactor      string  `json:"specularFactor"`
+	GlossinessFactor    float32 `json:"glossinessFactor"`
+}
+
+type jsonPBMetallicTextureDisplayProperties struct {
+	ID                 uint32  `json:"id"`
+	Name               string  `json:"name"`
+	MetallicTextureID  uint32  `json:"metallicTextureId"`
+	RoughnessTextureID uint32  `json:"roughnessTextureId"`
+	MetallicFactor     float32 `json:"metallicFactor"`
+	RoughnessFactor    float32 `json:"roughnessFactor"`
+}
+
+type jsonTranslucent struct {
+	Name            string     `json:"name"`
+	Attenuation     [3]float32 `json:"attenuation"`
+	RefractiveIndex [3]float32 `json:"refractiveIndex"`
+	Roughness       float32    `json:"roughness"`
+}
+
+type jsonTranslucentDisplayProperties struct {
+	ID         uint32            `json:"id"`
+	Properties []jsonTranslucent `json:"properties,omitempty"`
+}
+
+// MarshalJSON returns the JSON encoding of u.
+func (u BaseMaterialsAttr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonBaseMaterialsAttr(u))
+}
+
+// UnmarshalJSON decodes the JSON encoding of u.
+func (u *BaseMaterialsAttr) UnmarshalJSON(b []byte) error {
+	var ju jsonBaseMaterialsAttr
+	if err := json.Unmarshal(b, &ju); err != nil {
+		return err
+	}
+	*u = BaseMaterialsAttr(ju)
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of t.
+func (t Texture2D) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTexture2D{
+		ID:          t.ID,
+		Path:        t.Path,
+		ContentType: t.ContentType.String(),
+		TileStyleU:  t.TileStyleU.String(),
+		TileStyleV:  t.TileStyleV.String(),
+		Filter:      t.Filter.String(),
+	})
+}
+
+// UnmarshalJSON decodes the JSON encoding of t.
+func (t *Texture2D) UnmarshalJSON(b []byte) error {
+	var jt jsonTexture2D
+	if err := json.Unmarshal(b, &jt); err != nil {
+		return err
+	}
+	r := Texture2D{ID: jt.ID, Path: jt.Path}
+	var ok bool
+	if jt.ContentType != "" {
+		if r.ContentType, ok = newTexture2DType(jt.ContentType); !ok {
+			return fmt.Errorf("materials: invalid content type %s", jt.ContentType)
+		}
+	}
+	if jt.TileStyleU != "" {
+		if r.TileStyleU, ok = newTileStyle(jt.TileStyleU); !ok {
+			return fmt.Errorf("materials: invalid tile style %s", jt.TileStyleU)
+		}
+	}
+	if jt.TileStyleV != "" {
+		if r.TileStyleV, ok = newTileStyle(jt.TileStyleV); !ok {
+			return fmt.Errorf("materials: invalid tile style %s", jt.TileStyleV)
+		}
+	}
+	if jt.Filter != "" {
+		if r.Filter, ok = newTextureFilter(jt.Filter); !ok {
+			return fmt.Errorf("materials: invalid filter %s", jt.Filter)
+		}
+	}
+	*t = r
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r Texture2DGroup) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTexture2DGroup(r))
+}
+
+// UnmarshalJSON decodes the JSON encoding of r.
+func (r *Texture2DGroup) UnmarshalJSON(b []byte) error {
+	var jr jsonTexture2DGroup
+	if err := json.Unmarshal(b, &jr); err != nil {
+		return err
+	}
+	*r = Texture2DGroup(jr)
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r ColorGroup) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonColorGroup{
+		ID:                  r.ID,
+		DisplayPropertiesID: r.DisplayPropertiesID,
+		Colors:              formatColors(r.Colors),
+	})
+}
+
+// UnmarshalJSON decodes the JSON encoding of r.
+func (r *ColorGroup) UnmarshalJSON(b []byte) error {
+	var jr jsonColorGroup
+	if err := json.Unmarshal(b, &jr); err != nil {
+		return err
+	}
+	colors, err := parseColors(jr.Colors)
+	if err != nil {
+		return err
+	}
+	*r = ColorGroup{ID: jr.ID, DisplayPropertiesID: jr.DisplayPropertiesID, Colors: colors}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r CompositeMaterials) MarshalJSON() ([]byte, error) {
+	jr := jsonCompositeMaterials{
+		ID:                  r.ID,
+		MaterialID:          r.MaterialID,
+		DisplayPropertiesID: r.DisplayPropertiesID,
+		Indices:             r.Indices,
+	}
+	for _, c := range r.Composites {
+		jr.Composites = append(jr.Composites, c.Values)
+	}
+	return json.Marshal(jr)
+}
+
+// UnmarshalJSON decodes the JSON encoding of r.
+func (r *CompositeMaterials) UnmarshalJSON(b []byte) error {
+	var jr jsonCompositeMaterials
+	if err := json.Unmarshal(b, &jr); err != nil {
+		return err
+	}
+	*r = CompositeMaterials{
+		ID:                  jr.ID,
+		MaterialID:          jr.MaterialID,
+		DisplayPropertiesID: jr.DisplayPropertiesID,
+		Indices:             jr.Indices,
+	}
+	for _, v := range jr.Composites {
+		r.Composites = append(r.Composites, Composite{Values: v})
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r MultiProperties) MarshalJSON() ([]byte, error) {
+	jr := jsonMultiProperties{ID: r.ID, PIDs: r.PIDs}
+	for _, m := range r.BlendMethods {
+		jr.BlendMethods = append(jr.BlendMethods, m.String())
+	}
+	for _, m := range r.Multis {
+		jr.Multis = append(jr.Multis, m.PIndices)
+	}
+	return json.Marshal(jr)
+}
+
+// UnmarshalJSON decodes the JSON encoding of r.
+func (r *MultiProperties) UnmarshalJSON(b []byte) error {
+	var jr jsonMultiProperties
+	if err := json.Unmarshal(b, &jr); err != nil {
+		return err
+	}
+	mp := MultiProperties{ID: jr.ID, PIDs: jr.PIDs}
+	for _, s := range jr.BlendMethods {
+		m, ok := newBlendMethod(s)
+		if !ok {
+			return fmt.Errorf("materials: invalid blend method %s", s)
+		}
+		mp.BlendMethods = append(mp.BlendMethods, m)
+	}
+	for _, v := range jr.Multis {
+		mp.Multis = append(mp.Multis, Multi{PIndices: v})
+	}
+	*r = mp
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r PBSpecularDisplayProperties) MarshalJSON() ([]byte, error) {
+	jr := jsonPBSpecularDisplayProperties{ID: r.ID}
+	for _, p := range r.Properties {
+		jr.Properties = append(jr.Properties, jsonPBSpecular{
+			Name:          p.Name,
+			SpecularColor: spec.FormatRGBA(p.SpecularColor),
+			Glossiness:    p.Glossiness,
+		})
+	}
+	return json.Marshal(jr)
+}
+
+// UnmarshalJSON decodes the JSON encoding of r.
+func (r *PBSpecularDisplayProperties) UnmarshalJSON(b []byte) error {
+	var jr jsonPBSpecularDisplayProperties
+	if err := json.Unmarshal(b, &jr); err != nil {
+		return err
+	}
+	pb := PBSpecularDisplayProperties{ID: jr.ID}
+	for _, p := range jr.Properties {
+		c, err := spec.ParseRGBA(p.SpecularColor)
+		if err != nil {
+			return err
+		}
+		pb.Properties = append(pb.Properties, PBSpecular{Name: p.Name, SpecularColor: c, Glossiness: p.Glossiness})
+	}
+	*r = pb
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r PBMetallicDisplayProperties) MarshalJSON() ([]byte, error) {
+	jr := jsonPBMetallicDisplayProperties{ID: r.ID}
+	for _, p := range r.Properties {
+		jr.Properties = append(jr.Properties, jsonPBMetallic(p))
+	}
+	return json.Marshal(jr)
+}
+
+// UnmarshalJSON decodes the JSON encoding of r.
+func (r *PBMetallicDisplayProperties) UnmarshalJSON(b []byte) error {
+	var jr jsonPBMetallicDisplayProperties
+	if err := json.Unmarshal(b, &jr); err != nil {
+		return err
+	}
+	pb := PBMetallicDisplayProperties{ID: jr.ID}
+	for _, p := range jr.Properties {
+		pb.Properties = append(pb.Properties, PBMetallic(p))
+	}
+	*r = pb
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r PBSpecularTextureDisplayProperties) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonPBSpecularTextureDisplayProperties{
+		ID:                  r.ID,
+		Name:                r.Name,
+		SpecularTextureID:   r.SpecularTextureID,
+		GlossinessTextureID: r.GlossinessTextureID,
+		DiffuseFactor:       spec.FormatRGBA(r.DiffuseFactor),
+		SpecularFactor:      spec.FormatRGBA(r.SpecularFactor),
+		GlossinessFactor:    r.GlossinessFactor,
+	})
+}
+
+// UnmarshalJSON decodes the JSON encoding of r.
+func (r *PBSpecularTextureDisplayProperties) UnmarshalJSON(b []byte) error {
+	var jr jsonPBSpecularTextureDisplayProperties
+	if err := json.Unmarshal(b, &jr); err != nil {
+		return err
+	}
+	diffuse, err := spec.ParseRGBA(jr.DiffuseFactor)
+	if err != nil {
+		return err
+	}
+	specular, err := spec.ParseRGBA(jr.SpecularFactor)
+	if err != nil {
+		return err
+	}
+	*r = PBSpecularTextureDisplayProperties{
+		ID:                  jr.ID,
+		Name:                jr.Name,
+		SpecularTextureID:   jr.SpecularTextureID,
+		GlossinessTextureID: jr.GlossinessTextureID,
+		DiffuseFactor:       diffuse,
+		SpecularFactor:      specular,
+		GlossinessFactor:    jr.GlossinessFactor,
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r PBMetallicTextureDisplayProperties) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonPBMetallicTextureDisplayProperties(r))
+}
+
+// UnmarshalJSON decodes the JSON encoding of r.
+func (r *PBMetallicTextureDisplayProperties) UnmarshalJSON(b []byte) error {
+	var jr jsonPBMetallicTextureDisplayProperties
+	if err := json.Unmarshal(b, &jr); err != nil {
+		return err
+	}
+	*r = PBMetallicTextureDisplayProperties(jr)
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r TranslucentDisplayProperties) MarshalJSON() ([]byte, error) {
+	jr := jsonTranslucentDisplayProperties{ID: r.ID}
+	for _, p := range r.Properties {
+		jr.Properties = append(jr.Properties, jsonTranslucent(p))
+	}
+	return json.Marshal(jr)
+}
+
+// UnmarshalJSON decodes the JSON encoding of r.
+func (r *TranslucentDisplayProperties) UnmarshalJSON(b []byte) error {
+	var jr jsonTranslucentDisplayProperties
+	if err := json.Unmarshal(b, &jr); err != nil {
+		return err
+	}
+	tr := TranslucentDisplayProperties{ID: jr.ID}
+	for _, p := range jr.Properties {
+		tr.Properties = append(tr.Properties, Translucent(p))
+	}
+	*r = tr
+	return nil
+}
+
+func formatColors(colors []color.RGBA) []string {
+	var s []string
+	for _, c := range colors {
+		s = append(s, spec.FormatRGBA(c))
+	}
+	return s
+}
+
+func parseColors(s []string) ([]color.RGBA, error) {
+	var colors []color.RGBA
+	for _, v := range s {
+		c, err := spec.ParseRGBA(v)
+		if err != nil {
+			return nil, err
+		}
+		colors = append(colors, c)
+	}
+	return colors, nil
+}
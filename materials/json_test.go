@@ -0,0 +1,70 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/color"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func TestModel_JSON(t *testing.T) {
+	m := &go3mf.Model{Path: "/3D/3dmodel.model"}
+	m.Resources.Assets = []go3mf.Asset{
+		&go3mf.BaseMaterials{ID: 1, Materials: []go3mf.Base{{Name: "a", Color: color.RGBA{R: 255, A: 255}}}, AnyAttr: spec.AnyAttr{&BaseMaterialsAttr{DisplayPropertiesID: 9}}},
+		&Texture2D{ID: 2, Path: "/3D/Textures/tex.png", ContentType: TextureTypePNG, TileStyleU: TileMirror, TileStyleV: TileNone, Filter: TextureFilterNearest},
+		&Texture2DGroup{ID: 3, TextureID: 2, DisplayPropertiesID: 11, Coords: []TextureCoord{{0.3, 0.5}, {1, 0}}},
+		&ColorGroup{ID: 4, DisplayPropertiesID: 9, Colors: []color.RGBA{{R: 255, G: 1, B: 2, A: 3}}},
+		&CompositeMaterials{ID: 5, MaterialID: 1, Indices: []uint32{0, 0}, Composites: []Composite{{Values: []float32{0.25, 0.75}}}},
+		&MultiProperties{ID: 6, PIDs: []uint32{1, 4}, BlendMethods: []BlendMethod{BlendMultiply}, Multis: []Multi{{PIndices: []uint32{0, 0}}}},
+		&PBSpecularDisplayProperties{ID: 7, Properties: []PBSpecular{{Name: "s", SpecularColor: color.RGBA{R: 56, G: 56, B: 56, A: 255}, Glossiness: 0.5}}},
+		&PBMetallicDisplayProperties{ID: 8, Properties: []PBMetallic{{Name: "m", Metallicness: 1, Roughness: 0.2}}},
+		&PBSpecularTextureDisplayProperties{ID: 10, Name: "st", SpecularTextureID: 2, GlossinessTextureID: 2, DiffuseFactor: color.RGBA{R: 255, G: 255, B: 255, A: 255}, SpecularFactor: color.RGBA{A: 255}, GlossinessFactor: 1},
+		&PBMetallicTextureDisplayProperties{ID: 11, Name: "mt", MetallicTextureID: 2, RoughnessTextureID: 2, MetallicFactor: 1, RoughnessFactor: 0.5},
+		&TranslucentDisplayProperties{ID: 12, Properties: []Translucent{{Name: "t", Attenuation: [3]float32{1, 2, 3}, RefractiveIndex: [3]float32{1, 1, 1}, Roughness: 0.1}}},
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Model.MarshalJSON() error = %v", err)
+	}
+	if bytes.Contains(b, []byte(`"xml":`)) || bytes.Contains(b, []byte(`"attr":`)) {
+		t.Errorf("Model.MarshalJSON() encoded raw xml, s = %s", b)
+	}
+	got := new(go3mf.Model)
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatalf("Model.UnmarshalJSON() error = %v, s = %s", err, b)
+	}
+	if diff := deep.Equal(m, got); diff != nil {
+		t.Errorf("Model.UnmarshalJSON() = %v, s = %s", diff, b)
+	}
+}
+
+func TestModel_UnmarshalJSON_error(t *testing.T) {
+	tests := []struct {
+		name  string
+		local string
+		s     string
+	}{
+		{"contentType", attrTexture2D, `{"id":1,"contentType":"image/gif"}`},
+		{"tileStyle", attrTexture2D, `{"id":1,"tileStyleU":"foo"}`},
+		{"filter", attrTexture2D, `{"id":1,"filter":"foo"}`},
+		{"color", attrColorGroup, `{"id":1,"colors":["red"]}`},
+		{"blendMethod", attrMultiProps, `{"id":1,"blendMethods":["foo"]}`},
+		{"specularColor", attrPBSpecularDisplayProps, `{"id":1,"properties":[{"specularColor":"red"}]}`},
+		{"diffuseFactor", attrPBSpecularTextureDisplayProps, `{"id":1,"diffuseFactor":"red"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := `{"resources":{"assets":[{"element":{"space":"` + Namespace + `","local":"` + tt.local + `","json":` + tt.s + `}}]}}`
+			if err := json.Unmarshal([]byte(s), new(go3mf.Model)); err == nil {
+				t.Error("Model.UnmarshalJSON() expected error")
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"image/color"
 
 	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
 	"github.com/hpinc/go3mf/spec"
 )
 
@@ -25,8 +26,35 @@ var DefaultExtension = go3mf.Extension{
 	IsRequired: false,
 }
 
+// Declare adds DefaultExtension to the extensions of m if the
+// materials namespace is not declared yet, else the materials
+// and the properties referencing them would not be encoded.
+func Declare(m *go3mf.Model) {
+	for _, ext := range m.Extensions {
+		if ext.Namespace == Namespace {
+			return
+		}
+	}
+	m.Extensions = append(m.Extensions, DefaultExtension)
+}
+
 func init() {
 	spec.Register(Namespace, Spec{})
+	for _, r := range []specerr.Rule{
+		{ID: "materials.multi.blend-methods", Severity: specerr.SeverityError, Chapter: "5", Err: ErrMultiBlend},
+		{ID: "materials.multi.material-layer", Severity: specerr.SeverityError, Chapter: "5", Err: ErrMaterialMulti},
+		{ID: "materials.multi.reference-multi", Severity: specerr.SeverityError, Chapter: "5", Err: ErrMultiRefMulti},
+		{ID: "materials.multi.multiple-colors", Severity: specerr.SeverityError, Chapter: "5", Err: ErrMultiColors},
+		{ID: "materials.texture.reference", Severity: specerr.SeverityError, Chapter: "3", Err: ErrTextureReference},
+		{ID: "materials.composite.base", Severity: specerr.SeverityError, Chapter: "4", Err: ErrCompositeBase},
+		{ID: "materials.texture.missing-part", Severity: specerr.SeverityError, Chapter: "3", Err: ErrMissingTexturePart},
+		{ID: "materials.display.reference", Severity: specerr.SeverityError, Chapter: "6", Err: ErrDisplayProperties},
+		{ID: "materials.display.count", Severity: specerr.SeverityError, Chapter: "6", Err: ErrDisplayPropsCount},
+		{ID: "materials.texture.location", Severity: specerr.SeverityWarning, Chapter: "3", Err: ErrTextureLocation},
+		{ID: "materials.property-resource", Severity: specerr.SeverityError, Chapter: "1", Err: ErrPropertyResource},
+	} {
+		specerr.RegisterRule(r)
+	}
 }
 
 type Spec struct{}
@@ -39,6 +67,9 @@ var (
 	ErrTextureReference   = errors.New("MUST reference to a texture resource")
 	ErrCompositeBase      = errors.New("MUST reference to a basematerials group")
 	ErrMissingTexturePart = errors.New("texture part MUST be added as an attachment")
+	ErrDisplayProperties  = errors.New("displaypropertiesid MUST reference to a display properties resource of a compatible type")
+	ErrDisplayPropsCount  = errors.New("display properties MUST contain as many elements as the referencing group")
+	ErrTextureLocation    = errors.New("texture parts SHOULD be stored in the /3D/Textures/ directory")
 )
 
 // Texture2DType defines the allowed texture 2D types.
@@ -146,9 +177,10 @@ func (t TextureCoord) V() float32 {
 
 // Texture2DGroup acts as a container for texture coordinate properties.
 type Texture2DGroup struct {
-	ID        uint32
-	TextureID uint32
-	Coords    []TextureCoord
+	ID                  uint32
+	TextureID           uint32
+	DisplayPropertiesID uint32
+	Coords              []TextureCoord
 }
 
 // Len returns the materials count.
@@ -168,8 +200,9 @@ func (Texture2DGroup) XMLName() xml.Name {
 
 // ColorGroup acts as a container for color properties.
 type ColorGroup struct {
-	ID     uint32
-	Colors []color.RGBA
+	ID                  uint32
+	DisplayPropertiesID uint32
+	Colors              []color.RGBA
 }
 
 // Len returns the materials count.
@@ -194,10 +227,11 @@ type Composite struct {
 
 // CompositeMaterials defines materials derived by mixing 2 or more base materials in defined ratios.
 type CompositeMaterials struct {
-	ID         uint32
-	MaterialID uint32
-	Indices    []uint32
-	Composites []Composite
+	ID                  uint32
+	MaterialID          uint32
+	DisplayPropertiesID uint32
+	Indices             []uint32
+	Composites          []Composite
 }
 
 // Len returns the materials count.
@@ -244,6 +278,154 @@ func (MultiProperties) XMLName() xml.Name {
 	return xml.Name{Space: Namespace, Local: attrMultiProps}
 }
 
+// BaseMaterialsAttr provides the display properties
+// of a core basematerials group.
+type BaseMaterialsAttr struct {
+	DisplayPropertiesID uint32
+}
+
+func (BaseMaterialsAttr) Namespace() string { return Namespace }
+
+func GetBaseMaterialsAttr(r *go3mf.BaseMaterials) *BaseMaterialsAttr {
+	for _, a := range r.AnyAttr {
+		if a, ok := a.(*BaseMaterialsAttr); ok {
+			return a
+		}
+	}
+	return nil
+}
+
+// PBSpecular defines the specular and glossiness
+// properties of a material in the specular workflow.
+type PBSpecular struct {
+	Name          string
+	SpecularColor color.RGBA
+	Glossiness    float32
+}
+
+// PBSpecularDisplayProperties acts as a container for PBSpecular properties.
+type PBSpecularDisplayProperties struct {
+	ID         uint32
+	Properties []PBSpecular
+}
+
+// Len returns the materials count.
+func (r *PBSpecularDisplayProperties) Len() int {
+	return len(r.Properties)
+}
+
+// Identify returns the unique ID of the resource.
+func (r *PBSpecularDisplayProperties) Identify() uint32 {
+	return r.ID
+}
+
+// XMLName returns the xml identifier of the resource.
+func (PBSpecularDisplayProperties) XMLName() xml.Name {
+	return xml.Name{Space: Namespace, Local: attrPBSpecularDisplayProps}
+}
+
+// PBMetallic defines the metallicness and roughness
+// properties of a material in the metallic workflow.
+type PBMetallic struct {
+	Name         string
+	Metallicness float32
+	Roughness    float32
+}
+
+// PBMetallicDisplayProperties acts as a container for PBMetallic properties.
+type PBMetallicDisplayProperties struct {
+	ID         uint32
+	Properties []PBMetallic
+}
+
+// Len returns the materials count.
+func (r *PBMetallicDisplayProperties) Len() int {
+	return len(r.Properties)
+}
+
+// Identify returns the unique ID of the resource.
+func (r *PBMetallicDisplayProperties) Identify() uint32 {
+	return r.ID
+}
+
+// XMLName returns the xml identifier of the resource.
+func (PBMetallicDisplayProperties) XMLName() xml.Name {
+	return xml.Name{Space: Namespace, Local: attrPBMetallicDisplayProps}
+}
+
+// PBSpecularTextureDisplayProperties defines the specular workflow
+// properties of a texture2dgroup using specular and glossiness textures.
+type PBSpecularTextureDisplayProperties struct {
+	ID                  uint32
+	Name                string
+	SpecularTextureID   uint32
+	GlossinessTextureID uint32
+	DiffuseFactor       color.RGBA
+	SpecularFactor      color.RGBA
+	GlossinessFactor    float32
+}
+
+// Identify returns the unique ID of the resource.
+func (r *PBSpecularTextureDisplayProperties) Identify() uint32 {
+	return r.ID
+}
+
+// XMLName returns the xml identifier of the resource.
+func (PBSpecularTextureDisplayProperties) XMLName() xml.Name {
+	return xml.Name{Space: Namespace, Local: attrPBSpecularTextureDisplayProps}
+}
+
+// PBMetallicTextureDisplayProperties defines the metallic workflow
+// properties of a texture2dgroup using metallicness and roughness textures.
+type PBMetallicTextureDisplayProperties struct {
+	ID                 uint32
+	Name               string
+	MetallicTextureID  uint32
+	RoughnessTextureID uint32
+	MetallicFactor     float32
+	RoughnessFactor    float32
+}
+
+// Identify returns the unique ID of the resource.
+func (r *PBMetallicTextureDisplayProperties) Identify() uint32 {
+	return r.ID
+}
+
+// XMLName returns the xml identifier of the resource.
+func (PBMetallicTextureDisplayProperties) XMLName() xml.Name {
+	return xml.Name{Space: Namespace, Local: attrPBMetallicTextureDisplayProps}
+}
+
+// Translucent defines the attenuation, refraction
+// and roughness properties of a translucent material.
+type Translucent struct {
+	Name            string
+	Attenuation     [3]float32
+	RefractiveIndex [3]float32
+	Roughness       float32
+}
+
+// TranslucentDisplayProperties acts as a container for Translucent properties.
+type TranslucentDisplayProperties struct {
+	ID         uint32
+	Properties []Translucent
+}
+
+// Len returns the materials count.
+func (r *TranslucentDisplayProperties) Len() int {
+	return len(r.Properties)
+}
+
+// Identify returns the unique ID of the resource.
+func (r *TranslucentDisplayProperties) Identify() uint32 {
+	return r.ID
+}
+
+// XMLName returns the xml identifier of the resource.
+func (TranslucentDisplayProperties) XMLName() xml.Name {
+	return xml.Name{Space: Namespace, Local: attrTranslucentDisplayProps}
+}
+
 func newTexture2DType(s string) (t Texture2DType, ok bool) {
 	t, ok = map[string]Texture2DType{
 		"image/png":  TextureTypePNG,
@@ -304,4 +486,30 @@ const (
 	attrPIndices           = "pindices"
 	attrPIDs               = "pids"
 	attrBlendMethods       = "blendmethods"
+
+	attrDisplayPropertiesID           = "displaypropertiesid"
+	attrPBSpecularDisplayProps        = "pbspeculardisplayproperties"
+	attrPBSpecular                    = "pbspecular"
+	attrSpecularColor                 = "specularcolor"
+	attrGlossiness                    = "glossiness"
+	attrPBMetallicDisplayProps        = "pbmetallicdisplayproperties"
+	attrPBMetallic                    = "pbmetallic"
+	attrMetallicness                  = "metallicness"
+	attrRoughness                     = "roughness"
+	attrPBSpecularTextureDisplayProps = "pbspeculartexturedisplayproperties"
+	attrSpecularTextureID             = "speculartextureid"
+	attrGlossinessTextureID           = "glossinesstextureid"
+	attrDiffuseFactor                 = "diffusefactor"
+	attrSpecularFactor                = "specularfactor"
+	attrGlossinessFactor              = "glossinessfactor"
+	attrPBMetallicTextureDisplayProps = "pbmetallictexturedisplayproperties"
+	attrMetallicTextureID             = "metallictextureid"
+	attrRoughnessTextureID            = "roughnesstextureid"
+	attrMetallicFactor                = "metallicfactor"
+	attrRoughnessFactor               = "roughnessfactor"
+	attrTranslucentDisplayProps       = "translucentdisplayproperties"
+	attrTranslucent                   = "translucent"
+	attrAttenuation                   = "attenuation"
+	attrRefractiveIndex               = "refractiveindex"
+	attrName                          = "name"
 )
@@ -388,3 +388,79 @@ func TestTexture2DGroup_Len(t *testing.T) {
 		})
 	}
 }
+
+func TestPBSpecularDisplayProperties_Len(t *testing.T) {
+	tests := []struct {
+		name string
+		r    *PBSpecularDisplayProperties
+		want int
+	}{
+		{"empty", new(PBSpecularDisplayProperties), 0},
+		{"base", &PBSpecularDisplayProperties{Properties: make([]PBSpecular, 3)}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Len(); got != tt.want {
+				t.Errorf("PBSpecularDisplayProperties.Len() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPBMetallicDisplayProperties_Len(t *testing.T) {
+	tests := []struct {
+		name string
+		r    *PBMetallicDisplayProperties
+		want int
+	}{
+		{"empty", new(PBMetallicDisplayProperties), 0},
+		{"base", &PBMetallicDisplayProperties{Properties: make([]PBMetallic, 3)}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Len(); got != tt.want {
+				t.Errorf("PBMetallicDisplayProperties.Len() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslucentDisplayProperties_Len(t *testing.T) {
+	tests := []struct {
+		name string
+		r    *TranslucentDisplayProperties
+		want int
+	}{
+		{"empty", new(TranslucentDisplayProperties), 0},
+		{"base", &TranslucentDisplayProperties{Properties: make([]Translucent, 3)}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Len(); got != tt.want {
+				t.Errorf("TranslucentDisplayProperties.Len() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeclare(t *testing.T) {
+	other := go3mf.Extension{Namespace: "http://other.com", LocalName: "o"}
+	tests := []struct {
+		name string
+		exts []go3mf.Extension
+		want []go3mf.Extension
+	}{
+		{"empty", nil, []go3mf.Extension{DefaultExtension}},
+		{"other", []go3mf.Extension{other}, []go3mf.Extension{other, DefaultExtension}},
+		{"declared", []go3mf.Extension{{Namespace: Namespace, LocalName: "mat"}}, []go3mf.Extension{{Namespace: Namespace, LocalName: "mat"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &go3mf.Model{Extensions: tt.exts}
+			Declare(m)
+			if !reflect.DeepEqual(m.Extensions, tt.want) {
+				t.Errorf("Declare() = %v, want %v", m.Extensions, tt.want)
+			}
+		})
+	}
+}
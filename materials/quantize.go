@@ -0,0 +1,263 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
+)
+
+// maxQuantizeIterations limits the number of k-means iterations done by QuantizeColors.
+const maxQuantizeIterations = 32
+
+// ErrQuantizeCount is returned when the requested number of base materials is not positive.
+var ErrQuantizeCount = errors.New("the number of base materials MUST be greater than zero")
+
+// QuantizeReport describes the color error introduced by QuantizeColors.
+// Errors are euclidean distances in the RGBA space, where each channel goes from 0 to 255.
+type QuantizeReport struct {
+	// Colors is the number of distinct colors before the quantization.
+	Colors int
+	// MaxError is the biggest distance between a color and its base material.
+	MaxError float64
+	// MeanError is the mean distance between the color of each triangle and its base material.
+	MeanError float64
+}
+
+type quantizeEntry struct {
+	c     color.RGBA
+	count int
+}
+
+// QuantizeColors maps all the colors used by the mesh objects defined in
+// the model part path to at most n base materials, which are added to the
+// part resources as a single go3mf.BaseMaterials.
+// The triangles and objects with properties are re-pointed to the new group.
+//
+// The color of each triangle is the color at its centroid, evaluated with
+// a ColorEvaluator, so color groups, textures, composites and multi properties
+// are all supported. Base materials cannot be interpolated, therefore
+// the three vertices of a triangle share the same base material.
+//
+// The property groups that were referenced before are not removed.
+func QuantizeColors(m *go3mf.Model, path string, n int) (*go3mf.BaseMaterials, QuantizeReport, error) {
+	if n <= 0 {
+		return nil, QuantizeReport{}, ErrQuantizeCount
+	}
+	rs, ok := m.FindResources(path)
+	if !ok {
+		return nil, QuantizeReport{}, specerr.ErrMissingResource
+	}
+	var (
+		r        = NewResolver(m, path)
+		e        = NewColorEvaluator(m)
+		counts   = make(map[color.RGBA]int)
+		triColor = make([][]*color.RGBA, len(rs.Objects))
+		objColor = make([]*color.RGBA, len(rs.Objects))
+	)
+	for i, obj := range rs.Objects {
+		if obj.PID != 0 {
+			p, err := r.Property(obj.PID, obj.PIndex)
+			if err != nil {
+				return nil, QuantizeReport{}, specerr.WrapIndex(err, "object", i)
+			}
+			c, err := e.Color(p)
+			if err != nil {
+				return nil, QuantizeReport{}, specerr.WrapIndex(err, "object", i)
+			}
+			objColor[i] = &c
+			if _, ok := counts[c]; !ok {
+				counts[c] = 0
+			}
+		}
+		props, err := r.Mesh(obj)
+		if err != nil {
+			return nil, QuantizeReport{}, specerr.WrapIndex(err, "object", i)
+		}
+		triColor[i] = make([]*color.RGBA, len(props))
+		for j, p := range props {
+			if p[0] == nil {
+				continue
+			}
+			c, err := e.ColorAt(p, [3]float32{1.0 / 3, 1.0 / 3, 1.0 / 3})
+			if err != nil {
+				return nil, QuantizeReport{}, specerr.WrapIndex(specerr.WrapIndex(err, "triangle", j), "object", i)
+			}
+			triColor[i][j] = &c
+			counts[c]++
+		}
+	}
+	if len(counts) == 0 {
+		return nil, QuantizeReport{}, nil
+	}
+	entries := make([]quantizeEntry, 0, len(counts))
+	for c, count := range counts {
+		entries = append(entries, quantizeEntry{c, count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return colorLess(entries[i].c, entries[j].c)
+	})
+	palette := quantize(entries, n)
+	bm := &go3mf.BaseMaterials{ID: rs.UnusedID()}
+	for _, c := range palette {
+		bm.Materials = append(bm.Materials, go3mf.Base{Name: spec.FormatRGBA(c), Color: c})
+	}
+	report := QuantizeReport{Colors: len(entries)}
+	mapping := make(map[color.RGBA]uint32, len(entries))
+	var total int
+	for _, entry := range entries {
+		index, dist := nearestColor(palette, entry.c)
+		mapping[entry.c] = index
+		report.MaxError = math.Max(report.MaxError, dist)
+		report.MeanError += dist * float64(entry.count)
+		total += entry.count
+	}
+	if total > 0 {
+		report.MeanError /= float64(total)
+	}
+	for i, obj := range rs.Objects {
+		if objColor[i] != nil {
+			obj.PID, obj.PIndex = bm.ID, mapping[*objColor[i]]
+		}
+		for j, c := range triColor[i] {
+			if c == nil {
+				continue
+			}
+			t := &obj.Mesh.Triangles.Triangle[j]
+			index := mapping[*c]
+			t.PID, t.P1, t.P2, t.P3 = bm.ID, index, index, index
+		}
+	}
+	rs.Assets = append(rs.Assets, bm)
+	return bm, report, nil
+}
+
+// quantize returns at most n colors representing entries,
+// which must be sorted by decreasing count.
+// It uses weighted k-means with a farthest point initialization.
+func quantize(entries []quantizeEntry, n int) []color.RGBA {
+	if len(entries) <= n {
+		palette := make([]color.RGBA, len(entries))
+		for i, entry := range entries {
+			palette[i] = entry.c
+		}
+		return palette
+	}
+	centers := [][4]float64{colorVec(entries[0].c)}
+	minDist := make([]float64, len(entries))
+	for i, entry := range entries {
+		minDist[i] = vecDist(colorVec(entry.c), centers[0])
+	}
+	for len(centers) < n {
+		var farthest int
+		for i := range entries {
+			if minDist[i] > minDist[farthest] {
+				farthest = i
+			}
+		}
+		center := colorVec(entries[farthest].c)
+		centers = append(centers, center)
+		for i, entry := range entries {
+			minDist[i] = math.Min(minDist[i], vecDist(colorVec(entry.c), center))
+		}
+	}
+	assign := make([]int, len(entries))
+	for iter := 0; iter < maxQuantizeIterations; iter++ {
+		changed := iter == 0
+		for i, entry := range entries {
+			v := colorVec(entry.c)
+			best, bestDist := 0, math.Inf(1)
+			for k, center := range centers {
+				if d := vecDist(v, center); d < bestDist {
+					best, bestDist = k, d
+				}
+			}
+			if assign[i] != best {
+				assign[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+		sums := make([][4]float64, len(centers))
+		weights := make([]float64, len(centers))
+		for i, entry := range entries {
+			v, w := colorVec(entry.c), float64(entry.count)
+			for c := range v {
+				sums[assign[i]][c] += v[c] * w
+			}
+			weights[assign[i]] += w
+		}
+		for k := range centers {
+			if weights[k] > 0 {
+				for c := range centers[k] {
+					centers[k][c] = sums[k][c] / weights[k]
+				}
+			}
+		}
+	}
+	palette := make([]color.RGBA, 0, len(centers))
+	seen := make(map[color.RGBA]bool, len(centers))
+	for _, center := range centers {
+		c := color.RGBA{
+			R: uint8(math.Round(center[0])), G: uint8(math.Round(center[1])),
+			B: uint8(math.Round(center[2])), A: uint8(math.Round(center[3])),
+		}
+		if !seen[c] {
+			seen[c] = true
+			palette = append(palette, c)
+		}
+	}
+	return palette
+}
+
+func nearestColor(palette []color.RGBA, c color.RGBA) (uint32, float64) {
+	v := colorVec(c)
+	var (
+		best     uint32
+		bestDist = math.Inf(1)
+	)
+	for i, p := range palette {
+		if d := vecDist(v, colorVec(p)); d < bestDist {
+			best, bestDist = uint32(i), d
+		}
+	}
+	return best, math.Sqrt(bestDist)
+}
+
+func colorVec(c color.RGBA) [4]float64 {
+	return [4]float64{float64(c.R), float64(c.G), float64(c.B), float64(c.A)}
+}
+
+// vecDist returns the squared distance between a and b.
+func vecDist(a, b [4]float64) float64 {
+	var d float64
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return d
+}
+
+func colorLess(a, b color.RGBA) bool {
+	if a.R != b.R {
+		return a.R < b.R
+	}
+	if a.G != b.G {
+		return a.G < b.G
+	}
+	if a.B != b.B {
+		return a.B < b.B
+	}
+	return a.A < b.A
+}
@@ -0,0 +1,118 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func newQuantizeTestModel() *go3mf.Model {
+	tri := func(pid, p1, p2, p3 uint32) go3mf.Triangle {
+		return go3mf.Triangle{V1: 0, V2: 1, V3: 2, PID: pid, P1: p1, P2: p2, P3: p3}
+	}
+	m := new(go3mf.Model)
+	m.Resources.Assets = []go3mf.Asset{
+		&ColorGroup{ID: 1, Colors: []color.RGBA{
+			{R: 255, A: 255}, {R: 245, A: 255}, {B: 255, A: 255}, {B: 235, A: 255},
+		}},
+		&go3mf.BaseMaterials{ID: 2, Materials: []go3mf.Base{
+			{Name: "red", Color: color.RGBA{R: 255, A: 255}}, {Name: "blue", Color: color.RGBA{B: 255, A: 255}},
+		}},
+		&CompositeMaterials{ID: 3, MaterialID: 2, Indices: []uint32{0, 1}, Composites: []Composite{{Values: []float32{0.9, 0.1}}}},
+	}
+	vertices := go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}}
+	m.Resources.Objects = []*go3mf.Object{
+		{ID: 4, PID: 1, PIndex: 2, Mesh: &go3mf.Mesh{Vertices: vertices, Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+			tri(1, 0, 0, 0), tri(1, 1, 1, 1), tri(1, 0, 1, 0), {V1: 0, V2: 1, V3: 2},
+		}}}},
+		{ID: 5, Mesh: &go3mf.Mesh{Vertices: vertices, Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+			tri(1, 3, 3, 3), tri(3, 0, 0, 0), {V1: 0, V2: 1, V3: 2},
+		}}}},
+		{ID: 6, Components: &go3mf.Components{}},
+	}
+	return m
+}
+
+func TestQuantizeColors(t *testing.T) {
+	m := newQuantizeTestModel()
+	got, report, err := QuantizeColors(m, "", 2)
+	if err != nil {
+		t.Fatalf("QuantizeColors() error = %v", err)
+	}
+	blue, red := color.RGBA{B: 245, A: 255}, color.RGBA{R: 246, B: 7, A: 255}
+	want := &go3mf.BaseMaterials{ID: 7, Materials: []go3mf.Base{
+		{Name: spec.FormatRGBA(blue), Color: blue}, {Name: spec.FormatRGBA(red), Color: red},
+	}}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("QuantizeColors() = %v", diff)
+	}
+	if report.Colors != 6 || report.MaxError < 10 || report.MaxError > 30 || report.MeanError <= 0 || report.MeanError > report.MaxError {
+		t.Errorf("QuantizeColors() report = %+v", report)
+	}
+	obj := m.Resources.Objects[0]
+	if obj.PID != 7 || obj.PIndex != 0 {
+		t.Errorf("QuantizeColors() object property = %d %d", obj.PID, obj.PIndex)
+	}
+	wantIndices := [][]uint32{{1, 1, 1, 0}, {0, 1, 0}}
+	for i, indices := range wantIndices {
+		for j, index := range indices {
+			tri := m.Resources.Objects[i].Mesh.Triangles.Triangle[j]
+			wantPID := uint32(7)
+			if i == 1 && j == 2 {
+				wantPID, index = 0, 0
+			}
+			if tri.PID != wantPID || tri.P1 != index || tri.P2 != index || tri.P3 != index {
+				t.Errorf("QuantizeColors() object %d triangle %d = %+v, want %d %d", i, j, tri, wantPID, index)
+			}
+		}
+	}
+	if m.Resources.Assets[len(m.Resources.Assets)-1] != got {
+		t.Error("QuantizeColors() base materials not added to resources")
+	}
+}
+
+func TestQuantizeColors_Exact(t *testing.T) {
+	m := newQuantizeTestModel()
+	got, report, err := QuantizeColors(m, "", 10)
+	if err != nil {
+		t.Fatalf("QuantizeColors() error = %v", err)
+	}
+	if len(got.Materials) != 6 {
+		t.Errorf("QuantizeColors() materials = %d, want 6", len(got.Materials))
+	}
+	if diff := deep.Equal(report, QuantizeReport{Colors: 6}); diff != nil {
+		t.Errorf("QuantizeColors() report = %v", diff)
+	}
+}
+
+func TestQuantizeColors_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		m    *go3mf.Model
+		path string
+		n    int
+	}{
+		{"count", newQuantizeTestModel(), "", 0},
+		{"path", newQuantizeTestModel(), "/other.model", 2},
+		{"object", &go3mf.Model{Resources: go3mf.Resources{Objects: []*go3mf.Object{{ID: 1, PID: 100}}}}, "", 2},
+		{"triangle", &go3mf.Model{Resources: go3mf.Resources{Objects: []*go3mf.Object{{ID: 1, Mesh: &go3mf.Mesh{
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{PID: 100}}},
+		}}}}}, "", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := QuantizeColors(tt.m, tt.path, tt.n); err == nil {
+				t.Error("QuantizeColors() expected error")
+			}
+		})
+	}
+	if got, _, err := QuantizeColors(new(go3mf.Model), "", 2); got != nil || err != nil {
+		t.Errorf("QuantizeColors() = %v, %v, want nil", got, err)
+	}
+}
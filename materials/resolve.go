@@ -0,0 +1,253 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	"errors"
+	"image/color"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+)
+
+// ErrPropertyResource is returned when a property id
+// does not reference to a property resource.
+var ErrPropertyResource = errors.New("MUST reference to a property resource")
+
+// Property is the effective property of a triangle vertex.
+// It is one of BaseProperty, ColorProperty, TextureProperty,
+// CompositeProperty or MultiProperty.
+type Property interface {
+	// Identify returns the property group id and the index within the group.
+	Identify() (uint32, uint32)
+}
+
+// BaseProperty is a property resolved from a go3mf.BaseMaterials.
+type BaseProperty struct {
+	PID   uint32
+	Index uint32
+	Base  go3mf.Base
+}
+
+// Identify returns the property group id and the index within the group.
+func (p *BaseProperty) Identify() (uint32, uint32) {
+	return p.PID, p.Index
+}
+
+// ColorProperty is a property resolved from a ColorGroup.
+type ColorProperty struct {
+	PID   uint32
+	Index uint32
+	Color color.RGBA
+}
+
+// Identify returns the property group id and the index within the group.
+func (p *ColorProperty) Identify() (uint32, uint32) {
+	return p.PID, p.Index
+}
+
+// TextureProperty is a property resolved from a Texture2DGroup.
+type TextureProperty struct {
+	PID     uint32
+	Index   uint32
+	Texture *Texture2D
+	Coord   TextureCoord
+}
+
+// Identify returns the property group id and the index within the group.
+func (p *TextureProperty) Identify() (uint32, uint32) {
+	return p.PID, p.Index
+}
+
+// CompositeProperty is a property resolved from a CompositeMaterials.
+// Materials contains the base materials referenced by the composite,
+// in the same order as Values.
+type CompositeProperty struct {
+	PID       uint32
+	Index     uint32
+	Materials []go3mf.Base
+	Values    []float32
+}
+
+// Identify returns the property group id and the index within the group.
+func (p *CompositeProperty) Identify() (uint32, uint32) {
+	return p.PID, p.Index
+}
+
+// MultiProperty is a property resolved from a MultiProperties.
+// Layers contains the resolved property of each layer,
+// which are blended using BlendMethods.
+type MultiProperty struct {
+	PID          uint32
+	Index        uint32
+	BlendMethods []BlendMethod
+	Layers       []Property
+}
+
+// Identify returns the property group id and the index within the group.
+func (p *MultiProperty) Identify() (uint32, uint32) {
+	return p.PID, p.Index
+}
+
+// Resolver resolves the effective properties of the triangles
+// of the objects defined in a model part.
+type Resolver struct {
+	model  *go3mf.Model
+	path   string
+	assets map[uint32]go3mf.Asset
+}
+
+// NewResolver returns a Resolver for the objects
+// defined in the model part identified by path.
+func NewResolver(m *go3mf.Model, path string) *Resolver {
+	return &Resolver{model: m, path: path}
+}
+
+// Triangle returns the effective property of each vertex of t,
+// which is a triangle of the mesh of obj.
+// The triangle PID and P1, P2 and P3 are used if PID is defined,
+// else the object PID and PIndex apply to the three vertices.
+// The returned properties are nil if none of them are defined.
+func (r *Resolver) Triangle(obj *go3mf.Object, t *go3mf.Triangle) (props [3]Property, err error) {
+	if t.PID == 0 {
+		if obj.PID == 0 {
+			return
+		}
+		var p Property
+		if p, err = r.Property(obj.PID, obj.PIndex); err != nil {
+			return
+		}
+		props[0], props[1], props[2] = p, p, p
+		return
+	}
+	for i, index := range [3]uint32{t.P1, t.P2, t.P3} {
+		if props[i], err = r.Property(t.PID, index); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Mesh returns the effective properties of all the triangles of obj.
+// It returns nil if obj is not a mesh.
+func (r *Resolver) Mesh(obj *go3mf.Object) ([][3]Property, error) {
+	if obj.Mesh == nil {
+		return nil, nil
+	}
+	props := make([][3]Property, len(obj.Mesh.Triangles.Triangle))
+	for i := range obj.Mesh.Triangles.Triangle {
+		var err error
+		if props[i], err = r.Triangle(obj, &obj.Mesh.Triangles.Triangle[i]); err != nil {
+			return nil, specerr.WrapIndex(err, "triangle", i)
+		}
+	}
+	return props, nil
+}
+
+// Property returns the property at index of the property group pid.
+func (r *Resolver) Property(pid, index uint32) (Property, error) {
+	asset, ok := r.findAsset(pid)
+	if !ok {
+		return nil, specerr.ErrMissingResource
+	}
+	switch asset := asset.(type) {
+	case *go3mf.BaseMaterials:
+		if int(index) >= len(asset.Materials) {
+			return nil, specerr.ErrIndexOutOfBounds
+		}
+		return &BaseProperty{PID: pid, Index: index, Base: asset.Materials[index]}, nil
+	case *ColorGroup:
+		if int(index) >= len(asset.Colors) {
+			return nil, specerr.ErrIndexOutOfBounds
+		}
+		return &ColorProperty{PID: pid, Index: index, Color: asset.Colors[index]}, nil
+	case *Texture2DGroup:
+		return r.textureProperty(pid, index, asset)
+	case *CompositeMaterials:
+		return r.compositeProperty(pid, index, asset)
+	case *MultiProperties:
+		return r.multiProperty(pid, index, asset)
+	}
+	return nil, ErrPropertyResource
+}
+
+func (r *Resolver) textureProperty(pid, index uint32, group *Texture2DGroup) (Property, error) {
+	if int(index) >= len(group.Coords) {
+		return nil, specerr.ErrIndexOutOfBounds
+	}
+	asset, ok := r.findAsset(group.TextureID)
+	if !ok {
+		return nil, specerr.ErrMissingResource
+	}
+	tex, ok := asset.(*Texture2D)
+	if !ok {
+		return nil, ErrTextureReference
+	}
+	return &TextureProperty{PID: pid, Index: index, Texture: tex, Coord: group.Coords[index]}, nil
+}
+
+func (r *Resolver) compositeProperty(pid, index uint32, group *CompositeMaterials) (Property, error) {
+	if int(index) >= len(group.Composites) {
+		return nil, specerr.ErrIndexOutOfBounds
+	}
+	asset, ok := r.findAsset(group.MaterialID)
+	if !ok {
+		return nil, specerr.ErrMissingResource
+	}
+	bm, ok := asset.(*go3mf.BaseMaterials)
+	if !ok {
+		return nil, ErrCompositeBase
+	}
+	values := group.Composites[index].Values
+	if len(values) > len(group.Indices) {
+		values = values[:len(group.Indices)]
+	}
+	materials := make([]go3mf.Base, len(values))
+	for i := range values {
+		matIndex := group.Indices[i]
+		if int(matIndex) >= len(bm.Materials) {
+			return nil, specerr.ErrIndexOutOfBounds
+		}
+		materials[i] = bm.Materials[matIndex]
+	}
+	return &CompositeProperty{PID: pid, Index: index, Materials: materials, Values: values}, nil
+}
+
+func (r *Resolver) multiProperty(pid, index uint32, group *MultiProperties) (Property, error) {
+	if int(index) >= len(group.Multis) {
+		return nil, specerr.ErrIndexOutOfBounds
+	}
+	pindices := group.Multis[index].PIndices
+	layers := make([]Property, len(group.PIDs))
+	for i, layerID := range group.PIDs {
+		// Missing indices default to 0.
+		var layerIndex uint32
+		if i < len(pindices) {
+			layerIndex = pindices[i]
+		}
+		if asset, ok := r.findAsset(layerID); ok {
+			if _, ok := asset.(*MultiProperties); ok {
+				return nil, ErrMultiRefMulti
+			}
+		}
+		var err error
+		if layers[i], err = r.Property(layerID, layerIndex); err != nil {
+			return nil, err
+		}
+	}
+	return &MultiProperty{PID: pid, Index: index, BlendMethods: group.BlendMethods, Layers: layers}, nil
+}
+
+func (r *Resolver) findAsset(id uint32) (go3mf.Asset, bool) {
+	if r.assets == nil {
+		r.assets = make(map[uint32]go3mf.Asset)
+		if rs, ok := r.model.FindResources(r.path); ok {
+			for _, a := range rs.Assets {
+				r.assets[a.Identify()] = a
+			}
+		}
+	}
+	a, ok := r.assets[id]
+	return a, ok
+}
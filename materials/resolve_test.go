@@ -0,0 +1,136 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	goerrors "errors"
+	"image/color"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/errors"
+)
+
+func newResolverTestModel() *go3mf.Model {
+	m := new(go3mf.Model)
+	m.Resources.Assets = []go3mf.Asset{
+		&go3mf.BaseMaterials{ID: 1, Materials: []go3mf.Base{
+			{Name: "a", Color: color.RGBA{R: 255, A: 255}},
+			{Name: "b", Color: color.RGBA{G: 255, A: 255}},
+		}},
+		&ColorGroup{ID: 2, Colors: []color.RGBA{{R: 1, A: 255}, {G: 1, A: 255}}},
+		&Texture2D{ID: 3, Path: "/a.png", ContentType: TextureTypePNG},
+		&Texture2DGroup{ID: 4, TextureID: 3, Coords: []TextureCoord{{0.1, 0.2}, {0.3, 0.4}}},
+		&CompositeMaterials{ID: 5, MaterialID: 1, Indices: []uint32{1, 0}, Composites: []Composite{{Values: []float32{0.3, 0.7}}}},
+		&MultiProperties{ID: 6, PIDs: []uint32{1, 2}, BlendMethods: []BlendMethod{BlendMultiply}, Multis: []Multi{{PIndices: []uint32{1}}}},
+		&MultiProperties{ID: 7, PIDs: []uint32{6}, Multis: []Multi{{}}},
+		&Texture2DGroup{ID: 8, TextureID: 2, Coords: []TextureCoord{{}}},
+		&CompositeMaterials{ID: 9, MaterialID: 2, Indices: []uint32{0}, Composites: []Composite{{Values: []float32{1}}}},
+		&CompositeMaterials{ID: 10, MaterialID: 1, Indices: []uint32{5}, Composites: []Composite{{Values: []float32{1}}}},
+		&Texture2D{ID: 11},
+	}
+	return m
+}
+
+func TestResolver_Property(t *testing.T) {
+	m := newResolverTestModel()
+	tex := m.Resources.Assets[2].(*Texture2D)
+	tests := []struct {
+		name    string
+		pid     uint32
+		index   uint32
+		want    Property
+		wantErr error
+	}{
+		{"base", 1, 1, &BaseProperty{PID: 1, Index: 1, Base: go3mf.Base{Name: "b", Color: color.RGBA{G: 255, A: 255}}}, nil},
+		{"color", 2, 0, &ColorProperty{PID: 2, Index: 0, Color: color.RGBA{R: 1, A: 255}}, nil},
+		{"texture", 4, 1, &TextureProperty{PID: 4, Index: 1, Texture: tex, Coord: TextureCoord{0.3, 0.4}}, nil},
+		{"composite", 5, 0, &CompositeProperty{PID: 5, Index: 0, Materials: []go3mf.Base{
+			{Name: "b", Color: color.RGBA{G: 255, A: 255}}, {Name: "a", Color: color.RGBA{R: 255, A: 255}},
+		}, Values: []float32{0.3, 0.7}}, nil},
+		{"multi", 6, 0, &MultiProperty{PID: 6, Index: 0, BlendMethods: []BlendMethod{BlendMultiply}, Layers: []Property{
+			&BaseProperty{PID: 1, Index: 1, Base: go3mf.Base{Name: "b", Color: color.RGBA{G: 255, A: 255}}},
+			&ColorProperty{PID: 2, Index: 0, Color: color.RGBA{R: 1, A: 255}},
+		}}, nil},
+		{"missing", 100, 0, nil, errors.ErrMissingResource},
+		{"outOfBounds", 2, 2, nil, errors.ErrIndexOutOfBounds},
+		{"notProperty", 3, 0, nil, ErrPropertyResource},
+		{"multiRefMulti", 7, 0, nil, ErrMultiRefMulti},
+		{"textureReference", 8, 0, nil, ErrTextureReference},
+		{"compositeBase", 9, 0, nil, ErrCompositeBase},
+		{"compositeOutOfBounds", 10, 0, nil, errors.ErrIndexOutOfBounds},
+	}
+	r := NewResolver(m, "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Property(tt.pid, tt.index)
+			if err != tt.wantErr {
+				t.Fatalf("Resolver.Property() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Resolver.Property() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestResolver_Triangle(t *testing.T) {
+	m := newResolverTestModel()
+	red := &ColorProperty{PID: 2, Index: 0, Color: color.RGBA{R: 1, A: 255}}
+	green := &ColorProperty{PID: 2, Index: 1, Color: color.RGBA{G: 1, A: 255}}
+	base := &BaseProperty{PID: 1, Index: 0, Base: go3mf.Base{Name: "a", Color: color.RGBA{R: 255, A: 255}}}
+	tests := []struct {
+		name    string
+		obj     *go3mf.Object
+		t       *go3mf.Triangle
+		want    [3]Property
+		wantErr bool
+	}{
+		{"none", &go3mf.Object{}, &go3mf.Triangle{}, [3]Property{}, false},
+		{"object", &go3mf.Object{PID: 1}, &go3mf.Triangle{}, [3]Property{base, base, base}, false},
+		{"triangle", &go3mf.Object{PID: 1}, &go3mf.Triangle{PID: 2, P1: 1, P2: 0, P3: 1}, [3]Property{green, red, green}, false},
+		{"objectErr", &go3mf.Object{PID: 100}, &go3mf.Triangle{}, [3]Property{}, true},
+		{"triangleErr", &go3mf.Object{}, &go3mf.Triangle{PID: 2, P3: 5}, [3]Property{red, red, nil}, true},
+	}
+	r := NewResolver(m, "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Triangle(tt.obj, tt.t)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolver.Triangle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Resolver.Triangle() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestResolver_Mesh(t *testing.T) {
+	m := newResolverTestModel()
+	m.Childs = map[string]*go3mf.ChildModel{"/other.model": {Resources: m.Resources}}
+	m.Resources = go3mf.Resources{}
+	obj := &go3mf.Object{PID: 2, PIndex: 1, Mesh: &go3mf.Mesh{Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+		{}, {PID: 1, P1: 1, P2: 1, P3: 1}, {PID: 1, P1: 2},
+	}}}}
+	r := NewResolver(m, "/other.model")
+	if got, err := r.Mesh(&go3mf.Object{}); got != nil || err != nil {
+		t.Errorf("Resolver.Mesh() = %v, %v, want nil", got, err)
+	}
+	if _, err := r.Mesh(obj); !goerrors.Is(err, errors.ErrIndexOutOfBounds) {
+		t.Errorf("Resolver.Mesh() error = %v", err)
+	}
+	obj.Mesh.Triangles.Triangle = obj.Mesh.Triangles.Triangle[:2]
+	got, err := r.Mesh(obj)
+	if err != nil {
+		t.Fatalf("Resolver.Mesh() error = %v", err)
+	}
+	green := &ColorProperty{PID: 2, Index: 1, Color: color.RGBA{G: 1, A: 255}}
+	base := &BaseProperty{PID: 1, Index: 1, Base: go3mf.Base{Name: "b", Color: color.RGBA{G: 255, A: 255}}}
+	want := [][3]Property{{green, green, green}, {base, base, base}}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("Resolver.Mesh() = %v", diff)
+	}
+}
@@ -0,0 +1,11 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+//go:generate go run ../internal/cmd/xsdgen -pkg materials -const schema -o schema_gen.go schemas/material.xsd
+
+// Schema returns the XSD of the Materials extension.
+func (Spec) Schema() []byte {
+	return []byte(schema)
+}
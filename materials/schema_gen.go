@@ -0,0 +1,254 @@
+// Code generated by xsdgen from schemas/material.xsd. DO NOT EDIT.
+
+package materials
+
+// schema is the content of schemas/material.xsd.
+const schema = `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns="http://schemas.microsoft.com/3dmanufacturing/material/2015/02" xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://schemas.microsoft.com/3dmanufacturing/material/2015/02" elementFormDefault="unqualified" attributeFormDefault="unqualified" blockDefault="#all">
+	<!-- Complex Types -->
+	<xs:complexType name="CT_Texture2D">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="path" type="ST_UriReference" use="required"/>
+		<xs:attribute name="contenttype" type="ST_ContentType" use="required"/>
+		<xs:attribute name="tilestyleu" type="ST_TileStyle" default="wrap"/>
+		<xs:attribute name="tilestylev" type="ST_TileStyle" default="wrap"/>
+		<xs:attribute name="filter" type="ST_Filter" default="auto"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_ColorGroup">
+		<xs:sequence>
+			<xs:element ref="color" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="displaypropertiesid" type="ST_ResourceID"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Color">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="color" type="ST_ColorValue" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Texture2DGroup">
+		<xs:sequence>
+			<xs:element ref="tex2coord" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="texid" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="displaypropertiesid" type="ST_ResourceID"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Tex2Coord">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="u" type="ST_Number" use="required"/>
+		<xs:attribute name="v" type="ST_Number" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_CompositeMaterials">
+		<xs:sequence>
+			<xs:element ref="composite" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="matid" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="matindices" type="ST_ResourceIndices" use="required"/>
+		<xs:attribute name="displaypropertiesid" type="ST_ResourceID"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Composite">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="values" type="ST_Numbers" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_MultiProperties">
+		<xs:sequence>
+			<xs:element ref="multi" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="pids" type="ST_ResourceIDs" use="required"/>
+		<xs:attribute name="blendmethods" type="ST_BlendMethods"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Multi">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="pindices" type="ST_ResourceIndices"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_PBSpecularDisplayProperties">
+		<xs:sequence>
+			<xs:element ref="pbspecular" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_PBSpecular">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="name" type="xs:string" use="required"/>
+		<xs:attribute name="specularcolor" type="ST_ColorValue" default="#383838"/>
+		<xs:attribute name="glossiness" type="ST_Number" default="0"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_PBMetallicDisplayProperties">
+		<xs:sequence>
+			<xs:element ref="pbmetallic" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_PBMetallic">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="name" type="xs:string" use="required"/>
+		<xs:attribute name="metallicness" type="ST_Number" default="0"/>
+		<xs:attribute name="roughness" type="ST_Number" default="1"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_PBSpecularTextureDisplayProperties">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="name" type="xs:string" use="required"/>
+		<xs:attribute name="speculartextureid" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="glossinesstextureid" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="diffusefactor" type="ST_ColorValue" default="#FFFFFF"/>
+		<xs:attribute name="specularfactor" type="ST_ColorValue" default="#FFFFFF"/>
+		<xs:attribute name="glossinessfactor" type="ST_Number" default="1"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_PBMetallicTextureDisplayProperties">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="name" type="xs:string" use="required"/>
+		<xs:attribute name="metallictextureid" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="roughnesstextureid" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="metallicfactor" type="ST_Number" default="1"/>
+		<xs:attribute name="roughnessfactor" type="ST_Number" default="1"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_TranslucentDisplayProperties">
+		<xs:sequence>
+			<xs:element ref="translucent" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Translucent">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="name" type="xs:string" use="required"/>
+		<xs:attribute name="attenuation" type="ST_Numbers" use="required"/>
+		<xs:attribute name="refractiveindex" type="ST_Numbers" default="1 1 1"/>
+		<xs:attribute name="roughness" type="ST_Number" default="0"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<!-- Simple Types -->
+	<xs:simpleType name="ST_ContentType">
+		<xs:restriction base="xs:string">
+			<xs:enumeration value="image/png"/>
+			<xs:enumeration value="image/jpeg"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_TileStyle">
+		<xs:restriction base="xs:string">
+			<xs:enumeration value="wrap"/>
+			<xs:enumeration value="mirror"/>
+			<xs:enumeration value="clamp"/>
+			<xs:enumeration value="none"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_Filter">
+		<xs:restriction base="xs:string">
+			<xs:enumeration value="auto"/>
+			<xs:enumeration value="linear"/>
+			<xs:enumeration value="nearest"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_BlendMethod">
+		<xs:restriction base="xs:string">
+			<xs:enumeration value="mix"/>
+			<xs:enumeration value="multiply"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_BlendMethods">
+		<xs:list itemType="ST_BlendMethod"/>
+	</xs:simpleType>
+	<xs:simpleType name="ST_ResourceIDs">
+		<xs:list itemType="ST_ResourceID"/>
+	</xs:simpleType>
+	<xs:simpleType name="ST_ResourceIndices">
+		<xs:list itemType="ST_ResourceIndex"/>
+	</xs:simpleType>
+	<xs:simpleType name="ST_Numbers">
+		<xs:list itemType="ST_Number"/>
+	</xs:simpleType>
+	<xs:simpleType name="ST_ColorValue">
+		<xs:restriction base="xs:string">
+			<xs:pattern value="#[0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f]([0-9A-Fa-f][0-9A-Fa-f])?"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_UriReference">
+		<xs:restriction base="xs:anyURI">
+			<xs:pattern value="/.*"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_Number">
+		<xs:restriction base="xs:double">
+			<xs:whiteSpace value="collapse"/>
+			<xs:pattern value="((\-|\+)?(([0-9]+(\.[0-9]+)?)|(\.[0-9]+))((e|E)(\-|\+)?[0-9]+)?)"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_ResourceID">
+		<xs:restriction base="xs:positiveInteger">
+			<xs:maxExclusive value="2147483648"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_ResourceIndex">
+		<xs:restriction base="xs:nonNegativeInteger">
+			<xs:maxExclusive value="2147483648"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<!-- Elements -->
+	<xs:element name="texture2d" type="CT_Texture2D"/>
+	<xs:element name="colorgroup" type="CT_ColorGroup"/>
+	<xs:element name="color" type="CT_Color"/>
+	<xs:element name="texture2dgroup" type="CT_Texture2DGroup"/>
+	<xs:element name="tex2coord" type="CT_Tex2Coord"/>
+	<xs:element name="compositematerials" type="CT_CompositeMaterials"/>
+	<xs:element name="composite" type="CT_Composite"/>
+	<xs:element name="multiproperties" type="CT_MultiProperties"/>
+	<xs:element name="multi" type="CT_Multi"/>
+	<xs:element name="pbspeculardisplayproperties" type="CT_PBSpecularDisplayProperties"/>
+	<xs:element name="pbspecular" type="CT_PBSpecular"/>
+	<xs:element name="pbmetallicdisplayproperties" type="CT_PBMetallicDisplayProperties"/>
+	<xs:element name="pbmetallic" type="CT_PBMetallic"/>
+	<xs:element name="pbspeculartexturedisplayproperties" type="CT_PBSpecularTextureDisplayProperties"/>
+	<xs:element name="pbmetallictexturedisplayproperties" type="CT_PBMetallicTextureDisplayProperties"/>
+	<xs:element name="translucentdisplayproperties" type="CT_TranslucentDisplayProperties"/>
+	<xs:element name="translucent" type="CT_Translucent"/>
+	<!-- Attributes -->
+	<xs:attribute name="displaypropertiesid" type="ST_ResourceID"/>
+</xs:schema>
+`
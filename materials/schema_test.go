@@ -0,0 +1,77 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func TestSpec_Schema(t *testing.T) {
+	tests := []struct {
+		name   string
+		assets []go3mf.Asset
+		want   []string
+	}{
+		{"valid", []go3mf.Asset{
+			&Texture2D{ID: 1, Path: "/3D/Texture/a.png", ContentType: TextureTypePNG, TileStyleU: TileMirror, Filter: TextureFilterNearest},
+			&ColorGroup{ID: 2, Colors: []color.RGBA{{R: 255, A: 255}, {G: 255, A: 100}}, DisplayPropertiesID: 8},
+			&Texture2DGroup{ID: 3, TextureID: 1, Coords: []TextureCoord{{0.3, 0.5}}},
+			&go3mf.BaseMaterials{ID: 4, Materials: []go3mf.Base{{Name: "a", Color: color.RGBA{R: 255, A: 255}}}, AnyAttr: spec.AnyAttr{&BaseMaterialsAttr{DisplayPropertiesID: 7}}},
+			&CompositeMaterials{ID: 5, MaterialID: 4, Indices: []uint32{0, 0}, Composites: []Composite{{Values: []float32{0.5, 0.5}}}},
+			&MultiProperties{ID: 6, BlendMethods: []BlendMethod{BlendMultiply}, PIDs: []uint32{4, 2}, Multis: []Multi{{PIndices: []uint32{0, 1}}}},
+			&PBMetallicDisplayProperties{ID: 7, Properties: []PBMetallic{{Name: "Metal", Metallicness: 1, Roughness: 0.2}}},
+			&TranslucentDisplayProperties{ID: 8, Properties: []Translucent{{Name: "Glass", Attenuation: [3]float32{0.1, 0.2, 0.3}, RefractiveIndex: [3]float32{1.5, 1.5, 1.5}}}},
+			&PBSpecularDisplayProperties{ID: 9, Properties: []PBSpecular{{Name: "Shiny", SpecularColor: color.RGBA{R: 255, G: 255, B: 255, A: 255}, Glossiness: 0.8}}},
+			&PBSpecularTextureDisplayProperties{ID: 10, Name: "Tex", SpecularTextureID: 1, GlossinessTextureID: 1},
+			&PBMetallicTextureDisplayProperties{ID: 11, Name: "Tex", MetallicTextureID: 1, RoughnessTextureID: 1},
+		}, nil},
+		{"invalid", []go3mf.Asset{
+			&ColorGroup{ID: 1},
+			&Texture2D{ID: 2, Path: "a.png", ContentType: TextureTypePNG},
+		}, []string{
+			fmt.Sprintf("go3mf: Path: /3D/3dmodel.model Line: 2 XPath: /model/resources/colorgroup[0]: %v: colorgroup", specerr.ErrSchemaMissingElement),
+			fmt.Sprintf(`go3mf: Path: /3D/3dmodel.model Line: 2 XPath: /model/resources/texture2d[0]: %v: path="a.png"`, specerr.ErrSchemaAttrValue),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &go3mf.Model{Extensions: []go3mf.Extension{DefaultExtension}}
+			m.Resources.Assets = tt.assets
+			var buf bytes.Buffer
+			if err := go3mf.NewEncoder(&buf).Encode(m); err != nil {
+				t.Fatalf("go3mf.Encoder.Encode() error = %v", err)
+			}
+			d := go3mf.NewDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			d.ValidateSchema = true
+			var got []string
+			if err := d.Decode(new(go3mf.Model)); err != nil {
+				for _, err := range err.(*specerr.List).Errors {
+					got = append(got, err.Error())
+				}
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("go3mf.Decoder.Decode() = %v", diff)
+			}
+		})
+	}
+}
+
+func Test_schema(t *testing.T) {
+	b, err := ioutil.ReadFile("schemas/material.xsd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != schema {
+		t.Error("schema differs from schemas/material.xsd, run go generate")
+	}
+}
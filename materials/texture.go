@@ -0,0 +1,171 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"strings"
+
+	"github.com/hpinc/go3mf"
+)
+
+// Image decodes the texture image stored in the model attachments.
+// The attachment stream is preserved so the model can be encoded afterwards.
+func (t *Texture2D) Image(m *go3mf.Model) (image.Image, error) {
+	data, err := t.Data(m)
+	if err != nil {
+		return nil, err
+	}
+	var img image.Image
+	switch t.ContentType {
+	case TextureTypePNG:
+		img, err = png.Decode(bytes.NewReader(data))
+	case TextureTypeJPEG:
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	default:
+		img, _, err = image.Decode(bytes.NewReader(data))
+	}
+	return img, err
+}
+
+// Data returns the encoded texture image stored in the model attachments.
+// The attachment stream is preserved so the model can be encoded afterwards.
+func (t *Texture2D) Data(m *go3mf.Model) ([]byte, error) {
+	for i := range m.Attachments {
+		if strings.EqualFold(m.Attachments[i].Path, t.Path) {
+			return readAttachment(&m.Attachments[i])
+		}
+	}
+	return nil, ErrMissingTexturePart
+}
+
+func readAttachment(a *go3mf.Attachment) ([]byte, error) {
+	if b, ok := a.Stream.(*bytes.Buffer); ok {
+		return b.Bytes(), nil
+	}
+	data, err := ioutil.ReadAll(a.Stream)
+	if err != nil {
+		return nil, err
+	}
+	a.Stream = bytes.NewBuffer(data)
+	return data, nil
+}
+
+// TextureSampler samples a texture image at texture coordinates
+// following the tiling and filtering rules of the materials specification.
+//
+// The (0, 0) coordinate is the lower left corner of the image
+// and (1, 1) the upper right corner.
+type TextureSampler struct {
+	Image      image.Image
+	TileStyleU TileStyle
+	TileStyleV TileStyle
+	Filter     TextureFilter
+}
+
+// NewTextureSampler returns a TextureSampler for t,
+// whose image is decoded from the model attachments.
+func NewTextureSampler(m *go3mf.Model, t *Texture2D) (*TextureSampler, error) {
+	img, err := t.Image(m)
+	if err != nil {
+		return nil, err
+	}
+	return &TextureSampler{Image: img, TileStyleU: t.TileStyleU, TileStyleV: t.TileStyleV, Filter: t.Filter}, nil
+}
+
+// Sample returns the color of the texture at c.
+// Coordinates out of the [0, 1] range when the tile style is TileNone
+// return a fully transparent color.
+// TextureFilterAuto uses bilinear filtering.
+func (s *TextureSampler) Sample(c TextureCoord) color.RGBA {
+	u, v := float64(c.U()), float64(c.V())
+	if (s.TileStyleU == TileNone && (u < 0 || u > 1)) || (s.TileStyleV == TileNone && (v < 0 || v > 1)) {
+		return color.RGBA{}
+	}
+	b := s.Image.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return color.RGBA{}
+	}
+	x := tileCoord(u, s.TileStyleU) * float64(w)
+	y := (1 - tileCoord(v, s.TileStyleV)) * float64(h)
+	if s.Filter == TextureFilterNearest {
+		return s.at(int(math.Floor(x)), int(math.Floor(y)))
+	}
+	x, y = x-0.5, y-0.5
+	x0, y0 := math.Floor(x), math.Floor(y)
+	tx, ty := x-x0, y-y0
+	ix, iy := int(x0), int(y0)
+	c00, c10 := s.at(ix, iy), s.at(ix+1, iy)
+	c01, c11 := s.at(ix, iy+1), s.at(ix+1, iy+1)
+	lerp := func(a00, a10, a01, a11 uint8) uint8 {
+		top := float64(a00)*(1-tx) + float64(a10)*tx
+		bottom := float64(a01)*(1-tx) + float64(a11)*tx
+		return uint8(math.Round(top*(1-ty) + bottom*ty))
+	}
+	return color.RGBA{
+		R: lerp(c00.R, c10.R, c01.R, c11.R),
+		G: lerp(c00.G, c10.G, c01.G, c11.G),
+		B: lerp(c00.B, c10.B, c01.B, c11.B),
+		A: lerp(c00.A, c10.A, c01.A, c11.A),
+	}
+}
+
+// at returns the non-premultiplied color of the pixel (x, y),
+// relative to the image bounds, after tiling the pixel indices.
+func (s *TextureSampler) at(x, y int) color.RGBA {
+	b := s.Image.Bounds()
+	x = tileIndex(x, b.Dx(), s.TileStyleU)
+	y = tileIndex(y, b.Dy(), s.TileStyleV)
+	c := color.NRGBAModel.Convert(s.Image.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+	return color.RGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+// tileCoord maps the texture coordinate f into the [0, 1] range.
+func tileCoord(f float64, style TileStyle) float64 {
+	switch style {
+	case TileWrap:
+		f -= math.Floor(f)
+	case TileMirror:
+		f -= 2 * math.Floor(f/2)
+		if f > 1 {
+			f = 2 - f
+		}
+	}
+	return math.Max(0, math.Min(1, f))
+}
+
+// tileIndex maps the pixel index i into the [0, n) range.
+func tileIndex(i, n int, style TileStyle) int {
+	switch style {
+	case TileWrap:
+		i %= n
+		if i < 0 {
+			i += n
+		}
+		return i
+	case TileMirror:
+		i %= 2 * n
+		if i < 0 {
+			i += 2 * n
+		}
+		if i >= n {
+			i = 2*n - 1 - i
+		}
+		return i
+	}
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
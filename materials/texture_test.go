@@ -0,0 +1,139 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package materials
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/hpinc/go3mf"
+)
+
+var (
+	texRed   = color.RGBA{R: 255, A: 255}
+	texGreen = color.RGBA{G: 255, A: 255}
+	texBlue  = color.RGBA{B: 255, A: 255}
+	texWhite = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// newTestImage returns a 2x2 image with red and green in the upper row
+// and blue and white in the lower row.
+func newTestImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, texRed)
+	img.Set(1, 0, texGreen)
+	img.Set(0, 1, texBlue)
+	img.Set(1, 1, texWhite)
+	return img
+}
+
+func TestTexture2D_Image(t *testing.T) {
+	var pngData, jpegData bytes.Buffer
+	if err := png.Encode(&pngData, newTestImage()); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegData, newTestImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	m := &go3mf.Model{Attachments: []go3mf.Attachment{
+		{Path: "/3D/Textures/a.png", Stream: bytes.NewBuffer(pngData.Bytes())},
+		{Path: "/3D/Textures/b.jpg", Stream: ioutil.NopCloser(bytes.NewReader(jpegData.Bytes()))},
+		{Path: "/3D/Textures/c.png", Stream: strings.NewReader("invalid")},
+	}}
+	tests := []struct {
+		name    string
+		t       *Texture2D
+		wantErr bool
+	}{
+		{"png", &Texture2D{Path: "/3D/Textures/a.png", ContentType: TextureTypePNG}, false},
+		{"jpeg", &Texture2D{Path: "/3D/textures/B.jpg", ContentType: TextureTypeJPEG}, false},
+		{"auto", &Texture2D{Path: "/3D/Textures/a.png"}, false},
+		{"invalid", &Texture2D{Path: "/3D/Textures/c.png", ContentType: TextureTypePNG}, true},
+		{"missing", &Texture2D{Path: "/3D/Textures/d.png", ContentType: TextureTypePNG}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.t.Image(m)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Texture2D.Image() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Bounds() != image.Rect(0, 0, 2, 2) {
+				t.Errorf("Texture2D.Image() bounds = %v", got.Bounds())
+			}
+		})
+	}
+	if _, err := (&Texture2D{Path: "/3D/Textures/b.jpg", ContentType: TextureTypeJPEG}).Image(m); err != nil {
+		t.Errorf("Texture2D.Image() error decoding twice = %v", err)
+	}
+}
+
+func TestTextureSampler_Sample(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *TextureSampler
+		c    TextureCoord
+		want color.RGBA
+	}{
+		{"lowerLeft", &TextureSampler{Filter: TextureFilterNearest}, TextureCoord{0.25, 0.25}, texBlue},
+		{"upperRight", &TextureSampler{Filter: TextureFilterNearest}, TextureCoord{0.75, 0.75}, texGreen},
+		{"upperLeft", &TextureSampler{Filter: TextureFilterNearest}, TextureCoord{0, 1}, texRed},
+		{"wrap", &TextureSampler{Filter: TextureFilterNearest}, TextureCoord{1.75, -0.75}, texWhite},
+		{"mirror", &TextureSampler{Filter: TextureFilterNearest, TileStyleU: TileMirror, TileStyleV: TileMirror}, TextureCoord{1.75, 1.25}, texRed},
+		{"clamp", &TextureSampler{Filter: TextureFilterNearest, TileStyleU: TileClamp, TileStyleV: TileClamp}, TextureCoord{5, -5}, texWhite},
+		{"none", &TextureSampler{Filter: TextureFilterNearest, TileStyleU: TileNone}, TextureCoord{1.5, 0.5}, color.RGBA{}},
+		{"noneInside", &TextureSampler{Filter: TextureFilterNearest, TileStyleV: TileNone}, TextureCoord{0.25, 0.75}, texRed},
+		{"linearTexel", &TextureSampler{Filter: TextureFilterLinear, TileStyleU: TileClamp, TileStyleV: TileClamp}, TextureCoord{0.25, 0.75}, texRed},
+		{"linearCenter", &TextureSampler{TileStyleU: TileClamp, TileStyleV: TileClamp}, TextureCoord{0.5, 0.5}, color.RGBA{R: 128, G: 128, B: 128, A: 255}},
+		{"linearEdge", &TextureSampler{Filter: TextureFilterLinear, TileStyleU: TileClamp, TileStyleV: TileClamp}, TextureCoord{0.5, 1}, color.RGBA{R: 128, G: 128, A: 255}},
+		{"linearWrap", &TextureSampler{Filter: TextureFilterLinear}, TextureCoord{0, 1}, color.RGBA{R: 128, G: 128, B: 128, A: 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.s.Image = newTestImage()
+			if got := tt.s.Sample(tt.c); got != tt.want {
+				t.Errorf("TextureSampler.Sample() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTextureSampler(t *testing.T) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, newTestImage()); err != nil {
+		t.Fatal(err)
+	}
+	m := &go3mf.Model{Attachments: []go3mf.Attachment{{Path: "/a.png", Stream: &data}}}
+	s, err := NewTextureSampler(m, &Texture2D{Path: "/a.png", ContentType: TextureTypePNG, TileStyleU: TileClamp, Filter: TextureFilterNearest})
+	if err != nil {
+		t.Fatalf("NewTextureSampler() error = %v", err)
+	}
+	if s.TileStyleU != TileClamp || s.TileStyleV != TileWrap || s.Filter != TextureFilterNearest {
+		t.Errorf("NewTextureSampler() = %v", s)
+	}
+	if got := s.Sample(TextureCoord{0.75, 0.25}); got != texWhite {
+		t.Errorf("TextureSampler.Sample() = %v, want %v", got, texWhite)
+	}
+	if _, err := NewTextureSampler(m, &Texture2D{Path: "/b.png"}); err != ErrMissingTexturePart {
+		t.Errorf("NewTextureSampler() error = %v, want %v", err, ErrMissingTexturePart)
+	}
+}
+
+func TestTexture2D_Data(t *testing.T) {
+	m := &go3mf.Model{Attachments: []go3mf.Attachment{{Path: "/a.png", Stream: strings.NewReader("data")}}}
+	for i := 0; i < 2; i++ {
+		got, err := (&Texture2D{Path: "/A.png"}).Data(m)
+		if err != nil || string(got) != "data" {
+			t.Errorf("Texture2D.Data() = %s, %v", got, err)
+		}
+	}
+	if _, err := (&Texture2D{Path: "/b.png"}).Data(m); err != ErrMissingTexturePart {
+		t.Errorf("Texture2D.Data() error = %v, want %v", err, ErrMissingTexturePart)
+	}
+}
@@ -18,10 +18,22 @@ func (Spec) Validate(model interface{}, path string, asset interface{}) error {
 	return nil
 }
 
+// ValidateWarnings checks that the textures are stored in the recommended directory.
+func (Spec) ValidateWarnings(_ interface{}, _ string, asset interface{}) error {
+	if t, ok := asset.(*Texture2D); ok && !strings.HasPrefix(t.Path, go3mf.Default3DTexturesDir) {
+		return errors.Wrap(ErrTextureLocation, attrPath)
+	}
+	return nil
+}
+
 func validateAsset(m *go3mf.Model, path string, r go3mf.Asset) (errs error) {
 	switch r := r.(type) {
+	case *go3mf.BaseMaterials:
+		if a := GetBaseMaterialsAttr(r); a != nil {
+			errs = validateDisplayPropertiesID(m, path, a.DisplayPropertiesID, r.Len(), false)
+		}
 	case *ColorGroup:
-		errs = validateColorGroup(path, r)
+		errs = validateColorGroup(m, path, r)
 	case *Texture2DGroup:
 		errs = validateTexture2DGroup(m, path, r)
 	case *Texture2D:
@@ -30,11 +42,92 @@ func validateAsset(m *go3mf.Model, path string, r go3mf.Asset) (errs error) {
 		errs = validateMultiProps(m, path, r)
 	case *CompositeMaterials:
 		errs = validateCompositeMat(m, path, r)
+	case *PBSpecularDisplayProperties:
+		errs = validateDisplayProps(r.ID, r.Len(), func(i int) string { return r.Properties[i].Name }, attrPBSpecular)
+	case *PBMetallicDisplayProperties:
+		errs = validateDisplayProps(r.ID, r.Len(), func(i int) string { return r.Properties[i].Name }, attrPBMetallic)
+	case *TranslucentDisplayProperties:
+		errs = validateDisplayProps(r.ID, r.Len(), func(i int) string { return r.Properties[i].Name }, attrTranslucent)
+	case *PBSpecularTextureDisplayProperties:
+		errs = validateTextureDisplayProps(m, path, r.ID, r.Name,
+			[2]uint32{r.SpecularTextureID, r.GlossinessTextureID},
+			[2]string{attrSpecularTextureID, attrGlossinessTextureID})
+	case *PBMetallicTextureDisplayProperties:
+		errs = validateTextureDisplayProps(m, path, r.ID, r.Name,
+			[2]uint32{r.MetallicTextureID, r.RoughnessTextureID},
+			[2]string{attrMetallicTextureID, attrRoughnessTextureID})
+	}
+	return
+}
+
+func validateDisplayPropertiesID(m *go3mf.Model, path string, id uint32, count int, texture bool) error {
+	if id == 0 {
+		return nil
+	}
+	dp, ok := m.FindAsset(path, id)
+	if !ok {
+		return errors.ErrMissingResource
+	}
+	var propCount int
+	switch dp := dp.(type) {
+	case *PBSpecularDisplayProperties:
+		propCount = dp.Len()
+	case *PBMetallicDisplayProperties:
+		propCount = dp.Len()
+	case *TranslucentDisplayProperties:
+		propCount = dp.Len()
+	case *PBSpecularTextureDisplayProperties, *PBMetallicTextureDisplayProperties:
+		if texture {
+			return nil
+		}
+		return ErrDisplayProperties
+	default:
+		return ErrDisplayProperties
+	}
+	if texture {
+		return ErrDisplayProperties
+	}
+	if propCount != count {
+		return ErrDisplayPropsCount
+	}
+	return nil
+}
+
+func validateDisplayProps(id uint32, count int, name func(int) string, elem string) (errs error) {
+	if id == 0 {
+		errs = errors.Append(errs, errors.ErrMissingID)
+	}
+	if count == 0 {
+		errs = errors.Append(errs, errors.ErrEmptyResourceProps)
+	}
+	for j := 0; j < count; j++ {
+		if name(j) == "" {
+			errs = errors.Append(errs, errors.WrapIndex(errors.NewMissingFieldError(attrName), elem, j))
+		}
+	}
+	return
+}
+
+func validateTextureDisplayProps(m *go3mf.Model, path string, id uint32, name string, texIDs [2]uint32, texAttrs [2]string) (errs error) {
+	if id == 0 {
+		errs = errors.Append(errs, errors.ErrMissingID)
+	}
+	if name == "" {
+		errs = errors.Append(errs, errors.NewMissingFieldError(attrName))
+	}
+	for i, texID := range texIDs {
+		if texID == 0 {
+			errs = errors.Append(errs, errors.NewMissingFieldError(texAttrs[i]))
+		} else if text, ok := m.FindAsset(path, texID); !ok {
+			errs = errors.Append(errs, ErrTextureReference)
+		} else if _, ok := text.(*Texture2D); !ok {
+			errs = errors.Append(errs, ErrTextureReference)
+		}
 	}
 	return
 }
 
-func validateColorGroup(path string, r *ColorGroup) (errs error) {
+func validateColorGroup(m *go3mf.Model, path string, r *ColorGroup) (errs error) {
 	if r.ID == 0 {
 		errs = errors.Append(errs, errors.ErrMissingID)
 	}
@@ -46,6 +139,7 @@ func validateColorGroup(path string, r *ColorGroup) (errs error) {
 			errs = errors.Append(errs, errors.WrapIndex(errors.NewMissingFieldError(attrColor), attrColor, j))
 		}
 	}
+	errs = errors.Append(errs, validateDisplayPropertiesID(m, path, r.DisplayPropertiesID, r.Len(), false))
 	return
 }
 
@@ -65,6 +159,7 @@ func validateTexture2DGroup(m *go3mf.Model, path string, r *Texture2DGroup) (err
 	if len(r.Coords) == 0 {
 		errs = errors.Append(errs, errors.ErrEmptyResourceProps)
 	}
+	errs = errors.Append(errs, validateDisplayPropertiesID(m, path, r.DisplayPropertiesID, r.Len(), true))
 	return
 }
 
@@ -174,5 +269,6 @@ func validateCompositeMat(m *go3mf.Model, path string, r *CompositeMaterials) (e
 	if len(r.Composites) == 0 {
 		errs = errors.Append(errs, errors.ErrEmptyResourceProps)
 	}
+	errs = errors.Append(errs, validateDisplayPropertiesID(m, path, r.DisplayPropertiesID, r.Len(), false))
 	return
 }
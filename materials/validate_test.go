@@ -11,6 +11,7 @@ import (
 	"github.com/go-test/deep"
 	"github.com/hpinc/go3mf"
 	"github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
 )
 
 func TestValidate(t *testing.T) {
@@ -112,6 +113,39 @@ func TestValidate(t *testing.T) {
 			fmt.Sprintf("go3mf: XPath: /model/resources/compositematerials[4]: %v", ErrCompositeBase),
 			fmt.Sprintf("go3mf: XPath: /model/resources/compositematerials[5]: %v", errors.ErrMissingResource),
 		}},
+		{"displayProperties", &go3mf.Model{
+			Attachments: []go3mf.Attachment{{Path: "/a.png"}},
+			Resources: go3mf.Resources{Assets: []go3mf.Asset{
+				&Texture2D{ID: 1, ContentType: TextureTypePNG, Path: "/a.png"},
+				&PBSpecularDisplayProperties{ID: 2, Properties: []PBSpecular{{Name: "a"}, {Name: "b"}}},
+				&PBMetallicDisplayProperties{ID: 3, Properties: []PBMetallic{{}}},
+				&TranslucentDisplayProperties{ID: 4},
+				&PBSpecularTextureDisplayProperties{ID: 5, Name: "a", SpecularTextureID: 1, GlossinessTextureID: 2},
+				&PBMetallicTextureDisplayProperties{ID: 6},
+				&ColorGroup{ID: 7, DisplayPropertiesID: 2, Colors: []color.RGBA{{R: 1}, {G: 1}}},
+				&ColorGroup{ID: 8, DisplayPropertiesID: 3, Colors: []color.RGBA{{R: 1}, {G: 1}}},
+				&ColorGroup{ID: 9, DisplayPropertiesID: 5, Colors: []color.RGBA{{R: 1}}},
+				&ColorGroup{ID: 10, DisplayPropertiesID: 100, Colors: []color.RGBA{{R: 1}}},
+				&Texture2DGroup{ID: 11, TextureID: 1, DisplayPropertiesID: 5, Coords: []TextureCoord{{}}},
+				&Texture2DGroup{ID: 12, TextureID: 1, DisplayPropertiesID: 2, Coords: []TextureCoord{{}}},
+				&go3mf.BaseMaterials{ID: 13, Materials: []go3mf.Base{{Name: "a", Color: color.RGBA{R: 1}}}, AnyAttr: spec.AnyAttr{&BaseMaterialsAttr{DisplayPropertiesID: 3}}},
+				&go3mf.BaseMaterials{ID: 14, Materials: []go3mf.Base{{Name: "a", Color: color.RGBA{R: 1}}}, AnyAttr: spec.AnyAttr{&BaseMaterialsAttr{DisplayPropertiesID: 2}}},
+				&CompositeMaterials{ID: 15, MaterialID: 13, DisplayPropertiesID: 6, Indices: []uint32{0}, Composites: []Composite{{Values: []float32{1}}}},
+			}},
+		}, []string{
+			fmt.Sprintf("go3mf: XPath: /model/resources/pbmetallicdisplayproperties[2]/pbmetallic[0]: %v", &errors.MissingFieldError{Name: attrName}),
+			fmt.Sprintf("go3mf: XPath: /model/resources/translucentdisplayproperties[3]: %v", errors.ErrEmptyResourceProps),
+			fmt.Sprintf("go3mf: XPath: /model/resources/pbspeculartexturedisplayproperties[4]: %v", ErrTextureReference),
+			fmt.Sprintf("go3mf: XPath: /model/resources/pbmetallictexturedisplayproperties[5]: %v", &errors.MissingFieldError{Name: attrName}),
+			fmt.Sprintf("go3mf: XPath: /model/resources/pbmetallictexturedisplayproperties[5]: %v", &errors.MissingFieldError{Name: attrMetallicTextureID}),
+			fmt.Sprintf("go3mf: XPath: /model/resources/pbmetallictexturedisplayproperties[5]: %v", &errors.MissingFieldError{Name: attrRoughnessTextureID}),
+			fmt.Sprintf("go3mf: XPath: /model/resources/colorgroup[7]: %v", ErrDisplayPropsCount),
+			fmt.Sprintf("go3mf: XPath: /model/resources/colorgroup[8]: %v", ErrDisplayProperties),
+			fmt.Sprintf("go3mf: XPath: /model/resources/colorgroup[9]: %v", errors.ErrMissingResource),
+			fmt.Sprintf("go3mf: XPath: /model/resources/texture2dgroup[11]: %v", ErrDisplayProperties),
+			fmt.Sprintf("go3mf: XPath: /model/resources/basematerials[13]: %v", ErrDisplayPropsCount),
+			fmt.Sprintf("go3mf: XPath: /model/resources/compositematerials[14]: %v", ErrDisplayProperties),
+		}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -130,3 +164,24 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateWarnings(t *testing.T) {
+	model := &go3mf.Model{Extensions: []go3mf.Extension{DefaultExtension}, Resources: go3mf.Resources{Assets: []go3mf.Asset{
+		&Texture2D{ID: 1, Path: "/3D/Textures/a.png"},
+		&Texture2D{ID: 2, Path: "/3D/a.png"},
+	}}}
+	want := []string{
+		fmt.Sprintf("go3mf: XPath: /model/resources/texture2d[1]/path: %v", ErrTextureLocation),
+	}
+	err := model.ValidateWarnings(go3mf.WarningOptions{})
+	if err == nil {
+		t.Fatal("Model.ValidateWarnings() err nil")
+	}
+	var errs []string
+	for _, err := range err.(*errors.List).Errors {
+		errs = append(errs, err.Error())
+	}
+	if diff := deep.Equal(errs, want); diff != nil {
+		t.Errorf("Model.ValidateWarnings() = %v", diff)
+	}
+}
@@ -153,6 +153,14 @@ func (m1 Matrix) Mul(m2 Matrix) Matrix {
 	}
 }
 
+// Determinant returns the determinant of the 3x3 linear part of the matrix.
+// It is negative if the matrix mirrors the space.
+func (m1 Matrix) Determinant() float32 {
+	return m1[0]*(m1[5]*m1[10]-m1[6]*m1[9]) -
+		m1[4]*(m1[1]*m1[10]-m1[2]*m1[9]) +
+		m1[8]*(m1[1]*m1[6]-m1[2]*m1[5])
+}
+
 // Mul3D performs a "matrix product" between this matrix
 // and another 3D point.
 func (m1 Matrix) Mul3D(v Point3D) Point3D {
@@ -173,23 +181,21 @@ func (m1 Matrix) Mul2D(v Point2D) Point2D {
 }
 
 // MulBox performs a "matrix product" between this matrix
-// and a box
+// and a box, returning the axis aligned box that contains
+// the eight transformed corners.
 func (m1 Matrix) MulBox(b Box) Box {
 	if m1[15] == 0 {
 		return b
 	}
-	box := Box{
-		Min: m1.Mul3D(b.Min),
-		Max: m1.Mul3D(b.Max),
-	}
-	if box.Min.X() > box.Max.X() {
-		box.Min[0], box.Max[0] = box.Max[0], box.Min[0]
-	}
-	if box.Min.Y() > box.Max.Y() {
-		box.Min[1], box.Max[1] = box.Max[1], box.Min[1]
-	}
-	if box.Min.Z() > box.Max.Z() {
-		box.Min[2], box.Max[2] = box.Max[2], box.Min[2]
+	box := newLimitBox()
+	for i := 0; i < 8; i++ {
+		corner := b.Min
+		for j := 0; j < 3; j++ {
+			if i&(1<<j) != 0 {
+				corner[j] = b.Max[j]
+			}
+		}
+		box = box.extendPoint(m1.Mul3D(corner))
 	}
 	return box
 }
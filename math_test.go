@@ -204,6 +204,28 @@ func TestMatrix_Mul3D(t *testing.T) {
 	}
 }
 
+func TestMatrix_Determinant(t *testing.T) {
+	tests := []struct {
+		name string
+		m1   Matrix
+		want float32
+	}{
+		{"zero", Matrix{}, 0},
+		{"identity", Identity(), 1},
+		{"translate", Identity().Translate(1, 2, 3), 1},
+		{"scale", Matrix{2, 0, 0, 0, 0, 3, 0, 0, 0, 0, 4, 0, 0, 0, 0, 1}, 24},
+		{"mirror", Matrix{-1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}, -1},
+		{"rotate", Matrix{0, 1, 0, 0, -1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m1.Determinant(); got != tt.want {
+				t.Errorf("Matrix.Determinant() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMatrix_Mul2D(t *testing.T) {
 	type args struct {
 		v Point2D
@@ -368,6 +390,13 @@ func TestMatrix_MulBox(t *testing.T) {
 			Min: Point3D{-4, 2, 2},
 			Max: Point3D{-2, 4, 4},
 		}},
+		{"shear", Matrix{1, 0, 0, 0, -1, 1, 0, 0, 0, 0, 1, 0, 5, 0, 0, 1}, args{Box{
+			Min: Point3D{0, 0, 0},
+			Max: Point3D{1, 1, 3},
+		}}, Box{
+			Min: Point3D{4, 0, 0},
+			Max: Point3D{6, 1, 3},
+		}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
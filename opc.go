@@ -70,12 +70,17 @@ func newRelationships(rels []*opc.Relationship) []Relationship {
 }
 
 type opcFile struct {
-	r *opc.Reader
-	f *opc.File
+	r       *opc.Reader
+	f       *opc.File
+	maxSize int64
 }
 
 func (o *opcFile) Open() (io.ReadCloser, error) {
-	return o.f.Open()
+	rc, err := o.f.Open()
+	if err != nil {
+		return nil, err
+	}
+	return newLimitedReadCloser(rc, o.f.Name, o.maxSize), nil
 }
 
 func (o *opcFile) Name() string {
@@ -88,7 +93,7 @@ func (o *opcFile) ContentType() string {
 
 func (o *opcFile) FindFileFromName(name string) (packageFile, bool) {
 	name = opc.ResolveRelationship(o.f.Name, name)
-	return findOPCFileFromName(name, o.r)
+	return findOPCFileFromName(name, o.r, o.maxSize)
 }
 
 func (o *opcFile) Relationships() []Relationship {
@@ -96,16 +101,31 @@ func (o *opcFile) Relationships() []Relationship {
 }
 
 type opcReader struct {
-	ra   io.ReaderAt
-	size int64
-	r    *opc.Reader // nil until call Open.
+	ra     io.ReaderAt
+	size   int64
+	r      *opc.Reader // nil until call Open.
+	limits Limits
 }
 
-func (o *opcReader) Open(f func(r io.Reader) io.ReadCloser) (err error) {
+func (o *opcReader) Open(f func(r io.Reader) io.ReadCloser, limits Limits) (err error) {
+	o.limits = limits
 	o.r, err = opc.NewReader(o.ra, o.size)
+	if err != nil {
+		return
+	}
 	if f != nil {
 		o.r.SetDecompressor(f)
 	}
+	if limits.MaxParts > 0 && len(o.r.Files) > limits.MaxParts {
+		return &LimitError{Limit: LimitParts, Max: int64(limits.MaxParts)}
+	}
+	if limits.MaxPartSize > 0 {
+		for _, file := range o.r.Files {
+			if int64(file.Size) > limits.MaxPartSize {
+				return &LimitError{Limit: LimitPartSize, Max: limits.MaxPartSize, Path: file.Name}
+			}
+		}
+	}
 	return
 }
 
@@ -115,17 +135,17 @@ func (o *opcReader) Relationships() []Relationship {
 
 func (o *opcReader) FindFileFromName(name string) (packageFile, bool) {
 	name = opc.ResolveRelationship("/", name)
-	return findOPCFileFromName(name, o.r)
+	return findOPCFileFromName(name, o.r, o.limits.MaxPartSize)
 }
 
 func resolveRelationship(source, rel string) string {
 	return opc.ResolveRelationship(source, rel)
 }
 
-func findOPCFileFromName(name string, r *opc.Reader) (packageFile, bool) {
+func findOPCFileFromName(name string, r *opc.Reader, maxSize int64) (packageFile, bool) {
 	for _, f := range r.Files {
 		if f.Name == name {
-			return &opcFile{r, f}, true
+			return &opcFile{r, f, maxSize}, true
 		}
 	}
 	return nil, false
@@ -36,8 +36,8 @@ func Test_opcFile_Name(t *testing.T) {
 		o    *opcFile
 		want string
 	}{
-		{"empty", &opcFile{nil, &opc.File{Part: new(opc.Part)}}, ""},
-		{"base", &opcFile{nil, &opc.File{Part: &opc.Part{Name: "a.xml"}}}, "a.xml"},
+		{"empty", &opcFile{f: &opc.File{Part: new(opc.Part)}}, ""},
+		{"base", &opcFile{f: &opc.File{Part: &opc.Part{Name: "a.xml"}}}, "a.xml"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -54,8 +54,8 @@ func Test_opcFile_Relationships(t *testing.T) {
 		o    *opcFile
 		want []Relationship
 	}{
-		{"empty", &opcFile{nil, &opc.File{Part: new(opc.Part)}}, []Relationship{}},
-		{"base", &opcFile{nil, &opc.File{Part: &opc.Part{Relationships: []*opc.Relationship{
+		{"empty", &opcFile{f: &opc.File{Part: new(opc.Part)}}, []Relationship{}},
+		{"base", &opcFile{f: &opc.File{Part: &opc.Part{Relationships: []*opc.Relationship{
 			{Type: "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dtexture", TargetURI: "/a.xml"},
 			{Type: "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel", TargetURI: "/b.xml"},
 		}}}}, []Relationship{
@@ -83,9 +83,9 @@ func Test_opcReader_FindFileFromName(t *testing.T) {
 		args args
 		want packageFile
 	}{
-		{"foundA", &opcReader{nil, 0, reader}, args{"/a.xml"}, &opcFile{reader, &opc.File{Part: &opc.Part{Name: "/a.xml"}}}},
-		{"foundB", &opcReader{nil, 0, reader}, args{"/b.xml"}, &opcFile{reader, &opc.File{Part: &opc.Part{Name: "/b.xml"}}}},
-		{"notfound", &opcReader{nil, 0, reader}, args{"/c.xml"}, nil},
+		{"foundA", &opcReader{ra: nil, size: 0, r: reader}, args{"/a.xml"}, &opcFile{r: reader, f: &opc.File{Part: &opc.Part{Name: "/a.xml"}}}},
+		{"foundB", &opcReader{ra: nil, size: 0, r: reader}, args{"/b.xml"}, &opcFile{r: reader, f: &opc.File{Part: &opc.Part{Name: "/b.xml"}}}},
+		{"notfound", &opcReader{ra: nil, size: 0, r: reader}, args{"/c.xml"}, nil},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
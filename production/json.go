@@ -0,0 +1,86 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package production
+
+import (
+	"encoding/json"
+
+	"github.com/hpinc/go3mf/uuid"
+)
+
+type jsonAttr struct {
+	UUID string `json:"uuid,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+// unmarshalJSON decodes b and validates its UUID, as the XML decoder does.
+func unmarshalJSON(b []byte) (ja jsonAttr, err error) {
+	if err = json.Unmarshal(b, &ja); err != nil {
+		return
+	}
+	if ja.UUID != "" {
+		err = uuid.Validate(ja.UUID)
+	}
+	return
+}
+
+// MarshalJSON returns the JSON encoding of u.
+func (u BuildAttr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAttr{UUID: u.UUID})
+}
+
+// UnmarshalJSON decodes the JSON encoding of u.
+func (u *BuildAttr) UnmarshalJSON(b []byte) error {
+	ja, err := unmarshalJSON(b)
+	if err != nil {
+		return err
+	}
+	*u = BuildAttr{UUID: ja.UUID}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of u.
+func (u ObjectAttr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAttr{UUID: u.UUID})
+}
+
+// UnmarshalJSON decodes the JSON encoding of u.
+func (u *ObjectAttr) UnmarshalJSON(b []byte) error {
+	ja, err := unmarshalJSON(b)
+	if err != nil {
+		return err
+	}
+	*u = ObjectAttr{UUID: ja.UUID}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p ItemAttr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAttr(p))
+}
+
+// UnmarshalJSON decodes the JSON encoding of p.
+func (p *ItemAttr) UnmarshalJSON(b []byte) error {
+	ja, err := unmarshalJSON(b)
+	if err != nil {
+		return err
+	}
+	*p = ItemAttr(ja)
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p ComponentAttr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAttr(p))
+}
+
+// UnmarshalJSON decodes the JSON encoding of p.
+func (p *ComponentAttr) UnmarshalJSON(b []byte) error {
+	ja, err := unmarshalJSON(b)
+	if err != nil {
+		return err
+	}
+	*p = ComponentAttr(ja)
+	return nil
+}
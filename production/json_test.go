@@ -0,0 +1,52 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package production
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func TestModel_JSON(t *testing.T) {
+	m := &go3mf.Model{Path: "/3D/3dmodel.model"}
+	m.Resources.Objects = []*go3mf.Object{{
+		ID:      1,
+		AnyAttr: spec.AnyAttr{&ObjectAttr{UUID: "cb828680-8895-4e08-a1fc-be63e033df15"}},
+		Components: &go3mf.Components{Component: []*go3mf.Component{{
+			ObjectID: 2,
+			AnyAttr:  spec.AnyAttr{&ComponentAttr{UUID: "cb828680-8895-4e08-a1fc-be63e033df16", Path: "/3D/other.model"}},
+		}}},
+	}}
+	m.Build.AnyAttr = spec.AnyAttr{&BuildAttr{UUID: "e9e25302-6428-402e-8633-cc95528d0ed3"}}
+	m.Build.Items = []*go3mf.Item{{
+		ObjectID: 1,
+		AnyAttr:  spec.AnyAttr{&ItemAttr{UUID: "e9e25302-6428-402e-8633-cc95528d0ed2", Path: "/3D/3dmodel.model"}},
+	}}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Model.MarshalJSON() error = %v", err)
+	}
+	if bytes.Contains(b, []byte(`"attr":`)) {
+		t.Errorf("Model.MarshalJSON() encoded raw xml attributes, s = %s", b)
+	}
+	got := new(go3mf.Model)
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatalf("Model.UnmarshalJSON() error = %v, s = %s", err, b)
+	}
+	if diff := deep.Equal(m, got); diff != nil {
+		t.Errorf("Model.UnmarshalJSON() = %v, s = %s", diff, b)
+	}
+}
+
+func TestModel_UnmarshalJSON_error(t *testing.T) {
+	s := `{"build":{"anyAttr":[{"namespace":"` + Namespace + `","json":{"uuid":"foo"}}]}}`
+	if err := json.Unmarshal([]byte(s), new(go3mf.Model)); err == nil {
+		t.Error("Model.UnmarshalJSON() expected error")
+	}
+}
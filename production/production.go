@@ -7,6 +7,7 @@ import (
 	"errors"
 
 	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
 	"github.com/hpinc/go3mf/spec"
 	"github.com/hpinc/go3mf/uuid"
 )
@@ -34,6 +35,12 @@ type Spec struct{}
 
 func init() {
 	spec.Register(Namespace, Spec{})
+	for _, r := range []specerr.Rule{
+		{ID: "production.uuid", Severity: specerr.SeverityError, Chapter: "2", Err: ErrUUID},
+		{ID: "production.component.non-root", Severity: specerr.SeverityError, Chapter: "2", Err: ErrProdRefInNonRoot},
+	} {
+		specerr.RegisterRule(r)
+	}
 }
 
 // BuildAttr provides a UUID in the root model file build element to ensure
@@ -61,6 +68,12 @@ type ObjectAttr struct {
 
 func (ObjectAttr) Namespace() string { return Namespace }
 
+// PartAttrs implements spec.PartAttrGroup.
+// The parts of a split object and their components get new UUIDs.
+func (ObjectAttr) PartAttrs() (spec.AttrGroup, spec.AttrGroup) {
+	return &ObjectAttr{UUID: uuid.New()}, &ComponentAttr{UUID: uuid.New()}
+}
+
 func GetObjectAttr(obj *go3mf.Object) *ObjectAttr {
 	for _, a := range obj.AnyAttr {
 		if a, ok := a.(*ObjectAttr); ok {
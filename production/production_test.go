@@ -4,6 +4,7 @@
 package production
 
 import (
+	"image/color"
 	"testing"
 
 	"github.com/hpinc/go3mf"
@@ -14,6 +15,7 @@ var _ spec.Marshaler = new(BuildAttr)
 var _ spec.Marshaler = new(ItemAttr)
 var _ spec.Marshaler = new(ComponentAttr)
 var _ spec.Marshaler = new(ObjectAttr)
+var _ spec.PartAttrGroup = new(ObjectAttr)
 
 func TestComponentAttr_ObjectPath(t *testing.T) {
 	tests := []struct {
@@ -75,3 +77,43 @@ func TestSetMissingUUIDs(t *testing.T) {
 		t.Errorf("SetMissingUUIDs() should have filled object attrs")
 	}
 }
+
+func TestSplitByProperty(t *testing.T) {
+	m := &go3mf.Model{Path: "/3D/3dmodel.model", Extensions: []go3mf.Extension{DefaultExtension}}
+	m.Resources.Assets = []go3mf.Asset{&go3mf.BaseMaterials{ID: 1, Materials: []go3mf.Base{
+		{Name: "a", Color: color.RGBA{R: 255, A: 255}}, {Name: "b", Color: color.RGBA{B: 255, A: 255}},
+	}}}
+	m.Resources.Objects = []*go3mf.Object{{ID: 2, PID: 1, Mesh: &go3mf.Mesh{
+		Vertices: go3mf.Vertices{Vertex: []go3mf.Point3D{
+			{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1},
+			{5, 0, 0}, {6, 0, 0}, {5, 1, 0}, {5, 0, 1},
+		}},
+		Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{
+			{V1: 0, V2: 2, V3: 1}, {V1: 0, V2: 1, V3: 3}, {V1: 0, V2: 3, V3: 2}, {V1: 1, V2: 2, V3: 3},
+			{V1: 4, V2: 6, V3: 5, PID: 1, P1: 1, P2: 1, P3: 1}, {V1: 4, V2: 5, V3: 7, PID: 1, P1: 1, P2: 1, P3: 1},
+			{V1: 4, V2: 7, V3: 6, PID: 1, P1: 1, P2: 1, P3: 1}, {V1: 5, V2: 6, V3: 7, PID: 1, P1: 1, P2: 1, P3: 1},
+		}},
+	}}}
+	m.Build.Items = []*go3mf.Item{{ObjectID: 2}}
+	SetMissingUUIDs(m)
+	if err := m.Validate(); err != nil {
+		t.Fatalf("Model.Validate() error = %v", err)
+	}
+	parts, err := m.SplitByProperty("", 2)
+	if err != nil {
+		t.Fatalf("Model.SplitByProperty() error = %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("Model.SplitByProperty() = %d parts, want 2", len(parts))
+	}
+	uuids := map[string]bool{GetObjectAttr(m.Resources.Objects[2]).UUID: true}
+	for _, p := range parts {
+		uuids[GetObjectAttr(p).UUID] = true
+	}
+	if len(uuids) != 3 {
+		t.Errorf("Model.SplitByProperty() UUIDs = %v, want 3 different", uuids)
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("Model.Validate() error = %v", err)
+	}
+}
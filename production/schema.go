@@ -0,0 +1,11 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package production
+
+//go:generate go run ../internal/cmd/xsdgen -pkg production -const schema -o schema_gen.go schemas/production.xsd
+
+// Schema returns the XSD of the Production extension.
+func (Spec) Schema() []byte {
+	return []byte(schema)
+}
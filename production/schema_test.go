@@ -0,0 +1,82 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package production
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func TestSpec_Schema(t *testing.T) {
+	newMesh := func() *go3mf.Mesh {
+		return &go3mf.Mesh{
+			Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {10, 0, 0}, {0, 10, 0}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+		}
+	}
+	tests := []struct {
+		name  string
+		model *go3mf.Model
+		want  []string
+	}{
+		{"valid", &go3mf.Model{
+			Resources: go3mf.Resources{Objects: []*go3mf.Object{
+				{ID: 1, Mesh: newMesh(), AnyAttr: spec.AnyAttr{&ObjectAttr{UUID: "cb828680-8895-4e08-a1fc-be63e033df15"}}},
+				{ID: 2, Components: &go3mf.Components{Component: []*go3mf.Component{{ObjectID: 1, AnyAttr: spec.AnyAttr{
+					&ComponentAttr{UUID: "cb828680-8895-4e08-a1fc-be63e033df16"},
+				}}}}, AnyAttr: spec.AnyAttr{&ObjectAttr{UUID: "cb828680-8895-4e08-a1fc-be63e033df17"}}},
+			}},
+			Build: go3mf.Build{
+				AnyAttr: spec.AnyAttr{&BuildAttr{UUID: "e9e25302-6428-402e-8633-cc95528d0ed3"}},
+				Items:   []*go3mf.Item{{ObjectID: 2, AnyAttr: spec.AnyAttr{&ItemAttr{UUID: "e9e25302-6428-402e-8633-cc95528d0ed2", Path: "/3D/3dmodel.model"}}}},
+			},
+		}, nil},
+		{"invalid", &go3mf.Model{
+			Resources: go3mf.Resources{Objects: []*go3mf.Object{
+				{ID: 1, Mesh: newMesh(), AnyAttr: spec.AnyAttr{&ObjectAttr{UUID: "{cb828680-8895-4e08-a1fc-be63e033df15}"}}},
+			}},
+			Build: go3mf.Build{Items: []*go3mf.Item{{ObjectID: 1, AnyAttr: spec.AnyAttr{&ItemAttr{UUID: "e9e25302-6428-402e-8633-cc95528d0ed2", Path: "3dmodel.model"}}}}},
+		}, []string{
+			fmt.Sprintf(`go3mf: Path: /3D/3dmodel.model Line: 2 XPath: /model/resources/object[0]: %v: UUID="{cb828680-8895-4e08-a1fc-be63e033df15}"`, specerr.ErrSchemaAttrValue),
+			fmt.Sprintf(`go3mf: Path: /3D/3dmodel.model Line: 2 XPath: /model/build/item[0]: %v: path="3dmodel.model"`, specerr.ErrSchemaAttrValue),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.model.Extensions = []go3mf.Extension{DefaultExtension}
+			var buf bytes.Buffer
+			if err := go3mf.NewEncoder(&buf).Encode(tt.model); err != nil {
+				t.Fatalf("go3mf.Encoder.Encode() error = %v", err)
+			}
+			d := go3mf.NewDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			d.ValidateSchema = true
+			var got []string
+			if err := d.Decode(new(go3mf.Model)); err != nil {
+				for _, err := range err.(*specerr.List).Errors {
+					got = append(got, err.Error())
+				}
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("go3mf.Decoder.Decode() = %v", diff)
+			}
+		})
+	}
+}
+
+func Test_schema(t *testing.T) {
+	b, err := ioutil.ReadFile("schemas/production.xsd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != schema {
+		t.Error("schema differs from schemas/production.xsd, run go generate")
+	}
+}
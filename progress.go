@@ -0,0 +1,117 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"io"
+	"sync"
+)
+
+// progressEveryBytes defines how many bytes are written
+// to a part before reporting the encoding progress.
+var progressEveryBytes int64 = 1 << 16
+
+// Phase defines the stage of a decoding or encoding operation.
+type Phase uint8
+
+// Supported phases.
+const (
+	PhaseOPC Phase = iota
+	PhaseChildModels
+	PhaseRootModel
+	PhaseAttachments
+)
+
+func (p Phase) String() string {
+	return map[Phase]string{
+		PhaseOPC:         "opc",
+		PhaseChildModels: "childmodels",
+		PhaseRootModel:   "rootmodel",
+		PhaseAttachments: "attachments",
+	}[p]
+}
+
+// Progress describes the state of a decoding or encoding operation.
+//
+// Part is the name of the package part being processed, empty when in PhaseOPC.
+// Bytes is the amount of bytes read or written from Part.
+// Tokens is the amount of XML tokens decoded from Part, always zero when encoding.
+type Progress struct {
+	Phase  Phase
+	Part   string
+	Bytes  int64
+	Tokens int
+}
+
+// ProgressFunc is called to report the progress of a decoding or encoding operation.
+// Calls are serialized, even if parts are processed concurrently.
+type ProgressFunc func(Progress)
+
+type progressReporter struct {
+	mu sync.Mutex
+	fn ProgressFunc
+}
+
+func newProgressReporter(fn ProgressFunc) *progressReporter {
+	if fn == nil {
+		return nil
+	}
+	return &progressReporter{fn: fn}
+}
+
+func (p *progressReporter) report(pr Progress) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.fn(pr)
+	p.mu.Unlock()
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// progressPart reports the amount of written bytes
+// every progressEveryBytes and when calling done.
+type progressPart struct {
+	packagePart
+	reporter *progressReporter
+	phase    Phase
+	name     string
+	n        int64
+	next     int64
+}
+
+func newProgressPart(p packagePart, reporter *progressReporter, phase Phase, name string) packagePart {
+	if reporter == nil {
+		return p
+	}
+	return &progressPart{packagePart: p, reporter: reporter, phase: phase, name: name, next: progressEveryBytes}
+}
+
+func (p *progressPart) Write(b []byte) (int, error) {
+	n, err := p.packagePart.Write(b)
+	p.n += int64(n)
+	if p.n >= p.next {
+		p.next = p.n + progressEveryBytes
+		p.reporter.report(Progress{Phase: p.phase, Part: p.name, Bytes: p.n})
+	}
+	return n, err
+}
+
+func (p *progressPart) closePart() error {
+	if err := closePart(p.packagePart); err != nil {
+		return err
+	}
+	p.reporter.report(Progress{Phase: p.phase, Part: p.name, Bytes: p.n})
+	return nil
+}
@@ -0,0 +1,91 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPhase_String(t *testing.T) {
+	tests := []struct {
+		p    Phase
+		want string
+	}{
+		{PhaseOPC, "opc"},
+		{PhaseChildModels, "childmodels"},
+		{PhaseRootModel, "rootmodel"},
+		{PhaseAttachments, "attachments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.p.String(); got != tt.want {
+				t.Errorf("Phase.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgress_Roundtrip(t *testing.T) {
+	oldBytes, oldTokens := progressEveryBytes, checkEveryTokens
+	defer func() { progressEveryBytes, checkEveryTokens = oldBytes, oldTokens }()
+	progressEveryBytes = 10
+	checkEveryTokens = 1
+	m := &Model{
+		Attachments: []Attachment{
+			{ContentType: "image/png", Path: "/Metadata/thumbnail.png", Stream: bytes.NewBufferString("fake")},
+		},
+		RootRelationships: []Relationship{{Path: "/Metadata/thumbnail.png", Type: RelTypeThumbnail}},
+		Childs:            map[string]*ChildModel{"/3D/other.model": {}},
+	}
+	var encoded []Progress
+	buff := new(bytes.Buffer)
+	e := NewEncoder(buff)
+	e.OnProgress = func(p Progress) { encoded = append(encoded, p) }
+	if err := e.Encode(m); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	var decoded []Progress
+	d := NewDecoder(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+	d.OnProgress = func(p Progress) { decoded = append(decoded, p) }
+	if err := d.Decode(new(Model)); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	for _, tt := range []struct {
+		name     string
+		progress []Progress
+		want     []Phase
+	}{
+		{"encode", encoded, []Phase{PhaseAttachments, PhaseRootModel, PhaseChildModels}},
+		{"decode", decoded, []Phase{PhaseOPC, PhaseAttachments, PhaseChildModels, PhaseRootModel}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			last := make(map[string]Progress)
+			var phases []Phase
+			for _, p := range tt.progress {
+				if len(phases) == 0 || phases[len(phases)-1] != p.Phase {
+					phases = append(phases, p.Phase)
+				}
+				if prev, ok := last[p.Part]; ok && prev.Bytes > p.Bytes {
+					t.Errorf("progress bytes decreased for part %s: %d > %d", p.Part, prev.Bytes, p.Bytes)
+				}
+				last[p.Part] = p
+			}
+			if len(phases) != len(tt.want) {
+				t.Fatalf("phases = %v, want %v", phases, tt.want)
+			}
+			for i := range phases {
+				if phases[i] != tt.want[i] {
+					t.Errorf("phases = %v, want %v", phases, tt.want)
+				}
+			}
+			if last["/Metadata/thumbnail.png"].Bytes != 4 {
+				t.Errorf("attachment bytes = %d, want 4", last["/Metadata/thumbnail.png"].Bytes)
+			}
+			if last[DefaultModelPath].Bytes == 0 {
+				t.Error("root model bytes not reported")
+			}
+		})
+	}
+}
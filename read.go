@@ -8,15 +8,18 @@ import (
 	"context"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"unsafe"
 
 	specerr "github.com/hpinc/go3mf/errors"
 	xml3mf "github.com/hpinc/go3mf/internal/xml"
+	"github.com/hpinc/go3mf/internal/xsd"
 	"github.com/hpinc/go3mf/spec"
 )
 
@@ -31,7 +34,7 @@ type packageFile interface {
 }
 
 type packageReader interface {
-	Open(func(r io.Reader) io.ReadCloser) error
+	Open(func(r io.Reader) io.ReadCloser, Limits) error
 	FindFileFromName(string) (packageFile, bool)
 	Relationships() []Relationship
 }
@@ -61,79 +64,72 @@ func (r *ReadCloser) Close() error {
 	return r.f.Close()
 }
 
-func decodeModelFile(ctx context.Context, r io.Reader, model *Model, path string, isRoot, strict bool) error {
-	x := xml3mf.NewDecoder(r)
-	type stackElement struct {
-		decoder spec.ElementDecoder
-		name    xml.Name
-		i       int
+func (d *Decoder) decodeModelFile(ctx context.Context, r io.Reader, model *Model, path string, isRoot bool) error {
+	phase := PhaseChildModels
+	if isRoot {
+		phase = PhaseRootModel
 	}
-	stack := make([]stackElement, 0, 10)
-
+	cr := &countingReader{r: r}
+	x := xml3mf.NewDecoder(cr)
+	x.MaxDepth = d.Limits.MaxDepth
+	x.MaxAttrs = d.Limits.MaxAttributes
 	var (
-		currentDecoder spec.ElementDecoder
-		currentName    xml.Name
-		errs           specerr.List
+		elements              = &elementStack{current: &topLevelDecoder{isRoot: isRoot, model: model, path: path}}
+		limitErr              error
+		vertexCount, triCount int
+		err                   error
 	)
-	currentDecoder = &topLevelDecoder{isRoot: isRoot, model: model, path: path}
-	var err error
+	var validator *xsd.Validator
+	if d.schema != nil {
+		validator = d.schema.NewValidator()
+	}
 	x.OnStart = func(tp xml3mf.StartElement) {
-		if childDecoder, ok := currentDecoder.(spec.ChildElementDecoder); ok {
-			i, tmpDecoder := childDecoder.Child(tp.Name)
-			if tmpDecoder != nil {
-				stack = append(stack, stackElement{tmpDecoder, tp.Name, i})
-				currentName = tp.Name
-				currentDecoder = tmpDecoder
-				err := currentDecoder.Start(*(*[]spec.XMLAttr)(unsafe.Pointer(&tp.Attr)))
-				if err != nil {
-					for j := len(stack) - 1; j >= 0; j-- {
-						element := stack[j]
-						err = specerr.WrapIndex(err, element.name.Local, element.i)
-					}
-					specerr.Append(&errs, err)
+		if validator != nil {
+			validator.Start(tp.Name, tp.Attr, x.InputLine())
+		}
+		if tp.Name.Space == Namespace {
+			switch tp.Name.Local {
+			case attrMesh:
+				vertexCount, triCount = 0, 0
+			case attrVertex:
+				vertexCount++
+				if d.Limits.MaxVertices > 0 && vertexCount > d.Limits.MaxVertices {
+					limitErr = &LimitError{Limit: LimitVertices, Max: int64(d.Limits.MaxVertices), Path: path}
+					return
 				}
-			}
-		} else if appendDecoder, ok := currentDecoder.(spec.AppendTokenElementDecoder); ok {
-			var xattrs []xml.Attr
-			if len(tp.Attr) > 0 {
-				xattrs = make([]xml.Attr, len(tp.Attr))
-				for i, att := range tp.Attr {
-					xattrs[i] = xml.Attr{Name: att.Name, Value: string(att.Value)}
+			case attrTriangle:
+				triCount++
+				if d.Limits.MaxTriangles > 0 && triCount > d.Limits.MaxTriangles {
+					limitErr = &LimitError{Limit: LimitTriangles, Max: int64(d.Limits.MaxTriangles), Path: path}
+					return
 				}
 			}
-			appendDecoder.AppendToken(xml.StartElement{
-				Name: tp.Name,
-				Attr: xattrs,
-			})
 		}
+		elements.start(tp.Name, *(*[]spec.XMLAttr)(unsafe.Pointer(&tp.Attr)))
 	}
-	x.OnEnd = func(tp xml.EndElement) {
-		if currentName == tp.Name {
-			currentDecoder.End()
-			stack = stack[:len(stack)-1]
-			if len(stack) > 0 {
-				element := stack[len(stack)-1]
-				currentDecoder = element.decoder
-				currentName = element.name
-			}
-		} else if appendDecoder, ok := currentDecoder.(spec.AppendTokenElementDecoder); ok {
-			appendDecoder.AppendToken(tp)
+	x.OnEnd = elements.end
+	x.OnChar = elements.charData
+	if validator != nil {
+		x.OnEnd = func(tp xml.EndElement) {
+			validator.End(x.InputLine())
+			elements.end(tp)
 		}
-	}
-	x.OnChar = func(tp xml.CharData) {
-		if currentDecoder, ok := currentDecoder.(spec.CharDataElementDecoder); ok {
-			currentDecoder.CharData(tp)
-		} else if appendDecoder, ok := currentDecoder.(spec.AppendTokenElementDecoder); ok {
-			appendDecoder.AppendToken(tp)
+		x.OnChar = func(tp xml.CharData) {
+			validator.CharData(tp, x.InputLine())
+			elements.charData(tp)
 		}
 	}
 	var i int
 	for {
 		err = x.RawToken()
-		if err != nil || (strict && errs.Len() != 0) {
+		if limitErr != nil {
+			return limitErr
+		}
+		if err != nil || (d.Strict && elements.errs.Len() != 0) {
 			break
 		}
 		if i%checkEveryTokens == 0 {
+			d.reporter.report(Progress{Phase: phase, Part: path, Bytes: cr.n, Tokens: i})
 			select {
 			case <-ctx.Done():
 				err = ctx.Err()
@@ -145,25 +141,132 @@ func decodeModelFile(ctx context.Context, r io.Reader, model *Model, path string
 		}
 		i++
 	}
-	if err == io.EOF {
+	switch err {
+	case xml3mf.ErrMaxDepth:
+		return &LimitError{Limit: LimitDepth, Max: int64(d.Limits.MaxDepth), Path: path}
+	case xml3mf.ErrMaxAttrs:
+		return &LimitError{Limit: LimitAttributes, Max: int64(d.Limits.MaxAttributes), Path: path}
+	case io.EOF:
 		err = nil
+		d.reporter.report(Progress{Phase: phase, Part: path, Bytes: cr.n, Tokens: i})
+	}
+	if validator != nil {
+		d.addSchemaErrors(path, validator.Errors())
 	}
-	if err == nil && errs.Len() != 0 {
-		if strict || errs.Len() == 1 {
-			err = errs.Unwrap()
+	if err == nil && elements.errs.Len() != 0 {
+		if d.Strict || elements.errs.Len() == 1 {
+			err = elements.errs.Unwrap()
 		} else {
-			err = &errs
+			err = &elements.errs
 		}
 	}
 	return err
 }
 
+type stackElement struct {
+	decoder spec.ElementDecoder
+	name    xml.Name
+	i       int
+}
+
+// elementStack dispatches the XML tokens to the element decoders,
+// starting with the child decoders of current.
+type elementStack struct {
+	current     spec.ElementDecoder
+	currentName xml.Name
+	stack       []stackElement
+	errs        specerr.List
+}
+
+func (s *elementStack) start(name xml.Name, attrs []spec.XMLAttr) {
+	if childDecoder, ok := s.current.(spec.ChildElementDecoder); ok {
+		i, tmpDecoder := childDecoder.Child(name)
+		if tmpDecoder != nil {
+			s.stack = append(s.stack, stackElement{tmpDecoder, name, i})
+			s.currentName = name
+			s.current = tmpDecoder
+			err := s.current.Start(attrs)
+			if err != nil {
+				for j := len(s.stack) - 1; j >= 0; j-- {
+					element := s.stack[j]
+					err = specerr.WrapIndex(err, element.name.Local, element.i)
+				}
+				specerr.Append(&s.errs, err)
+			}
+		}
+	} else if appendDecoder, ok := s.current.(spec.AppendTokenElementDecoder); ok {
+		var xattrs []xml.Attr
+		if len(attrs) > 0 {
+			xattrs = make([]xml.Attr, len(attrs))
+			for i, att := range attrs {
+				xattrs[i] = xml.Attr{Name: att.Name, Value: string(att.Value)}
+			}
+		}
+		appendDecoder.AppendToken(xml.StartElement{
+			Name: name,
+			Attr: xattrs,
+		})
+	}
+}
+
+func (s *elementStack) end(tp xml.EndElement) {
+	if s.currentName == tp.Name {
+		s.current.End()
+		s.stack = s.stack[:len(s.stack)-1]
+		if len(s.stack) > 0 {
+			element := s.stack[len(s.stack)-1]
+			s.current = element.decoder
+			s.currentName = element.name
+		}
+	} else if appendDecoder, ok := s.current.(spec.AppendTokenElementDecoder); ok {
+		appendDecoder.AppendToken(tp)
+	}
+}
+
+func (s *elementStack) charData(tp xml.CharData) {
+	if currentDecoder, ok := s.current.(spec.CharDataElementDecoder); ok {
+		currentDecoder.CharData(tp)
+	}
+}
+
+// A PartDecrypter decrypts package parts while decoding.
+//
+// Init is called once the package has been opened, before reading any other part.
+// rels are the package root relationships and open returns the raw
+// content of a package part.
+// Decrypt is called for every model part and attachment before reading it,
+// and must return rc unmodified when the part is not encrypted.
+type PartDecrypter interface {
+	Init(rels []Relationship, open func(name string) (io.ReadCloser, error)) error
+	Decrypt(name string, rc io.ReadCloser) (io.ReadCloser, error)
+}
+
 // Decoder implements a 3mf file decoder.
+//
+// If OnProgress is not nil it will be called periodically
+// while decoding, see ProgressFunc for more details.
+// Limits bounds the resources used while decoding, see Limits for more details.
+// If Decrypter is not nil it will be used to decrypt the package parts.
+// If ValidateSchema is true every model part is also checked against the core XSD
+// and the XSDs of the registered specs, reporting the elements and attributes
+// that the decoder would otherwise accept, such as unknown attributes or elements out of order.
+// The violations are returned as an *errors.List of *errors.SchemaError
+// sorted by part path and line, when the model is decoded without other errors.
+// As the only exception to the XSDs, the extension resources are accepted in any position
+// of the resources element, as they have to precede the objects referencing them.
 type Decoder struct {
-	Strict        bool
-	p             packageReader
-	flate         func(r io.Reader) io.ReadCloser
-	nonRootModels []packageFile
+	Strict         bool
+	OnProgress     ProgressFunc
+	Limits         Limits
+	Decrypter      PartDecrypter
+	ValidateSchema bool
+	p              packageReader
+	flate          func(r io.Reader) io.ReadCloser
+	nonRootModels  []packageFile
+	reporter       *progressReporter
+	schema         *xsd.Schema
+	schemaMu       sync.Mutex
+	schemaErrs     []*specerr.SchemaError
 }
 
 // NewDecoder returns a new Decoder reading a 3mf file from r.
@@ -181,6 +284,14 @@ func (d *Decoder) Decode(model *Model) error {
 
 // DecodeContext reads the 3mf file and unmarshall its content into the model.
 func (d *Decoder) DecodeContext(ctx context.Context, model *Model) error {
+	d.reporter = newProgressReporter(d.OnProgress)
+	d.schema, d.schemaErrs = nil, nil
+	if d.ValidateSchema {
+		var err error
+		if d.schema, err = loadSchema(); err != nil {
+			return err
+		}
+	}
 	rootFile, err := d.processOPC(model)
 	if err != nil {
 		return err
@@ -188,7 +299,41 @@ func (d *Decoder) DecodeContext(ctx context.Context, model *Model) error {
 	if err := d.processNonRootModels(ctx, model); err != nil {
 		return err
 	}
-	return d.processRootModel(ctx, rootFile, model)
+	if err := d.processRootModel(ctx, rootFile, model); err != nil {
+		return err
+	}
+	return d.schemaError()
+}
+
+func (d *Decoder) addSchemaErrors(path string, errs []*specerr.SchemaError) {
+	if len(errs) == 0 {
+		return
+	}
+	for _, err := range errs {
+		err.Path = path
+	}
+	d.schemaMu.Lock()
+	d.schemaErrs = append(d.schemaErrs, errs...)
+	d.schemaMu.Unlock()
+}
+
+// schemaError returns the schema violations sorted by part path and line.
+func (d *Decoder) schemaError() error {
+	if len(d.schemaErrs) == 0 {
+		return nil
+	}
+	sort.SliceStable(d.schemaErrs, func(i, j int) bool {
+		a, b := d.schemaErrs[i], d.schemaErrs[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	errs := make([]error, len(d.schemaErrs))
+	for i, err := range d.schemaErrs {
+		errs[i] = err
+	}
+	return &specerr.List{Errors: errs}
 }
 
 // UnmarshalModel fills a model with the data of a root model file
@@ -200,12 +345,12 @@ func UnmarshalModel(data []byte, model *Model) error {
 }
 
 func (d *Decoder) processRootModel(ctx context.Context, rootFile packageFile, model *Model) error {
-	f, err := rootFile.Open()
+	f, err := d.open(rootFile)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	err = decodeModelFile(ctx, f, model, rootFile.Name(), true, d.Strict)
+	err = d.decodeModelFile(ctx, f, model, rootFile.Name(), true)
 	if err != nil {
 		return err
 	}
@@ -238,9 +383,21 @@ func (d *Decoder) processNonRootModels(ctx context.Context, model *Model) (errs
 }
 
 func (d *Decoder) processOPC(model *Model) (packageFile, error) {
-	if err := d.p.Open(d.flate); err != nil {
+	if err := d.p.Open(d.flate, d.Limits); err != nil {
 		return nil, err
 	}
+	d.reporter.report(Progress{Phase: PhaseOPC})
+	if d.Decrypter != nil {
+		err := d.Decrypter.Init(d.p.Relationships(), func(name string) (io.ReadCloser, error) {
+			if f, ok := d.p.FindFileFromName(name); ok {
+				return f.Open()
+			}
+			return nil, fmt.Errorf("go3mf: package part %s not found", name)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 	var rootFile packageFile
 	for _, r := range d.p.Relationships() {
 		if r.Type == RelType3DModel {
@@ -295,7 +452,8 @@ func (d *Decoder) addAttachment(attachments []Attachment, file packageFile) []At
 			return attachments
 		}
 	}
-	if buff, err := copyFile(file); err == nil {
+	if buff, err := d.copyFile(file); err == nil {
+		d.reporter.report(Progress{Phase: PhaseAttachments, Part: file.Name(), Bytes: int64(buff.Len())})
 		return append(attachments, Attachment{
 			Path:        file.Name(),
 			Stream:      buff,
@@ -307,12 +465,12 @@ func (d *Decoder) addAttachment(attachments []Attachment, file packageFile) []At
 
 func (d *Decoder) readChildModel(ctx context.Context, i int, model *Model) error {
 	attachment := d.nonRootModels[i]
-	file, err := attachment.Open()
+	file, err := d.open(attachment)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	err = decodeModelFile(ctx, file, model, attachment.Name(), false, d.Strict)
+	err = d.decodeModelFile(ctx, file, model, attachment.Name(), false)
 	select {
 	case <-ctx.Done():
 		err = ctx.Err()
@@ -321,8 +479,23 @@ func (d *Decoder) readChildModel(ctx context.Context, i int, model *Model) error
 	return err
 }
 
-func copyFile(file packageFile) (io.Reader, error) {
-	stream, err := file.Open()
+func (d *Decoder) open(file packageFile) (io.ReadCloser, error) {
+	rc, err := file.Open()
+	if err != nil || d.Decrypter == nil {
+		return rc, err
+	}
+	drc, err := d.Decrypter.Decrypt(file.Name(), rc)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	// The limit of rc only applies to the encrypted data,
+	// which may inflate past it once decrypted.
+	return newLimitedReadCloser(drc, file.Name(), d.Limits.MaxPartSize), nil
+}
+
+func (d *Decoder) copyFile(file packageFile) (*bytes.Buffer, error) {
+	stream, err := d.open(file)
 	if err != nil {
 		return nil, err
 	}
@@ -215,7 +215,7 @@ type mockPackage struct {
 
 func newMockPackage(other *mockFile) *mockPackage {
 	m := new(mockPackage)
-	m.On("Open", mock.Anything).Return(nil).Maybe()
+	m.On("Open", mock.Anything, mock.Anything).Return(nil).Maybe()
 	m.On("Create", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 	m.On("Relationships").Return([]Relationship{{Path: DefaultModelPath, Type: RelType3DModel}}).Maybe()
 	m.On("FindFileFromName", mock.Anything).Return(other, other != nil).Maybe()
@@ -241,8 +241,8 @@ func (m *mockPackage) Create(args0, args1 string) (packagePart, error) {
 	return args.Get(0).(packagePart), args.Error(1)
 }
 
-func (m *mockPackage) Open(f func(r io.Reader) io.ReadCloser) error {
-	args := m.Called(f)
+func (m *mockPackage) Open(f func(r io.Reader) io.ReadCloser, limits Limits) error {
+	args := m.Called(f, limits)
 	return args.Error(0)
 }
 
@@ -616,7 +616,7 @@ func Test_modelFile_Decode(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := decodeModelFile(tt.args.ctx, tt.args.r, new(Model), "", true, false); (err != nil) != tt.wantErr {
+			if err := new(Decoder).decodeModelFile(tt.args.ctx, tt.args.r, new(Model), "", true); (err != nil) != tt.wantErr {
 				t.Errorf("modelFile.Decode() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
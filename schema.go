@@ -0,0 +1,42 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+//go:generate go run ./internal/cmd/xsdgen -pkg go3mf -const coreSchema -o schema_gen.go schemas/core.xsd
+
+import (
+	"encoding/xml"
+	"sync"
+
+	"github.com/hpinc/go3mf/internal/xsd"
+	"github.com/hpinc/go3mf/spec"
+)
+
+var schemaCache struct {
+	sync.Mutex
+	docs   int
+	schema *xsd.Schema
+}
+
+// loadSchema compiles the core schema together with the schemas
+// of the registered specs. The result is cached until a new spec is registered.
+func loadSchema() (*xsd.Schema, error) {
+	docs := append([][]byte{[]byte(coreSchema)}, spec.Schemas()...)
+	schemaCache.Lock()
+	defer schemaCache.Unlock()
+	if schemaCache.schema != nil && schemaCache.docs == len(docs) {
+		return schemaCache.schema, nil
+	}
+	s, err := xsd.Parse(docs...)
+	if err != nil {
+		return nil, err
+	}
+	// The extensions define assets that must precede the objects referencing them,
+	// but the core schema only allows elements of other namespaces after the objects.
+	// The assets are accepted in any position of the resources, the core elements
+	// still have to follow the core order.
+	s.Interleave(xml.Name{Space: Namespace, Local: attrResources})
+	schemaCache.schema, schemaCache.docs = s, len(docs)
+	return s, nil
+}
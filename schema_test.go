@@ -0,0 +1,130 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func TestDecoder_ValidateSchema(t *testing.T) {
+	spec.Register(fakeSpec.Namespace, new(qmExtension))
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"valid", `<model xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02" xmlns:qm="http://dummy.com/fake_ext" unit="millimeter" xml:lang="en-US" qm:foo="bar">
+			<metadata name="Title" preserve="1">Cube</metadata>
+			<resources>
+				<basematerials id="1"><base name="Red" displaycolor="#FF0000"/></basematerials>
+				<object id="2" type="model" pid="1" pindex="0">
+					<mesh>
+						<vertices><vertex x="0" y="0" z="0"/><vertex x="1.5" y="0" z="0"/><vertex x="0" y="1e2" z="0"/></vertices>
+						<triangles><triangle v1="0" v2="1" v3="2"/></triangles>
+					</mesh>
+				</object>
+				<qm:asset/>
+			</resources>
+			<build><item objectid="2" transform="1 0 0 0 1 0 0 0 1 -66.4 -87.1 8.8"/></build>
+		</model>`, nil},
+		{"invalid", `<model xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02" color="red">
+			<build/>
+			<resources>
+				<object id="0" foo="bar">
+					<mesh>
+						<vertices><vertex x="0" y="0" z="0"/><vertex x="1" y="0" z="0"/></vertices>
+						<triangles><triangle v1="0" v2="1"/></triangles>
+					</mesh>
+				</object>
+			</resources>
+			<metadata name="Title">Cube</metadata>
+		</model>`, []string{
+			fmt.Sprintf("go3mf: Path: /3D/3dmodel.model Line: 1 XPath: /model: %v: color", specerr.ErrSchemaAttr),
+			fmt.Sprintf("go3mf: Path: /3D/3dmodel.model Line: 2 XPath: /model/build: %v: build", specerr.ErrSchemaElement),
+			fmt.Sprintf(`go3mf: Path: /3D/3dmodel.model Line: 4 XPath: /model/resources/object[0]: %v: id="0"`, specerr.ErrSchemaAttrValue),
+			fmt.Sprintf("go3mf: Path: /3D/3dmodel.model Line: 4 XPath: /model/resources/object[0]: %v: foo", specerr.ErrSchemaAttr),
+			fmt.Sprintf("go3mf: Path: /3D/3dmodel.model Line: 6 XPath: /model/resources/object[0]/mesh/vertices: %v: vertices", specerr.ErrSchemaMissingElement),
+			fmt.Sprintf("go3mf: Path: /3D/3dmodel.model Line: 7 XPath: /model/resources/object[0]/mesh/triangles/triangle[0]: %v: v3", specerr.ErrSchemaMissingAttr),
+		}},
+		{"resourcesOrder", `<model xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02" xmlns:qm="http://dummy.com/fake_ext">
+			<resources>
+				<qm:asset/>
+				<object id="1"><mesh><vertices><vertex x="0" y="0" z="0"/><vertex x="1" y="0" z="0"/><vertex x="0" y="1" z="0"/></vertices><triangles><triangle v1="0" v2="1" v3="2"/></triangles></mesh></object>
+				<qm:asset/>
+				<basematerials id="2"><base name="Red" displaycolor="#FF0000"/></basematerials>
+			</resources>
+			<build/>
+		</model>`, []string{
+			fmt.Sprintf("go3mf: Path: /3D/3dmodel.model Line: 6 XPath: /model/resources/basematerials[0]: %v: basematerials", specerr.ErrSchemaElement),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Decoder{ValidateSchema: true}
+			var err error
+			if d.schema, err = loadSchema(); err != nil {
+				t.Fatalf("loadSchema() error = %v", err)
+			}
+			if err = d.decodeModelFile(context.Background(), strings.NewReader(tt.doc), new(Model), "/3D/3dmodel.model", true); err != nil {
+				t.Fatalf("Decoder.decodeModelFile() error = %v", err)
+			}
+			var got []string
+			if err = d.schemaError(); err != nil {
+				for _, err := range err.(*specerr.List).Errors {
+					got = append(got, err.Error())
+					if r, _ := specerr.RuleOf(err); r.Severity != specerr.SeverityError {
+						t.Errorf("Decoder.schemaError() severity = %v, want error", r.Severity)
+					}
+				}
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Decoder.schemaError() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_ValidateSchema(t *testing.T) {
+	spec.Register(fakeSpec.Namespace, new(qmExtension))
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(newTestModel()); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	d := NewDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	d.ValidateSchema = true
+	if err := d.Decode(new(Model)); err != nil {
+		t.Errorf("Decoder.Decode() error = %v", err)
+	}
+
+	r, err := OpenReader("testdata/cube.3mf")
+	if err != nil {
+		t.Fatalf("OpenReader err = %v", err)
+	}
+	defer r.Close()
+	r.ValidateSchema = true
+	want := fmt.Sprintf("go3mf: Path: /3D/3dmodel.model Line: 2 XPath: /model: %v: model", specerr.ErrSchemaText)
+	err = r.Decode(new(Model))
+	if errs, ok := err.(*specerr.List); !ok || len(errs.Errors) != 1 || errs.Errors[0].Error() != want {
+		t.Errorf("Decoder.Decode() error = %v, want %s", err, want)
+	}
+}
+
+func Test_coreSchema(t *testing.T) {
+	b, err := ioutil.ReadFile("schemas/core.xsd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != coreSchema {
+		t.Error("coreSchema differs from schemas/core.xsd, run go generate")
+	}
+}
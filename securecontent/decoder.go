@@ -0,0 +1,389 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package securecontent
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/xml"
+	"hash"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	xml3mf "github.com/hpinc/go3mf/internal/xml"
+)
+
+// DecodeKeyStore reads a key store part from r.
+func DecodeKeyStore(r io.Reader) (*KeyStore, error) {
+	var (
+		ks   KeyStore
+		errs error
+		char *[]byte
+		buf  []byte
+	)
+	x := xml3mf.NewDecoder(r)
+	x.OnStart = func(tp xml3mf.StartElement) {
+		char = nil
+		switch tp.Name.Local {
+		case attrKeyStore:
+			for _, a := range tp.Attr {
+				if a.Name.Space == "" && a.Name.Local == attrUUID {
+					ks.UUID = string(a.Value)
+				}
+			}
+		case attrConsumer:
+			var c Consumer
+			for _, a := range tp.Attr {
+				if a.Name.Space != "" {
+					continue
+				}
+				switch a.Name.Local {
+				case attrConsumerID:
+					c.ID = string(a.Value)
+				case attrKeyID:
+					c.KeyID = string(a.Value)
+				}
+			}
+			ks.Consumers = append(ks.Consumers, c)
+		case attrKeyValue:
+			buf = buf[:0]
+			char = &buf
+		case attrResourceDataGroup:
+			var g ResourceDataGroup
+			for _, a := range tp.Attr {
+				if a.Name.Space == "" && a.Name.Local == attrKeyUUID {
+					g.KeyUUID = string(a.Value)
+				}
+			}
+			ks.ResourceDataGroups = append(ks.ResourceDataGroups, g)
+		case attrAccessRight:
+			if g := ks.lastGroup(); g != nil {
+				var ar AccessRight
+				for _, a := range tp.Attr {
+					if a.Name.Space == "" && a.Name.Local == attrConsumerIndex {
+						val, err := strconv.ParseUint(string(a.Value), 10, 32)
+						if err != nil {
+							errs = specerr.Append(errs, specerr.Wrap(specerr.NewParseAttrError(attrConsumerIndex, true), attrAccessRight))
+						}
+						ar.ConsumerIndex = uint32(val)
+					}
+				}
+				g.AccessRights = append(g.AccessRights, ar)
+			}
+		case attrKEKParams:
+			if ar := ks.lastAccessRight(); ar != nil {
+				for _, a := range tp.Attr {
+					if a.Name.Space != "" {
+						continue
+					}
+					var ok bool
+					switch a.Name.Local {
+					case attrWrappingAlgorithm:
+						ar.Wrapping, ok = newWrappingAlgorithm(string(a.Value))
+					case attrMGFAlgorithm:
+						ar.MGF, ok = newMGFAlgorithm(string(a.Value))
+					case attrDigestMethod:
+						ar.Digest, ok = newDigestMethod(string(a.Value))
+					default:
+						ok = true
+					}
+					if !ok {
+						errs = specerr.Append(errs, specerr.Wrap(specerr.NewParseAttrError(a.Name.Local, false), attrKEKParams))
+					}
+				}
+			}
+		case attrCipherValue:
+			buf = buf[:0]
+			char = &buf
+		case attrResourceData:
+			if g := ks.lastGroup(); g != nil {
+				var rd ResourceData
+				for _, a := range tp.Attr {
+					if a.Name.Space == "" && a.Name.Local == attrPath {
+						rd.Path = string(a.Value)
+					}
+				}
+				g.ResourceData = append(g.ResourceData, rd)
+			}
+		case attrCEKParams:
+			if rd := ks.lastResourceData(); rd != nil {
+				for _, a := range tp.Attr {
+					if a.Name.Space != "" {
+						continue
+					}
+					var ok bool
+					switch a.Name.Local {
+					case attrEncryptionAlg:
+						rd.Encryption, ok = newEncryptionAlgorithm(string(a.Value))
+					case attrCompression:
+						rd.Compression, ok = newCompression(string(a.Value))
+					default:
+						ok = true
+					}
+					if !ok {
+						errs = specerr.Append(errs, specerr.Wrap(specerr.NewParseAttrError(a.Name.Local, true), attrCEKParams))
+					}
+				}
+			}
+		case attrIV, attrTag, attrAAD:
+			buf = buf[:0]
+			char = &buf
+		}
+	}
+	x.OnChar = func(tp xml.CharData) {
+		if char != nil {
+			*char = append(*char, tp...)
+		}
+	}
+	x.OnEnd = func(tp xml.EndElement) {
+		if char == nil {
+			return
+		}
+		char = nil
+		if tp.Name.Local == attrKeyValue {
+			if len(ks.Consumers) > 0 {
+				ks.Consumers[len(ks.Consumers)-1].KeyValue = strings.TrimSpace(string(buf))
+			}
+			return
+		}
+		val, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(buf)))
+		if err != nil {
+			errs = specerr.Append(errs, specerr.NewParseAttrError(tp.Name.Local, true))
+			return
+		}
+		switch tp.Name.Local {
+		case attrCipherValue:
+			if ar := ks.lastAccessRight(); ar != nil {
+				ar.CipherValue = val
+			}
+		case attrIV, attrTag, attrAAD:
+			if rd := ks.lastResourceData(); rd != nil {
+				switch tp.Name.Local {
+				case attrIV:
+					rd.IV = val
+				case attrTag:
+					rd.Tag = val
+				default:
+					rd.AAD = val
+				}
+			}
+		}
+	}
+	var err error
+	for err == nil {
+		err = x.RawToken()
+	}
+	if err != io.EOF {
+		return nil, err
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return &ks, nil
+}
+
+func (k *KeyStore) lastGroup() *ResourceDataGroup {
+	if len(k.ResourceDataGroups) == 0 {
+		return nil
+	}
+	return &k.ResourceDataGroups[len(k.ResourceDataGroups)-1]
+}
+
+func (k *KeyStore) lastAccessRight() *AccessRight {
+	g := k.lastGroup()
+	if g == nil || len(g.AccessRights) == 0 {
+		return nil
+	}
+	return &g.AccessRights[len(g.AccessRights)-1]
+}
+
+func (k *KeyStore) lastResourceData() *ResourceData {
+	g := k.lastGroup()
+	if g == nil || len(g.ResourceData) == 0 {
+		return nil
+	}
+	return &g.ResourceData[len(g.ResourceData)-1]
+}
+
+// KeyUnwrapFunc returns the content encryption key wrapped in ar,
+// which grants access to consumer.
+// It should return ErrNoKey if the caller does not own the consumer private key.
+type KeyUnwrapFunc func(consumer *Consumer, ar *AccessRight) ([]byte, error)
+
+// NewRSAKeyUnwrap returns a KeyUnwrapFunc that unwraps
+// content encryption keys using the RSA private key priv.
+// Consumers whose KeyValue does not match priv public key are skipped.
+func NewRSAKeyUnwrap(priv *rsa.PrivateKey) KeyUnwrapFunc {
+	return func(consumer *Consumer, ar *AccessRight) ([]byte, error) {
+		if consumer.KeyValue != "" {
+			pub, err := parsePublicKey(consumer.KeyValue)
+			if err != nil {
+				return nil, err
+			}
+			if pub.N.Cmp(priv.N) != 0 || pub.E != priv.E {
+				return nil, ErrNoKey
+			}
+		}
+		h, err := oaepHash(ar)
+		if err != nil {
+			return nil, err
+		}
+		return rsa.DecryptOAEP(h, nil, priv, ar.CipherValue, nil)
+	}
+}
+
+// oaepHash returns the hash used by the OAEP padding of ar.
+// Only access rights whose mask generation function uses
+// the same hash as the digest method are supported.
+func oaepHash(ar *AccessRight) (hash.Hash, error) {
+	mgf := MGF1SHA1
+	if ar.Wrapping == WrappingRSAOAEP {
+		mgf = ar.MGF
+	}
+	switch {
+	case ar.Digest == DigestSHA1 && mgf == MGF1SHA1:
+		return sha1.New(), nil
+	case ar.Digest == DigestSHA256 && mgf == MGF1SHA256:
+		return sha256.New(), nil
+	case ar.Digest == DigestSHA384 && mgf == MGF1SHA384:
+		return sha512.New384(), nil
+	case ar.Digest == DigestSHA512 && mgf == MGF1SHA512:
+		return sha512.New(), nil
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// Decrypter implements go3mf.PartDecrypter for the 3MF Secure Content extension.
+//
+// The key store is read from the package when initialized and
+// the content encryption keys are unwrapped using Unwrap,
+// the first time they are needed.
+// Decrypt can be called concurrently, as the child model parts are decoded in parallel.
+type Decrypter struct {
+	Unwrap   KeyUnwrapFunc
+	KeyStore *KeyStore
+	mu       sync.Mutex
+	ceks     map[*ResourceDataGroup][]byte
+}
+
+// NewDecrypter returns a new Decrypter that unwraps keys using unwrap.
+func NewDecrypter(unwrap KeyUnwrapFunc) *Decrypter {
+	return &Decrypter{Unwrap: unwrap}
+}
+
+// Init implements go3mf.PartDecrypter.
+// It does nothing if the package does not have a key store.
+func (d *Decrypter) Init(rels []go3mf.Relationship, open func(string) (io.ReadCloser, error)) error {
+	d.KeyStore = nil
+	d.mu.Lock()
+	d.ceks = make(map[*ResourceDataGroup][]byte)
+	d.mu.Unlock()
+	for _, r := range rels {
+		if r.Type != RelTypeKeyStore {
+			continue
+		}
+		rc, err := open(r.Path)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		ks, err := DecodeKeyStore(rc)
+		if err != nil {
+			return specerr.WrapPath(err, attrKeyStore, r.Path)
+		}
+		d.KeyStore = ks
+		break
+	}
+	return nil
+}
+
+// Decrypt implements go3mf.PartDecrypter.
+func (d *Decrypter) Decrypt(name string, rc io.ReadCloser) (io.ReadCloser, error) {
+	if d.KeyStore == nil {
+		return rc, nil
+	}
+	g, rd, ok := d.KeyStore.FindResourceData(name)
+	if !ok {
+		return rc, nil
+	}
+	defer rc.Close()
+	cek, err := d.cek(g)
+	if err != nil {
+		return nil, specerr.WrapPath(err, attrResourceData, name)
+	}
+	// AES-GCM authenticates the whole ciphertext before returning any plaintext,
+	// so it is read at once. The size of rc is bounded by go3mf.Limits.MaxPartSize.
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	data, err = decrypt(cek, rd, data)
+	if err != nil {
+		return nil, specerr.WrapPath(err, attrResourceData, name)
+	}
+	if rd.Compression == CompressionDeflate {
+		return flate.NewReader(bytes.NewReader(data)), nil
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (d *Decrypter) cek(g *ResourceDataGroup) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if cek, ok := d.ceks[g]; ok {
+		return cek, nil
+	}
+	if d.Unwrap == nil {
+		return nil, ErrNoKey
+	}
+	err := ErrNoKey
+	for i := range g.AccessRights {
+		ar := &g.AccessRights[i]
+		if int(ar.ConsumerIndex) >= len(d.KeyStore.Consumers) {
+			err = ErrConsumerIndex
+			continue
+		}
+		cek, uerr := d.Unwrap(&d.KeyStore.Consumers[ar.ConsumerIndex], ar)
+		if uerr == nil {
+			d.ceks[g] = cek
+			return cek, nil
+		}
+	}
+	return nil, err
+}
+
+func decrypt(cek []byte, rd *ResourceData, data []byte) ([]byte, error) {
+	if rd.Encryption != EncryptionAES256GCM {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	// aes.NewCipher also accepts AES-128 and AES-192 keys.
+	if len(cek) != cekSize {
+		return nil, ErrInvalidKeyValue
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(rd.IV))
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, rd.IV, append(data, rd.Tag...), rd.AAD)
+}
+
+func equalPath(a, b string) bool {
+	return strings.EqualFold(strings.TrimPrefix(a, "/"), strings.TrimPrefix(b, "/"))
+}
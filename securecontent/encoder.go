@@ -0,0 +1,319 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package securecontent
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"github.com/hpinc/go3mf"
+	xml3mf "github.com/hpinc/go3mf/internal/xml"
+	"github.com/hpinc/go3mf/uuid"
+)
+
+const (
+	cekSize   = 32
+	nonceSize = 12
+)
+
+// Encode writes the key store k to w.
+func (k *KeyStore) Encode(w io.Writer) error {
+	x := xml3mf.Printer{Writer: bufio.NewWriter(w)}
+	x.WriteString(xml.Header)
+	root := xml.StartElement{Name: xml.Name{Local: attrKeyStore}, Attr: []xml.Attr{
+		{Name: xml.Name{Local: "xmlns"}, Value: Namespace},
+		{Name: xml.Name{Space: "xmlns", Local: "xenc"}, Value: nsXMLEnc},
+		{Name: xml.Name{Local: attrUUID}, Value: k.UUID},
+	}}
+	x.WriteStart(&root)
+	for _, c := range k.Consumers {
+		attrs := []xml.Attr{{Name: xml.Name{Local: attrConsumerID}, Value: c.ID}}
+		if c.KeyID != "" {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: attrKeyID}, Value: c.KeyID})
+		}
+		x.WriteStart(&xml.StartElement{Name: xml.Name{Local: attrConsumer}, Attr: attrs})
+		if c.KeyValue != "" {
+			writeText(&x, xml.Name{Local: attrKeyValue}, c.KeyValue)
+		}
+		x.WriteEnd(xml.Name{Local: attrConsumer})
+	}
+	for _, g := range k.ResourceDataGroups {
+		x.WriteStart(&xml.StartElement{Name: xml.Name{Local: attrResourceDataGroup}, Attr: []xml.Attr{
+			{Name: xml.Name{Local: attrKeyUUID}, Value: g.KeyUUID},
+		}})
+		for _, ar := range g.AccessRights {
+			x.WriteStart(&xml.StartElement{Name: xml.Name{Local: attrAccessRight}, Attr: []xml.Attr{
+				{Name: xml.Name{Local: attrConsumerIndex}, Value: strconv.FormatUint(uint64(ar.ConsumerIndex), 10)},
+			}})
+			attrs := []xml.Attr{{Name: xml.Name{Local: attrWrappingAlgorithm}, Value: ar.Wrapping.String()}}
+			if ar.Wrapping == WrappingRSAOAEP {
+				attrs = append(attrs, xml.Attr{Name: xml.Name{Local: attrMGFAlgorithm}, Value: ar.MGF.String()})
+			}
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: attrDigestMethod}, Value: ar.Digest.String()})
+			x.AutoClose = true
+			x.WriteStart(&xml.StartElement{Name: xml.Name{Local: attrKEKParams}, Attr: attrs})
+			x.AutoClose = false
+			x.WriteStart(&xml.StartElement{Name: xml.Name{Local: attrCipherData}})
+			writeText(&x, xml.Name{Space: nsXMLEnc, Local: attrCipherValue}, base64.StdEncoding.EncodeToString(ar.CipherValue))
+			x.WriteEnd(xml.Name{Local: attrCipherData})
+			x.WriteEnd(xml.Name{Local: attrAccessRight})
+		}
+		for _, rd := range g.ResourceData {
+			x.WriteStart(&xml.StartElement{Name: xml.Name{Local: attrResourceData}, Attr: []xml.Attr{
+				{Name: xml.Name{Local: attrPath}, Value: rd.Path},
+			}})
+			x.WriteStart(&xml.StartElement{Name: xml.Name{Local: attrCEKParams}, Attr: []xml.Attr{
+				{Name: xml.Name{Local: attrEncryptionAlg}, Value: rd.Encryption.String()},
+				{Name: xml.Name{Local: attrCompression}, Value: rd.Compression.String()},
+			}})
+			writeText(&x, xml.Name{Local: attrIV}, base64.StdEncoding.EncodeToString(rd.IV))
+			writeText(&x, xml.Name{Local: attrTag}, base64.StdEncoding.EncodeToString(rd.Tag))
+			if len(rd.AAD) > 0 {
+				writeText(&x, xml.Name{Local: attrAAD}, base64.StdEncoding.EncodeToString(rd.AAD))
+			}
+			x.WriteEnd(xml.Name{Local: attrCEKParams})
+			x.WriteEnd(xml.Name{Local: attrResourceData})
+		}
+		x.WriteEnd(xml.Name{Local: attrResourceDataGroup})
+	}
+	x.WriteEnd(root.Name)
+	return x.Flush()
+}
+
+func writeText(x *xml3mf.Printer, name xml.Name, text string) {
+	x.WriteStart(&xml.StartElement{Name: name})
+	x.EscapeString(text)
+	x.WriteEnd(name)
+}
+
+// KeyWrapFunc wraps the content encryption key cek for consumer.
+type KeyWrapFunc func(consumer *Consumer, cek []byte) (AccessRight, error)
+
+// WrapRSAKey is the default KeyWrapFunc.
+// It wraps cek using RSA-OAEP with SHA1 and the consumer public key,
+// which must be defined in consumer.KeyValue as a PEM block.
+func WrapRSAKey(consumer *Consumer, cek []byte) (AccessRight, error) {
+	pub, err := parsePublicKey(consumer.KeyValue)
+	if err != nil {
+		return AccessRight{}, err
+	}
+	cipherValue, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, pub, cek, nil)
+	if err != nil {
+		return AccessRight{}, err
+	}
+	return AccessRight{
+		Wrapping:    WrappingRSAOAEPMGF1P,
+		MGF:         MGF1SHA1,
+		Digest:      DigestSHA1,
+		CipherValue: cipherValue,
+	}, nil
+}
+
+func parsePublicKey(keyValue string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(keyValue))
+	if block == nil {
+		return nil, ErrInvalidKeyValue
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		if pub, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+			return pub, nil
+		}
+		return nil, ErrInvalidKeyValue
+	}
+	if pub, ok := pub.(*rsa.PublicKey); ok {
+		return pub, nil
+	}
+	return nil, ErrInvalidKeyValue
+}
+
+// EncodePublicKey returns pub as a PEM block suitable for Consumer.KeyValue.
+func EncodePublicKey(pub *rsa.PublicKey) (string, error) {
+	b, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b})), nil
+}
+
+// Encrypter implements go3mf.PartEncrypter for the 3MF Secure Content extension.
+//
+// All the Parts are encrypted with the same content encryption key,
+// which is randomly generated and wrapped for each of the Consumers using Wrap.
+// If Wrap is nil WrapRSAKey is used.
+// The key store is written to Path, or DefaultKeyStorePath if empty.
+type Encrypter struct {
+	Path        string
+	UUID        string
+	Consumers   []Consumer
+	Parts       []string
+	Compression Compression
+	Wrap        KeyWrapFunc
+	cek         []byte
+	group       ResourceDataGroup
+}
+
+// NewEncrypter returns a new Encrypter that encrypts parts for consumers.
+func NewEncrypter(consumers []Consumer, parts ...string) *Encrypter {
+	return &Encrypter{Consumers: consumers, Parts: parts}
+}
+
+// Encrypt implements go3mf.PartEncrypter.
+func (e *Encrypter) Encrypt(name string, w io.Writer) (io.WriteCloser, error) {
+	if !e.encrypts(name) {
+		return nil, nil
+	}
+	if e.cek == nil {
+		e.cek = make([]byte, cekSize)
+		if _, err := rand.Read(e.cek); err != nil {
+			e.cek = nil
+			return nil, err
+		}
+		e.group = ResourceDataGroup{KeyUUID: uuid.New()}
+	}
+	return &encryptWriter{e: e, name: name, w: w}, nil
+}
+
+// Close implements go3mf.PartEncrypter.
+// It returns the key store part, which has an encrypted file
+// relationship to every encrypted part, and its root relationship,
+// or nothing if no part has been encrypted.
+func (e *Encrypter) Close() ([]go3mf.EncrypterPart, []go3mf.Relationship, error) {
+	if e.cek == nil {
+		return nil, nil, nil
+	}
+	cek, group := e.cek, e.group
+	e.cek, e.group = nil, ResourceDataGroup{}
+	wrap := e.Wrap
+	if wrap == nil {
+		wrap = WrapRSAKey
+	}
+	for i := range e.Consumers {
+		ar, err := wrap(&e.Consumers[i], cek)
+		if err != nil {
+			return nil, nil, err
+		}
+		ar.ConsumerIndex = uint32(i)
+		group.AccessRights = append(group.AccessRights, ar)
+	}
+	ks := KeyStore{UUID: e.UUID, Consumers: e.Consumers, ResourceDataGroups: []ResourceDataGroup{group}}
+	if ks.UUID == "" {
+		ks.UUID = uuid.New()
+	}
+	var buf bytes.Buffer
+	if err := ks.Encode(&buf); err != nil {
+		return nil, nil, err
+	}
+	path := e.Path
+	if path == "" {
+		path = DefaultKeyStorePath
+	}
+	part := go3mf.EncrypterPart{Attachment: go3mf.Attachment{Stream: &buf, Path: path, ContentType: ContentTypeKeyStore}}
+	for _, rd := range group.ResourceData {
+		part.Relationships = append(part.Relationships, go3mf.Relationship{Path: rd.Path, Type: RelTypeEncryptedFile})
+	}
+	return []go3mf.EncrypterPart{part}, []go3mf.Relationship{{Path: path, Type: RelTypeKeyStore}}, nil
+}
+
+func (e *Encrypter) encrypts(name string) bool {
+	for _, p := range e.Parts {
+		if equalPath(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+type encryptWriter struct {
+	e    *Encrypter
+	name string
+	w    io.Writer
+	buf  bytes.Buffer
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *encryptWriter) Close() error {
+	data := w.buf.Bytes()
+	if w.e.Compression == CompressionDeflate {
+		var zbuf bytes.Buffer
+		fw, err := flate.NewWriter(&zbuf, flate.DefaultCompression)
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write(data); err != nil {
+			return err
+		}
+		if err = fw.Close(); err != nil {
+			return err
+		}
+		data = zbuf.Bytes()
+	}
+	block, err := aes.NewCipher(w.e.cek)
+	if err != nil {
+		return err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	iv := make([]byte, nonceSize)
+	if _, err = rand.Read(iv); err != nil {
+		return err
+	}
+	sealed := gcm.Seal(nil, iv, data, nil)
+	ct, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	if _, err = w.w.Write(ct); err != nil {
+		return err
+	}
+	w.e.group.ResourceData = append(w.e.group.ResourceData, ResourceData{
+		Path:        w.name,
+		Encryption:  EncryptionAES256GCM,
+		Compression: w.e.Compression,
+		IV:          iv,
+		Tag:         tag,
+	})
+	return nil
+}
+
+// RemoveKeyStore removes the key store attachment and
+// root relationship from m, which are added when decoding
+// a package with a key store. Use it before encoding m
+// again with a new Encrypter.
+func RemoveKeyStore(m *go3mf.Model) {
+	var path string
+	rels := m.RootRelationships[:0]
+	for _, r := range m.RootRelationships {
+		if r.Type == RelTypeKeyStore {
+			path = r.Path
+		} else {
+			rels = append(rels, r)
+		}
+	}
+	m.RootRelationships = rels
+	if path == "" {
+		return
+	}
+	atts := m.Attachments[:0]
+	for _, a := range m.Attachments {
+		if !equalPath(a.Path, path) {
+			atts = append(atts, a)
+		}
+	}
+	m.Attachments = atts
+}
@@ -0,0 +1,269 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package securecontent
+
+import (
+	"encoding/xml"
+	"errors"
+
+	"github.com/hpinc/go3mf"
+	"github.com/hpinc/go3mf/spec"
+)
+
+const (
+	// Namespace is the canonical name of this extension.
+	Namespace = "http://schemas.microsoft.com/3dmanufacturing/securecontent/2019/04"
+	// RelTypeKeyStore is the canonical key store relationship type.
+	RelTypeKeyStore = "http://schemas.microsoft.com/3dmanufacturing/2019/04/keystore"
+	// RelTypeEncryptedFile is the canonical encrypted file relationship type.
+	RelTypeEncryptedFile = "http://schemas.openxmlformats.org/package/2006/relationships/encryptedfile"
+	// ContentTypeKeyStore is the key store content type.
+	ContentTypeKeyStore = "application/vnd.ms-package.3dmanufacturing-keystore+xml"
+	// DefaultKeyStorePath is the recommended key store part name.
+	DefaultKeyStorePath = "/Secure/keystore.xml"
+
+	nsXMLEnc = "http://www.w3.org/2001/04/xmlenc#"
+)
+
+var DefaultExtension = go3mf.Extension{
+	Namespace:  Namespace,
+	LocalName:  "sc",
+	IsRequired: false,
+}
+
+func init() {
+	spec.Register(Namespace, Spec{})
+}
+
+type Spec struct{}
+
+func (Spec) NewAttrGroup(xml.Name) spec.AttrGroup {
+	return nil
+}
+
+func (Spec) NewElementDecoder(xml.Name) spec.GetterElementDecoder {
+	return nil
+}
+
+var (
+	ErrNoKey                = errors.New("securecontent: no access right could be unwrapped")
+	ErrUnsupportedAlgorithm = errors.New("securecontent: unsupported algorithm")
+	ErrInvalidKeyValue      = errors.New("securecontent: consumer key values MUST be PEM encoded RSA public keys and content keys MUST be 256 bits")
+	ErrConsumerIndex        = errors.New("securecontent: consumer index out of bounds")
+)
+
+// WrappingAlgorithm defines the algorithm used to wrap the content encryption key.
+type WrappingAlgorithm uint8
+
+// Supported wrapping algorithms.
+const (
+	WrappingRSAOAEPMGF1P WrappingAlgorithm = iota
+	WrappingRSAOAEP
+)
+
+func (w WrappingAlgorithm) String() string {
+	return map[WrappingAlgorithm]string{
+		WrappingRSAOAEPMGF1P: "http://www.w3.org/2001/04/xmlenc#rsa-oaep-mgf1p",
+		WrappingRSAOAEP:      "http://www.w3.org/2009/xmlenc11#rsa-oaep",
+	}[w]
+}
+
+// MGFAlgorithm defines the mask generation function used with RSA-OAEP.
+type MGFAlgorithm uint8
+
+// Supported mask generation functions.
+const (
+	MGF1SHA1 MGFAlgorithm = iota
+	MGF1SHA224
+	MGF1SHA256
+	MGF1SHA384
+	MGF1SHA512
+)
+
+func (m MGFAlgorithm) String() string {
+	return map[MGFAlgorithm]string{
+		MGF1SHA1:   "http://www.w3.org/2009/xmlenc11#mgf1sha1",
+		MGF1SHA224: "http://www.w3.org/2009/xmlenc11#mgf1sha224",
+		MGF1SHA256: "http://www.w3.org/2009/xmlenc11#mgf1sha256",
+		MGF1SHA384: "http://www.w3.org/2009/xmlenc11#mgf1sha384",
+		MGF1SHA512: "http://www.w3.org/2009/xmlenc11#mgf1sha512",
+	}[m]
+}
+
+// DigestMethod defines the message digest used with RSA-OAEP.
+type DigestMethod uint8
+
+// Supported digest methods.
+const (
+	DigestSHA1 DigestMethod = iota
+	DigestSHA256
+	DigestSHA384
+	DigestSHA512
+)
+
+func (d DigestMethod) String() string {
+	return map[DigestMethod]string{
+		DigestSHA1:   "http://www.w3.org/2000/09/xmldsig#sha1",
+		DigestSHA256: "http://www.w3.org/2001/04/xmlenc#sha256",
+		DigestSHA384: "http://www.w3.org/2001/04/xmldsig-more#sha384",
+		DigestSHA512: "http://www.w3.org/2001/04/xmlenc#sha512",
+	}[d]
+}
+
+// EncryptionAlgorithm defines the algorithm used to encrypt the part contents.
+type EncryptionAlgorithm uint8
+
+// Supported encryption algorithms.
+const (
+	EncryptionAES256GCM EncryptionAlgorithm = iota
+)
+
+func (e EncryptionAlgorithm) String() string {
+	return map[EncryptionAlgorithm]string{
+		EncryptionAES256GCM: "http://www.w3.org/2009/xmlenc11#aes256-gcm",
+	}[e]
+}
+
+// Compression defines the compression applied to the part contents before encrypting them.
+type Compression uint8
+
+// Supported compressions.
+const (
+	CompressionNone Compression = iota
+	CompressionDeflate
+)
+
+func (c Compression) String() string {
+	return map[Compression]string{
+		CompressionNone:    "none",
+		CompressionDeflate: "deflate",
+	}[c]
+}
+
+// A KeyStore is an in memory representation of the 3MF key store part.
+type KeyStore struct {
+	UUID               string
+	Consumers          []Consumer
+	ResourceDataGroups []ResourceDataGroup
+}
+
+// FindResourceData returns the resource data that encrypts path
+// and the group it belongs to.
+func (k *KeyStore) FindResourceData(path string) (*ResourceDataGroup, *ResourceData, bool) {
+	for i := range k.ResourceDataGroups {
+		g := &k.ResourceDataGroups[i]
+		for j := range g.ResourceData {
+			if equalPath(g.ResourceData[j].Path, path) {
+				return g, &g.ResourceData[j], true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// A Consumer identifies the entity that can access the encrypted content.
+// KeyValue, if not empty, is the PEM encoded public key of the consumer.
+type Consumer struct {
+	ID       string
+	KeyID    string
+	KeyValue string
+}
+
+// A ResourceDataGroup groups the parts encrypted with the same content encryption key.
+type ResourceDataGroup struct {
+	KeyUUID      string
+	AccessRights []AccessRight
+	ResourceData []ResourceData
+}
+
+// An AccessRight contains the content encryption key of a
+// resource data group wrapped for a specific consumer.
+type AccessRight struct {
+	ConsumerIndex uint32
+	Wrapping      WrappingAlgorithm
+	MGF           MGFAlgorithm
+	Digest        DigestMethod
+	CipherValue   []byte
+}
+
+// ResourceData defines how a part has been encrypted.
+type ResourceData struct {
+	Path        string
+	Encryption  EncryptionAlgorithm
+	Compression Compression
+	IV          []byte
+	Tag         []byte
+	AAD         []byte
+}
+
+func newWrappingAlgorithm(s string) (w WrappingAlgorithm, ok bool) {
+	w, ok = map[string]WrappingAlgorithm{
+		"http://www.w3.org/2001/04/xmlenc#rsa-oaep-mgf1p": WrappingRSAOAEPMGF1P,
+		"http://www.w3.org/2009/xmlenc11#rsa-oaep":        WrappingRSAOAEP,
+	}[s]
+	return
+}
+
+func newMGFAlgorithm(s string) (m MGFAlgorithm, ok bool) {
+	m, ok = map[string]MGFAlgorithm{
+		"http://www.w3.org/2009/xmlenc11#mgf1sha1":   MGF1SHA1,
+		"http://www.w3.org/2009/xmlenc11#mgf1sha224": MGF1SHA224,
+		"http://www.w3.org/2009/xmlenc11#mgf1sha256": MGF1SHA256,
+		"http://www.w3.org/2009/xmlenc11#mgf1sha384": MGF1SHA384,
+		"http://www.w3.org/2009/xmlenc11#mgf1sha512": MGF1SHA512,
+	}[s]
+	return
+}
+
+func newDigestMethod(s string) (d DigestMethod, ok bool) {
+	d, ok = map[string]DigestMethod{
+		"http://www.w3.org/2000/09/xmldsig#sha1":        DigestSHA1,
+		"http://www.w3.org/2001/04/xmlenc#sha256":       DigestSHA256,
+		"http://www.w3.org/2001/04/xmldsig-more#sha384": DigestSHA384,
+		"http://www.w3.org/2001/04/xmlenc#sha512":       DigestSHA512,
+	}[s]
+	return
+}
+
+func newEncryptionAlgorithm(s string) (e EncryptionAlgorithm, ok bool) {
+	e, ok = map[string]EncryptionAlgorithm{
+		"http://www.w3.org/2009/xmlenc11#aes256-gcm": EncryptionAES256GCM,
+	}[s]
+	return
+}
+
+func newCompression(s string) (c Compression, ok bool) {
+	c, ok = map[string]Compression{
+		"none":    CompressionNone,
+		"deflate": CompressionDeflate,
+	}[s]
+	return
+}
+
+const (
+	attrKeyStore          = "keystore"
+	attrUUID              = "UUID"
+	attrConsumer          = "consumer"
+	attrConsumerID        = "consumerid"
+	attrKeyID             = "keyid"
+	attrKeyValue          = "keyvalue"
+	attrResourceDataGroup = "resourcedatagroup"
+	attrKeyUUID           = "keyuuid"
+	attrAccessRight       = "accessright"
+	attrConsumerIndex     = "consumerindex"
+	attrKEKParams         = "kekparams"
+	attrWrappingAlgorithm = "wrappingalgorithm"
+	attrMGFAlgorithm      = "mgfalgorithm"
+	attrDigestMethod      = "digestmethod"
+	attrCipherData        = "cipherdata"
+	attrCipherValue       = "CipherValue"
+	attrResourceData      = "resourcedata"
+	attrPath              = "path"
+	attrCEKParams         = "cekparams"
+	attrEncryptionAlg     = "encryptionalgorithm"
+	attrCompression       = "compression"
+	attrIV                = "iv"
+	attrTag               = "tag"
+	attrAAD               = "aad"
+)
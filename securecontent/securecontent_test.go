@@ -0,0 +1,327 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package securecontent
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	"github.com/qmuntal/opc"
+)
+
+func TestKeyStore_Roundtrip(t *testing.T) {
+	ks := &KeyStore{
+		UUID: "bdc5c3c8-1a38-4a45-8a3a-0ed43e69a4e3",
+		Consumers: []Consumer{
+			{ID: "consumer1", KeyID: "key1", KeyValue: "-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----"},
+			{ID: "consumer2"},
+		},
+		ResourceDataGroups: []ResourceDataGroup{{
+			KeyUUID: "3f1a7e4c-7f3b-4d7a-9b0b-7d0b1b7e2a11",
+			AccessRights: []AccessRight{
+				{ConsumerIndex: 0, Wrapping: WrappingRSAOAEPMGF1P, MGF: MGF1SHA1, Digest: DigestSHA1, CipherValue: []byte{1, 2, 3}},
+				{ConsumerIndex: 1, Wrapping: WrappingRSAOAEP, MGF: MGF1SHA256, Digest: DigestSHA256, CipherValue: []byte{4, 5, 6}},
+			},
+			ResourceData: []ResourceData{
+				{Path: "/3D/3dmodel.model", Encryption: EncryptionAES256GCM, Compression: CompressionDeflate, IV: []byte{7}, Tag: []byte{8}, AAD: []byte{9}},
+				{Path: "/3D/other.model", Encryption: EncryptionAES256GCM, Compression: CompressionNone, IV: []byte{10}, Tag: []byte{11}},
+			},
+		}},
+	}
+	var buf bytes.Buffer
+	if err := ks.Encode(&buf); err != nil {
+		t.Fatalf("KeyStore.Encode() error = %v", err)
+	}
+	got, err := DecodeKeyStore(&buf)
+	if err != nil {
+		t.Fatalf("DecodeKeyStore() error = %v", err)
+	}
+	if diff := deep.Equal(got, ks); diff != nil {
+		t.Errorf("DecodeKeyStore() = %v", diff)
+	}
+}
+
+func TestDecodeKeyStore_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"consumerindex", `<keystore xmlns="` + Namespace + `"><resourcedatagroup><accessright consumerindex="a"/></resourcedatagroup></keystore>`},
+		{"wrapping", `<keystore xmlns="` + Namespace + `"><resourcedatagroup><accessright consumerindex="0"><kekparams wrappingalgorithm="a"/></accessright></resourcedatagroup></keystore>`},
+		{"compression", `<keystore xmlns="` + Namespace + `"><resourcedatagroup><resourcedata path="/a"><cekparams compression="zip"/></resourcedata></resourcedatagroup></keystore>`},
+		{"iv", `<keystore xmlns="` + Namespace + `"><resourcedatagroup><resourcedata path="/a"><cekparams><iv>$$</iv></cekparams></resourcedata></resourcedatagroup></keystore>`},
+		{"syntax", `<keystore`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeKeyStore(strings.NewReader(tt.data)); err == nil {
+				t.Error("DecodeKeyStore() expected error")
+			}
+		})
+	}
+}
+
+func TestEncrypter_Roundtrip(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := EncodePublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, err := EncodePublicKey(&other.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &go3mf.Model{
+		Path: "/3D/3dmodel.model",
+		Attachments: []go3mf.Attachment{
+			{ContentType: "image/png", Path: "/Metadata/thumbnail.png", Stream: bytes.NewBufferString("fake")},
+		},
+		RootRelationships: []go3mf.Relationship{{Path: "/Metadata/thumbnail.png", Type: go3mf.RelTypeThumbnail}},
+		Resources: go3mf.Resources{Objects: []*go3mf.Object{
+			{ID: 1, Mesh: &go3mf.Mesh{
+				Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+				Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+			}},
+		}},
+		Build: go3mf.Build{Items: []*go3mf.Item{{ObjectID: 1}}},
+	}
+	consumers := []Consumer{{ID: "other", KeyValue: otherPub}, {ID: "me", KeyValue: pub}}
+	tests := []struct {
+		name        string
+		compression Compression
+		unwrap      KeyUnwrapFunc
+		wantErr     error
+	}{
+		{"none", CompressionNone, NewRSAKeyUnwrap(priv), nil},
+		{"deflate", CompressionDeflate, NewRSAKeyUnwrap(priv), nil},
+		{"nokey", CompressionNone, nil, ErrNoKey},
+		{"shortKey", CompressionNone, func(*Consumer, *AccessRight) ([]byte, error) { return make([]byte, 16), nil }, ErrInvalidKeyValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.Attachments[0].Stream = bytes.NewBufferString("fake")
+			var buf bytes.Buffer
+			enc := NewEncrypter(consumers, "/3D/3dmodel.model", "/Metadata/thumbnail.png")
+			enc.Compression = tt.compression
+			e := go3mf.NewEncoder(&buf)
+			e.Encrypter = enc
+			if err := e.Encode(m); err != nil {
+				t.Fatalf("Encoder.Encode() error = %v", err)
+			}
+			if diff := deep.Equal(encryptedFiles(t, buf.Bytes()), []string{"/Metadata/thumbnail.png", "/3D/3dmodel.model"}); diff != nil {
+				t.Errorf("Encoder.Encode() encrypted files = %v", diff)
+			}
+			// The ciphertext may not contain any XML markup, so it is not always a decoding error.
+			encrypted := new(go3mf.Model)
+			if err := go3mf.NewDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len())).Decode(encrypted); err == nil && len(encrypted.Resources.Objects) != 0 {
+				t.Error("Decoder.Decode() expected error decoding without Decrypter")
+			}
+			d := go3mf.NewDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			d.Decrypter = NewDecrypter(tt.unwrap)
+			got := new(go3mf.Model)
+			err := d.Decode(got)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decoder.Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			if diff := deep.Equal(got.Resources, m.Resources); diff != nil {
+				t.Errorf("Decoder.Decode() = %v", diff)
+			}
+			var thumbnail []byte
+			for _, a := range got.Attachments {
+				if a.Path == "/Metadata/thumbnail.png" {
+					thumbnail, _ = ioutil.ReadAll(a.Stream)
+				}
+			}
+			if string(thumbnail) != "fake" {
+				t.Errorf("Decoder.Decode() thumbnail = %s, want fake", thumbnail)
+			}
+			RemoveKeyStore(got)
+			if len(got.RootRelationships) != 1 || got.RootRelationships[0].Type != go3mf.RelTypeThumbnail {
+				t.Errorf("RemoveKeyStore() relationships = %v", got.RootRelationships)
+			}
+			if len(got.Attachments) != 1 {
+				t.Errorf("RemoveKeyStore() attachments = %v", got.Attachments)
+			}
+		})
+	}
+}
+
+// encryptedFiles returns the targets of the encrypted file
+// relationships of the key store part in pkg.
+func encryptedFiles(t *testing.T, pkg []byte) []string {
+	t.Helper()
+	r, err := opc.NewReader(bytes.NewReader(pkg), int64(len(pkg)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range r.Files {
+		if !equalPath(f.Name, DefaultKeyStorePath) {
+			continue
+		}
+		for _, rel := range f.Relationships {
+			if rel.Type == RelTypeEncryptedFile {
+				paths = append(paths, rel.TargetURI)
+			}
+		}
+	}
+	return paths
+}
+
+func TestDecrypter_MaxPartSize(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := EncodePublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The repeated vertices compress to a few bytes but inflate past the limit.
+	mesh := &go3mf.Mesh{
+		Vertices:  go3mf.Vertices{Vertex: make([]go3mf.Point3D, 5000)},
+		Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+	}
+	m := &go3mf.Model{
+		Path:      "/3D/3dmodel.model",
+		Resources: go3mf.Resources{Objects: []*go3mf.Object{{ID: 1, Mesh: mesh}}},
+		Build:     go3mf.Build{Items: []*go3mf.Item{{ObjectID: 1}}},
+	}
+	var buf bytes.Buffer
+	enc := NewEncrypter([]Consumer{{ID: "me", KeyValue: pub}}, "/3D/3dmodel.model")
+	enc.Compression = CompressionDeflate
+	e := go3mf.NewEncoder(&buf)
+	e.Encrypter = enc
+	if err := e.Encode(m); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	d := go3mf.NewDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	d.Decrypter = NewDecrypter(NewRSAKeyUnwrap(priv))
+	d.Limits = go3mf.Limits{MaxPartSize: 16 << 10}
+	var limitErr *go3mf.LimitError
+	if err := d.Decode(new(go3mf.Model)); !errors.As(err, &limitErr) || limitErr.Limit != go3mf.LimitPartSize {
+		t.Errorf("Decoder.Decode() error = %v, want part size limit", err)
+	}
+}
+
+func TestDecrypter_ChildModels(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := EncodePublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &go3mf.Model{Path: "/3D/3dmodel.model", Childs: make(map[string]*go3mf.ChildModel)}
+	parts := []string{"/3D/3dmodel.model"}
+	for i := 0; i < 4; i++ {
+		path := fmt.Sprintf("/3D/child%d.model", i)
+		m.Childs[path] = &go3mf.ChildModel{Resources: go3mf.Resources{Objects: []*go3mf.Object{
+			{ID: 1, Mesh: &go3mf.Mesh{
+				Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {float32(i), 0, 0}, {0, 1, 0}}},
+				Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+			}},
+		}}}
+		parts = append(parts, path)
+	}
+	var buf bytes.Buffer
+	e := go3mf.NewEncoder(&buf)
+	e.Encrypter = NewEncrypter([]Consumer{{ID: "me", KeyValue: pub}}, parts...)
+	if err := e.Encode(m); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	d := go3mf.NewDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	d.Strict = false
+	d.Decrypter = NewDecrypter(NewRSAKeyUnwrap(priv))
+	got := new(go3mf.Model)
+	if err := d.Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	for path, child := range m.Childs {
+		if got.Childs[path] == nil {
+			t.Errorf("Decoder.Decode() missing child %s", path)
+			continue
+		}
+		if diff := deep.Equal(got.Childs[path].Resources, child.Resources); diff != nil {
+			t.Errorf("Decoder.Decode() child %s = %v", path, diff)
+		}
+	}
+}
+
+func TestDecrypter_cek(t *testing.T) {
+	key := []byte{1, 2, 3}
+	unwrap := func(consumer *Consumer, ar *AccessRight) ([]byte, error) {
+		if consumer.ID != "me" {
+			return nil, ErrNoKey
+		}
+		return key, nil
+	}
+	tests := []struct {
+		name    string
+		ars     []AccessRight
+		want    []byte
+		wantErr error
+	}{
+		{"first", []AccessRight{{ConsumerIndex: 1}, {ConsumerIndex: 0}}, key, nil},
+		{"skipIndex", []AccessRight{{ConsumerIndex: 5}, {ConsumerIndex: 0}, {ConsumerIndex: 1}}, key, nil},
+		{"badIndex", []AccessRight{{ConsumerIndex: 5}, {ConsumerIndex: 0}}, nil, ErrConsumerIndex},
+		{"nokey", []AccessRight{{ConsumerIndex: 0}}, nil, ErrNoKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecrypter(unwrap)
+			d.KeyStore = &KeyStore{Consumers: []Consumer{{ID: "other"}, {ID: "me"}}}
+			d.ceks = make(map[*ResourceDataGroup][]byte)
+			got, err := d.cek(&ResourceDataGroup{AccessRights: tt.ars})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decrypter.cek() error = %v, want %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Decrypter.cek() = %v", diff)
+			}
+		})
+	}
+}
+
+func Test_oaepHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		ar      *AccessRight
+		wantErr bool
+	}{
+		{"mgf1p", &AccessRight{Wrapping: WrappingRSAOAEPMGF1P, MGF: MGF1SHA256, Digest: DigestSHA1}, false},
+		{"mgf1pSHA256", &AccessRight{Wrapping: WrappingRSAOAEPMGF1P, Digest: DigestSHA256}, true},
+		{"sha256", &AccessRight{Wrapping: WrappingRSAOAEP, MGF: MGF1SHA256, Digest: DigestSHA256}, false},
+		{"mismatch", &AccessRight{Wrapping: WrappingRSAOAEP, MGF: MGF1SHA512, Digest: DigestSHA256}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := oaepHash(tt.ar); (err != nil) != tt.wantErr {
+				t.Errorf("oaepHash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
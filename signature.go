@@ -0,0 +1,691 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	xml3mf "github.com/hpinc/go3mf/internal/xml"
+	"github.com/hpinc/go3mf/uuid"
+	"github.com/qmuntal/opc"
+)
+
+const (
+	// RelTypeSignatureOrigin is the canonical digital signature origin relationship type.
+	RelTypeSignatureOrigin = "http://schemas.openxmlformats.org/package/2006/relationships/digital-signature/origin"
+	// RelTypeSignature is the canonical digital signature relationship type.
+	RelTypeSignature = "http://schemas.openxmlformats.org/package/2006/relationships/digital-signature/signature"
+
+	// DefaultSignatureOriginPath is the recommended digital signature origin part name.
+	DefaultSignatureOriginPath = "/package/services/digital-signature/origin.psdsor"
+	// DefaultSignatureDir is the recommended directory for digital signature parts.
+	DefaultSignatureDir = "/package/services/digital-signature/xml-signature/"
+
+	// ContentTypeSignatureOrigin is the digital signature origin content type.
+	ContentTypeSignatureOrigin = "application/vnd.openxmlformats-package.digital-signature-origin"
+	// ContentTypeSignature is the XML digital signature content type.
+	ContentTypeSignature = "application/vnd.openxmlformats-package.digital-signature-xmlsignature+xml"
+
+	contentTypeRelationships = "application/vnd.openxmlformats-package.relationships+xml"
+	nsRelationships          = "http://schemas.openxmlformats.org/package/2006/relationships"
+	nsDSig                   = "http://www.w3.org/2000/09/xmldsig#"
+	nsOPCDSig                = "http://schemas.openxmlformats.org/package/2006/digital-signature"
+	packageObjectID          = "idPackageObject"
+
+	algC14N                  = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"
+	algRelationshipTransform = "http://schemas.openxmlformats.org/package/2006/RelationshipTransform"
+	algSHA1                  = "http://www.w3.org/2000/09/xmldsig#sha1"
+	algSHA256                = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algSHA512                = "http://www.w3.org/2001/04/xmlenc#sha512"
+	algRSASHA1               = "http://www.w3.org/2000/09/xmldsig#rsa-sha1"
+	algRSASHA256             = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algRSASHA512             = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	algECDSASHA256           = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+	algECDSASHA512           = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512"
+
+	signatureTimeFormat = "YYYY-MM-DDThh:mm:ssTZD"
+)
+
+// ErrSignatureKey is returned when the Signer key is not supported.
+var ErrSignatureKey = errors.New("go3mf: signature key MUST be a RSA or ECDSA private key")
+
+// A Signer defines how to digitally sign a package while encoding it.
+//
+// The package is signed with Key following the OPC digital signature
+// framework and Certificate is embedded in the signature part.
+// Parts contains the names of the parts to sign, together with their relationships,
+// if empty all the parts are signed. The package relationships are always signed.
+// Time is the signing time, if zero the current time is used.
+type Signer struct {
+	Key         crypto.Signer
+	Certificate *x509.Certificate
+	Parts       []string
+	Time        time.Time
+}
+
+func (s *Signer) signs(name string) bool {
+	if len(s.Parts) == 0 {
+		return true
+	}
+	for _, p := range s.Parts {
+		if strings.EqualFold(opc.NormalizePartName(p), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// A Signature is the verification result of a package digital signature.
+//
+// Certificate is the certificate embedded in the signature, it is
+// not verified against any trusted root, which is responsibility of the caller.
+// Valid reports whether the signature value and all the
+// digests of the signed parts and relationships match.
+type Signature struct {
+	Path        string
+	Certificate *x509.Certificate
+	Time        time.Time
+	Valid       bool
+	Parts       []SignedPart
+}
+
+// A SignedPart is a package part, or relationships part, covered by a Signature.
+// Valid reports whether the part digest matches.
+type SignedPart struct {
+	Path        string
+	ContentType string
+	Valid       bool
+}
+
+// VerifySignatures verifies the package digital signatures
+// and returns one result for each signature part.
+// It returns an empty slice if the package is not signed.
+func (d *Decoder) VerifySignatures() ([]Signature, error) {
+	if err := d.p.Open(d.flate, d.Limits); err != nil {
+		return nil, err
+	}
+	var sigs []Signature
+	for _, r := range d.p.Relationships() {
+		if r.Type != RelTypeSignatureOrigin {
+			continue
+		}
+		origin, ok := d.p.FindFileFromName(r.Path)
+		if !ok {
+			return nil, errors.New("go3mf: package signature origin points to an unexisting file")
+		}
+		for _, sr := range origin.Relationships() {
+			if sr.Type != RelTypeSignature {
+				continue
+			}
+			file, ok := origin.FindFileFromName(sr.Path)
+			if !ok {
+				return nil, errors.New("go3mf: package signature points to an unexisting file")
+			}
+			sig, err := d.verifySignature(file)
+			if err != nil {
+				return nil, fmt.Errorf("go3mf: Path: %s: %v", file.Name(), err)
+			}
+			sigs = append(sigs, sig)
+		}
+	}
+	return sigs, nil
+}
+
+type xmlDSigTransform struct {
+	Algorithm  string            `xml:"Algorithm,attr"`
+	SourceIDs  []xmlDSigSourceID `xml:"RelationshipReference"`
+	SourceType []xmlDSigSourceID `xml:"RelationshipsGroupReference"`
+}
+
+type xmlDSigSourceID struct {
+	ID   string `xml:"SourceId,attr"`
+	Type string `xml:"SourceType,attr"`
+}
+
+type xmlDSigReference struct {
+	URI          string             `xml:"URI,attr"`
+	Transforms   []xmlDSigTransform `xml:"Transforms>Transform"`
+	DigestMethod struct {
+		Algorithm string `xml:"Algorithm,attr"`
+	} `xml:"DigestMethod"`
+	DigestValue string `xml:"DigestValue"`
+}
+
+type xmlDSigSignature struct {
+	SignedInfo struct {
+		SignatureMethod struct {
+			Algorithm string `xml:"Algorithm,attr"`
+		} `xml:"SignatureMethod"`
+		References []xmlDSigReference `xml:"Reference"`
+	} `xml:"SignedInfo"`
+	SignatureValue   string   `xml:"SignatureValue"`
+	X509Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+	Objects          []struct {
+		ID         string             `xml:"Id,attr"`
+		References []xmlDSigReference `xml:"Manifest>Reference"`
+		Times      []string           `xml:"SignatureProperties>SignatureProperty>SignatureTime>Value"`
+	} `xml:"Object"`
+}
+
+func (d *Decoder) verifySignature(file packageFile) (Signature, error) {
+	sig := Signature{Path: file.Name()}
+	data, err := readPart(file)
+	if err != nil {
+		return sig, err
+	}
+	var x xmlDSigSignature
+	if err = xml.Unmarshal(data, &x); err != nil {
+		return sig, err
+	}
+	if id, ok := duplicatedID(data); ok {
+		return sig, fmt.Errorf("duplicated signature Id %q", id)
+	}
+	if len(x.X509Certificates) > 0 {
+		der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(x.X509Certificates[0]), ""))
+		if err != nil {
+			return sig, err
+		}
+		if sig.Certificate, err = x509.ParseCertificate(der); err != nil {
+			return sig, err
+		}
+	}
+	signedInfo, err := xml3mf.Canonicalize(data, func(name xml.Name, _ []xml.Attr) bool {
+		return name.Space == nsDSig && name.Local == "SignedInfo"
+	})
+	if err != nil {
+		return sig, err
+	}
+	sig.Valid = sig.Certificate != nil && verifySignatureValue(sig.Certificate.PublicKey, x.SignedInfo.SignatureMethod.Algorithm, signedInfo, x.SignatureValue)
+	// Only the objects whose digest is checked by SignedInfo are covered by the signature.
+	signed := make(map[string]bool)
+	for _, ref := range x.SignedInfo.References {
+		if !strings.HasPrefix(ref.URI, "#") {
+			sig.Valid = false
+			continue
+		}
+		id := ref.URI[1:]
+		obj, err := xml3mf.Canonicalize(data, func(_ xml.Name, attrs []xml.Attr) bool {
+			for _, a := range attrs {
+				if a.Name.Space == "" && a.Name.Local == "Id" && a.Value == id {
+					return true
+				}
+			}
+			return false
+		})
+		if err != nil || !checkDigest(ref.DigestMethod.Algorithm, obj, ref.DigestValue) {
+			sig.Valid = false
+			continue
+		}
+		signed[id] = true
+	}
+	for _, obj := range x.Objects {
+		if !signed[obj.ID] {
+			continue
+		}
+		for _, t := range obj.Times {
+			if tm, err := time.Parse(time.RFC3339, strings.TrimSpace(t)); err == nil {
+				sig.Time = tm
+			}
+		}
+		for _, ref := range obj.References {
+			p := d.verifyReference(ref)
+			sig.Valid = sig.Valid && p.Valid
+			sig.Parts = append(sig.Parts, p)
+		}
+	}
+	return sig, nil
+}
+
+// duplicatedID returns the first Id attribute value
+// used by more than one element in the signature.
+func duplicatedID(data []byte) (string, bool) {
+	ids := make(map[string]bool)
+	x := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		t, err := x.Token()
+		if err != nil {
+			return "", false
+		}
+		if se, ok := t.(xml.StartElement); ok {
+			for _, a := range se.Attr {
+				if a.Name.Space == "" && a.Name.Local == "Id" {
+					if ids[a.Value] {
+						return a.Value, true
+					}
+					ids[a.Value] = true
+				}
+			}
+		}
+	}
+}
+
+func (d *Decoder) verifyReference(ref xmlDSigReference) SignedPart {
+	name, contentType := ref.URI, ""
+	if i := strings.IndexByte(name, '?'); i >= 0 {
+		name, contentType = name[:i], strings.TrimPrefix(name[i+1:], "ContentType=")
+	}
+	if s, err := url.PathUnescape(name); err == nil {
+		name = s
+	}
+	p := SignedPart{Path: name, ContentType: contentType}
+	var relTransform *xmlDSigTransform
+	var c14n bool
+	for i, t := range ref.Transforms {
+		switch t.Algorithm {
+		case algRelationshipTransform:
+			relTransform = &ref.Transforms[i]
+		case algC14N:
+			c14n = true
+		default:
+			return p
+		}
+	}
+	var data []byte
+	if relTransform != nil {
+		rels, ok := d.sourceRelationships(name)
+		if !ok {
+			return p
+		}
+		data = transformRelationships(rels, relTransform)
+	} else {
+		file, ok := d.p.FindFileFromName(name)
+		if !ok || file.ContentType() != contentType {
+			return p
+		}
+		var err error
+		if data, err = readPart(file); err != nil {
+			return p
+		}
+		if c14n {
+			if data, err = xml3mf.Canonicalize(data, func(xml.Name, []xml.Attr) bool { return true }); err != nil {
+				return p
+			}
+		}
+	}
+	p.Valid = checkDigest(ref.DigestMethod.Algorithm, data, ref.DigestValue)
+	return p
+}
+
+// sourceRelationships returns the relationships
+// stored in the relationships part name.
+func (d *Decoder) sourceRelationships(name string) ([]Relationship, bool) {
+	dir, base := path.Split(name)
+	if path.Base(dir) != "_rels" || !strings.HasSuffix(base, ".rels") {
+		return nil, false
+	}
+	source := path.Join(path.Dir(strings.TrimSuffix(dir, "/")), strings.TrimSuffix(base, ".rels"))
+	if source == "/" {
+		return d.p.Relationships(), true
+	}
+	file, ok := d.p.FindFileFromName(source)
+	if !ok {
+		return nil, false
+	}
+	return file.Relationships(), true
+}
+
+// transformRelationships implements the OPC relationship transform
+// followed by the canonicalization of the resulting relationships part.
+func transformRelationships(rels []Relationship, t *xmlDSigTransform) []byte {
+	selected := make([]Relationship, 0, len(rels))
+	for _, r := range rels {
+		for _, s := range t.SourceIDs {
+			if r.ID == s.ID {
+				selected = append(selected, r)
+			}
+		}
+		for _, s := range t.SourceType {
+			if r.Type == s.Type {
+				selected = append(selected, r)
+			}
+		}
+	}
+	return canonicalRelationships(selected)
+}
+
+func canonicalRelationships(rels []Relationship) []byte {
+	sort.Slice(rels, func(i, j int) bool {
+		return rels[i].ID < rels[j].ID
+	})
+	var b bytes.Buffer
+	b.WriteString(`<Relationships xmlns="` + nsRelationships + `">`)
+	for _, r := range rels {
+		mode := "Internal"
+		if u, err := url.Parse(r.Path); err == nil && u.Scheme != "" {
+			mode = "External"
+		}
+		fmt.Fprintf(&b, `<Relationship Id="%s" Target="%s" TargetMode="%s" Type="%s"/>`,
+			escapeAttr(r.ID), escapeAttr(r.Path), mode, escapeAttr(r.Type))
+	}
+	b.WriteString("</Relationships>")
+	c14n, _ := xml3mf.Canonicalize(b.Bytes(), func(xml.Name, []xml.Attr) bool { return true })
+	return c14n
+}
+
+func escapeAttr(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func readPart(file packageFile) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+func newDigestHash(alg string) (hash.Hash, crypto.Hash, bool) {
+	switch alg {
+	case algSHA1, algRSASHA1:
+		return sha1.New(), crypto.SHA1, true
+	case algSHA256, algRSASHA256, algECDSASHA256:
+		return sha256.New(), crypto.SHA256, true
+	case algSHA512, algRSASHA512, algECDSASHA512:
+		return sha512.New(), crypto.SHA512, true
+	}
+	return nil, 0, false
+}
+
+func digest(h hash.Hash, data []byte) []byte {
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func checkDigest(alg string, data []byte, value string) bool {
+	h, _, ok := newDigestHash(alg)
+	if !ok {
+		return false
+	}
+	want, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	return err == nil && bytes.Equal(digest(h, data), want)
+}
+
+func verifySignatureValue(pub interface{}, alg string, signedInfo []byte, value string) bool {
+	h, hashID, ok := newDigestHash(alg)
+	if !ok {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+	if err != nil {
+		return false
+	}
+	hashed := digest(h, signedInfo)
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if alg != algRSASHA1 && alg != algRSASHA256 && alg != algRSASHA512 {
+			return false
+		}
+		return rsa.VerifyPKCS1v15(pub, hashID, hashed, sig) == nil
+	case *ecdsa.PublicKey:
+		if alg != algECDSASHA256 && alg != algECDSASHA512 || len(sig)%2 != 0 {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(sig[:len(sig)/2]), new(big.Int).SetBytes(sig[len(sig)/2:])
+		return ecdsa.Verify(pub, hashed, r, s)
+	}
+	return false
+}
+
+// signingWriter records the digests of the parts and the relationships
+// written to the package, and adds a signature part when closed.
+type signingWriter struct {
+	packageWriter
+	signer *Signer
+	parts  []*signedPart
+	rels   []Relationship
+}
+
+func newSigningWriter(w packageWriter, signer *Signer) *signingWriter {
+	return &signingWriter{packageWriter: w, signer: signer}
+}
+
+func (w *signingWriter) Create(name, contentType string) (packagePart, error) {
+	p, err := w.packageWriter.Create(name, contentType)
+	if err != nil {
+		return nil, err
+	}
+	sp := &signedPart{packagePart: p, name: opc.NormalizePartName(name), contentType: contentType, h: sha256.New()}
+	w.parts = append(w.parts, sp)
+	return sp, nil
+}
+
+func (w *signingWriter) AddRelationship(r Relationship) {
+	var ok bool
+	if w.rels, r, ok = addSignedRelationship(w.rels, r); ok {
+		w.packageWriter.AddRelationship(r)
+	}
+}
+
+func (w *signingWriter) Close() error {
+	if err := w.sign(); err != nil {
+		return err
+	}
+	return w.packageWriter.Close()
+}
+
+// signedPart hashes all the written bytes.
+// The relationships IDs are assigned when added
+// so they can be referenced by the signature.
+type signedPart struct {
+	packagePart
+	name        string
+	contentType string
+	h           hash.Hash
+	rels        []Relationship
+}
+
+func (p *signedPart) Write(b []byte) (int, error) {
+	n, err := p.packagePart.Write(b)
+	p.h.Write(b[:n])
+	return n, err
+}
+
+func (p *signedPart) AddRelationship(r Relationship) {
+	var ok bool
+	if p.rels, r, ok = addSignedRelationship(p.rels, r); ok {
+		p.packagePart.AddRelationship(r)
+	}
+}
+
+func addSignedRelationship(rels []Relationship, r Relationship) ([]Relationship, Relationship, bool) {
+	for _, ro := range rels {
+		if ro.Type == r.Type && ro.Path == r.Path {
+			return rels, r, false
+		}
+	}
+	if r.ID == "" {
+		for i := len(rels) + 1; ; i++ {
+			id := fmt.Sprintf("rId%d", i)
+			var used bool
+			for _, ro := range rels {
+				if ro.ID == id {
+					used = true
+					break
+				}
+			}
+			if !used {
+				r.ID = id
+				break
+			}
+		}
+	}
+	return append(rels, r), r, true
+}
+
+func (w *signingWriter) sign() error {
+	if w.signer.Certificate == nil {
+		return errors.New("go3mf: signature certificate is required")
+	}
+	sigAlg, hashID, err := signatureAlgorithm(w.signer.Key)
+	if err != nil {
+		return err
+	}
+	sigTime := w.signer.Time
+	if sigTime.IsZero() {
+		sigTime = time.Now()
+	}
+
+	var manifest bytes.Buffer
+	writeRelsReference(&manifest, "/_rels/.rels", w.rels)
+	for _, p := range w.parts {
+		if !w.signer.signs(p.name) {
+			continue
+		}
+		writeReference(&manifest, p.name+"?ContentType="+p.contentType, "", p.h.Sum(nil))
+		if len(p.rels) > 0 {
+			dir, base := path.Split(p.name)
+			writeRelsReference(&manifest, dir+"_rels/"+base+".rels", p.rels)
+		}
+	}
+	var object bytes.Buffer
+	object.WriteString(`<Object Id="` + packageObjectID + `"><Manifest>`)
+	object.Write(manifest.Bytes())
+	object.WriteString(`</Manifest><SignatureProperties><SignatureProperty Id="idSignatureTime" Target="#SignatureId">`)
+	object.WriteString(`<mdssi:SignatureTime xmlns:mdssi="` + nsOPCDSig + `"><mdssi:Format>` + signatureTimeFormat + `</mdssi:Format>`)
+	object.WriteString(`<mdssi:Value>` + sigTime.UTC().Format(time.RFC3339) + `</mdssi:Value></mdssi:SignatureTime>`)
+	object.WriteString(`</SignatureProperty></SignatureProperties></Object>`)
+
+	objectC14N, err := canonicalizeSignatureElement(object.Bytes(), "Object")
+	if err != nil {
+		return err
+	}
+	var signedInfo bytes.Buffer
+	signedInfo.WriteString(`<SignedInfo><CanonicalizationMethod Algorithm="` + algC14N + `"></CanonicalizationMethod>`)
+	signedInfo.WriteString(`<SignatureMethod Algorithm="` + sigAlg + `"></SignatureMethod>`)
+	signedInfo.WriteString(`<Reference Type="http://www.w3.org/2000/09/xmldsig#Object" URI="#` + packageObjectID + `">`)
+	writeDigest(&signedInfo, digest(sha256.New(), objectC14N))
+	signedInfo.WriteString(`</Reference></SignedInfo>`)
+	signedInfoC14N, err := canonicalizeSignatureElement(signedInfo.Bytes(), "SignedInfo")
+	if err != nil {
+		return err
+	}
+	h := hashID.New()
+	h.Write(signedInfoC14N)
+	sigValue, err := w.signer.Key.Sign(rand.Reader, h.Sum(nil), hashID)
+	if err != nil {
+		return err
+	}
+	if pub, ok := w.signer.Key.Public().(*ecdsa.PublicKey); ok {
+		if sigValue, err = ecdsaRawSignature(pub, sigValue); err != nil {
+			return err
+		}
+	}
+
+	origin, err := w.packageWriter.Create(DefaultSignatureOriginPath, ContentTypeSignatureOrigin)
+	if err != nil {
+		return err
+	}
+	sigPath := DefaultSignatureDir + uuid.New() + ".psdsxs"
+	origin.AddRelationship(Relationship{Type: RelTypeSignature, Path: sigPath})
+	sig, err := w.packageWriter.Create(sigPath, ContentTypeSignature)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<Signature xmlns="` + nsDSig + `" Id="SignatureId">`)
+	b.Write(signedInfo.Bytes())
+	b.WriteString(`<SignatureValue>` + base64.StdEncoding.EncodeToString(sigValue) + `</SignatureValue>`)
+	b.WriteString(`<KeyInfo><X509Data><X509Certificate>` + base64.StdEncoding.EncodeToString(w.signer.Certificate.Raw) + `</X509Certificate></X509Data></KeyInfo>`)
+	b.Write(object.Bytes())
+	b.WriteString(`</Signature>`)
+	if _, err = sig.Write(b.Bytes()); err != nil {
+		return err
+	}
+	w.packageWriter.AddRelationship(Relationship{Type: RelTypeSignatureOrigin, Path: DefaultSignatureOriginPath})
+	return nil
+}
+
+func signatureAlgorithm(key crypto.Signer) (string, crypto.Hash, error) {
+	if key == nil {
+		return "", 0, ErrSignatureKey
+	}
+	switch key.Public().(type) {
+	case *rsa.PublicKey:
+		return algRSASHA256, crypto.SHA256, nil
+	case *ecdsa.PublicKey:
+		return algECDSASHA256, crypto.SHA256, nil
+	}
+	return "", 0, ErrSignatureKey
+}
+
+// ecdsaRawSignature converts an ASN.1 ECDSA signature
+// to the concatenated r and s format used by XML-DSig.
+func ecdsaRawSignature(pub *ecdsa.PublicKey, sig []byte) ([]byte, error) {
+	var rs struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &rs); err != nil {
+		return nil, err
+	}
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	raw := make([]byte, 2*size)
+	r, s := rs.R.Bytes(), rs.S.Bytes()
+	copy(raw[size-len(r):size], r)
+	copy(raw[2*size-len(s):], s)
+	return raw, nil
+}
+
+func canonicalizeSignatureElement(data []byte, local string) ([]byte, error) {
+	doc := make([]byte, 0, len(data)+64)
+	doc = append(doc, `<Signature xmlns="`+nsDSig+`">`...)
+	doc = append(doc, data...)
+	doc = append(doc, "</Signature>"...)
+	return xml3mf.Canonicalize(doc, func(name xml.Name, _ []xml.Attr) bool {
+		return name.Space == nsDSig && name.Local == local
+	})
+}
+
+func writeReference(w *bytes.Buffer, uri, transforms string, sum []byte) {
+	w.WriteString(`<Reference URI="`)
+	xml.EscapeText(w, []byte(uri))
+	w.WriteString(`">`)
+	w.WriteString(transforms)
+	writeDigest(w, sum)
+	w.WriteString(`</Reference>`)
+}
+
+func writeRelsReference(w *bytes.Buffer, name string, rels []Relationship) {
+	if len(rels) == 0 {
+		return
+	}
+	var t bytes.Buffer
+	t.WriteString(`<Transforms><Transform Algorithm="` + algRelationshipTransform + `">`)
+	for _, r := range rels {
+		t.WriteString(`<mdssi:RelationshipReference xmlns:mdssi="` + nsOPCDSig + `" SourceId="` + escapeAttr(r.ID) + `"></mdssi:RelationshipReference>`)
+	}
+	t.WriteString(`</Transform><Transform Algorithm="` + algC14N + `"></Transform></Transforms>`)
+	sorted := make([]Relationship, len(rels))
+	copy(sorted, rels)
+	writeReference(w, name+"?ContentType="+contentTypeRelationships, t.String(), digest(sha256.New(), canonicalRelationships(sorted)))
+}
+
+func writeDigest(w io.Writer, sum []byte) {
+	io.WriteString(w, `<DigestMethod Algorithm="`+algSHA256+`"></DigestMethod><DigestValue>`)
+	io.WriteString(w, base64.StdEncoding.EncodeToString(sum))
+	io.WriteString(w, `</DigestValue>`)
+}
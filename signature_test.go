@@ -0,0 +1,287 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func newTestSigner(t *testing.T, key crypto.Signer) *Signer {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go3mf"},
+		NotBefore:    time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Signer{Key: key, Certificate: cert, Time: time.Date(2021, 5, 6, 7, 8, 9, 0, time.UTC)}
+}
+
+func newSignatureTestModel() *Model {
+	return &Model{
+		Attachments: []Attachment{
+			{ContentType: "image/png", Path: "/Metadata/thumbnail.png", Stream: bytes.NewBufferString("fake")},
+		},
+		RootRelationships: []Relationship{{Path: "/Metadata/thumbnail.png", Type: RelTypeThumbnail}},
+		Childs: map[string]*ChildModel{"/3D/other.model": {Resources: Resources{Objects: []*Object{
+			{ID: 1, Mesh: &Mesh{
+				Vertices:  Vertices{Vertex: []Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+				Triangles: Triangles{Triangle: []Triangle{{V1: 0, V2: 1, V3: 2}}},
+			}},
+		}}}},
+		Resources: Resources{Objects: []*Object{
+			{ID: 1, Mesh: &Mesh{
+				Vertices:  Vertices{Vertex: []Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+				Triangles: Triangles{Triangle: []Triangle{{V1: 0, V2: 1, V3: 2}}},
+			}},
+		}},
+		Build: Build{Items: []*Item{{ObjectID: 1}}},
+	}
+}
+
+// replacePart returns a copy of the zip package data
+// with the content of name replaced by content.
+func replacePart(t *testing.T, data []byte, name string, content string) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		w, err := zw.Create(f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Name == name {
+			io.WriteString(w, content)
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(w, rc)
+		rc.Close()
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEncoder_Signer(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allParts := []SignedPart{
+		{Path: "/_rels/.rels", ContentType: contentTypeRelationships, Valid: true},
+		{Path: "/Metadata/thumbnail.png", ContentType: "image/png", Valid: true},
+		{Path: "/3D/3dmodel.model", ContentType: ContentType3DModel, Valid: true},
+		{Path: "/3D/_rels/3dmodel.model.rels", ContentType: contentTypeRelationships, Valid: true},
+		{Path: "/3D/other.model", ContentType: ContentType3DModel, Valid: true},
+	}
+	tests := []struct {
+		name   string
+		key    crypto.Signer
+		parts  []string
+		tamper string
+		want   []SignedPart
+	}{
+		{"rsa", rsaKey, nil, "", allParts},
+		{"ecdsa", ecKey, nil, "", allParts},
+		{"parts", rsaKey, []string{"3D/3dmodel.model"}, "", []SignedPart{
+			{Path: "/_rels/.rels", ContentType: contentTypeRelationships, Valid: true},
+			{Path: "/3D/3dmodel.model", ContentType: ContentType3DModel, Valid: true},
+			{Path: "/3D/_rels/3dmodel.model.rels", ContentType: contentTypeRelationships, Valid: true},
+		}},
+		{"tampered", rsaKey, nil, "3D/other.model", []SignedPart{
+			allParts[0], allParts[1], allParts[2], allParts[3],
+			{Path: "/3D/other.model", ContentType: ContentType3DModel, Valid: false},
+		}},
+		{"tamperedRels", rsaKey, nil, "_rels/.rels", []SignedPart{
+			{Path: "/_rels/.rels", ContentType: contentTypeRelationships, Valid: false},
+			allParts[1], allParts[2], allParts[3], allParts[4],
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := newTestSigner(t, tt.key)
+			signer.Parts = tt.parts
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.Signer = signer
+			if err := e.Encode(newSignatureTestModel()); err != nil {
+				t.Fatalf("Encoder.Encode() error = %v", err)
+			}
+			data := buf.Bytes()
+			switch tt.tamper {
+			case "":
+			case "_rels/.rels":
+				data = replacePart(t, data, tt.tamper, `<?xml version="1.0" encoding="UTF-8"?>`+
+					`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`+
+					`<Relationship Id="rId1" Type="`+RelTypeThumbnail+`" Target="/3D/other.model"/>`+
+					`<Relationship Id="rId2" Type="`+RelType3DModel+`" Target="/3D/3dmodel.model"/>`+
+					`<Relationship Id="rId3" Type="`+RelTypeSignatureOrigin+`" Target="`+DefaultSignatureOriginPath+`"/>`+
+					`</Relationships>`)
+			default:
+				data = replacePart(t, data, tt.tamper, "tampered")
+			}
+			d := NewDecoder(bytes.NewReader(data), int64(len(data)))
+			got, err := d.VerifySignatures()
+			if err != nil {
+				t.Fatalf("Decoder.VerifySignatures() error = %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("Decoder.VerifySignatures() = %d signatures, want 1", len(got))
+			}
+			if got[0].Valid != (tt.tamper == "") {
+				t.Errorf("Decoder.VerifySignatures() Valid = %v", got[0].Valid)
+			}
+			if !got[0].Time.Equal(signer.Time) {
+				t.Errorf("Decoder.VerifySignatures() Time = %v, want %v", got[0].Time, signer.Time)
+			}
+			if !got[0].Certificate.Equal(signer.Certificate) {
+				t.Error("Decoder.VerifySignatures() unexpected Certificate")
+			}
+			if diff := deep.Equal(got[0].Parts, tt.want); diff != nil {
+				t.Errorf("Decoder.VerifySignatures() = %v", diff)
+			}
+			if err := d.Decode(new(Model)); err != nil && tt.tamper == "" {
+				t.Errorf("Decoder.Decode() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestDecoder_VerifySignatures_Unsigned(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(newSignatureTestModel()); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	got, err := NewDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len())).VerifySignatures()
+	if err != nil {
+		t.Fatalf("Decoder.VerifySignatures() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Decoder.VerifySignatures() = %v, want empty", got)
+	}
+}
+
+func TestDecoder_VerifySignatures_InjectedObject(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := newTestSigner(t, rsaKey)
+	signer.Parts = []string{"3D/3dmodel.model"}
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.Signer = signer
+	if err := e.Encode(newSignatureTestModel()); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sigName, sigContent string
+	for _, f := range zr.File {
+		if strings.HasPrefix("/"+f.Name, DefaultSignatureDir) {
+			rc, _ := f.Open()
+			b, _ := ioutil.ReadAll(rc)
+			rc.Close()
+			sigName, sigContent = f.Name, string(b)
+		}
+	}
+	// The thumbnail digest is correct but the object listing it is not signed.
+	var thumbnail bytes.Buffer
+	writeReference(&thumbnail, "/Metadata/thumbnail.png?ContentType=image/png", "", digest(sha256.New(), []byte("fake")))
+	signed := []SignedPart{
+		{Path: "/_rels/.rels", ContentType: contentTypeRelationships, Valid: true},
+		{Path: "/3D/3dmodel.model", ContentType: ContentType3DModel, Valid: true},
+		{Path: "/3D/_rels/3dmodel.model.rels", ContentType: contentTypeRelationships, Valid: true},
+	}
+	tests := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{"unsigned", "idInjected", false},
+		{"duplicatedId", packageObjectID, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object := `<Object Id="` + tt.id + `"><Manifest>` + thumbnail.String() + `</Manifest></Object></Signature>`
+			data := replacePart(t, buf.Bytes(), sigName, strings.Replace(sigContent, "</Signature>", object, 1))
+			got, err := NewDecoder(bytes.NewReader(data), int64(len(data))).VerifySignatures()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decoder.VerifySignatures() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != 1 || !got[0].Valid {
+				t.Fatalf("Decoder.VerifySignatures() = %v, want one valid signature", got)
+			}
+			if diff := deep.Equal(got[0].Parts, signed); diff != nil {
+				t.Errorf("Decoder.VerifySignatures() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestEncoder_Signer_Error(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		signer *Signer
+	}{
+		{"nocert", &Signer{Key: rsaKey}},
+		{"nokey", &Signer{Certificate: newTestSigner(t, rsaKey).Certificate}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEncoder(new(bytes.Buffer))
+			e.Signer = tt.signer
+			if err := e.Encode(newSignatureTestModel()); err == nil {
+				t.Error("Encoder.Encode() expected error")
+			}
+		})
+	}
+}
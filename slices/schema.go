@@ -0,0 +1,11 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package slices
+
+//go:generate go run ../internal/cmd/xsdgen -pkg slices -const schema -o schema_gen.go schemas/slice.xsd
+
+// Schema returns the XSD of the Slices extension.
+func (Spec) Schema() []byte {
+	return []byte(schema)
+}
@@ -0,0 +1,109 @@
+// Code generated by xsdgen from schemas/slice.xsd. DO NOT EDIT.
+
+package slices
+
+// schema is the content of schemas/slice.xsd.
+const schema = `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns="http://schemas.microsoft.com/3dmanufacturing/slice/2015/07" xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://schemas.microsoft.com/3dmanufacturing/slice/2015/07" elementFormDefault="unqualified" attributeFormDefault="unqualified" blockDefault="#all">
+	<!-- Complex Types -->
+	<xs:complexType name="CT_SliceStack">
+		<xs:sequence>
+			<xs:element ref="slice" minOccurs="0" maxOccurs="2147483647"/>
+			<xs:element ref="sliceref" minOccurs="0" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="id" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="zbottom" type="ST_Number" default="0"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Slice">
+		<xs:sequence>
+			<xs:element ref="vertices" minOccurs="0"/>
+			<xs:element ref="polygon" minOccurs="0" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="ztop" type="ST_Number" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Vertices">
+		<xs:sequence>
+			<xs:element ref="vertex" minOccurs="0" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Vertex">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="x" type="ST_Number" use="required"/>
+		<xs:attribute name="y" type="ST_Number" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Polygon">
+		<xs:sequence>
+			<xs:element ref="segment" maxOccurs="2147483647"/>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="startv" type="ST_ResourceIndex" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_Segment">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="v2" type="ST_ResourceIndex" use="required"/>
+		<xs:attribute name="p1" type="ST_ResourceIndex"/>
+		<xs:attribute name="p2" type="ST_ResourceIndex"/>
+		<xs:attribute name="pid" type="ST_ResourceID"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<xs:complexType name="CT_SliceRef">
+		<xs:sequence>
+			<xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2147483647"/>
+		</xs:sequence>
+		<xs:attribute name="slicestackid" type="ST_ResourceID" use="required"/>
+		<xs:attribute name="slicepath" type="ST_UriReference" use="required"/>
+		<xs:anyAttribute namespace="##other" processContents="lax"/>
+	</xs:complexType>
+	<!-- Simple Types -->
+	<xs:simpleType name="ST_MeshResolution">
+		<xs:restriction base="xs:string">
+			<xs:enumeration value="fullres"/>
+			<xs:enumeration value="lowres"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_UriReference">
+		<xs:restriction base="xs:anyURI">
+			<xs:pattern value="/.*"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_Number">
+		<xs:restriction base="xs:double">
+			<xs:whiteSpace value="collapse"/>
+			<xs:pattern value="((\-|\+)?(([0-9]+(\.[0-9]+)?)|(\.[0-9]+))((e|E)(\-|\+)?[0-9]+)?)"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_ResourceID">
+		<xs:restriction base="xs:positiveInteger">
+			<xs:maxExclusive value="2147483648"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="ST_ResourceIndex">
+		<xs:restriction base="xs:nonNegativeInteger">
+			<xs:maxExclusive value="2147483648"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<!-- Elements -->
+	<xs:element name="slicestack" type="CT_SliceStack"/>
+	<xs:element name="slice" type="CT_Slice"/>
+	<xs:element name="vertices" type="CT_Vertices"/>
+	<xs:element name="vertex" type="CT_Vertex"/>
+	<xs:element name="polygon" type="CT_Polygon"/>
+	<xs:element name="segment" type="CT_Segment"/>
+	<xs:element name="sliceref" type="CT_SliceRef"/>
+	<!-- Attributes -->
+	<xs:attribute name="slicestackid" type="ST_ResourceID"/>
+	<xs:attribute name="meshresolution" type="ST_MeshResolution"/>
+</xs:schema>
+`
@@ -0,0 +1,81 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package slices
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func TestSpec_Schema(t *testing.T) {
+	newMesh := func() *go3mf.Mesh {
+		return &go3mf.Mesh{
+			Vertices:  go3mf.Vertices{Vertex: []go3mf.Point3D{{0, 0, 0}, {10, 0, 0}, {0, 10, 0}}},
+			Triangles: go3mf.Triangles{Triangle: []go3mf.Triangle{{V1: 0, V2: 1, V3: 2}}},
+		}
+	}
+	tests := []struct {
+		name  string
+		model *go3mf.Model
+		want  []string
+	}{
+		{"valid", &go3mf.Model{Resources: go3mf.Resources{
+			Assets: []go3mf.Asset{
+				&SliceStack{ID: 1, BottomZ: 1, Slices: []Slice{{
+					TopZ:     1.5,
+					Vertices: Vertices{Vertex: []go3mf.Point2D{{1.01, 1.02}, {9.03, 1.04}, {9.05, 9.06}}},
+					Polygons: []Polygon{{StartV: 0, Segments: []Segment{{V2: 1, PID: 10, P1: 1, P2: 2}, {V2: 2}, {V2: 0}}}},
+				}}},
+				&SliceStack{ID: 2, Refs: []SliceRef{{SliceStackID: 10, Path: "/2D/2dmodel.model"}}},
+			},
+			Objects: []*go3mf.Object{{ID: 3, Mesh: newMesh(), AnyAttr: spec.AnyAttr{&ObjectAttr{SliceStackID: 1, MeshResolution: ResolutionLow}}}},
+		}}, nil},
+		{"invalid", &go3mf.Model{Resources: go3mf.Resources{
+			Assets: []go3mf.Asset{
+				&SliceStack{ID: 1, Slices: []Slice{{Polygons: []Polygon{{StartV: 0}}}}},
+				&SliceStack{ID: 2, Refs: []SliceRef{{SliceStackID: 10, Path: "2dmodel.model"}}},
+			},
+		}}, []string{
+			fmt.Sprintf("go3mf: Path: /3D/3dmodel.model Line: 2 XPath: /model/resources/slicestack[0]/slice[0]/polygon[0]: %v: polygon", specerr.ErrSchemaMissingElement),
+			fmt.Sprintf(`go3mf: Path: /3D/3dmodel.model Line: 2 XPath: /model/resources/slicestack[1]/sliceref[0]: %v: slicepath="2dmodel.model"`, specerr.ErrSchemaAttrValue),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.model.Extensions = []go3mf.Extension{DefaultExtension}
+			var buf bytes.Buffer
+			if err := go3mf.NewEncoder(&buf).Encode(tt.model); err != nil {
+				t.Fatalf("go3mf.Encoder.Encode() error = %v", err)
+			}
+			d := go3mf.NewDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			d.ValidateSchema = true
+			var got []string
+			if err := d.Decode(new(go3mf.Model)); err != nil {
+				for _, err := range err.(*specerr.List).Errors {
+					got = append(got, err.Error())
+				}
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("go3mf.Decoder.Decode() = %v", diff)
+			}
+		})
+	}
+}
+
+func Test_schema(t *testing.T) {
+	b, err := ioutil.ReadFile("schemas/slice.xsd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != schema {
+		t.Error("schema differs from schemas/slice.xsd, run go generate")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 
 	"github.com/hpinc/go3mf"
+	specerr "github.com/hpinc/go3mf/errors"
 	"github.com/hpinc/go3mf/spec"
 )
 
@@ -22,6 +23,22 @@ var DefaultExtension = go3mf.Extension{
 
 func init() {
 	spec.Register(Namespace, Spec{})
+	for _, r := range []specerr.Rule{
+		{ID: "slices.model.required-extension", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSliceExtRequired},
+		{ID: "slices.object.non-slicestack", Severity: specerr.SeverityError, Chapter: "2", Err: ErrNonSliceStack},
+		{ID: "slices.slicestack.slices-and-refs", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSlicesAndRefs},
+		{ID: "slices.sliceref.same-part", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSliceRefSamePart},
+		{ID: "slices.sliceref.nested", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSliceRefRef},
+		{ID: "slices.slice.small-ztop", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSliceSmallTopZ},
+		{ID: "slices.slice.non-monotonic", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSliceNoMonotonic},
+		{ID: "slices.slice.insufficient-vertices", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSliceInsufficientVertices},
+		{ID: "slices.slice.insufficient-polygons", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSliceInsufficientPolygons},
+		{ID: "slices.polygon.insufficient-segments", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSliceInsufficientSegments},
+		{ID: "slices.polygon.not-closed", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSlicePolygonNotClosed},
+		{ID: "slices.item.transform", Severity: specerr.SeverityError, Chapter: "2", Err: ErrSliceInvalidTranform},
+	} {
+		specerr.RegisterRule(r)
+	}
 }
 
 type Spec struct{}
@@ -5,6 +5,7 @@ package spec
 
 import (
 	"encoding/xml"
+	"sort"
 	"sync"
 )
 
@@ -40,9 +41,44 @@ func LoadValidator(ns string) (ValidateSpec, bool) {
 	return nil, false
 }
 
+func LoadWarner(ns string) (WarnSpec, bool) {
+	specMu.RLock()
+	ext, ok := specs[ns]
+	specMu.RUnlock()
+	if ok {
+		ext, ok := ext.(WarnSpec)
+		return ext, ok
+	}
+	return nil, false
+}
+
+// Schemas returns the XSD documents of the registered specs
+// that implement SchemaSpec, sorted by namespace.
+func Schemas() [][]byte {
+	specMu.RLock()
+	defer specMu.RUnlock()
+	namespaces := make([]string, 0, len(specs))
+	for ns, ext := range specs {
+		if _, ok := ext.(SchemaSpec); ok {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	schemas := make([][]byte, len(namespaces))
+	for i, ns := range namespaces {
+		schemas[i] = specs[ns].(SchemaSpec).Schema()
+	}
+	return schemas
+}
+
 // Spec is the interface that must be implemented by a 3mf spec.
 //
-// Specs may implement ValidateSpec.
+// Specs may implement ValidateSpec, WarnSpec and SchemaSpec.
+//
+// The attribute groups and the elements returned by a Spec may also implement
+// json.Marshaler and json.Unmarshaler, the elements also having an XMLName method,
+// to be encoded as native JSON by go3mf.Model.MarshalJSON.
+// Otherwise they are encoded as the XML tokens written by Marshal3MF.
 type Spec interface {
 	NewAttrGroup(parent xml.Name) AttrGroup
 	NewElementDecoder(name xml.Name) GetterElementDecoder
@@ -57,6 +93,26 @@ type ValidateSpec interface {
 	Validate(model interface{}, path string, element interface{}) error
 }
 
+// If a Spec implemented WarnSpec, then model.ValidateWarnings will call
+// ValidateWarnings and aggregate the resulting warnings,
+// which are the errors of the rules registered with SeverityWarning.
+//
+// model is guaranteed to be a *go3mf.Model
+type WarnSpec interface {
+	Spec
+	ValidateWarnings(model interface{}, path string, element interface{}) error
+}
+
+// If a Spec implemented SchemaSpec, then the decoder will validate
+// the elements and attributes of its namespace against Schema
+// when schema validation is enabled.
+//
+// Schema must return an XSD document whose target namespace is the spec namespace.
+type SchemaSpec interface {
+	Spec
+	Schema() []byte
+}
+
 // An XMLAttr represents an attribute in an XML element (Name=Value).
 type XMLAttr struct {
 	Name  xml.Name
@@ -77,6 +133,15 @@ type AttrGroup interface {
 	Namespace() string
 }
 
+// PartAttrGroup must be implemented by the object attribute groups that
+// also apply to the parts an object is split into by go3mf.Model.SplitByProperty.
+// PartAttrs returns the attribute groups of a new part and of the component
+// that references it from the split object, any of them can be nil.
+type PartAttrGroup interface {
+	AttrGroup
+	PartAttrs() (object AttrGroup, component AttrGroup)
+}
+
 type PropertyGroup interface {
 	Len() int
 }
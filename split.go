@@ -0,0 +1,144 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"errors"
+
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
+)
+
+// ErrSplitMeshExtension is returned when splitting a mesh that contains
+// extension elements, such as beam lattices, which reference its vertices.
+var ErrSplitMeshExtension = errors.New("go3mf: mesh with extension elements cannot be split")
+
+type propertyKey struct {
+	pid, pindex uint32
+}
+
+// SplitByProperty splits the mesh object id, defined in the model part path,
+// into one mesh object per effective triangle property.
+//
+// The effective property of a triangle is its PID and P1, or the object PID
+// and PIndex if the triangle does not define a PID. Vertices are only duplicated
+// when they are shared by triangles with different properties.
+//
+// The new objects are added to the resources just before the split object,
+// which keeps its ID and is converted into a components object referencing them,
+// so build items and components referencing it are still valid.
+// The object attribute groups implementing spec.PartAttrGroup, such as
+// the production UUID, provide the attributes of the parts and components,
+// the other ones are kept only by the split object.
+// It returns the new objects, or nil if the mesh uses less than two properties.
+func (m *Model) SplitByProperty(path string, id uint32) ([]*Object, error) {
+	rs, ok := m.FindResources(path)
+	if !ok {
+		return nil, specerr.ErrMissingResource
+	}
+	index := -1
+	for i, o := range rs.Objects {
+		if o.ID == id {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return nil, specerr.ErrMissingResource
+	}
+	obj := rs.Objects[index]
+	if obj.Mesh == nil {
+		return nil, nil
+	}
+	if len(obj.Mesh.Any) > 0 {
+		return nil, ErrSplitMeshExtension
+	}
+	var (
+		keys   []propertyKey
+		groups = make(map[propertyKey][]int)
+	)
+	nv := uint32(len(obj.Mesh.Vertices.Vertex))
+	for i, t := range obj.Mesh.Triangles.Triangle {
+		if t.V1 >= nv || t.V2 >= nv || t.V3 >= nv {
+			return nil, specerr.WrapIndex(specerr.ErrIndexOutOfBounds, attrTriangle, i)
+		}
+		key := propertyKey{obj.PID, obj.PIndex}
+		if t.PID != 0 {
+			key = propertyKey{t.PID, t.P1}
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+	if len(keys) < 2 {
+		return nil, nil
+	}
+	objs := make([]*Object, len(keys))
+	components := &Components{Component: make([]*Component, len(keys))}
+	for i, key := range keys {
+		part := &Object{
+			ID:         rs.UnusedID(),
+			Name:       obj.Name,
+			PartNumber: obj.PartNumber,
+			PID:        key.pid,
+			PIndex:     key.pindex,
+			Type:       obj.Type,
+			Mesh:       splitMesh(obj.Mesh, groups[key]),
+		}
+		objs[i] = part
+		components.Component[i] = &Component{ObjectID: part.ID, Transform: Identity()}
+		for _, a := range obj.AnyAttr {
+			if a, ok := a.(spec.PartAttrGroup); ok {
+				objAttr, compAttr := a.PartAttrs()
+				if objAttr != nil {
+					part.AnyAttr = append(part.AnyAttr, objAttr)
+				}
+				if compAttr != nil {
+					components.Component[i].AnyAttr = append(components.Component[i].AnyAttr, compAttr)
+				}
+			}
+		}
+		// Add the object now so UnusedID does not return its ID again.
+		rs.Objects = append(rs.Objects, part)
+	}
+	rs.Objects = rs.Objects[:len(rs.Objects)-len(objs)]
+	newObjects := make([]*Object, 0, len(rs.Objects)+len(objs))
+	newObjects = append(newObjects, rs.Objects[:index]...)
+	newObjects = append(newObjects, objs...)
+	newObjects = append(newObjects, rs.Objects[index:]...)
+	rs.Objects = newObjects
+	obj.Mesh = nil
+	obj.PID, obj.PIndex = 0, 0
+	obj.Components = components
+	return objs, nil
+}
+
+// splitMesh returns a new mesh with the triangles of mesh
+// identified by indices and the vertices they reference.
+func splitMesh(mesh *Mesh, indices []int) *Mesh {
+	var (
+		newMesh = &Mesh{
+			Vertices:  Vertices{AnyAttr: mesh.Vertices.AnyAttr},
+			Triangles: Triangles{Triangle: make([]Triangle, len(indices)), AnyAttr: mesh.Triangles.AnyAttr},
+			AnyAttr:   mesh.AnyAttr,
+		}
+		remap = make(map[uint32]uint32)
+	)
+	vertex := func(v uint32) uint32 {
+		nv, ok := remap[v]
+		if !ok {
+			nv = uint32(len(newMesh.Vertices.Vertex))
+			remap[v] = nv
+			newMesh.Vertices.Vertex = append(newMesh.Vertices.Vertex, mesh.Vertices.Vertex[v])
+		}
+		return nv
+	}
+	for i, index := range indices {
+		t := mesh.Triangles.Triangle[index]
+		t.V1, t.V2, t.V3 = vertex(t.V1), vertex(t.V2), vertex(t.V3)
+		newMesh.Triangles.Triangle[i] = t
+	}
+	return newMesh
+}
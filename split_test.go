@@ -0,0 +1,111 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-test/deep"
+	specerr "github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func newSplitTestModel() *Model {
+	m := &Model{Build: Build{Items: []*Item{{ObjectID: 3}}}}
+	m.Resources.Assets = []Asset{&BaseMaterials{ID: 1, Materials: []Base{{Name: "a"}, {Name: "b"}}}}
+	m.Resources.Objects = []*Object{
+		{ID: 2, Mesh: &Mesh{Vertices: Vertices{Vertex: []Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+			Triangles: Triangles{Triangle: []Triangle{{V1: 0, V2: 1, V3: 2}}}}},
+		{ID: 3, Name: "box", PID: 1, PIndex: 0, Mesh: &Mesh{
+			Vertices: Vertices{Vertex: []Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {2, 2, 0}}},
+			Triangles: Triangles{Triangle: []Triangle{
+				{V1: 0, V2: 1, V3: 2},
+				{V1: 1, V2: 3, V3: 2, PID: 1, P1: 1, P2: 1, P3: 1},
+				{V1: 3, V2: 4, V3: 2, PID: 1, P1: 0, P2: 0, P3: 0},
+			}},
+		}},
+	}
+	return m
+}
+
+func TestModel_SplitByProperty(t *testing.T) {
+	m := newSplitTestModel()
+	got, err := m.SplitByProperty("", 3)
+	if err != nil {
+		t.Fatalf("Model.SplitByProperty() error = %v", err)
+	}
+	want := []*Object{
+		{ID: 4, Name: "box", PID: 1, PIndex: 0, Mesh: &Mesh{
+			Vertices: Vertices{Vertex: []Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {2, 2, 0}}},
+			Triangles: Triangles{Triangle: []Triangle{
+				{V1: 0, V2: 1, V3: 2},
+				{V1: 3, V2: 4, V3: 2, PID: 1, P1: 0, P2: 0, P3: 0},
+			}},
+		}},
+		{ID: 5, Name: "box", PID: 1, PIndex: 1, Mesh: &Mesh{
+			Vertices: Vertices{Vertex: []Point3D{{1, 0, 0}, {1, 1, 0}, {0, 1, 0}}},
+			Triangles: Triangles{Triangle: []Triangle{
+				{V1: 0, V2: 1, V3: 2, PID: 1, P1: 1, P2: 1, P3: 1},
+			}},
+		}},
+	}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("Model.SplitByProperty() = %v", diff)
+	}
+	wantObjects := []uint32{2, 4, 5, 3}
+	var ids []uint32
+	for _, o := range m.Resources.Objects {
+		ids = append(ids, o.ID)
+	}
+	if diff := deep.Equal(ids, wantObjects); diff != nil {
+		t.Errorf("Model.SplitByProperty() objects = %v", diff)
+	}
+	parent := m.Resources.Objects[3]
+	wantParent := &Object{ID: 3, Name: "box", Components: &Components{Component: []*Component{
+		{ObjectID: 4, Transform: Identity()}, {ObjectID: 5, Transform: Identity()},
+	}}}
+	if diff := deep.Equal(parent, wantParent); diff != nil {
+		t.Errorf("Model.SplitByProperty() parent = %v", diff)
+	}
+}
+
+func TestModel_SplitByProperty_Noop(t *testing.T) {
+	m := newSplitTestModel()
+	if got, err := m.SplitByProperty("", 2); got != nil || err != nil {
+		t.Errorf("Model.SplitByProperty() = %v, %v, want nil", got, err)
+	}
+	if _, err := m.SplitByProperty("", 3); err != nil {
+		t.Fatalf("Model.SplitByProperty() error = %v", err)
+	}
+	if got, err := m.SplitByProperty("", 3); got != nil || err != nil {
+		t.Errorf("Model.SplitByProperty() components = %v, %v, want nil", got, err)
+	}
+}
+
+func TestModel_SplitByProperty_Error(t *testing.T) {
+	beams := newSplitTestModel()
+	beams.Resources.Objects[1].Mesh.Any = spec.Any{spec.UnknownTokens{}}
+	outOfBounds := newSplitTestModel()
+	outOfBounds.Resources.Objects[1].Mesh.Triangles.Triangle[2].V3 = 10
+	tests := []struct {
+		name    string
+		m       *Model
+		path    string
+		id      uint32
+		wantErr error
+	}{
+		{"path", newSplitTestModel(), "/other.model", 3, specerr.ErrMissingResource},
+		{"object", newSplitTestModel(), "", 10, specerr.ErrMissingResource},
+		{"extension", beams, "", 3, ErrSplitMeshExtension},
+		{"outOfBounds", outOfBounds, "", 3, specerr.ErrIndexOutOfBounds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.m.SplitByProperty(tt.path, tt.id); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Model.SplitByProperty() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
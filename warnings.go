@@ -0,0 +1,185 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
+)
+
+// WarningOptions configures ValidateWarnings.
+type WarningOptions struct {
+	// BuildVolume is the printable volume of the target device.
+	// Items are not checked against it if it is empty.
+	BuildVolume Box
+}
+
+// ValidateWarnings checks that the model follows the recommendations (SHOULD)
+// of the 3MF specs, such as the part locations, the thumbnail formats,
+// the metadata values and the build volume.
+//
+// The returned errors are reported with errors.SeverityWarning
+// and they do not make the model invalid.
+func (m *Model) ValidateWarnings(opts WarningOptions) error {
+	var errs error
+	rootPath := m.PathOrDefault()
+	if !strings.HasPrefix(rootPath, "/3D/") {
+		errs = errors.Append(errs, errors.ErrModelLocation)
+	}
+	errs = errors.Append(errs, warnRelationships(m, m.RootRelationships, true))
+	errs = errors.Append(errs, warnRelationships(m, m.Relationships, false))
+	if m.Thumbnail != "" && !isImagePart(m, m.Thumbnail) {
+		errs = errors.Append(errs, errors.Wrap(errors.ErrThumbnailFormat, attrThumbnail))
+	}
+	errs = errors.Append(errs, warnMetadata(m.Metadata))
+
+	for _, ext := range m.Extensions {
+		if ext, ok := spec.LoadWarner(ext.Namespace); ok {
+			errs = errors.Append(errs, ext.ValidateWarnings(m, rootPath, m))
+		}
+	}
+
+	for _, path := range m.sortedChilds() {
+		var cErrs error
+		if !strings.HasPrefix(path, "/3D/") {
+			cErrs = errors.Append(cErrs, errors.ErrModelLocation)
+		}
+		c := m.Childs[path]
+		cErrs = errors.Append(cErrs, warnRelationships(m, c.Relationships, false))
+		cErrs = errors.Append(cErrs, c.Resources.validateWarnings(m, path))
+		if cErrs != nil {
+			errs = errors.Append(errs, errors.WrapPath(cErrs, attrResources, path))
+		}
+	}
+	if err := m.Resources.validateWarnings(m, rootPath); err != nil {
+		errs = errors.Append(errs, errors.Wrap(err, attrResources))
+	}
+	for i, item := range m.Build.Items {
+		iErrs := warnMetadata(item.Metadata.Metadata)
+		if opts.BuildVolume != emptyBox {
+			if o, ok := m.FindObject(item.ObjectPath(), item.ObjectID); ok {
+				box := o.boundingBox(m, item.ObjectPath())
+				if box != emptyBox && !boxContains(opts.BuildVolume, item.Transform.MulBox(box)) {
+					iErrs = errors.Append(iErrs, errors.ErrItemOutsideVolume)
+				}
+			}
+		}
+		if iErrs != nil {
+			errs = errors.Append(errs, errors.Wrap(errors.WrapIndex(iErrs, attrItem, i), attrBuild))
+		}
+	}
+	if errs != nil {
+		return errors.Wrap(errs, attrModel)
+	}
+	return nil
+}
+
+func (res *Resources) validateWarnings(m *Model, path string) error {
+	var errs error
+	for i, r := range res.Assets {
+		var aErrs error
+		for _, ext := range m.Extensions {
+			if ext, ok := spec.LoadWarner(ext.Namespace); ok {
+				aErrs = errors.Append(aErrs, ext.ValidateWarnings(m, path, r))
+			}
+		}
+		errs = errors.Append(errs, errors.WrapIndex(aErrs, r.XMLName().Local, i))
+	}
+	for i, r := range res.Objects {
+		var oErrs error
+		if r.Thumbnail != "" && !isImagePart(m, r.Thumbnail) {
+			oErrs = errors.Append(oErrs, errors.ErrThumbnailFormat)
+		}
+		oErrs = errors.Append(oErrs, warnMetadata(r.Metadata.Metadata))
+		for _, ext := range m.Extensions {
+			if ext, ok := spec.LoadWarner(ext.Namespace); ok {
+				oErrs = errors.Append(oErrs, ext.ValidateWarnings(m, path, r))
+			}
+		}
+		errs = errors.Append(errs, errors.WrapIndex(oErrs, attrObject, i))
+	}
+	return errs
+}
+
+func warnRelationships(m *Model, rels []Relationship, root bool) error {
+	var errs error
+	for i, r := range rels {
+		var rErrs error
+		switch r.Type {
+		case RelTypeThumbnail:
+			if root && !strings.HasPrefix(r.Path, DefaultMetadataDir) {
+				rErrs = errors.Append(rErrs, errors.ErrThumbnailLocation)
+			}
+			if !isImagePart(m, r.Path) {
+				rErrs = errors.Append(rErrs, errors.ErrThumbnailFormat)
+			}
+		case RelTypePrintTicket:
+			if !strings.EqualFold(r.Path, DefaultPrintTicketName) {
+				rErrs = errors.Append(rErrs, errors.ErrPrintTicketLocation)
+			}
+		}
+		errs = errors.Append(errs, errors.WrapIndex(rErrs, "relationship", i))
+	}
+	return errs
+}
+
+// isImagePart reports whether the part is a PNG or JPEG image,
+// using the content type of the attachment or else the extension of the part name.
+func isImagePart(m *Model, name string) bool {
+	if a, ok := findAttachment(m.Attachments, name); ok && a.ContentType != "" {
+		switch strings.ToLower(a.ContentType) {
+		case "image/png", "image/jpeg":
+			return true
+		}
+		return false
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg":
+		return true
+	}
+	return false
+}
+
+var metadataDateLayouts = [...]string{"2006-01-02", "2006-01-02T15:04:05", time.RFC3339}
+
+func warnMetadata(md []Metadata) error {
+	var errs error
+	for i, m := range md {
+		if m.Type != "" && !strings.HasPrefix(m.Type, "xs:") {
+			errs = errors.Append(errs, errors.WrapIndex(errors.ErrMetadataType, attrMetadata, i))
+		}
+		if m.Name.Space != "" {
+			continue
+		}
+		switch strings.ToLower(m.Name.Local) {
+		case "creationdate", "modificationdate":
+			if !isISO8601(m.Value) {
+				errs = errors.Append(errs, errors.WrapIndex(errors.ErrMetadataDate, attrMetadata, i))
+			}
+		}
+	}
+	return errs
+}
+
+func isISO8601(s string) bool {
+	for _, layout := range metadataDateLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func boxContains(b, v Box) bool {
+	for i := 0; i < 3; i++ {
+		if v.Min[i] < b.Min[i] || v.Max[i] > b.Max[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+// © Copyright 2021 HP Development Company, L.P.
+// SPDX-License Identifier: BSD-2-Clause
+
+package go3mf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hpinc/go3mf/errors"
+	"github.com/hpinc/go3mf/spec"
+)
+
+func TestModel_ValidateWarnings(t *testing.T) {
+	cube := &Mesh{Vertices: Vertices{Vertex: []Point3D{{0, 0, 0}, {10, 10, 10}}}}
+	tests := []struct {
+		name  string
+		model *Model
+		opts  WarningOptions
+		want  []string
+	}{
+		{"empty", new(Model), WarningOptions{}, nil},
+		{"location", &Model{Path: "/model.model", Childs: map[string]*ChildModel{"/other.model": {}, "/3D/other.model": {}}}, WarningOptions{}, []string{
+			fmt.Sprintf("go3mf: XPath: /model: %v", errors.ErrModelLocation),
+			fmt.Sprintf("go3mf: Path: /other.model XPath: /model/resources: %v", errors.ErrModelLocation),
+		}},
+		{"rels", &Model{
+			Thumbnail:   "/Metadata/a.bmp",
+			Attachments: []Attachment{{Path: "/Metadata/a.png", ContentType: "image/png"}, {Path: "/a.jpg", ContentType: "image/gif"}},
+			RootRelationships: []Relationship{
+				{Path: "/Metadata/a.png", Type: RelTypeThumbnail}, {Path: "/a.jpg", Type: RelTypeThumbnail},
+			},
+			Relationships: []Relationship{
+				{Path: "/a.jpg", Type: RelTypeThumbnail}, {Path: "/b.jpeg", Type: RelTypeThumbnail},
+				{Path: DefaultPrintTicketName, Type: RelTypePrintTicket}, {Path: "/ticket.xml", Type: RelTypePrintTicket},
+			},
+		}, WarningOptions{}, []string{
+			fmt.Sprintf("go3mf: XPath: /model/relationship[1]: %v", errors.ErrThumbnailLocation),
+			fmt.Sprintf("go3mf: XPath: /model/relationship[1]: %v", errors.ErrThumbnailFormat),
+			fmt.Sprintf("go3mf: XPath: /model/relationship[0]: %v", errors.ErrThumbnailFormat),
+			fmt.Sprintf("go3mf: XPath: /model/relationship[3]: %v", errors.ErrPrintTicketLocation),
+			fmt.Sprintf("go3mf: XPath: /model/thumbnail: %v", errors.ErrThumbnailFormat),
+		}},
+		{"metadata", &Model{Metadata: []Metadata{
+			{Name: xml.Name{Local: "CreationDate"}, Value: "2021-01-02"},
+			{Name: xml.Name{Local: "ModificationDate"}, Value: "2021-01-02T10:00:00Z"},
+			{Name: xml.Name{Local: "CreationDate"}, Value: "01/02/2021"},
+			{Name: xml.Name{Local: "Title"}, Type: "string"},
+			{Name: xml.Name{Space: "qm", Local: "CreationDate"}, Value: "foo", Type: "xs:string"},
+		}}, WarningOptions{}, []string{
+			fmt.Sprintf("go3mf: XPath: /model/metadata[2]: %v", errors.ErrMetadataDate),
+			fmt.Sprintf("go3mf: XPath: /model/metadata[3]: %v", errors.ErrMetadataType),
+		}},
+		{"objects", &Model{Resources: Resources{Objects: []*Object{
+			{ID: 1, Thumbnail: "/3D/a.png", Mesh: cube},
+			{ID: 2, Thumbnail: "/3D/a.tiff", Mesh: cube, Metadata: MetadataGroup{Metadata: []Metadata{{Name: xml.Name{Local: "Title"}, Type: "string"}}}},
+		}}}, WarningOptions{}, []string{
+			fmt.Sprintf("go3mf: XPath: /model/resources/object[1]: %v", errors.ErrThumbnailFormat),
+			fmt.Sprintf("go3mf: XPath: /model/resources/object[1]/metadata[0]: %v", errors.ErrMetadataType),
+		}},
+		{"volume", &Model{Resources: Resources{Objects: []*Object{{ID: 1, Mesh: cube}}}, Build: Build{Items: []*Item{
+			{ObjectID: 1},
+			{ObjectID: 1, Transform: Identity().Translate(95, 0, 0)},
+			{ObjectID: 2},
+			{ObjectID: 1, Transform: Matrix{0.7071068, 0.7071068, 0, 0, -0.7071068, 0.7071068, 0, 0, 0, 0, 1, 0, 95, 0, 0, 1}},
+		}}}, WarningOptions{BuildVolume: Box{Max: Point3D{100, 100, 100}}}, []string{
+			fmt.Sprintf("go3mf: XPath: /model/build/item[1]: %v", errors.ErrItemOutsideVolume),
+			fmt.Sprintf("go3mf: XPath: /model/build/item[3]: %v", errors.ErrItemOutsideVolume),
+		}},
+		{"noVolume", &Model{Resources: Resources{Objects: []*Object{{ID: 1, Mesh: cube}}}, Build: Build{Items: []*Item{
+			{ObjectID: 1, Transform: Identity().Translate(95, 0, 0)},
+		}}}, WarningOptions{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.model.ValidateWarnings(tt.opts)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("Model.ValidateWarnings() err = %v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("Model.ValidateWarnings() err nil = want %v", tt.want)
+			}
+			var errs []string
+			for _, err := range got.(*errors.List).Errors {
+				errs = append(errs, err.Error())
+				if r, _ := errors.RuleOf(err); r.Severity != errors.SeverityWarning {
+					t.Errorf("Model.ValidateWarnings() severity = %v, want warning", r.Severity)
+				}
+			}
+			if diff := deep.Equal(errs, tt.want); diff != nil {
+				t.Errorf("Model.ValidateWarnings() = %v", diff)
+			}
+		})
+	}
+}
+
+type pathWarner struct {
+	paths []string
+}
+
+func (*pathWarner) NewAttrGroup(xml.Name) spec.AttrGroup { return nil }
+
+func (*pathWarner) NewElementDecoder(xml.Name) spec.GetterElementDecoder { return nil }
+
+func (w *pathWarner) ValidateWarnings(_ interface{}, path string, element interface{}) error {
+	if _, ok := element.(*Model); ok {
+		w.paths = append(w.paths, path)
+	}
+	return nil
+}
+
+func TestModel_ValidateWarnings_rootPath(t *testing.T) {
+	w := new(pathWarner)
+	spec.Register("http://dummy.com/warner", w)
+	m := &Model{Extensions: []Extension{{Namespace: "http://dummy.com/warner", LocalName: "w"}}}
+	m.ValidateWarnings(WarningOptions{})
+	m.Path = "/3D/other.model"
+	m.ValidateWarnings(WarningOptions{})
+	if diff := deep.Equal(w.paths, []string{DefaultModelPath, "/3D/other.model"}); diff != nil {
+		t.Errorf("Model.ValidateWarnings() paths = %v", diff)
+	}
+}